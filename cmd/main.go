@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/db"
+	"github.com/iyiola-dev/numeris/internal/mailer"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
 	"github.com/iyiola-dev/numeris/internal/routes"
+	"github.com/iyiola-dev/numeris/internal/scheduler"
+	"github.com/iyiola-dev/numeris/internal/service"
 	"github.com/joho/godotenv"
 )
 
@@ -28,26 +35,123 @@ func main() {
 	// AutoMigrate models
 	err := db.DB.AutoMigrate(
 		&models.User{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.UserToken{},
 		&models.Customer{},
 		&models.Invoice{},
 		&models.InvoiceItem{},
+		&models.TaxRate{},
+		&models.InvoiceItemTax{},
+		&models.InvoiceTax{},
+		&models.InvoiceRevision{},
+		&models.InvoiceSequence{},
+		&models.InvoiceTemplateSettings{},
+		&models.ShareLink{},
+		&models.RecurringInvoice{},
+		&models.RecurringInvoiceItem{},
+		&models.RecurringInvoiceRun{},
+		&models.ReminderSettings{},
+		&models.InvoiceReminder{},
+		&models.InvoiceDelivery{},
+		&models.LateFeePolicy{},
+		&models.LateFee{},
+		&models.CreditNoteSequence{},
+		&models.CreditNote{},
+		&models.CreditNoteItem{},
+		&models.QuoteSequence{},
+		&models.Quote{},
+		&models.QuoteItem{},
 		&models.ActivityLog{},
 		&models.PaymentDetails{},
+		&models.Payment{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
+	// Drafts have no number until they are issued, which the old index on
+	// every invoice number would reject
+	if db.DB.Migrator().HasIndex(&models.Invoice{}, "idx_invoices_user_number") {
+		if err := db.DB.Migrator().DropIndex(&models.Invoice{}, "idx_invoices_user_number"); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	}
 	log.Println("Migrations completed successfully!")
 
-	// Initialize router
-	router := routes.SetupRouter()
-
-	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	tokenConfig, err := auth.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid token configuration: %v", err)
+	}
+	tokens, err := auth.NewTokens(tokenConfig)
+	if err != nil {
+		log.Fatalf("Invalid token configuration: %v", err)
+	}
 
+	// Initialize dependencies
+	repo := repository.NewRepository()
+	opts := []service.Option{service.WithPublicURL(publicURL), service.WithTokens(tokens)}
+	if config, ok := mailer.SMTPConfigFromEnv(); ok {
+		opts = append(opts, service.WithMailer(mailer.NewSMTPSender(config)))
+	} else {
+		log.Println("SMTP_HOST is not set, emails will not be sent")
+	}
+	if os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true" {
+		opts = append(opts, service.WithEmailVerificationRequired())
+	}
+	svc := service.NewService(repo, opts...)
+
+	// Start background jobs
+	interval := time.Minute
+	if value := os.Getenv("SCHEDULER_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid SCHEDULER_INTERVAL %q", value)
+		}
+	}
+	jobs := scheduler.New(interval,
+		scheduler.JobFunc("recurring invoices", func(ctx context.Context, now time.Time) error {
+			generated, err := svc.GenerateRecurringInvoices(now)
+			if generated > 0 {
+				log.Printf("Generated %d recurring invoices", generated)
+			}
+			return err
+		}),
+		scheduler.JobFunc("expired quotes", func(ctx context.Context, now time.Time) error {
+			_, err := svc.ExpireQuotes(now)
+			return err
+		}),
+		scheduler.JobFunc("overdue invoices", func(ctx context.Context, now time.Time) error {
+			_, err := svc.MarkOverdueInvoices(now)
+			return err
+		}),
+		scheduler.JobFunc("late fees", func(ctx context.Context, now time.Time) error {
+			charged, err := svc.ChargeLateFees(now)
+			if charged > 0 {
+				log.Printf("Charged %d late fees", charged)
+			}
+			return err
+		}),
+		scheduler.JobFunc("payment reminders", func(ctx context.Context, now time.Time) error {
+			_, err := svc.SendInvoiceReminders(now)
+			return err
+		}),
+	)
+	jobs.Start(context.Background())
+	defer jobs.Stop()
+
+	// Initialize router
+	router := routes.SetupRouter(repo, svc, tokens)
+
+	// Start server
 	log.Printf("Server starting on port %s...\n", port)
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
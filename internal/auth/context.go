@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx that carries principal.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal ctx carries. ok is false for requests
+// that were not authenticated.
+func FromContext(ctx context.Context) (principal Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,27 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	_, ok := auth.FromContext(context.Background())
+	assert.False(t, ok)
+
+	principal := auth.Principal{
+		UserID:     uuid.New(),
+		Roles:      []auth.Role{auth.RoleOwner},
+		TokenID:    "token-id",
+		AuthMethod: auth.MethodJWT,
+	}
+	got, ok := auth.FromContext(auth.NewContext(context.Background(), principal))
+
+	assert.True(t, ok)
+	assert.Equal(t, principal, got)
+	assert.True(t, got.HasRole(auth.RoleOwner))
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms tokens can be signed with
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minSecretLength is the shortest HS256 secret accepted, as long as the hash
+const minSecretLength = 32
+
+// Key signs or verifies tokens. A key without its private half only verifies,
+// which is how a retired key keeps its tokens valid until they expire.
+type Key struct {
+	ID        string
+	Algorithm string
+	// Secret is the shared key of an HS256 key
+	Secret     []byte
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+func (k Key) canSign() bool {
+	if k.Algorithm == AlgHS256 {
+		return len(k.Secret) > 0
+	}
+	return k.PrivateKey != nil
+}
+
+// signingKey is what jwt signs with for the key's algorithm
+func (k Key) signingKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.PrivateKey
+}
+
+// verificationKey is what jwt verifies with for the key's algorithm
+func (k Key) verificationKey() interface{} {
+	if k.Algorithm == AlgHS256 {
+		return k.Secret
+	}
+	return k.PublicKey
+}
+
+func (k Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// ParseKey reads a key for algorithm from data: the secret of an HS256 key,
+// or a PEM encoded private or public key for the others.
+func ParseKey(id, algorithm string, data []byte) (Key, error) {
+	key := Key{ID: id, Algorithm: algorithm}
+	if id == "" {
+		return key, errors.New("key id is required")
+	}
+
+	var err error
+	switch algorithm {
+	case AlgHS256:
+		// Secret files usually end in a newline that is not part of the secret
+		key.Secret = bytes.TrimSpace(data)
+		if len(key.Secret) < minSecretLength {
+			return key, fmt.Errorf("key %s: HS256 secrets must be at least %d bytes", id, minSecretLength)
+		}
+	case AlgRS256:
+		var private *rsa.PrivateKey
+		if private, err = jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.PrivateKey, key.PublicKey = private, &private.PublicKey
+		} else {
+			key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		}
+	case AlgES256:
+		var public *ecdsa.PublicKey
+		if private, privateErr := jwt.ParseECPrivateKeyFromPEM(data); privateErr == nil {
+			key.PrivateKey, public = private, &private.PublicKey
+		} else if public, err = jwt.ParseECPublicKeyFromPEM(data); err != nil {
+			break
+		}
+		if public.Curve != elliptic.P256() {
+			return key, fmt.Errorf("key %s: ES256 keys must use the P-256 curve", id)
+		}
+		key.PublicKey = public
+	case AlgEdDSA:
+		var private crypto.PrivateKey
+		if private, err = jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.PrivateKey = private
+			key.PublicKey = private.(ed25519.PrivateKey).Public()
+		} else {
+			key.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+		}
+	default:
+		return key, fmt.Errorf("key %s: unsupported algorithm %q", id, algorithm)
+	}
+	if err != nil {
+		return key, fmt.Errorf("key %s: %w", id, err)
+	}
+	return key, nil
+}
+
+// Config is how access tokens are signed and what they must carry.
+type Config struct {
+	Issuer   string
+	Audience string
+	// TTL is how long access tokens last, RefreshTTL how long a session
+	// lasts without logging in again
+	TTL        time.Duration
+	RefreshTTL time.Duration
+	// SigningKeyID is the key new tokens are signed with. The other keys
+	// only verify.
+	SigningKeyID string
+	Keys         []Key
+}
+
+// ConfigFromEnv reads JWT_ISSUER (default numeris), JWT_AUDIENCE (default
+// numeris-api), JWT_TTL (default 15m), JWT_REFRESH_TTL (default 720h) and the
+// keys. JWT_KEYS lists them as
+// comma separated kid:algorithm:file entries, the file holding an HS256
+// secret or a PEM key; give a retired key's public key so its tokens still
+// verify. JWT_SIGNING_KEY names the key to sign with, by default the first.
+// Without JWT_KEYS, JWT_SECRET sets a single HS256 key.
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		Issuer:       os.Getenv("JWT_ISSUER"),
+		Audience:     os.Getenv("JWT_AUDIENCE"),
+		TTL:          15 * time.Minute,
+		RefreshTTL:   30 * 24 * time.Hour,
+		SigningKeyID: os.Getenv("JWT_SIGNING_KEY"),
+	}
+	if config.Issuer == "" {
+		config.Issuer = "numeris"
+	}
+	if config.Audience == "" {
+		config.Audience = "numeris-api"
+	}
+	if value := os.Getenv("JWT_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return config, fmt.Errorf("invalid JWT_TTL %q", value)
+		}
+		config.TTL = ttl
+	}
+	if value := os.Getenv("JWT_REFRESH_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return config, fmt.Errorf("invalid JWT_REFRESH_TTL %q", value)
+		}
+		config.RefreshTTL = ttl
+	}
+
+	entries := os.Getenv("JWT_KEYS")
+	if entries == "" {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return config, errors.New("JWT_KEYS or JWT_SECRET must be set")
+		}
+		key, err := ParseKey("default", AlgHS256, []byte(secret))
+		if err != nil {
+			return config, err
+		}
+		config.Keys = []Key{key}
+	}
+	for _, entry := range strings.Split(entries, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return config, fmt.Errorf("invalid JWT_KEYS entry %q, want kid:algorithm:file", entry)
+		}
+		data, err := os.ReadFile(parts[2])
+		if err != nil {
+			return config, fmt.Errorf("key %s: %w", parts[0], err)
+		}
+		key, err := ParseKey(parts[0], parts[1], data)
+		if err != nil {
+			return config, err
+		}
+		config.Keys = append(config.Keys, key)
+	}
+
+	if config.SigningKeyID == "" && len(config.Keys) > 0 {
+		config.SigningKeyID = config.Keys[0].ID
+	}
+	return config, nil
+}
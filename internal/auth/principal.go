@@ -0,0 +1,47 @@
+package auth
+
+import "github.com/google/uuid"
+
+// Method is how a principal proved who they are.
+type Method string
+
+const (
+	MethodJWT Method = "jwt"
+)
+
+// Role is what a principal may do within their organisation.
+type Role string
+
+const (
+	RoleOwner Role = "owner"
+)
+
+// Principal is the authenticated user a service call acts for. Everything a
+// principal reads or changes is scoped to what that user owns.
+type Principal struct {
+	UserID uuid.UUID
+	// OrgID is the organisation the user acts in. Users do not share
+	// organisations yet, so each one is an organisation of their own.
+	OrgID uuid.UUID
+	Roles []Role
+	// TokenID identifies the token the request was authenticated with and
+	// SessionID the login it belongs to
+	TokenID    string
+	SessionID  uuid.UUID
+	AuthMethod Method
+
+	// Where the request came from, kept with what the principal does
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// HasRole reports whether the principal holds role.
+func (p Principal) HasRole(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// leeway absorbs clock drift between us and other services checking expiry
+const leeway = 30 * time.Second
+
+// Claims are what an access token says. SessionID ties the token to the
+// login it was issued for, so revoking the session revokes the token.
+type Claims struct {
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Tokens issues and verifies the API's access tokens.
+type Tokens struct {
+	config  Config
+	keys    map[string]Key
+	signing Key
+	now     func() time.Time
+}
+
+// NewTokens checks config and returns tokens signed with its signing key.
+func NewTokens(config Config) (*Tokens, error) {
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, errors.New("token issuer and audience are required")
+	}
+	if config.TTL <= 0 || config.RefreshTTL <= 0 {
+		return nil, errors.New("token lifetimes must be positive")
+	}
+
+	t := &Tokens{config: config, keys: make(map[string]Key), now: time.Now}
+	for _, key := range config.Keys {
+		if _, ok := t.keys[key.ID]; ok {
+			return nil, fmt.Errorf("key %s is configured twice", key.ID)
+		}
+		if key.method() == nil {
+			return nil, fmt.Errorf("key %s: unsupported algorithm %q", key.ID, key.Algorithm)
+		}
+		t.keys[key.ID] = key
+	}
+
+	signing, ok := t.keys[config.SigningKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", config.SigningKeyID)
+	}
+	if !signing.canSign() {
+		return nil, fmt.Errorf("signing key %s has no private key", signing.ID)
+	}
+	t.signing = signing
+	return t, nil
+}
+
+// RefreshTTL is how long a session lasts without logging in again.
+func (t *Tokens) RefreshTTL() time.Duration {
+	return t.config.RefreshTTL
+}
+
+// Issue returns a signed access token for userID in session sessionID and
+// when it expires.
+func (t *Tokens) Issue(userID, sessionID uuid.UUID) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.config.TTL)
+	claims := Claims{
+		SessionID: sessionID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
+			Issuer:    t.config.Issuer,
+			Audience:  jwt.ClaimStrings{t.config.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(t.signing.method(), claims)
+	token.Header["kid"] = t.signing.ID
+	signed, err := token.SignedString(t.signing.signingKey())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks a token's signature, issuer, audience and lifetime and returns
+// its claims. Tokens are checked with the key their kid names, so tokens
+// signed before a rotation stay valid while their key is still configured.
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithIssuer(t.config.Issuer),
+		jwt.WithAudience(t.config.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+		jwt.WithTimeFunc(t.now),
+	)
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := t.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		// The key decides the algorithm, never the token
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("key %s does not sign with %s", kid, token.Method.Alg())
+		}
+		return key.verificationKey(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,291 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func privatePEM(t *testing.T, key crypto.PrivateKey) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// testKeys returns a freshly generated private key PEM for each algorithm.
+func testKeys(t *testing.T) map[string][]byte {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return map[string][]byte{
+		auth.AlgHS256: testSecret,
+		auth.AlgRS256: privatePEM(t, rsaKey),
+		auth.AlgES256: privatePEM(t, ecKey),
+		auth.AlgEdDSA: privatePEM(t, edKey),
+	}
+}
+
+func newTokens(t *testing.T, keys ...auth.Key) *auth.Tokens {
+	tokens, err := auth.NewTokens(auth.Config{
+		Issuer:       "numeris",
+		Audience:     "numeris-api",
+		TTL:          time.Hour,
+		RefreshTTL:   24 * time.Hour,
+		SigningKeyID: keys[0].ID,
+		Keys:         keys,
+	})
+	require.NoError(t, err)
+	return tokens
+}
+
+func TestTokens_Algorithms(t *testing.T) {
+	for algorithm, data := range testKeys(t) {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := auth.ParseKey("key-1", algorithm, data)
+			require.NoError(t, err)
+			tokens := newTokens(t, key)
+
+			userID, sessionID := uuid.New(), uuid.New()
+			token, expiresAt, err := tokens.Issue(userID, sessionID)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+			claims, err := tokens.Verify(token)
+			require.NoError(t, err)
+			assert.Equal(t, userID.String(), claims.Subject)
+			assert.Equal(t, sessionID.String(), claims.SessionID)
+			assert.NotEmpty(t, claims.ID)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, "key-1", parsed.Header["kid"])
+			assert.Equal(t, algorithm, parsed.Header["alg"])
+		})
+	}
+}
+
+func TestTokens_Rotation(t *testing.T) {
+	keys := testKeys(t)
+	old, err := auth.ParseKey("2026-01", auth.AlgRS256, keys[auth.AlgRS256])
+	require.NoError(t, err)
+	current, err := auth.ParseKey("2026-07", auth.AlgES256, keys[auth.AlgES256])
+	require.NoError(t, err)
+
+	oldToken, _, err := newTokens(t, old).Issue(uuid.New(), uuid.New())
+	require.NoError(t, err)
+
+	// The old key is retired: only its public half is kept, to verify
+	retired, err := auth.ParseKey(old.ID, auth.AlgRS256, publicPEM(t, old.PublicKey))
+	require.NoError(t, err)
+	rotated := newTokens(t, current, retired)
+
+	_, err = rotated.Verify(oldToken)
+	assert.NoError(t, err, "tokens signed with a retired key stay valid")
+
+	newToken, _, err := rotated.Issue(uuid.New(), uuid.New())
+	require.NoError(t, err)
+	_, err = rotated.Verify(newToken)
+	assert.NoError(t, err)
+
+	// Once the retired key is dropped, its tokens are rejected
+	_, err = newTokens(t, current).Verify(oldToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestTokens_Rejected(t *testing.T) {
+	key := auth.Key{ID: "key-1", Algorithm: auth.AlgHS256, Secret: testSecret}
+	tokens := newTokens(t, key)
+
+	sign := func(method jwt.SigningMethod, kid string, claims jwt.RegisteredClaims, signingKey interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signingKey)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := jwt.RegisteredClaims{
+		Subject:   uuid.NewString(),
+		Issuer:    "numeris",
+		Audience:  jwt.ClaimStrings{"numeris-api"},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	_, err := tokens.Verify(sign(jwt.SigningMethodHS256, "key-1", valid, testSecret))
+	require.NoError(t, err)
+
+	otherIssuer := valid
+	otherIssuer.Issuer = "someone-else"
+	otherAudience := valid
+	otherAudience.Audience = jwt.ClaimStrings{"another-api"}
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	tests := map[string]string{
+		"wrong issuer":   sign(jwt.SigningMethodHS256, "key-1", otherIssuer, testSecret),
+		"wrong audience": sign(jwt.SigningMethodHS256, "key-1", otherAudience, testSecret),
+		"expired":        sign(jwt.SigningMethodHS256, "key-1", expired, testSecret),
+		"no expiry":      sign(jwt.SigningMethodHS256, "key-1", noExpiry, testSecret),
+		"unknown kid":    sign(jwt.SigningMethodHS256, "key-2", valid, testSecret),
+		"wrong secret":   sign(jwt.SigningMethodHS256, "key-1", valid, []byte("fedcba9876543210fedcba9876543210")),
+		"other alg":      sign(jwt.SigningMethodHS512, "key-1", valid, testSecret),
+		"not a token":    "not-a-token",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tokens.Verify(token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+func TestNewTokens_Invalid(t *testing.T) {
+	keys := testKeys(t)
+	signing := auth.Key{ID: "key-1", Algorithm: auth.AlgHS256, Secret: testSecret}
+	rsaKey, err := auth.ParseKey("rsa", auth.AlgRS256, keys[auth.AlgRS256])
+	require.NoError(t, err)
+	verifyOnly, err := auth.ParseKey("rsa", auth.AlgRS256, publicPEM(t, rsaKey.PublicKey))
+	require.NoError(t, err)
+
+	tests := map[string]auth.Config{
+		"no issuer":           {Audience: "api", TTL: time.Hour, RefreshTTL: time.Hour, SigningKeyID: "key-1", Keys: []auth.Key{signing}},
+		"no lifetime":         {Issuer: "numeris", Audience: "api", SigningKeyID: "key-1", Keys: []auth.Key{signing}},
+		"no refresh lifetime": {Issuer: "numeris", Audience: "api", TTL: time.Hour, SigningKeyID: "key-1", Keys: []auth.Key{signing}},
+		"unknown signing key": {Issuer: "numeris", Audience: "api", TTL: time.Hour, RefreshTTL: time.Hour, SigningKeyID: "key-2", Keys: []auth.Key{signing}},
+		"duplicate key":       {Issuer: "numeris", Audience: "api", TTL: time.Hour, RefreshTTL: time.Hour, SigningKeyID: "key-1", Keys: []auth.Key{signing, signing}},
+		"public signing key":  {Issuer: "numeris", Audience: "api", TTL: time.Hour, RefreshTTL: time.Hour, SigningKeyID: "rsa", Keys: []auth.Key{verifyOnly}},
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := auth.NewTokens(config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	keys := testKeys(t)
+
+	_, err := auth.ParseKey("short", auth.AlgHS256, []byte("too-short"))
+	assert.Error(t, err)
+	_, err = auth.ParseKey("mismatch", auth.AlgRS256, keys[auth.AlgES256])
+	assert.Error(t, err)
+	_, err = auth.ParseKey("none", "none", testSecret)
+	assert.Error(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = auth.ParseKey("p384", auth.AlgES256, privatePEM(t, p384))
+	assert.EqualError(t, err, "key p384: ES256 keys must use the P-256 curve")
+}
+
+func TestJWKS(t *testing.T) {
+	keys := testKeys(t)
+	var configured []auth.Key
+	for _, algorithm := range []string{auth.AlgRS256, auth.AlgES256, auth.AlgEdDSA, auth.AlgHS256} {
+		key, err := auth.ParseKey(algorithm+"-key", algorithm, keys[algorithm])
+		require.NoError(t, err)
+		configured = append(configured, key)
+	}
+
+	set := newTokens(t, configured...).JWKS()
+
+	require.Len(t, set.Keys, 3, "HS256 secrets are not published")
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.NotEmpty(t, set.Keys[0].N)
+	assert.Equal(t, "EC", set.Keys[1].KeyType)
+	assert.Equal(t, "P-256", set.Keys[1].Curve)
+	assert.Len(t, set.Keys[1].X, 43)
+	assert.Len(t, set.Keys[1].Y, 43)
+	assert.Equal(t, "OKP", set.Keys[2].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[2].Curve)
+	for _, jwk := range set.Keys {
+		assert.Equal(t, "sig", jwk.Use)
+		assert.Equal(t, jwk.KeyID, jwk.Algorithm+"-key")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	keys := testKeys(t)
+	dir := t.TempDir()
+	rsaFile := filepath.Join(dir, "rsa.pem")
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(rsaFile, keys[auth.AlgRS256], 0o600))
+	require.NoError(t, os.WriteFile(secretFile, append(testSecret, '\n'), 0o600))
+
+	t.Setenv("JWT_ISSUER", "https://numeris.example.com")
+	t.Setenv("JWT_AUDIENCE", "")
+	t.Setenv("JWT_TTL", "5m")
+	t.Setenv("JWT_REFRESH_TTL", "168h")
+	t.Setenv("JWT_KEYS", "rsa-1:RS256:"+rsaFile+", hs-1:HS256:"+secretFile)
+	t.Setenv("JWT_SIGNING_KEY", "")
+
+	config, err := auth.ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "https://numeris.example.com", config.Issuer)
+	assert.Equal(t, "numeris-api", config.Audience)
+	assert.Equal(t, 5*time.Minute, config.TTL)
+	assert.Equal(t, 168*time.Hour, config.RefreshTTL)
+	assert.Equal(t, "rsa-1", config.SigningKeyID)
+	require.Len(t, config.Keys, 2)
+	assert.Equal(t, testSecret, config.Keys[1].Secret)
+
+	_, err = auth.NewTokens(config)
+	assert.NoError(t, err)
+}
+
+func TestConfigFromEnv_Secret(t *testing.T) {
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SIGNING_KEY", "")
+	t.Setenv("JWT_TTL", "")
+	t.Setenv("JWT_REFRESH_TTL", "")
+
+	t.Setenv("JWT_SECRET", "")
+	_, err := auth.ConfigFromEnv()
+	assert.EqualError(t, err, "JWT_KEYS or JWT_SECRET must be set")
+
+	t.Setenv("JWT_SECRET", string(testSecret))
+	config, err := auth.ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "default", config.SigningKeyID)
+	assert.Equal(t, 15*time.Minute, config.TTL)
+	assert.Equal(t, 30*24*time.Hour, config.RefreshTTL)
+
+	t.Setenv("JWT_TTL", "forever")
+	_, err = auth.ConfigFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("JWT_TTL", "")
+	t.Setenv("JWT_REFRESH_TTL", "forever")
+	_, err = auth.ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -1,20 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/response"
 	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/iyiola-dev/numeris/internal/util"
 )
 
 type Handler struct {
-	svc service.Service
+	svc    service.Service
+	tokens *auth.Tokens
 }
 
-func NewHandler(svc service.Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc service.Service, tokens *auth.Tokens) *Handler {
+	return &Handler{svc: svc, tokens: tokens}
 }
 
 // Auth handlers
@@ -40,6 +48,9 @@ func (h *Handler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	input.IPAddress = c.ClientIP()
+	input.UserAgent = c.Request.UserAgent()
+	input.RequestID = c.GetString(util.RequestIDKey)
 
 	response, err := h.svc.Login(input)
 	if err != nil {
@@ -50,6 +61,148 @@ func (h *Handler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// Refresh swaps a refresh token for a new token pair. It is public, since the
+// caller's access token has usually expired by the time they refresh.
+func (h *Handler) Refresh(c *gin.Context) {
+	var input inputs.RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.IPAddress = c.ClientIP()
+	input.UserAgent = c.Request.UserAgent()
+	input.RequestID = c.GetString(util.RequestIDKey)
+
+	tokens, err := h.svc.Refresh(input)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.Logout(principal); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
+}
+
+// LogoutAll ends every session of the caller, on every device.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	revoked, err := h.svc.LogoutAll(principal)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out of all sessions", "sessions_revoked": revoked})
+}
+
+// RequestEmailVerification sends a new verification link. It answers the
+// same for every address, so it cannot be used to find out who has an
+// account.
+func (h *Handler) RequestEmailVerification(c *gin.Context) {
+	var input inputs.EmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.RequestEmailVerification(input); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the address has an unverified account, a verification link has been sent"})
+}
+
+// VerifyEmail is where the link in the verification email leads.
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	if err := h.svc.VerifyEmail(c.Query("token")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email address verified"})
+}
+
+// ForgotPassword sends a password reset link. Like RequestEmailVerification,
+// it answers the same for every address.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var input inputs.EmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.RequestPasswordReset(input); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the address has an account, a password reset link has been sent"})
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var input inputs.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.IPAddress = c.ClientIP()
+	input.UserAgent = c.Request.UserAgent()
+	input.RequestID = c.GetString(util.RequestIDKey)
+
+	if err := h.svc.ResetPassword(input); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset; please log in again"})
+}
+
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var input inputs.ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.svc.ChangePassword(principal, input); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed"})
+}
+
+// GetJWKS publishes the public keys our access tokens can be verified with.
+func (h *Handler) GetJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.tokens.JWKS())
+}
+
 // Invoice handlers
 func (h *Handler) CreateInvoice(c *gin.Context) {
 	var input inputs.CreateInvoiceInput
@@ -58,92 +211,1486 @@ func (h *Handler) CreateInvoice(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
-	if !exists {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.CreateInvoice(principal, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+func (h *Handler) GetInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.GetInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *Handler) GetInvoices(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoices, err := h.svc.GetInvoices(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func (h *Handler) UpdateInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var input inputs.UpdateInvoiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.UpdateInvoice(principal, id, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *Handler) DeleteInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invoice deleted successfully"})
+}
+
+func (h *Handler) GetDeletedInvoices(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoices, err := h.svc.GetDeletedInvoices(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch deleted invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func (h *Handler) RestoreInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.RestoreInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *Handler) VoidInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.VoidInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// Customer handlers
+func (h *Handler) CreateCustomer(c *gin.Context) {
+	var input inputs.CreateCustomerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	customer, err := h.svc.CreateCustomer(principal, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, customer)
+}
+
+func (h *Handler) GetCustomers(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	customers, err := h.svc.GetCustomers(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch customers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customers)
+}
+
+func (h *Handler) GetCustomer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	customer, err := h.svc.GetCustomer(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+func (h *Handler) UpdateCustomer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdateCustomer(principal, id, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "customer updated successfully"})
+}
+
+func (h *Handler) DeleteCustomer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteCustomer(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "customer deleted successfully"})
+}
+
+// errorStatus maps the service's sentinel errors to HTTP status codes
+func errorStatus(err error) int {
+	var transitionErr *service.InvalidTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrCustomerNotFound),
+		errors.Is(err, service.ErrTaxRateNotFound),
+		errors.Is(err, service.ErrInvoiceNotFound),
+		errors.Is(err, service.ErrInvoiceRevisionNotFound),
+		errors.Is(err, service.ErrPaymentDetailsNotFound),
+		errors.Is(err, service.ErrPaymentNotFound),
+		errors.Is(err, service.ErrShareLinkNotFound),
+		errors.Is(err, service.ErrRecurringInvoiceNotFound),
+		errors.Is(err, service.ErrLateFeeNotFound),
+		errors.Is(err, service.ErrLateFeePolicyNotFound),
+		errors.Is(err, service.ErrCreditNoteNotFound),
+		errors.Is(err, service.ErrQuoteNotFound),
+		errors.Is(err, service.ErrQuoteLinkNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCustomerHasInvoices),
+		errors.Is(err, service.ErrCustomerInUse),
+		errors.Is(err, service.ErrInvoiceIssued),
+		errors.Is(err, service.ErrInvoiceNumbered),
+		errors.Is(err, service.ErrInvoiceLocked),
+		errors.Is(err, service.ErrQuoteClosed),
+		errors.Is(err, service.ErrQuoteConverted),
+		errors.Is(err, service.ErrPaymentHasRefunds),
+		errors.Is(err, service.ErrLateFeeReversed):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvoiceDeliveryFailed):
+		return http.StatusBadGateway
+	case errors.Is(err, service.ErrMailerNotConfigured):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Tax rate handlers
+func (h *Handler) CreateTaxRate(c *gin.Context) {
+	var input inputs.CreateTaxRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	rate, err := h.svc.CreateTaxRate(principal, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+func (h *Handler) GetTaxRates(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	rates, err := h.svc.GetTaxRates(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tax rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+func (h *Handler) GetTaxRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tax rate ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	rate, err := h.svc.GetTaxRate(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+func (h *Handler) UpdateTaxRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tax rate ID"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdateTaxRate(principal, id, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tax rate updated successfully"})
+}
+
+func (h *Handler) DeleteTaxRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tax rate ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteTaxRate(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tax rate deleted successfully"})
+}
+
+// Recurring invoice handlers
+func (h *Handler) CreateRecurringInvoice(c *gin.Context) {
+	var input inputs.CreateRecurringInvoiceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recurring, err := h.svc.CreateRecurringInvoice(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurring)
+}
+
+func (h *Handler) GetRecurringInvoices(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recurring, err := h.svc.GetRecurringInvoices(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch recurring invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recurring)
+}
+
+func (h *Handler) GetRecurringInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	recurring, err := h.svc.GetRecurringInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recurring)
+}
+
+func (h *Handler) UpdateRecurringInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring invoice ID"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdateRecurringInvoice(principal, id, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring invoice updated successfully"})
+}
+
+func (h *Handler) DeleteRecurringInvoice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteRecurringInvoice(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring invoice deleted successfully"})
+}
+
+// Invoice delivery handlers
+func (h *Handler) SendInvoice(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var input inputs.SendInvoiceInput
+	// The body is optional; without one the invoice goes to the customer only
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+
+	delivery, err := h.svc.SendInvoice(principal, input)
+	if err != nil {
+		body := gin.H{"error": err.Error()}
+		// A failed attempt is still recorded and returned
+		if delivery != nil {
+			body["delivery"] = delivery
+		}
+		c.JSON(errorStatus(err), body)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *Handler) GetInvoiceDeliveries(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	deliveries, err := h.svc.GetInvoiceDeliveries(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Payment handlers
+func (h *Handler) RecordPayment(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var input inputs.RecordPaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+
+	payment, err := h.svc.RecordPayment(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+func (h *Handler) GetPayments(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	payments, err := h.svc.GetPayments(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *Handler) RefundPayment(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	paymentID, err := uuid.Parse(c.Param("payment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	var input inputs.RefundPaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+	input.PaymentID = paymentID
+
+	refund, err := h.svc.RefundPayment(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *Handler) DeletePayment(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	paymentID, err := uuid.Parse(c.Param("payment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeletePayment(principal, invoiceID, paymentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payment deleted successfully"})
+}
+
+// Invoice PDF handlers
+func (h *Handler) GetInvoicePDF(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, err := h.svc.GetInvoicePDF(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, file)
+}
+
+// Invoice revision handlers
+func (h *Handler) GetInvoiceRevisions(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	revisions, err := h.svc.GetInvoiceRevisions(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *Handler) GetInvoiceRevision(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	revision, err := h.svc.GetInvoiceRevision(principal, invoiceID, number)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffInvoiceRevisions compares the revisions given by the from and to query
+// parameters.
+func (h *Handler) DiffInvoiceRevisions(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from revision"})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to revision"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	diff, err := h.svc.DiffInvoiceRevisions(principal, invoiceID, from, to)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *Handler) GetInvoiceRevisionPDF(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, err := h.svc.GetInvoiceRevisionPDF(principal, invoiceID, number)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, file)
+}
+
+func (h *Handler) GetInvoiceTemplateSettings(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := h.svc.GetInvoiceTemplateSettings(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invoice template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateInvoiceTemplateSettings(c *gin.Context) {
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateInvoiceTemplateSettings(principal, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invoice template updated successfully"})
+}
+
+// Reminder settings handlers
+func (h *Handler) GetReminderSettings(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	settings, err := h.svc.GetReminderSettings(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch reminder settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateReminderSettings(c *gin.Context) {
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateReminderSettings(principal, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "reminder settings updated successfully"})
+}
+
+// Late fee handlers
+func (h *Handler) GetLateFeePolicy(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	policy, err := h.svc.GetLateFeePolicy(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch late fee policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *Handler) UpdateLateFeePolicy(c *gin.Context) {
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateLateFeePolicy(principal, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "late fee policy updated successfully"})
+}
+
+func (h *Handler) GetInvoiceLateFeePolicy(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	policy, err := h.svc.GetInvoiceLateFeePolicy(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *Handler) UpdateInvoiceLateFeePolicy(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdateInvoiceLateFeePolicy(principal, invoiceID, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "late fee policy updated successfully"})
+}
+
+func (h *Handler) DeleteInvoiceLateFeePolicy(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteInvoiceLateFeePolicy(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "late fee policy deleted successfully"})
+}
+
+func (h *Handler) GetLateFees(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	fees, err := h.svc.GetLateFees(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fees)
+}
+
+func (h *Handler) ReverseLateFee(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	feeID, err := uuid.Parse(c.Param("fee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid late fee ID"})
+		return
+	}
+
+	var input inputs.ReverseLateFeeInput
+	// The body is optional; it only carries the reason
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+	input.LateFeeID = feeID
+
+	fee, err := h.svc.ReverseLateFee(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fee)
+}
+
+// Credit note handlers
+func (h *Handler) CreateCreditNote(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var input inputs.CreateCreditNoteInput
+	// The body is optional; without items the whole invoice is credited
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+
+	note, err := h.svc.CreateCreditNote(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+func (h *Handler) GetInvoiceCreditNotes(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	notes, err := h.svc.GetInvoiceCreditNotes(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+func (h *Handler) GetCreditNotes(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	notes, err := h.svc.GetCreditNotes(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch credit notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+func (h *Handler) GetCreditNote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credit note ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	note, err := h.svc.GetCreditNote(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *Handler) GetCreditNotePDF(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credit note ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, err := h.svc.GetCreditNotePDF(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, file)
+}
+
+// Quote handlers
+func (h *Handler) CreateQuote(c *gin.Context) {
+	var input inputs.CreateQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	quote, err := h.svc.CreateQuote(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
+
+func (h *Handler) GetQuotes(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	quotes, err := h.svc.GetQuotes(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quotes)
+}
+
+func (h *Handler) GetQuote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	input.UserID = userID.(uuid.UUID)
 
-	invoice, err := h.svc.CreateInvoice(input)
+	quote, err := h.svc.GetQuote(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *Handler) UpdateQuote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote ID"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, invoice)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdateQuote(principal, id, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "quote updated successfully"})
 }
 
-func (h *Handler) GetInvoice(c *gin.Context) {
+func (h *Handler) DeleteQuote(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote ID"})
 		return
 	}
 
-	invoice, err := h.svc.GetInvoiceWithItems(id)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeleteQuote(principal, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, invoice)
+	c.JSON(http.StatusOK, gin.H{"message": "quote deleted successfully"})
 }
 
-func (h *Handler) GetInvoices(c *gin.Context) {
-	// Get user ID from context
-	userID, _ := c.Get("userID")
+func (h *Handler) SendQuote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote ID"})
+		return
+	}
 
-	filters := map[string]interface{}{
-		"user_id": userID.(uuid.UUID),
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
 	}
 
-	invoices, err := h.svc.GetInvoices(filters)
+	link, err := h.svc.SendQuote(principal, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invoices"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, invoices)
+	c.JSON(http.StatusOK, link)
 }
 
-func (h *Handler) UpdateInvoice(c *gin.Context) {
+func (h *Handler) ConvertQuote(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invoice, err := h.svc.ConvertQuote(principal, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// writePDF sends a rendered invoice for the browser to display.
+func writePDF(c *gin.Context, file *response.InvoicePDF) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", file.FileName))
+	c.Data(http.StatusOK, "application/pdf", file.Content)
+}
+
+// Invoice numbering handlers
+func (h *Handler) GetInvoiceSequence(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sequence, err := h.svc.GetInvoiceSequence(principal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invoice numbering"})
 		return
 	}
 
+	c.JSON(http.StatusOK, sequence)
+}
+
+func (h *Handler) UpdateInvoiceSequence(c *gin.Context) {
 	var updates map[string]interface{}
 	if err := c.ShouldBindJSON(&updates); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = h.svc.UpdateInvoice(id, updates)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateInvoiceSequence(principal, updates)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "invoice updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "invoice numbering updated successfully"})
 }
 
-func (h *Handler) DeleteInvoice(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *Handler) GetCreditNoteSequence(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sequence, err := h.svc.GetCreditNoteSequence(principal)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch credit note numbering"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sequence)
+}
+
+func (h *Handler) UpdateCreditNoteSequence(c *gin.Context) {
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateCreditNoteSequence(principal, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "credit note numbering updated successfully"})
+}
+
+func (h *Handler) GetQuoteSequence(c *gin.Context) {
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	err = h.svc.DeleteInvoice(id)
+	sequence, err := h.svc.GetQuoteSequence(principal)
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch quote numbering"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sequence)
+}
+
+func (h *Handler) UpdateQuoteSequence(c *gin.Context) {
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "invoice deleted successfully"})
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.svc.UpdateQuoteSequence(principal, updates)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "quote numbering updated successfully"})
 }
 
 // Payment Details handlers
@@ -155,16 +1702,22 @@ func (h *Handler) CreatePaymentDetails(c *gin.Context) {
 	}
 
 	// Parse invoice ID from URL
-	invoiceID, err := uuid.Parse(c.Param("invoice_id"))
+	invoiceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
 		return
 	}
 	input.InvoiceID = invoiceID
 
-	details, err := h.svc.CreatePaymentDetails(input)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	details, err := h.svc.CreatePaymentDetails(principal, input)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -172,15 +1725,21 @@ func (h *Handler) CreatePaymentDetails(c *gin.Context) {
 }
 
 func (h *Handler) GetPaymentDetails(c *gin.Context) {
-	invoiceID, err := uuid.Parse(c.Param("invoice_id"))
+	invoiceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
 		return
 	}
 
-	details, err := h.svc.GetPaymentDetailsByInvoiceID(invoiceID)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	details, err := h.svc.GetPaymentDetails(principal, invoiceID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "payment details not found"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -188,7 +1747,7 @@ func (h *Handler) GetPaymentDetails(c *gin.Context) {
 }
 
 func (h *Handler) UpdatePaymentDetails(c *gin.Context) {
-	invoiceID, err := uuid.Parse(c.Param("invoice_id"))
+	invoiceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
 		return
@@ -200,9 +1759,15 @@ func (h *Handler) UpdatePaymentDetails(c *gin.Context) {
 		return
 	}
 
-	err = h.svc.UpdatePaymentDetails(invoiceID, updates)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.UpdatePaymentDetails(principal, invoiceID, updates)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -210,74 +1775,259 @@ func (h *Handler) UpdatePaymentDetails(c *gin.Context) {
 }
 
 func (h *Handler) DeletePaymentDetails(c *gin.Context) {
-	invoiceID, err := uuid.Parse(c.Param("invoice_id"))
+	invoiceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
 		return
 	}
 
-	err = h.svc.DeletePaymentDetails(invoiceID)
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.DeletePaymentDetails(principal, invoiceID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "payment details deleted successfully"})
 }
 
-// Shareable link handler
-func (h *Handler) GetInvoiceByShareableLink(c *gin.Context) {
-	invoiceNumber := c.Param("invoice_number")
-	if invoiceNumber == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice number"})
+// Share link handlers
+func (h *Handler) CreateShareLink(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var input inputs.CreateShareLinkInput
+	// The body is optional; without one the link never expires
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	input.InvoiceID = invoiceID
+
+	link, err := h.svc.CreateShareLink(principal, input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *Handler) GetShareLinks(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	links, err := h.svc.GetShareLinks(principal, invoiceID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *Handler) RevokeShareLink(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	linkID, err := uuid.Parse(c.Param("link_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid share link ID"})
+		return
+	}
+
+	principal, ok := currentPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err = h.svc.RevokeShareLink(principal, invoiceID, linkID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "share link revoked successfully"})
+}
+
+// GetSharedInvoice is the public, customer facing view of a shared invoice
+func (h *Handler) GetSharedInvoice(c *gin.Context) {
+	invoice, err := h.svc.GetSharedInvoice(c.Param("token"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *Handler) GetSharedInvoicePDF(c *gin.Context) {
+	file, err := h.svc.GetSharedInvoicePDF(c.Param("token"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writePDF(c, file)
+}
+
+func (h *Handler) GetSharedQuote(c *gin.Context) {
+	quote, err := h.svc.GetSharedQuote(c.Param("token"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *Handler) AcceptQuote(c *gin.Context) {
+	var input inputs.QuoteResponseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Token = c.Param("token")
+	input.IPAddress = c.ClientIP()
+
+	quote, err := h.svc.AcceptQuote(input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get invoice using filters
-	filters := map[string]interface{}{
-		"invoice_number": invoiceNumber,
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *Handler) DeclineQuote(c *gin.Context) {
+	var input inputs.QuoteResponseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	invoices, err := h.svc.GetInvoices(filters)
-	if err != nil || len(invoices) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+	input.Token = c.Param("token")
+	input.IPAddress = c.ClientIP()
+
+	quote, err := h.svc.DeclineQuote(input)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, invoices[0])
+	c.JSON(http.StatusOK, quote)
 }
 
 // Activity Log handlers
 func (h *Handler) GetActivityLogs(c *gin.Context) {
-	// Get user ID from context
-	userID, exists := c.Get("userID")
-	if !exists {
+	principal, ok := currentPrincipal(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	// Get invoice ID from params if provided
-	var filters map[string]interface{}
-	if invoiceID := c.Query("invoice_id"); invoiceID != "" {
-		id, err := uuid.Parse(invoiceID)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+	input := inputs.ActivityLogsInput{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		Sort:       c.Query("sort"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	var err error
+	if input.EntityID, err = queryUUID(c, "entity_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.InvoiceID, err = queryUUID(c, "invoice_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.From, err = queryTime(c, "from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.To, err = queryTime(c, "to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		if input.Limit, err = strconv.Atoi(limit); err != nil || input.Limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
 			return
 		}
-		filters = map[string]interface{}{
-			"user_id": userID.(uuid.UUID),
-			"invoice_id": id,
-		}
-	} else {
-		filters = map[string]interface{}{
-			"user_id": userID.(uuid.UUID),
-		}
 	}
 
-	logs, err := h.svc.GetActivityLogs(filters)
+	page, err := h.svc.GetActivityLogs(principal, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch activity logs"})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	c.JSON(http.StatusOK, page)
+}
+
+func queryUUID(c *gin.Context, param string) (*uuid.UUID, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", param)
+	}
+	return &id, nil
+}
+
+// queryTime reads a query parameter given as an RFC 3339 time or a date. A
+// date that ends a range (end is true) covers the whole day.
+func queryTime(c *gin.Context, param string, end bool) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date (2006-01-02) or an RFC 3339 time", param)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// currentPrincipal returns who util.AuthMiddleware authenticated the request
+// as. ok is false on public routes.
+func currentPrincipal(c *gin.Context) (auth.Principal, bool) {
+	return auth.FromContext(c.Request.Context())
 }
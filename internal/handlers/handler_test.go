@@ -0,0 +1,641 @@
+package handlers_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"github.com/iyiola-dev/numeris/internal/routes"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/iyiola-dev/numeris/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// publicRoutes need no token. The value says whether the route calls the
+// service.
+var publicRoutes = map[string]bool{
+	"POST /api/auth/register":                true,
+	"POST /api/auth/login":                   true,
+	"POST /api/auth/refresh":                 true,
+	"POST /api/auth/verify-email":            true,
+	"GET /api/auth/verify-email":             true,
+	"POST /api/auth/forgot-password":         true,
+	"POST /api/auth/reset-password":          true,
+	"GET /api/shared/:token":                 true,
+	"GET /api/shared/:token/pdf":             true,
+	"GET /api/shared-quotes/:token":          true,
+	"POST /api/shared-quotes/:token/accept":  true,
+	"POST /api/shared-quotes/:token/decline": true,
+	"GET /.well-known/jwks.json":             false,
+}
+
+// stubService answers every call with zero values and an error, so each
+// handler stops right after the service call it makes.
+func stubService() *mocks.Service {
+	svc := new(mocks.Service)
+	serviceType := reflect.TypeOf((*service.Service)(nil)).Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	for i := 0; i < serviceType.NumMethod(); i++ {
+		method := serviceType.Method(i)
+		args := make([]interface{}, method.Type.NumIn())
+		for j := range args {
+			args[j] = mock.Anything
+		}
+		returns := make([]interface{}, method.Type.NumOut())
+		for j := range returns {
+			switch out := method.Type.Out(j); {
+			case out == errorType:
+				returns[j] = errors.New("stub")
+			case out.Kind() == reflect.Ptr, out.Kind() == reflect.Slice, out.Kind() == reflect.Map:
+				returns[j] = nil
+			default:
+				returns[j] = reflect.Zero(out).Interface()
+			}
+		}
+		svc.On(method.Name, args...).Return(returns...)
+	}
+	return svc
+}
+
+func testTokens(t *testing.T) *auth.Tokens {
+	tokens, err := auth.NewTokens(auth.Config{
+		Issuer:       "numeris",
+		Audience:     "numeris-api",
+		TTL:          time.Hour,
+		RefreshTTL:   24 * time.Hour,
+		SigningKeyID: "test",
+		Keys:         []auth.Key{{ID: "test", Algorithm: auth.AlgHS256, Secret: []byte("0123456789abcdef0123456789abcdef")}},
+	})
+	require.NoError(t, err)
+	return tokens
+}
+
+// requestPath fills in a route's parameters with values its handler accepts.
+func requestPath(route gin.RouteInfo) string {
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		switch {
+		case segment == ":number":
+			segments[i] = "1"
+		case segment == ":token":
+			segments[i] = "token"
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = uuid.NewString()
+		}
+	}
+	path := strings.Join(segments, "/")
+	if strings.HasSuffix(route.Path, "/revisions/diff") {
+		path += "?from=1&to=2"
+	}
+	return path
+}
+
+func newRequest(route gin.RouteInfo, token string) *http.Request {
+	var body *strings.Reader
+	if route.Method == http.MethodPost || route.Method == http.MethodPut {
+		body = strings.NewReader("{}")
+	} else {
+		body = strings.NewReader("")
+	}
+	req := httptest.NewRequest(route.Method, requestPath(route), body)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestRoutes_Unauthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(mocks.Repository)
+	svc := stubService()
+	tokens := testTokens(t)
+	router := routes.SetupRouter(repo, svc, tokens)
+
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		t.Run(key, func(t *testing.T) {
+			svc.Calls = nil
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newRequest(route, ""))
+
+			if reachesService, public := publicRoutes[key]; public {
+				assert.NotEqual(t, http.StatusInternalServerError, w.Code)
+				if reachesService {
+					assert.NotEmpty(t, svc.Calls, "public routes reach the service")
+				}
+				return
+			}
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Empty(t, svc.Calls, "the service must not be reached")
+		})
+	}
+}
+
+func TestRoutes_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(mocks.Repository)
+	svc := stubService()
+	tokens := testTokens(t)
+	router := routes.SetupRouter(repo, svc, tokens)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(gin.RouteInfo{Method: http.MethodGet, Path: "/api/invoices"}, "not-a-token"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, svc.Calls)
+}
+
+// signedIn is a router backed by svc together with a live session for a user
+// and an access token for it.
+type signedIn struct {
+	router  *gin.Engine
+	user    *models.User
+	session *models.Session
+	token   string
+}
+
+func signIn(t *testing.T, svc *mocks.Service) signedIn {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: uuid.New(), Active: true}
+	session := &models.Session{ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	repo := new(mocks.Repository)
+	repo.On("GetSessionByID", session.ID).Return(session, nil)
+	repo.On("GetUserByID", user.ID).Return(user, nil)
+	tokens := testTokens(t)
+
+	token, _, err := tokens.Issue(user.ID, session.ID)
+	require.NoError(t, err)
+	return signedIn{router: routes.SetupRouter(repo, svc, tokens), user: user, session: session, token: token}
+}
+
+// assertPrincipal checks that a service call was made for the signed in
+// user's session.
+func assertPrincipal(t *testing.T, s signedIn, call mock.Call) {
+	t.Helper()
+	principal, ok := call.Arguments.Get(0).(auth.Principal)
+	require.True(t, ok, "%s must be called with the principal", call.Method)
+	assert.Equal(t, s.user.ID, principal.UserID)
+	assert.Equal(t, auth.MethodJWT, principal.AuthMethod)
+	assert.True(t, principal.HasRole(auth.RoleOwner))
+	assert.NotEmpty(t, principal.TokenID)
+	assert.Equal(t, s.session.ID, principal.SessionID)
+	assert.Equal(t, "request-1", principal.RequestID)
+}
+
+func TestRoutes_Authenticated(t *testing.T) {
+	svc := stubService()
+	s := signIn(t, svc)
+	router, token := s.router, s.token
+
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if _, public := publicRoutes[key]; public {
+			continue
+		}
+		t.Run(key, func(t *testing.T) {
+			svc.Calls = nil
+			req := newRequest(route, token)
+			req.Header.Set(util.RequestIDHeader, "request-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+			require.NotEmpty(t, svc.Calls, "the handler must call the service")
+			for _, call := range svc.Calls {
+				assertPrincipal(t, s, call)
+			}
+		})
+	}
+}
+
+// TestRoutes_Success takes one route of each resource through a successful
+// service call.
+func TestRoutes_Success(t *testing.T) {
+	invoiceID := uuid.New()
+	customerID := uuid.New()
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	invoice := &models.Invoice{
+		ID:          invoiceID,
+		CustomerID:  customerID,
+		Currency:    "USD",
+		Status:      models.InvoiceStatusDraft,
+		SubTotal:    money.NewFromInt(200),
+		TotalAmount: money.NewFromInt(200),
+		BalanceDue:  money.NewFromInt(200),
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		call    string
+		args    []interface{} // after the principal
+		returns []interface{}
+		status  int
+		check   func(t *testing.T, body []byte)
+	}{
+		{
+			name:   "invoices",
+			method: http.MethodPost,
+			path:   "/api/invoices",
+			body:   `{"CustomerID":"` + customerID.String() + `","Currency":"USD","Items":[{"Description":"Chairs","Quantity":2,"UnitPrice":"100"}]}`,
+			call:   "CreateInvoice",
+			args: []interface{}{mock.MatchedBy(func(input inputs.CreateInvoiceInput) bool {
+				return input.CustomerID == customerID && input.Currency == "USD" &&
+					len(input.Items) == 1 && input.Items[0].UnitPrice.Equal(money.NewFromInt(100))
+			})},
+			returns: []interface{}{invoice, nil},
+			status:  http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, invoiceID.String(), got["ID"])
+				assert.Equal(t, "draft", got["Status"])
+				assert.Equal(t, 200.0, got["TotalAmount"])
+			},
+		},
+		{
+			name:   "customers",
+			method: http.MethodGet,
+			path:   "/api/customers/" + customerID.String(),
+			call:   "GetCustomer",
+			args:   []interface{}{customerID},
+			returns: []interface{}{&response.CustomerResponse{
+				Customer:           &models.Customer{ID: customerID, Name: "Acme"},
+				Invoices:           []models.Invoice{*invoice},
+				OutstandingBalance: map[string]money.Decimal{"USD": money.NewFromInt(200)},
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "Acme", got["customer"].(map[string]interface{})["Name"])
+				assert.Len(t, got["invoices"], 1)
+				assert.Equal(t, map[string]interface{}{"USD": 200.0}, got["outstanding_balance"])
+			},
+		},
+		{
+			name:   "tax rates",
+			method: http.MethodPost,
+			path:   "/api/tax-rates",
+			body:   `{"Name":"VAT","Rate":"20"}`,
+			call:   "CreateTaxRate",
+			args: []interface{}{mock.MatchedBy(func(input inputs.CreateTaxRateInput) bool {
+				return input.Name == "VAT" && input.Rate.Equal(money.NewFromInt(20))
+			})},
+			returns: []interface{}{&models.TaxRate{ID: uuid.New(), Name: "VAT", Rate: money.NewFromInt(20), Active: true}, nil},
+			status:  http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "VAT", got["Name"])
+				assert.Equal(t, 20.0, got["Rate"])
+				assert.Equal(t, true, got["Active"])
+			},
+		},
+		{
+			name:    "recurring invoices",
+			method:  http.MethodGet,
+			path:    "/api/recurring-invoices",
+			call:    "GetRecurringInvoices",
+			returns: []interface{}{[]models.RecurringInvoice{{ID: uuid.New(), Name: "Retainer", Cadence: "monthly"}}, nil},
+			status:  http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeList(t, body)
+				require.Len(t, got, 1)
+				assert.Equal(t, "Retainer", got[0]["Name"])
+				assert.Equal(t, "monthly", got[0]["Cadence"])
+			},
+		},
+		{
+			name:   "payments",
+			method: http.MethodPost,
+			path:   "/api/invoices/" + invoiceID.String() + "/payments",
+			body:   `{"Amount":"50.25","Method":"bank_transfer"}`,
+			call:   "RecordPayment",
+			args: []interface{}{mock.MatchedBy(func(input inputs.RecordPaymentInput) bool {
+				return input.InvoiceID == invoiceID && input.Amount.Equal(money.MustParse("50.25"))
+			})},
+			returns: []interface{}{&models.Payment{
+				ID:        uuid.New(),
+				InvoiceID: invoiceID,
+				Kind:      models.PaymentKindPayment,
+				Amount:    money.MustParse("50.25"),
+				Currency:  "USD",
+				PaidAt:    now,
+				Method:    "bank_transfer",
+			}, nil},
+			status: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, invoiceID.String(), got["InvoiceID"])
+				assert.Equal(t, "payment", got["Kind"])
+				assert.Equal(t, 50.25, got["Amount"])
+				assert.Equal(t, "2026-03-02T09:00:00Z", got["PaidAt"])
+			},
+		},
+		{
+			name:    "payment details",
+			method:  http.MethodGet,
+			path:    "/api/invoices/" + invoiceID.String() + "/payment",
+			call:    "GetPaymentDetails",
+			args:    []interface{}{invoiceID},
+			returns: []interface{}{&models.PaymentDetails{ID: uuid.New(), InvoiceID: invoiceID, AccountName: "Numeris Ltd", AccountNumber: "0123456789"}, nil},
+			status:  http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "Numeris Ltd", got["AccountName"])
+				assert.Equal(t, "0123456789", got["AccountNumber"])
+			},
+		},
+		{
+			name:   "deliveries",
+			method: http.MethodGet,
+			path:   "/api/invoices/" + invoiceID.String() + "/deliveries",
+			call:   "GetInvoiceDeliveries",
+			args:   []interface{}{invoiceID},
+			returns: []interface{}{[]models.InvoiceDelivery{
+				{ID: uuid.New(), InvoiceID: invoiceID, To: "billing@acme.test", Status: models.DeliveryStatusSent},
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeList(t, body)
+				require.Len(t, got, 1)
+				assert.Equal(t, "billing@acme.test", got[0]["To"])
+				assert.Equal(t, models.DeliveryStatusSent, got[0]["Status"])
+			},
+		},
+		{
+			name:   "share links",
+			method: http.MethodPost,
+			path:   "/api/invoices/" + invoiceID.String() + "/share-links",
+			call:   "CreateShareLink",
+			args: []interface{}{mock.MatchedBy(func(input inputs.CreateShareLinkInput) bool {
+				return input.InvoiceID == invoiceID && input.ExpiresAt == nil
+			})},
+			returns: []interface{}{&response.ShareLinkResponse{
+				Link:  &models.ShareLink{ID: uuid.New(), InvoiceID: invoiceID, TokenPrefix: "abcd1234"},
+				Token: "abcd1234secret",
+				Path:  "/api/shared/abcd1234secret",
+			}, nil},
+			status: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "abcd1234secret", got["token"])
+				assert.Equal(t, "/api/shared/abcd1234secret", got["path"])
+				assert.Equal(t, "abcd1234", got["link"].(map[string]interface{})["TokenPrefix"])
+			},
+		},
+		{
+			name:   "late fees",
+			method: http.MethodGet,
+			path:   "/api/invoices/" + invoiceID.String() + "/late-fees",
+			call:   "GetLateFees",
+			args:   []interface{}{invoiceID},
+			returns: []interface{}{[]models.LateFee{
+				{ID: uuid.New(), InvoiceID: invoiceID, Kind: models.LateFeeFlat, Amount: money.NewFromInt(10), Description: "Late fee"},
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeList(t, body)
+				require.Len(t, got, 1)
+				assert.Equal(t, "Late fee", got[0]["Description"])
+				assert.Equal(t, 10.0, got[0]["Amount"])
+			},
+		},
+		{
+			name:   "credit notes",
+			method: http.MethodPost,
+			path:   "/api/invoices/" + invoiceID.String() + "/credit-notes",
+			body:   `{"Reason":"Order cancelled"}`,
+			call:   "CreateCreditNote",
+			args: []interface{}{mock.MatchedBy(func(input inputs.CreateCreditNoteInput) bool {
+				return input.InvoiceID == invoiceID && input.Reason == "Order cancelled" && len(input.Items) == 0
+			})},
+			returns: []interface{}{&models.CreditNote{
+				ID:               uuid.New(),
+				InvoiceID:        invoiceID,
+				CreditNoteNumber: "CN-2026-00001",
+				Reason:           "Order cancelled",
+				TotalAmount:      money.NewFromInt(-200),
+			}, nil},
+			status: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "CN-2026-00001", got["CreditNoteNumber"])
+				assert.Equal(t, -200.0, got["TotalAmount"])
+			},
+		},
+		{
+			name:   "quotes",
+			method: http.MethodPost,
+			path:   "/api/quotes",
+			body:   `{"CustomerID":"` + customerID.String() + `","Currency":"USD","Items":[{"Description":"Design","Quantity":1,"UnitPrice":"500"}]}`,
+			call:   "CreateQuote",
+			args: []interface{}{mock.MatchedBy(func(input inputs.CreateQuoteInput) bool {
+				return input.CustomerID == customerID && len(input.Items) == 1
+			})},
+			returns: []interface{}{&models.Quote{ID: uuid.New(), CustomerID: customerID, QuoteNumber: "QUO-2026-00001", Currency: "USD"}, nil},
+			status:  http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "QUO-2026-00001", got["QuoteNumber"])
+				assert.Equal(t, customerID.String(), got["CustomerID"])
+			},
+		},
+		{
+			name:   "credit note list",
+			method: http.MethodGet,
+			path:   "/api/credit-notes",
+			call:   "GetCreditNotes",
+			returns: []interface{}{[]models.CreditNote{
+				{ID: uuid.New(), InvoiceID: invoiceID, CreditNoteNumber: "CN-2026-00001"},
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeList(t, body)
+				require.Len(t, got, 1)
+				assert.Equal(t, "CN-2026-00001", got[0]["CreditNoteNumber"])
+			},
+		},
+		{
+			name:   "revisions",
+			method: http.MethodGet,
+			path:   "/api/invoices/" + invoiceID.String() + "/revisions/diff?from=1&to=2",
+			call:   "DiffInvoiceRevisions",
+			args:   []interface{}{invoiceID, 1, 2},
+			returns: []interface{}{&response.InvoiceRevisionDiff{
+				From:    1,
+				To:      2,
+				Changes: []response.FieldChange{{Field: "Note", From: "", To: "Thanks"}},
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, 1.0, got["from"])
+				assert.Equal(t, 2.0, got["to"])
+				assert.Equal(t, []interface{}{map[string]interface{}{"field": "Note", "from": "", "to": "Thanks"}}, got["changes"])
+			},
+		},
+		{
+			name:   "settings",
+			method: http.MethodGet,
+			path:   "/api/settings/invoice-numbering",
+			call:   "GetInvoiceSequence",
+			returns: []interface{}{&response.InvoiceSequenceResponse{
+				Sequence:          &models.InvoiceSequence{Prefix: "INV", Separator: "-", IncludeYear: true, Padding: 5, NextNumber: 4},
+				NextInvoiceNumber: "INV-2026-00004",
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "INV-2026-00004", got["next_invoice_number"])
+				assert.Equal(t, "INV", got["sequence"].(map[string]interface{})["Prefix"])
+			},
+		},
+		{
+			name:   "activity",
+			method: http.MethodGet,
+			path:   "/api/activity?limit=1",
+			call:   "GetActivityLogs",
+			args: []interface{}{mock.MatchedBy(func(input inputs.ActivityLogsInput) bool {
+				return input.Limit == 1
+			})},
+			returns: []interface{}{&response.ActivityLogPage{
+				Logs:       []models.ActivityLog{{ID: uuid.New(), InvoiceID: &invoiceID, Action: models.ActivityInvoiceCreated, Timestamp: now}},
+				NextCursor: "next",
+			}, nil},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, "next", got["next_cursor"])
+				require.Len(t, got["logs"], 1)
+				assert.Equal(t, "INVOICE_CREATED", got["logs"].([]interface{})[0].(map[string]interface{})["Action"])
+			},
+		},
+		{
+			name:    "sessions",
+			method:  http.MethodPost,
+			path:    "/api/auth/logout-all",
+			call:    "LogoutAll",
+			returns: []interface{}{3, nil},
+			status:  http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				got := decodeObject(t, body)
+				assert.Equal(t, 3.0, got["sessions_revoked"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.Service)
+			svc.On(tt.call, append([]interface{}{mock.AnythingOfType("auth.Principal")}, tt.args...)...).Return(tt.returns...).Once()
+			s := signIn(t, svc)
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+s.token)
+			req.Header.Set(util.RequestIDHeader, "request-1")
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			svc.AssertExpectations(t)
+			require.Len(t, svc.Calls, 1)
+			assertPrincipal(t, s, svc.Calls[0])
+			tt.check(t, w.Body.Bytes())
+		})
+	}
+}
+
+func decodeObject(t *testing.T, body []byte) map[string]interface{} {
+	t.Helper()
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &got))
+	return got
+}
+
+func decodeList(t *testing.T, body []byte) []map[string]interface{} {
+	t.Helper()
+	var got []map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &got))
+	return got
+}
+
+func TestRoutes_RevokedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: uuid.New(), Active: true}
+	revokedAt := time.Now().Add(-time.Minute)
+	sessions := map[string]*models.Session{
+		"revoked":        {ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+		"expired":        {ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(-time.Minute)},
+		"another user's": {ID: uuid.New(), UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	for name, session := range sessions {
+		t.Run(name, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			repo.On("GetSessionByID", session.ID).Return(session, nil)
+			repo.On("GetUserByID", user.ID).Return(user, nil)
+			svc := stubService()
+			tokens := testTokens(t)
+			router := routes.SetupRouter(repo, svc, tokens)
+
+			token, _, err := tokens.Issue(user.ID, session.ID)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newRequest(gin.RouteInfo{Method: http.MethodGet, Path: "/api/invoices"}, token))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Empty(t, svc.Calls)
+		})
+	}
+}
+
+func TestGetJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	tokens, err := auth.NewTokens(auth.Config{
+		Issuer:       "numeris",
+		Audience:     "numeris-api",
+		TTL:          time.Hour,
+		RefreshTTL:   24 * time.Hour,
+		SigningKeyID: "ed-1",
+		Keys:         []auth.Key{{ID: "ed-1", Algorithm: auth.AlgEdDSA, PrivateKey: private, PublicKey: private.Public()}},
+	})
+	require.NoError(t, err)
+	router := routes.SetupRouter(new(mocks.Repository), stubService(), tokens)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var set auth.JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "ed-1", set.Keys[0].KeyID)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
 )
 
 type RegisterInput struct {
@@ -15,39 +16,206 @@ type RegisterInput struct {
 }
 
 type LoginInput struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type RefreshInput struct {
+	RefreshToken string
+	IPAddress    string
+	UserAgent    string
+	RequestID    string
+}
+
+type EmailInput struct {
+	Email string
 }
 
+type ResetPasswordInput struct {
+	Token       string
+	NewPassword string
+	IPAddress   string
+	UserAgent   string
+	RequestID   string
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string
+	NewPassword     string
+}
 
 type CreateInvoiceInput struct {
-    UserID        uuid.UUID
-    CustomerID    uuid.UUID
-    InvoiceNumber string
-    IssueDate     time.Time
-    DueDate       time.Time
-    Currency      string
-    SubTotal      float64
-    Discount      float64
-    TotalAmount   float64
-    Note          string
-    Items         []CreateInvoiceItemInput
+	CustomerID       uuid.UUID
+	IssueDate        time.Time
+	DueDate          time.Time
+	Currency         string
+	SubTotal         money.Decimal
+	DiscountType     string
+	Discount         money.Decimal // an amount or a percentage, depending on DiscountType
+	TotalAmount      money.Decimal
+	PricesIncludeTax bool
+	Note             string
+	Items            []CreateInvoiceItemInput
 }
 
 type CreateInvoiceItemInput struct {
-    Description string
-    Quantity    int
-    UnitPrice   float64
-    Amount      float64
+	Description string
+	Quantity    int
+	UnitPrice   money.Decimal
+	Amount      money.Decimal
+	TaxRateIDs  []uuid.UUID
 }
 
+// UpdateInvoiceInput edits a draft invoice. Nil fields are left unchanged.
+// Items, when given, is the invoice's full list of lines in their new order:
+// lines with an ID are edited, lines without one are added and lines left
+// out are removed.
+type UpdateInvoiceInput struct {
+	CustomerID       *uuid.UUID
+	IssueDate        *time.Time
+	DueDate          *time.Time
+	Currency         *string
+	DiscountType     *string
+	Discount         *money.Decimal
+	PricesIncludeTax *bool
+	Note             *string
+	Status           *string // may also change on an issued invoice
+	Items            []UpdateInvoiceItemInput
+}
+
+type UpdateInvoiceItemInput struct {
+	ID          *uuid.UUID // the line being edited; nil adds a new line
+	Description string
+	Quantity    int
+	UnitPrice   money.Decimal
+	TaxRateIDs  []uuid.UUID
+}
 
 type CreatePaymentDetailsInput struct {
-    InvoiceID      uuid.UUID
-    AccountName    string
-    AccountNumber  string
-    BankName       string
-    BankAddress    string
-    RoutingNumber  string
-    PaymentDueDate time.Time
+	InvoiceID      uuid.UUID
+	AccountName    string
+	AccountNumber  string
+	BankName       string
+	BankAddress    string
+	RoutingNumber  string
+	PaymentDueDate time.Time
+}
+
+type CreateCustomerInput struct {
+	Name          string
+	Email         string
+	Address       string
+	TaxNumber     string
+	ReverseCharge bool
+}
+
+type CreateTaxRateInput struct {
+	Name     string
+	Rate     money.Decimal
+	Compound bool
+}
+
+type RecordPaymentInput struct {
+	InvoiceID uuid.UUID
+	Amount    money.Decimal
+	Currency  string
+	PaidAt    time.Time
+	Method    string
+	Reference string
+	Note      string
+}
+
+type RefundPaymentInput struct {
+	InvoiceID uuid.UUID
+	PaymentID uuid.UUID
+	Amount    money.Decimal
+	RefundAt  time.Time
+	Reference string
+	Note      string
+}
+
+type ReverseLateFeeInput struct {
+	InvoiceID uuid.UUID
+	LateFeeID uuid.UUID
+	Reason    string
+}
+
+type CreateCreditNoteInput struct {
+	InvoiceID uuid.UUID
+	IssueDate time.Time // defaults to today
+	Reason    string
+	Items     []CreditNoteItemInput // empty credits everything not yet credited
+}
+
+type CreditNoteItemInput struct {
+	InvoiceItemID uuid.UUID
+	Quantity      int
+}
+
+type CreateQuoteInput struct {
+	CustomerID       uuid.UUID
+	IssueDate        time.Time // defaults to today
+	ExpiryDate       time.Time // defaults to 30 days after the issue date
+	Currency         string
+	DiscountType     string
+	Discount         money.Decimal // an amount or a percentage, depending on DiscountType
+	PricesIncludeTax bool
+	Note             string
+	Items            []CreateInvoiceItemInput
+}
+
+// QuoteResponseInput is a customer's answer to a quote, given through its
+// acceptance link.
+type QuoteResponseInput struct {
+	Token     string
+	Name      string
+	Signature string // typed name or an image data URL; needed to accept
+	Reason    string // why the quote was declined
+	IPAddress string
+}
+
+type CreateShareLinkInput struct {
+	InvoiceID uuid.UUID
+	ExpiresAt *time.Time
+}
+
+type CreateRecurringInvoiceInput struct {
+	CustomerID       uuid.UUID
+	Name             string
+	Currency         string
+	DiscountType     string
+	Discount         money.Decimal
+	PricesIncludeTax bool
+	Note             string
+	PaymentTermsDays *int // defaults to 30
+	Cadence          string
+	CronRule         string
+	StartDate        time.Time
+	EndDate          *time.Time
+	AutoSend         bool
+	Items            []CreateInvoiceItemInput
+}
+
+type SendInvoiceInput struct {
+	InvoiceID uuid.UUID
+	Cc        []string
+	Bcc       []string
+	Message   string
+}
+
+// ActivityLogsInput selects a page of a user's activity log. Empty fields do
+// not filter; To is exclusive.
+type ActivityLogsInput struct {
+	Action     string
+	EntityType string
+	EntityID   *uuid.UUID
+	InvoiceID  *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	Sort       string // "desc" (default) or "asc"
+	Cursor     string // NextCursor of the previous page
+	Limit      int    // defaults to 50, at most 200
 }
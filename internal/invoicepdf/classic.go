@@ -0,0 +1,49 @@
+package invoicepdf
+
+import "github.com/iyiola-dev/numeris/internal/pdf"
+
+// classic is a plain layout: the title in the accent colour with the logo
+// opposite it, and accent rules under the header and the table titles.
+type classic struct{}
+
+func (classic) Render(doc *pdf.Document, data Data, branding Branding) error {
+	invoice := data.document()
+	accent := branding.Accent
+
+	doc.AddPage()
+	drawFooter(doc, branding)
+
+	doc.SetFont(pdf.HelveticaBold, 26)
+	doc.SetTextColor(accent)
+	doc.Text(margin, margin+20, data.title())
+	if label := stamp(invoice); label != "" {
+		doc.SetFont(pdf.HelveticaBold, 12)
+		doc.SetTextColor(mutedColor)
+		doc.Text(margin, margin+40, label)
+	}
+	if _, err := drawLogo(doc, branding, contentRight, margin-5, 160, 50, true); err != nil {
+		return err
+	}
+
+	y := drawMeta(doc, data.meta(), margin+70, mutedColor)
+	doc.SetLineColor(accent)
+	doc.SetLineWidth(1.5)
+	doc.Line(margin, y, contentRight, y)
+	doc.SetLineWidth(0.5)
+
+	p := &page{doc: doc, branding: branding, y: y + 30}
+	drawParties(p, invoice, accent)
+	drawItems(p, invoice, tableStyle{
+		header: func(p *page) {
+			itemHeaderText(p.doc, p.y, accent)
+			p.doc.SetLineColor(accent)
+			p.doc.Line(margin, p.y+5, contentRight, p.y+5)
+			p.y += lineHeight
+		},
+	})
+	drawTotals(p, invoice, accent)
+	drawPaymentDetails(p, data.PaymentDetails, accent)
+	drawNote(p, invoice.Note, accent)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package invoicepdf
+
+import "github.com/iyiola-dev/numeris/internal/models"
+
+// title is the heading printed at the top of the document.
+func (d Data) title() string {
+	if d.CreditNote != nil {
+		return "CREDIT NOTE"
+	}
+	return "INVOICE"
+}
+
+// document returns the invoice the templates draw. A credit note is drawn as
+// an invoice holding its own number, date, lines and totals, which are all
+// negative, and the parties and pricing of the invoice it credits.
+func (d Data) document() *models.Invoice {
+	note := d.CreditNote
+	if note == nil {
+		return d.Invoice
+	}
+
+	invoice := d.Invoice
+	doc := &models.Invoice{
+		User:             invoice.User,
+		Customer:         invoice.Customer,
+		InvoiceNumber:    note.CreditNoteNumber,
+		IssueDate:        note.IssueDate,
+		Currency:         note.Currency,
+		SubTotal:         note.SubTotal,
+		DiscountType:     invoice.DiscountType,
+		DiscountValue:    invoice.DiscountValue,
+		Discount:         note.Discount,
+		TaxTotal:         note.TaxTotal,
+		TotalAmount:      note.TotalAmount,
+		PricesIncludeTax: invoice.PricesIncludeTax,
+		ReverseCharge:    invoice.ReverseCharge,
+		Note:             note.Reason,
+	}
+	for _, item := range note.Items {
+		doc.Items = append(doc.Items, models.InvoiceItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+	for _, tax := range note.Taxes {
+		doc.Taxes = append(doc.Taxes, models.InvoiceTax{Name: tax.Name, Rate: tax.Rate, Amount: tax.Amount})
+	}
+	return doc
+}
+
+// meta returns the number and date rows printed under the title.
+func (d Data) meta() [][2]string {
+	if note := d.CreditNote; note != nil {
+		return [][2]string{
+			{"Credit note number", note.CreditNoteNumber},
+			{"Issue date", note.IssueDate.Format(dateLayout)},
+			{"Credits invoice", d.Invoice.InvoiceNumber},
+		}
+	}
+	return [][2]string{
+		{"Invoice number", invoiceNumber(d.Invoice)},
+		{"Issue date", d.Invoice.IssueDate.Format(dateLayout)},
+		{"Due date", d.Invoice.DueDate.Format(dateLayout)},
+	}
+}
@@ -0,0 +1,396 @@
+package invoicepdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/pdf"
+)
+
+// Page geometry shared by the built-in templates.
+const (
+	margin       = 50.0
+	contentRight = pdf.PageWidth - margin
+	footerTop    = pdf.PageHeight - 60
+	lineHeight   = 14.0
+	dateLayout   = "02 Jan 2006"
+)
+
+var (
+	textColor  = pdf.Color{R: 0x22, G: 0x22, B: 0x22}
+	mutedColor = pdf.Color{R: 0x6B, G: 0x6B, B: 0x6B}
+	ruleColor  = pdf.Color{R: 0xD0, G: 0xD0, B: 0xD0}
+)
+
+// Item table columns: the description is left aligned, the numbers end at
+// their column's right edge.
+const (
+	colDescription = margin
+	colQuantity    = 350.0
+	colUnitPrice   = 450.0
+	colAmount      = contentRight
+	descWidth      = 260.0
+)
+
+// page tracks where the next block goes and starts new pages as needed.
+type page struct {
+	doc      *pdf.Document
+	branding Branding
+	y        float64
+	header   func(*page) // draws the table header again after a page break
+}
+
+// ensure starts a new page unless height points still fit above the footer.
+func (p *page) ensure(height float64) {
+	if p.y+height <= footerTop-lineHeight {
+		return
+	}
+	p.doc.AddPage()
+	drawFooter(p.doc, p.branding)
+	p.y = margin
+	if p.header != nil {
+		p.header(p)
+	}
+}
+
+// partyLines returns the lines that describe the issuer and the customer.
+func partyLines(invoice *models.Invoice) (from, to []string) {
+	user := invoice.User
+	from = append(from, strings.TrimSpace(user.FirstName+" "+user.LastName))
+	from = append(from, splitLines(user.Address)...)
+	from = append(from, user.Email)
+
+	customer := invoice.Customer
+	to = append(to, customer.Name)
+	to = append(to, splitLines(customer.Address)...)
+	to = append(to, customer.Email)
+	if customer.TaxNumber != "" {
+		to = append(to, "Tax number: "+customer.TaxNumber)
+	}
+	return compact(from), compact(to)
+}
+
+// drawParties draws the issuer and customer side by side.
+func drawParties(p *page, invoice *models.Invoice, accent pdf.Color) {
+	from, to := partyLines(invoice)
+	doc := p.doc
+
+	doc.SetFont(pdf.HelveticaBold, 9)
+	doc.SetTextColor(accent)
+	doc.Text(margin, p.y, "FROM")
+	doc.Text(300, p.y, "BILL TO")
+
+	doc.SetFont(pdf.Helvetica, 10)
+	doc.SetTextColor(textColor)
+	for i := 0; i < len(from) || i < len(to); i++ {
+		y := p.y + lineHeight*float64(i+1)
+		if i < len(from) {
+			doc.Text(margin, y, from[i])
+		}
+		if i < len(to) {
+			doc.Text(300, y, to[i])
+		}
+	}
+	p.y += lineHeight * float64(max(len(from), len(to))+2)
+}
+
+// drawMeta draws the document number and dates as label/value rows ending at
+// the right margin.
+func drawMeta(doc *pdf.Document, rows [][2]string, top float64, color pdf.Color) float64 {
+	y := top
+	for _, row := range rows {
+		doc.SetFont(pdf.Helvetica, 9)
+		doc.SetTextColor(color)
+		doc.TextRight(contentRight-110, y, row[0])
+		doc.SetFont(pdf.HelveticaBold, 9)
+		doc.TextRight(contentRight, y, row[1])
+		y += 12
+	}
+	return y
+}
+
+// drawItems draws the line item table. style decides how the header row and
+// alternate rows look.
+func drawItems(p *page, invoice *models.Invoice, style tableStyle) {
+	p.header = func(p *page) {
+		style.header(p)
+		p.y += 10
+	}
+	p.header(p)
+
+	doc := p.doc
+	for i, item := range invoice.Items {
+		doc.SetFont(pdf.Helvetica, 10)
+		lines := doc.WrapText(item.Description, descWidth)
+		height := lineHeight*float64(len(lines)) + 6
+
+		p.ensure(height)
+		if style.stripe != nil && i%2 == 1 {
+			doc.SetFillColor(*style.stripe)
+			doc.Rect(margin, p.y-lineHeight+3, contentRight-margin, height)
+		}
+
+		doc.SetFont(pdf.Helvetica, 10)
+		doc.SetTextColor(textColor)
+		for j, line := range lines {
+			doc.Text(colDescription+4, p.y+lineHeight*float64(j), line)
+		}
+		doc.TextRight(colQuantity, p.y, fmt.Sprintf("%d", item.Quantity))
+		doc.TextRight(colUnitPrice, p.y, formatAmount(item.UnitPrice, invoice.Currency))
+		doc.TextRight(colAmount-4, p.y, formatAmount(item.Amount, invoice.Currency))
+		p.y += height
+	}
+
+	p.header = nil
+	doc.SetLineColor(ruleColor)
+	doc.Line(margin, p.y-lineHeight+6, contentRight, p.y-lineHeight+6)
+	p.y += 6
+}
+
+// tableStyle varies the item table between templates.
+type tableStyle struct {
+	header func(*page)
+	stripe *pdf.Color
+}
+
+// itemHeaderText writes the column titles at the current position.
+func itemHeaderText(doc *pdf.Document, y float64, color pdf.Color) {
+	doc.SetFont(pdf.HelveticaBold, 9)
+	doc.SetTextColor(color)
+	doc.Text(colDescription+4, y, "DESCRIPTION")
+	doc.TextRight(colQuantity, y, "QTY")
+	doc.TextRight(colUnitPrice, y, "UNIT PRICE")
+	doc.TextRight(colAmount-4, y, "AMOUNT")
+}
+
+// drawTotals draws the subtotal, discount, taxes and totals at the right.
+func drawTotals(p *page, invoice *models.Invoice, accent pdf.Color) {
+	currency := invoice.Currency
+	type row struct {
+		label, value string
+		strong       bool
+	}
+
+	rows := []row{{"Subtotal", formatAmount(invoice.SubTotal, currency), false}}
+	if !invoice.Discount.IsZero() {
+		label := "Discount"
+		if invoice.DiscountType == models.DiscountTypePercentage {
+			label = fmt.Sprintf("Discount (%s%%)", invoice.DiscountValue)
+		}
+		rows = append(rows, row{label, formatAmount(invoice.Discount.Neg(), currency), false})
+	}
+	for _, tax := range invoice.Taxes {
+		rows = append(rows, row{fmt.Sprintf("%s (%s%%)", tax.Name, tax.Rate), formatAmount(tax.Amount, currency), false})
+	}
+	rows = append(rows, row{"Total (" + currency + ")", formatAmount(invoice.TotalAmount, currency), true})
+	if !invoice.LateFeeTotal.IsZero() {
+		rows = append(rows, row{"Late fees", formatAmount(invoice.LateFeeTotal, currency), false})
+	}
+	if !invoice.CreditTotal.IsZero() {
+		rows = append(rows, row{"Credit notes", formatAmount(invoice.CreditTotal.Neg(), currency), false})
+	}
+	if !invoice.AmountPaid.IsZero() {
+		rows = append(rows, row{"Amount paid", "-" + formatAmount(invoice.AmountPaid, currency), false})
+	}
+	if !invoice.AmountPaid.IsZero() || !invoice.LateFeeTotal.IsZero() || !invoice.CreditTotal.IsZero() {
+		rows = append(rows, row{"Balance due (" + currency + ")", formatAmount(invoice.BalanceDue, currency), true})
+	}
+
+	p.ensure(lineHeight * float64(len(rows)+1))
+	doc := p.doc
+	for _, r := range rows {
+		if r.strong {
+			doc.SetFillColor(accent.Tint(0.85))
+			doc.Rect(330, p.y-lineHeight+3, contentRight-330, lineHeight+2)
+			doc.SetFont(pdf.HelveticaBold, 10)
+		} else {
+			doc.SetFont(pdf.Helvetica, 10)
+		}
+		doc.SetTextColor(textColor)
+		doc.Text(336, p.y, r.label)
+		doc.TextRight(colAmount-4, p.y, r.value)
+		p.y += lineHeight + 2
+	}
+
+	var notes []string
+	if invoice.PricesIncludeTax && len(invoice.Taxes) > 0 {
+		notes = append(notes, "Prices include tax.")
+	}
+	if invoice.ReverseCharge {
+		notes = append(notes, "Reverse charge: the customer is liable for the tax on this invoice.")
+	}
+	doc.SetFont(pdf.Helvetica, 8)
+	doc.SetTextColor(mutedColor)
+	for _, note := range notes {
+		doc.TextRight(colAmount-4, p.y, note)
+		p.y += 11
+	}
+	p.y += lineHeight
+}
+
+// drawPaymentDetails draws where to pay, when the invoice has payment details.
+func drawPaymentDetails(p *page, details *models.PaymentDetails, accent pdf.Color) {
+	if details == nil {
+		return
+	}
+
+	rows := compactRows([][2]string{
+		{"Account name", details.AccountName},
+		{"Account number", details.AccountNumber},
+		{"Bank", details.BankName},
+		{"Bank address", details.BankAddress},
+		{"Routing number", details.RoutingNumber},
+	})
+	if !details.PaymentDueDate.IsZero() {
+		rows = append(rows, [2]string{"Pay by", details.PaymentDueDate.Format(dateLayout)})
+	}
+	drawSection(p, "PAYMENT DETAILS", accent, func() {
+		for _, r := range rows {
+			p.doc.SetFont(pdf.Helvetica, 9)
+			p.doc.SetTextColor(mutedColor)
+			p.doc.Text(margin, p.y, r[0])
+			p.doc.SetTextColor(textColor)
+			p.doc.Text(margin+100, p.y, r[1])
+			p.y += 12
+		}
+	}, float64(len(rows))*12)
+}
+
+// drawNote draws the invoice note, wrapped to the page width.
+func drawNote(p *page, note string, accent pdf.Color) {
+	if strings.TrimSpace(note) == "" {
+		return
+	}
+	p.doc.SetFont(pdf.Helvetica, 9)
+	lines := p.doc.WrapText(note, contentRight-margin)
+	drawSection(p, "NOTES", accent, func() {
+		p.doc.SetFont(pdf.Helvetica, 9)
+		p.doc.SetTextColor(textColor)
+		for _, line := range lines {
+			p.ensure(12)
+			p.doc.Text(margin, p.y, line)
+			p.y += 12
+		}
+	}, 12)
+}
+
+// drawSection draws a titled block, keeping the title with at least the
+// first minHeight points of its body.
+func drawSection(p *page, title string, accent pdf.Color, body func(), minHeight float64) {
+	p.ensure(lineHeight + minHeight)
+	p.doc.SetFont(pdf.HelveticaBold, 9)
+	p.doc.SetTextColor(accent)
+	p.doc.Text(margin, p.y, title)
+	p.y += lineHeight
+	body()
+	p.y += lineHeight
+}
+
+// drawFooter writes the user's footer text at the bottom of the current page.
+func drawFooter(doc *pdf.Document, branding Branding) {
+	doc.SetLineColor(ruleColor)
+	doc.Line(margin, footerTop, contentRight, footerTop)
+
+	doc.SetFont(pdf.Helvetica, 8)
+	doc.SetTextColor(mutedColor)
+	y := footerTop + 14
+	for _, line := range doc.WrapText(branding.FooterText, contentRight-margin) {
+		doc.TextCenter(pdf.PageWidth/2, y, line)
+		y += 10
+	}
+	doc.TextRight(contentRight, pdf.PageHeight-20, fmt.Sprintf("Page %d", doc.PageCount()))
+}
+
+// drawLogo draws the logo scaled to fit a box, keeping its aspect ratio.
+// It returns the width used.
+func drawLogo(doc *pdf.Document, branding Branding, x, y, maxWidth, maxHeight float64, alignRight bool) (float64, error) {
+	if branding.Logo == nil {
+		return 0, nil
+	}
+	bounds := branding.Logo.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	if w == 0 || h == 0 {
+		return 0, nil
+	}
+	scale := min(maxWidth/w, maxHeight/h)
+	w, h = w*scale, h*scale
+	if alignRight {
+		x -= w
+	}
+	return w, doc.Image(branding.Logo, x, y, w, h)
+}
+
+// stamp is the word printed prominently on invoices in a final or
+// provisional state.
+func stamp(invoice *models.Invoice) string {
+	switch invoice.Status {
+	case models.InvoiceStatusDraft:
+		return "DRAFT"
+	case models.InvoiceStatusPaid:
+		return "PAID"
+	case models.InvoiceStatusVoid:
+		return "VOID"
+	default:
+		return ""
+	}
+}
+
+// invoiceNumber is an invoice's number, or a placeholder for a draft, which
+// is only numbered when it is issued.
+func invoiceNumber(invoice *models.Invoice) string {
+	if invoice.InvoiceNumber == "" {
+		return "Not issued yet"
+	}
+	return invoice.InvoiceNumber
+}
+
+// formatAmount writes an amount with the currency's decimals and thousands
+// separators, e.g. 1,296.00.
+func formatAmount(d money.Decimal, currency string) string {
+	s := d.Format(currency)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString("." + fraction)
+	}
+	return sign + b.String()
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// compact drops blank lines.
+func compact(lines []string) []string {
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// compactRows drops rows without a value.
+func compactRows(rows [][2]string) [][2]string {
+	out := rows[:0]
+	for _, r := range rows {
+		if strings.TrimSpace(r[1]) != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package invoicepdf
+
+import "github.com/iyiola-dev/numeris/internal/pdf"
+
+// modern opens with a full width band in the accent colour holding the logo
+// and title, and shades the table header and every other row.
+type modern struct{}
+
+const bandHeight = 110.0
+
+func (modern) Render(doc *pdf.Document, data Data, branding Branding) error {
+	invoice := data.document()
+	accent := branding.Accent
+
+	doc.AddPage()
+	drawFooter(doc, branding)
+
+	doc.SetFillColor(accent)
+	doc.Rect(0, 0, pdf.PageWidth, bandHeight)
+	if _, err := drawLogo(doc, branding, margin, 25, 140, 60, false); err != nil {
+		return err
+	}
+
+	title := data.title()
+	if label := stamp(invoice); label != "" {
+		title += " - " + label
+	}
+	doc.SetFont(pdf.HelveticaBold, 24)
+	doc.SetTextColor(pdf.White)
+	doc.TextRight(contentRight, 58, title)
+	doc.SetFont(pdf.Helvetica, 11)
+	doc.TextRight(contentRight, 78, invoiceNumber(invoice))
+
+	y := drawMeta(doc, data.meta(), bandHeight+30, mutedColor)
+
+	stripe := accent.Tint(0.92)
+	p := &page{doc: doc, branding: branding, y: y + 20}
+	drawParties(p, invoice, accent)
+	drawItems(p, invoice, tableStyle{
+		header: func(p *page) {
+			p.doc.SetFillColor(accent)
+			p.doc.Rect(margin, p.y-lineHeight+2, contentRight-margin, lineHeight+6)
+			itemHeaderText(p.doc, p.y, pdf.White)
+			p.y += lineHeight
+		},
+		stripe: &stripe,
+	})
+	drawTotals(p, invoice, accent)
+	drawPaymentDetails(p, data.PaymentDetails, accent)
+	drawNote(p, invoice.Note, accent)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Package invoicepdf renders invoices as PDF documents. The page layout is
+// chosen by a Template, and each user's logo, accent colour and footer text
+// are applied through Branding.
+package invoicepdf
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/pdf"
+)
+
+// DefaultTemplate is used for users who have not picked a template.
+const DefaultTemplate = "classic"
+
+// DefaultAccent is the accent colour used when a user has not set one.
+var DefaultAccent = pdf.Color{R: 0x1F, G: 0x4E, B: 0x79}
+
+// Data is everything drawn on an invoice. The invoice must have its Items,
+// Taxes, Customer and User loaded; PaymentDetails may be nil. When CreditNote
+// is set the document is that credit note, with its Items loaded, and Invoice
+// is the invoice it credits.
+type Data struct {
+	Invoice        *models.Invoice
+	PaymentDetails *models.PaymentDetails
+	CreditNote     *models.CreditNote
+}
+
+// Branding is the part of the look that each user controls.
+type Branding struct {
+	Logo       image.Image
+	Accent     pdf.Color
+	FooterText string
+}
+
+// Template draws an invoice or credit note onto a document.
+type Template interface {
+	Render(doc *pdf.Document, data Data, branding Branding) error
+}
+
+var templates = map[string]Template{
+	"classic": classic{},
+	"modern":  modern{},
+}
+
+// Register makes a template available under name, replacing any template
+// already registered with that name.
+func Register(name string, t Template) {
+	templates[name] = t
+}
+
+// Lookup returns the template registered under name.
+func Lookup(name string) (Template, bool) {
+	t, ok := templates[name]
+	return t, ok
+}
+
+// Names lists the registered templates in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render draws an invoice with the named template and returns the PDF.
+func Render(name string, data Data, branding Branding) ([]byte, error) {
+	t, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown invoice template %q", name)
+	}
+
+	title := "Invoice " + data.Invoice.InvoiceNumber
+	if data.Invoice.InvoiceNumber == "" {
+		title = "Draft invoice"
+	}
+	if data.CreditNote != nil {
+		title = "Credit note " + data.CreditNote.CreditNoteNumber
+	}
+	doc := pdf.New(title)
+	if err := t.Render(doc, data, branding); err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
@@ -0,0 +1,179 @@
+package invoicepdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/invoicepdf"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var streamPattern = regexp.MustCompile(`/Length (\d+) >>\nstream\n`)
+
+// pageText returns the drawing operators of every stream in a document.
+func pageText(t *testing.T, doc []byte) string {
+	t.Helper()
+	var out strings.Builder
+	for _, m := range streamPattern.FindAllSubmatchIndex(doc, -1) {
+		length, _ := strconv.Atoi(string(doc[m[2]:m[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(doc[m[1] : m[1]+length]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		out.Write(content)
+	}
+	return out.String()
+}
+
+func testInvoice(items int) *models.Invoice {
+	invoice := &models.Invoice{
+		ID:            uuid.New(),
+		InvoiceNumber: "INV-2026-00042",
+		IssueDate:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		DueDate:       time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Currency:      "EUR",
+		SubTotal:      money.MustParse("1200"),
+		DiscountType:  models.DiscountTypePercentage,
+		DiscountValue: money.NewFromInt(10),
+		Discount:      money.MustParse("120"),
+		TaxTotal:      money.MustParse("216"),
+		TotalAmount:   money.MustParse("1296"),
+		AmountPaid:    money.MustParse("296"),
+		BalanceDue:    money.MustParse("1000"),
+		Status:        models.InvoiceStatusPartiallyPaid,
+		Note:          "Thank you for your business.",
+		User:          models.User{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Address: "1 Analytical Way\nLondon"},
+		Customer:      models.Customer{Name: "Acme GmbH", Email: "billing@acme.example", Address: "Hauptstraße 5\nBerlin", TaxNumber: "DE123456789"},
+		Taxes:         []models.InvoiceTax{{Name: "VAT", Rate: money.NewFromInt(20), Amount: money.MustParse("216")}},
+	}
+	for i := 0; i < items; i++ {
+		invoice.Items = append(invoice.Items, models.InvoiceItem{
+			Description: fmt.Sprintf("Consulting, week %d", i+1),
+			Quantity:    2,
+			UnitPrice:   money.NewFromInt(600),
+			Amount:      money.NewFromInt(1200),
+		})
+	}
+	return invoice
+}
+
+func TestRender(t *testing.T) {
+	details := &models.PaymentDetails{AccountName: "Ada Lovelace", AccountNumber: "DE89 3704 0044 0532 0130 00", BankName: "Example Bank"}
+	logo := image.NewRGBA(image.Rect(0, 0, 200, 100))
+
+	for _, name := range invoicepdf.Names() {
+		t.Run(name, func(t *testing.T) {
+			out, err := invoicepdf.Render(name, invoicepdf.Data{
+				Invoice:        testInvoice(1),
+				PaymentDetails: details,
+			}, invoicepdf.Branding{
+				Logo:       logo,
+				Accent:     invoicepdf.DefaultAccent,
+				FooterText: "Ada Lovelace Ltd, registered in England",
+			})
+			require.NoError(t, err)
+
+			text := pageText(t, out)
+			for _, want := range []string{
+				"(INV-2026-00042)",
+				"(01 Mar 2026)",
+				"(Acme GmbH)",
+				"(Hauptstra\xdfe 5)",
+				"(Tax number: DE123456789)",
+				"(Consulting, week 1)",
+				"(Discount \\(10%\\))",
+				"(VAT \\(20%\\))",
+				"(Total \\(EUR\\))",
+				"(1,296.00)",
+				"(Balance due \\(EUR\\))",
+				"(DE89 3704 0044 0532 0130 00)",
+				"(Thank you for your business.)",
+				"(Ada Lovelace Ltd, registered in England)",
+				"/Im1 Do",
+			} {
+				assert.True(t, strings.Contains(text, want), "missing %q", want)
+			}
+			assert.Contains(t, string(out), "/Count 1")
+		})
+	}
+}
+
+func TestRender_CreditNote(t *testing.T) {
+	invoice := testInvoice(1)
+	note := &models.CreditNote{
+		CreditNoteNumber: "CN-2026-00003",
+		IssueDate:        time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC),
+		Currency:         "EUR",
+		Reason:           "One week was not delivered.",
+		SubTotal:         money.MustParse("-600"),
+		Discount:         money.MustParse("-60"),
+		TaxTotal:         money.MustParse("-108"),
+		TotalAmount:      money.MustParse("-648"),
+		Taxes:            []models.CreditNoteTax{{Name: "VAT", Rate: money.NewFromInt(20), Amount: money.MustParse("-108")}},
+		Items: []models.CreditNoteItem{{
+			Description: "Consulting, week 1",
+			Quantity:    1,
+			UnitPrice:   money.MustParse("-600"),
+			Amount:      money.MustParse("-600"),
+		}},
+	}
+
+	for _, name := range invoicepdf.Names() {
+		t.Run(name, func(t *testing.T) {
+			out, err := invoicepdf.Render(name, invoicepdf.Data{
+				Invoice:    invoice,
+				CreditNote: note,
+			}, invoicepdf.Branding{Accent: invoicepdf.DefaultAccent})
+			require.NoError(t, err)
+
+			text := pageText(t, out)
+			for _, want := range []string{
+				"(CREDIT NOTE)",
+				"(CN-2026-00003)",
+				"(20 Mar 2026)",
+				"(INV-2026-00042)",
+				"(Acme GmbH)",
+				"(-600.00)",
+				"(60.00)",
+				"(-108.00)",
+				"(-648.00)",
+				"(One week was not delivered.)",
+			} {
+				assert.True(t, strings.Contains(text, want), "missing %q", want)
+			}
+			assert.NotContains(t, text, "(Due date)")
+			assert.NotContains(t, text, "(Balance due \\(EUR\\))")
+			assert.Contains(t, string(out), "Credit note CN-2026-00003")
+		})
+	}
+}
+
+func TestRender_MultiplePages(t *testing.T) {
+	out, err := invoicepdf.Render(invoicepdf.DefaultTemplate, invoicepdf.Data{
+		Invoice: testInvoice(80),
+	}, invoicepdf.Branding{Accent: invoicepdf.DefaultAccent})
+	require.NoError(t, err)
+
+	text := pageText(t, out)
+	assert.Contains(t, text, "(Consulting, week 80)")
+	assert.Contains(t, text, "(Page 3)")
+	// The table header is repeated on every page
+	assert.GreaterOrEqual(t, strings.Count(text, "(DESCRIPTION)"), 3)
+}
+
+func TestRender_UnknownTemplate(t *testing.T) {
+	_, err := invoicepdf.Render("fancy", invoicepdf.Data{Invoice: testInvoice(1)}, invoicepdf.Branding{})
+	assert.EqualError(t, err, `unknown invoice template "fancy"`)
+}
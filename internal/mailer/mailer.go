@@ -0,0 +1,62 @@
+// Package mailer sends email. Sender is implemented over SMTP for production
+// and in memory for tests.
+package mailer
+
+import (
+	"errors"
+	"strings"
+)
+
+// Message is an email. Body is the plain text version; HTMLBody, when set, is
+// offered as an alternative. ReplyTo, Cc, Bcc and Attachments are optional.
+type Message struct {
+	To          string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Body        string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with a message.
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(msg Message) error
+}
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+// validate rejects messages that cannot be sent, including header values that
+// would inject extra headers.
+func (m Message) validate() error {
+	if strings.TrimSpace(m.To) == "" {
+		return ErrNoRecipient
+	}
+	headers := []string{m.To, m.ReplyTo, m.Subject}
+	headers = append(headers, m.Cc...)
+	headers = append(headers, m.Bcc...)
+	for _, attachment := range m.Attachments {
+		headers = append(headers, attachment.FileName, attachment.ContentType)
+	}
+	for _, value := range headers {
+		if strings.ContainsAny(value, "\r\n") {
+			return errors.New("message headers may not contain line breaks")
+		}
+	}
+	return nil
+}
+
+// recipients returns every address the message is delivered to, Bcc included.
+func (m Message) recipients() []string {
+	all := []string{m.To}
+	all = append(all, m.Cc...)
+	return append(all, m.Bcc...)
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	sender := NewMemory()
+
+	require.NoError(t, sender.Send(Message{To: "ada@example.com", Subject: "Hello", Body: "Hi"}))
+	assert.ErrorIs(t, sender.Send(Message{Subject: "Nobody"}), ErrNoRecipient)
+	assert.Error(t, sender.Send(Message{To: "ada@example.com", Subject: "Hi\r\nBcc: eve@example.com"}))
+
+	sent := sender.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "Hello", sent[0].Subject)
+}
+
+func TestBuildMessage(t *testing.T) {
+	from := &mail.Address{Name: "Numeris", Address: "billing@example.com"}
+	date := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	data, err := buildMessage(from, Message{
+		To:      "ada@example.com",
+		ReplyTo: "grace@example.com",
+		Subject: "Invoice déjà vu",
+		Body:    "Amount due: €100.00",
+	}, date)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Numeris" <billing@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "grace@example.com", msg.Header.Get("Reply-To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Invoice déjà vu", subject)
+	assert.Contains(t, string(data), "Amount due: =E2=82=AC100.00")
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import "sync"
+
+// Memory keeps sent messages instead of delivering them. It is safe for
+// concurrent use.
+type Memory struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewMemory returns an empty in-memory sender.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns the messages sent so far, oldest first.
+func (m *Memory) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
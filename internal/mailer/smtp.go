@@ -0,0 +1,218 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is where and as whom mail is sent. Username and Password are
+// optional; when set, PLAIN authentication is used, which net/smtp only
+// allows over TLS or to localhost.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPConfigFromEnv reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME,
+// SMTP_PASSWORD and SMTP_FROM. It reports false when SMTP_HOST is not set.
+func SMTPConfigFromEnv() (SMTPConfig, bool) {
+	config := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return config, config.Host != ""
+}
+
+// SMTPSender sends mail through an SMTP server.
+type SMTPSender struct {
+	config SMTPConfig
+	now    func() time.Time
+}
+
+// NewSMTPSender returns a sender for the given server.
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	return &SMTPSender{config: config, now: time.Now}
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	var recipients []string
+	for _, address := range msg.recipients() {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", address, err)
+		}
+		recipients = append(recipients, parsed.Address)
+	}
+	from, err := mail.ParseAddress(s.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	data, err := buildMessage(from, msg, s.now())
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(s.config.Host, s.config.Port)
+	return smtp.SendMail(addr, auth, from.Address, recipients, data)
+}
+
+// buildMessage renders msg as an RFC 5322 message. A plain text message is
+// sent as is; an HTML alternative or attachments make it multipart. Bcc
+// recipients are left out of the headers.
+func buildMessage(from *mail.Address, msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	header("From", from.String())
+	header("To", msg.To)
+	if len(msg.Cc) > 0 {
+		header("Cc", strings.Join(msg.Cc, ", "))
+	}
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	textHeader, text, err := textPart(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		for _, name := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			if value := textHeader.Get(name); value != "" {
+				header(name, value)
+			}
+		}
+		buf.WriteString("\r\n")
+		buf.Write(text)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	part, err := mixed.CreatePart(textHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(text); err != nil {
+		return nil, err
+	}
+	for _, attachment := range msg.Attachments {
+		if err := writeAttachment(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// textPart returns the headers and content of the message text: plain text
+// alone, or plain text and HTML as alternatives.
+func textPart(msg Message) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	if msg.HTMLBody == "" {
+		if err := writeQuotedPrintable(&buf, msg.Body); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buf.Bytes(), nil
+	}
+
+	alternative := multipart.NewWriter(&buf)
+	for _, text := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Body},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	} {
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {text.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(part, text.body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	}, buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeAttachment(w *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wrap the encoded content at 76 characters as RFC 2045 asks
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return err
+}
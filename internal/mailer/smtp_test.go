@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTP is a local SMTP stand-in that accepts one message and records the
+// envelope and data.
+type fakeSMTP struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       []byte
+	done       chan struct{}
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTP{listener: listener, done: make(chan struct{})}
+	go server.serve()
+	return server
+}
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+	reply("220 localhost ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			f.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			f.recipients = append(f.recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			f.data, err = text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (f *fakeSMTP) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "Numeris <billing@example.com>"}
+}
+
+func TestSMTPSender(t *testing.T) {
+	server := startFakeSMTP(t)
+	sender := NewSMTPSender(server.config())
+
+	err := sender.Send(Message{
+		To:       "ada@example.com",
+		Cc:       []string{"accounts@example.com"},
+		Bcc:      []string{"archive@example.com"},
+		Subject:  "Invoice INV-1",
+		Body:     "Please find the invoice attached.",
+		HTMLBody: "<p>Please find the invoice attached.</p>",
+		Attachments: []Attachment{
+			{FileName: "INV-1.pdf", ContentType: "application/pdf", Content: []byte(strings.Repeat("%PDF-1.4 ", 20))},
+		},
+	})
+	require.NoError(t, err)
+	<-server.done
+
+	assert.Equal(t, "billing@example.com", server.from)
+	assert.Equal(t, []string{"ada@example.com", "accounts@example.com", "archive@example.com"}, server.recipients)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(server.data)))
+	require.NoError(t, err)
+	assert.Equal(t, "accounts@example.com", msg.Header.Get("Cc"))
+	assert.Empty(t, msg.Header.Get("Bcc"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	text, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(text.Header.Get("Content-Type"), "multipart/alternative"))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "INV-1.pdf", attachment.FileName())
+	content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachment))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("%PDF-1.4 ", 20), string(content))
+}
+
+func TestSMTPSender_InvalidRecipient(t *testing.T) {
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: "1", From: "billing@example.com"})
+
+	err := sender.Send(Message{To: "ada@example.com", Cc: []string{"not an address"}, Subject: "Hi"})
+	assert.Error(t, err)
+}
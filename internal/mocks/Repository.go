@@ -4,9 +4,11 @@ package mocks
 
 import (
 	models "github.com/iyiola-dev/numeris/internal/models"
+	repository "github.com/iyiola-dev/numeris/internal/repository"
 	mock "github.com/stretchr/testify/mock"
 
 	uuid "github.com/google/uuid"
+	time "time"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -14,6 +16,174 @@ type Repository struct {
 	mock.Mock
 }
 
+// ClaimInvoiceReminder provides a mock function with given fields: reminder
+func (_m *Repository) ClaimInvoiceReminder(reminder *models.InvoiceReminder) (bool, error) {
+	ret := _m.Called(reminder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimInvoiceReminder")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.InvoiceReminder) (bool, error)); ok {
+		return rf(reminder)
+	}
+	if rf, ok := ret.Get(0).(func(*models.InvoiceReminder) bool); ok {
+		r0 = rf(reminder)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.InvoiceReminder) error); ok {
+		r1 = rf(reminder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimLateFee provides a mock function with given fields: fee
+func (_m *Repository) ClaimLateFee(fee *models.LateFee) (bool, error) {
+	ret := _m.Called(fee)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimLateFee")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.LateFee) (bool, error)); ok {
+		return rf(fee)
+	}
+	if rf, ok := ret.Get(0).(func(*models.LateFee) bool); ok {
+		r0 = rf(fee)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.LateFee) error); ok {
+		r1 = rf(fee)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimQuoteConversion provides a mock function with given fields: id, at
+func (_m *Repository) ClaimQuoteConversion(id uuid.UUID, at time.Time) (bool, error) {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimQuoteConversion")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) (bool, error)); ok {
+		return rf(id, at)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) bool); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(id, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimRecurringInvoiceRun provides a mock function with given fields: run
+func (_m *Repository) ClaimRecurringInvoiceRun(run *models.RecurringInvoiceRun) (bool, error) {
+	ret := _m.Called(run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimRecurringInvoiceRun")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.RecurringInvoiceRun) (bool, error)); ok {
+		return rf(run)
+	}
+	if rf, ok := ret.Get(0).(func(*models.RecurringInvoiceRun) bool); ok {
+		r0 = rf(run)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.RecurringInvoiceRun) error); ok {
+		r1 = rf(run)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimRefreshToken provides a mock function with given fields: id, at
+func (_m *Repository) ClaimRefreshToken(id uuid.UUID, at time.Time) (bool, error) {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimRefreshToken")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) (bool, error)); ok {
+		return rf(id, at)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) bool); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(id, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimUserToken provides a mock function with given fields: id, at
+func (_m *Repository) ClaimUserToken(id uuid.UUID, at time.Time) (bool, error) {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimUserToken")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) (bool, error)); ok {
+		return rf(id, at)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) bool); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(id, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateActivityLog provides a mock function with given fields: log
 func (_m *Repository) CreateActivityLog(log *models.ActivityLog) error {
 	ret := _m.Called(log)
@@ -32,6 +202,24 @@ func (_m *Repository) CreateActivityLog(log *models.ActivityLog) error {
 	return r0
 }
 
+// CreateCreditNote provides a mock function with given fields: note
+func (_m *Repository) CreateCreditNote(note *models.CreditNote) error {
+	ret := _m.Called(note)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCreditNote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CreditNote) error); ok {
+		r0 = rf(note)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateCustomer provides a mock function with given fields: customer
 func (_m *Repository) CreateCustomer(customer *models.Customer) error {
 	ret := _m.Called(customer)
@@ -68,6 +256,24 @@ func (_m *Repository) CreateInvoice(invoice *models.Invoice) error {
 	return r0
 }
 
+// CreateInvoiceDelivery provides a mock function with given fields: delivery
+func (_m *Repository) CreateInvoiceDelivery(delivery *models.InvoiceDelivery) error {
+	ret := _m.Called(delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateInvoiceDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.InvoiceDelivery) error); ok {
+		r0 = rf(delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateInvoiceItem provides a mock function with given fields: item
 func (_m *Repository) CreateInvoiceItem(item *models.InvoiceItem) error {
 	ret := _m.Called(item)
@@ -86,6 +292,42 @@ func (_m *Repository) CreateInvoiceItem(item *models.InvoiceItem) error {
 	return r0
 }
 
+// CreateInvoiceRevision provides a mock function with given fields: revision
+func (_m *Repository) CreateInvoiceRevision(revision *models.InvoiceRevision) error {
+	ret := _m.Called(revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateInvoiceRevision")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.InvoiceRevision) error); ok {
+		r0 = rf(revision)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreatePayment provides a mock function with given fields: payment
+func (_m *Repository) CreatePayment(payment *models.Payment) error {
+	ret := _m.Called(payment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Payment) error); ok {
+		r0 = rf(payment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreatePaymentDetails provides a mock function with given fields: details
 func (_m *Repository) CreatePaymentDetails(details *models.PaymentDetails) error {
 	ret := _m.Called(details)
@@ -104,6 +346,114 @@ func (_m *Repository) CreatePaymentDetails(details *models.PaymentDetails) error
 	return r0
 }
 
+// CreateQuote provides a mock function with given fields: quote
+func (_m *Repository) CreateQuote(quote *models.Quote) error {
+	ret := _m.Called(quote)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateQuote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Quote) error); ok {
+		r0 = rf(quote)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateRecurringInvoice provides a mock function with given fields: recurring
+func (_m *Repository) CreateRecurringInvoice(recurring *models.RecurringInvoice) error {
+	ret := _m.Called(recurring)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRecurringInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.RecurringInvoice) error); ok {
+		r0 = rf(recurring)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateRefreshToken provides a mock function with given fields: token
+func (_m *Repository) CreateRefreshToken(token *models.RefreshToken) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.RefreshToken) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: session
+func (_m *Repository) CreateSession(session *models.Session) error {
+	ret := _m.Called(session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Session) error); ok {
+		r0 = rf(session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateShareLink provides a mock function with given fields: link
+func (_m *Repository) CreateShareLink(link *models.ShareLink) error {
+	ret := _m.Called(link)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateShareLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.ShareLink) error); ok {
+		r0 = rf(link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateTaxRate provides a mock function with given fields: rate
+func (_m *Repository) CreateTaxRate(rate *models.TaxRate) error {
+	ret := _m.Called(rate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTaxRate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.TaxRate) error); ok {
+		r0 = rf(rate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateUser provides a mock function with given fields: user
 func (_m *Repository) CreateUser(user *models.User) error {
 	ret := _m.Called(user)
@@ -122,6 +472,24 @@ func (_m *Repository) CreateUser(user *models.User) error {
 	return r0
 }
 
+// CreateUserToken provides a mock function with given fields: token
+func (_m *Repository) CreateUserToken(token *models.UserToken) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUserToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.UserToken) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteCustomer provides a mock function with given fields: id
 func (_m *Repository) DeleteCustomer(id uuid.UUID) error {
 	ret := _m.Called(id)
@@ -176,12 +544,12 @@ func (_m *Repository) DeleteInvoiceItem(id uuid.UUID) error {
 	return r0
 }
 
-// DeletePaymentDetails provides a mock function with given fields: id
-func (_m *Repository) DeletePaymentDetails(id uuid.UUID) error {
+// DeleteInvoiceReminder provides a mock function with given fields: id
+func (_m *Repository) DeleteInvoiceReminder(id uuid.UUID) error {
 	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeletePaymentDetails")
+		panic("no return value specified for DeleteInvoiceReminder")
 	}
 
 	var r0 error
@@ -194,12 +562,12 @@ func (_m *Repository) DeletePaymentDetails(id uuid.UUID) error {
 	return r0
 }
 
-// DeleteUser provides a mock function with given fields: id
-func (_m *Repository) DeleteUser(id uuid.UUID) error {
+// DeleteLateFeePolicy provides a mock function with given fields: id
+func (_m *Repository) DeleteLateFeePolicy(id uuid.UUID) error {
 	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteUser")
+		panic("no return value specified for DeleteLateFeePolicy")
 	}
 
 	var r0 error
@@ -212,59 +580,135 @@ func (_m *Repository) DeleteUser(id uuid.UUID) error {
 	return r0
 }
 
-// GetActivityLogs provides a mock function with given fields: filters
-func (_m *Repository) GetActivityLogs(filters map[string]interface{}) ([]models.ActivityLog, error) {
-	ret := _m.Called(filters)
+// DeletePayment provides a mock function with given fields: id
+func (_m *Repository) DeletePayment(id uuid.UUID) error {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetActivityLogs")
+		panic("no return value specified for DeletePayment")
 	}
 
-	var r0 []models.ActivityLog
-	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.ActivityLog, error)); ok {
-		return rf(filters)
-	}
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.ActivityLog); ok {
-		r0 = rf(filters)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.ActivityLog)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
-		r1 = rf(filters)
-	} else {
-		r1 = ret.Error(1)
-	}
+	return r0
+}
 
-	return r0, r1
+// DeletePaymentDetails provides a mock function with given fields: id
+func (_m *Repository) DeletePaymentDetails(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePaymentDetails")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// GetCustomerByID provides a mock function with given fields: id
-func (_m *Repository) GetCustomerByID(id uuid.UUID) (*models.Customer, error) {
+// DeleteQuote provides a mock function with given fields: id
+func (_m *Repository) DeleteQuote(id uuid.UUID) error {
 	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCustomerByID")
+		panic("no return value specified for DeleteQuote")
 	}
 
-	var r0 *models.Customer
-	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Customer, error)); ok {
-		return rf(id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Customer); ok {
+
+	return r0
+}
+
+// DeleteRecurringInvoice provides a mock function with given fields: id
+func (_m *Repository) DeleteRecurringInvoice(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRecurringInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
 		r0 = rf(id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Customer)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(id)
+	return r0
+}
+
+// DeleteTaxRate provides a mock function with given fields: id
+func (_m *Repository) DeleteTaxRate(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTaxRate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUser provides a mock function with given fields: id
+func (_m *Repository) DeleteUser(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpireQuotes provides a mock function with given fields: expiredBefore
+func (_m *Repository) ExpireQuotes(expiredBefore time.Time) (int64, error) {
+	ret := _m.Called(expiredBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireQuotes")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(expiredBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(expiredBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(expiredBefore)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -272,29 +716,47 @@ func (_m *Repository) GetCustomerByID(id uuid.UUID) (*models.Customer, error) {
 	return r0, r1
 }
 
-// GetCustomers provides a mock function with given fields: filters
-func (_m *Repository) GetCustomers(filters map[string]interface{}) ([]models.Customer, error) {
-	ret := _m.Called(filters)
+// ExpireUserTokens provides a mock function with given fields: userID, purpose, at
+func (_m *Repository) ExpireUserTokens(userID uuid.UUID, purpose string, at time.Time) error {
+	ret := _m.Called(userID, purpose, at)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCustomers")
+		panic("no return value specified for ExpireUserTokens")
 	}
 
-	var r0 []models.Customer
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, time.Time) error); ok {
+		r0 = rf(userID, purpose, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActivityLogs provides a mock function with given fields: filter
+func (_m *Repository) GetActivityLogs(filter repository.ActivityLogFilter) ([]models.ActivityLog, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActivityLogs")
+	}
+
+	var r0 []models.ActivityLog
 	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Customer, error)); ok {
-		return rf(filters)
+	if rf, ok := ret.Get(0).(func(repository.ActivityLogFilter) ([]models.ActivityLog, error)); ok {
+		return rf(filter)
 	}
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Customer); ok {
-		r0 = rf(filters)
+	if rf, ok := ret.Get(0).(func(repository.ActivityLogFilter) []models.ActivityLog); ok {
+		r0 = rf(filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Customer)
+			r0 = ret.Get(0).([]models.ActivityLog)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
-		r1 = rf(filters)
+	if rf, ok := ret.Get(1).(func(repository.ActivityLogFilter) error); ok {
+		r1 = rf(filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -302,24 +764,24 @@ func (_m *Repository) GetCustomers(filters map[string]interface{}) ([]models.Cus
 	return r0, r1
 }
 
-// GetInvoiceByID provides a mock function with given fields: id
-func (_m *Repository) GetInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
+// GetCreditNoteByID provides a mock function with given fields: id
+func (_m *Repository) GetCreditNoteByID(id uuid.UUID) (*models.CreditNote, error) {
 	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetInvoiceByID")
+		panic("no return value specified for GetCreditNoteByID")
 	}
 
-	var r0 *models.Invoice
+	var r0 *models.CreditNote
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Invoice, error)); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.CreditNote, error)); ok {
 		return rf(id)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Invoice); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.CreditNote); ok {
 		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Invoice)
+			r0 = ret.Get(0).(*models.CreditNote)
 		}
 	}
 
@@ -332,29 +794,29 @@ func (_m *Repository) GetInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
 	return r0, r1
 }
 
-// GetInvoiceItems provides a mock function with given fields: invoiceID
-func (_m *Repository) GetInvoiceItems(invoiceID uuid.UUID) ([]models.InvoiceItem, error) {
-	ret := _m.Called(invoiceID)
+// GetCreditNoteSequence provides a mock function with given fields: userID
+func (_m *Repository) GetCreditNoteSequence(userID uuid.UUID) (*models.CreditNoteSequence, error) {
+	ret := _m.Called(userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetInvoiceItems")
+		panic("no return value specified for GetCreditNoteSequence")
 	}
 
-	var r0 []models.InvoiceItem
+	var r0 *models.CreditNoteSequence
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.InvoiceItem, error)); ok {
-		return rf(invoiceID)
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.CreditNoteSequence, error)); ok {
+		return rf(userID)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.InvoiceItem); ok {
-		r0 = rf(invoiceID)
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.CreditNoteSequence); ok {
+		r0 = rf(userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.InvoiceItem)
+			r0 = ret.Get(0).(*models.CreditNoteSequence)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(invoiceID)
+		r1 = rf(userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -362,24 +824,24 @@ func (_m *Repository) GetInvoiceItems(invoiceID uuid.UUID) ([]models.InvoiceItem
 	return r0, r1
 }
 
-// GetInvoices provides a mock function with given fields: filters
-func (_m *Repository) GetInvoices(filters map[string]interface{}) ([]models.Invoice, error) {
+// GetCreditNotes provides a mock function with given fields: filters
+func (_m *Repository) GetCreditNotes(filters map[string]interface{}) ([]models.CreditNote, error) {
 	ret := _m.Called(filters)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetInvoices")
+		panic("no return value specified for GetCreditNotes")
 	}
 
-	var r0 []models.Invoice
+	var r0 []models.CreditNote
 	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Invoice, error)); ok {
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.CreditNote, error)); ok {
 		return rf(filters)
 	}
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Invoice); ok {
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.CreditNote); ok {
 		r0 = rf(filters)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Invoice)
+			r0 = ret.Get(0).([]models.CreditNote)
 		}
 	}
 
@@ -392,29 +854,29 @@ func (_m *Repository) GetInvoices(filters map[string]interface{}) ([]models.Invo
 	return r0, r1
 }
 
-// GetPaymentDetailsByInvoiceID provides a mock function with given fields: invoiceID
-func (_m *Repository) GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models.PaymentDetails, error) {
-	ret := _m.Called(invoiceID)
+// GetCustomerByID provides a mock function with given fields: id
+func (_m *Repository) GetCustomerByID(id uuid.UUID) (*models.Customer, error) {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetPaymentDetailsByInvoiceID")
+		panic("no return value specified for GetCustomerByID")
 	}
 
-	var r0 *models.PaymentDetails
+	var r0 *models.Customer
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.PaymentDetails, error)); ok {
-		return rf(invoiceID)
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Customer, error)); ok {
+		return rf(id)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.PaymentDetails); ok {
-		r0 = rf(invoiceID)
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Customer); ok {
+		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.PaymentDetails)
+			r0 = ret.Get(0).(*models.Customer)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(invoiceID)
+		r1 = rf(id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -422,24 +884,54 @@ func (_m *Repository) GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models
 	return r0, r1
 }
 
-// GetUserByID provides a mock function with given fields: id
-func (_m *Repository) GetUserByID(id uuid.UUID) (*models.User, error) {
+// GetCustomers provides a mock function with given fields: filters
+func (_m *Repository) GetCustomers(filters map[string]interface{}) ([]models.Customer, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomers")
+	}
+
+	var r0 []models.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Customer, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Customer); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedInvoiceByID provides a mock function with given fields: id
+func (_m *Repository) GetDeletedInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
 	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserByID")
+		panic("no return value specified for GetDeletedInvoiceByID")
 	}
 
-	var r0 *models.User
+	var r0 *models.Invoice
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.User, error)); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Invoice, error)); ok {
 		return rf(id)
 	}
-	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.User); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Invoice); ok {
 		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
+			r0 = ret.Get(0).(*models.Invoice)
 		}
 	}
 
@@ -452,24 +944,24 @@ func (_m *Repository) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return r0, r1
 }
 
-// GetUsers provides a mock function with given fields: filters
-func (_m *Repository) GetUsers(filters map[string]interface{}) ([]models.User, error) {
+// GetDeletedInvoices provides a mock function with given fields: filters
+func (_m *Repository) GetDeletedInvoices(filters map[string]interface{}) ([]models.Invoice, error) {
 	ret := _m.Called(filters)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUsers")
+		panic("no return value specified for GetDeletedInvoices")
 	}
 
-	var r0 []models.User
+	var r0 []models.Invoice
 	var r1 error
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.User, error)); ok {
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Invoice, error)); ok {
 		return rf(filters)
 	}
-	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.User); ok {
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Invoice); ok {
 		r0 = rf(filters)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.User)
+			r0 = ret.Get(0).([]models.Invoice)
 		}
 	}
 
@@ -482,48 +974,1492 @@ func (_m *Repository) GetUsers(filters map[string]interface{}) ([]models.User, e
 	return r0, r1
 }
 
-// UpdateCustomer provides a mock function with given fields: id, customer
-func (_m *Repository) UpdateCustomer(id uuid.UUID, customer *models.Customer) error {
-	ret := _m.Called(id, customer)
+// GetDueRecurringInvoices provides a mock function with given fields: now
+func (_m *Repository) GetDueRecurringInvoices(now time.Time) ([]models.RecurringInvoice, error) {
+	ret := _m.Called(now)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateCustomer")
+		panic("no return value specified for GetDueRecurringInvoices")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.Customer) error); ok {
-		r0 = rf(id, customer)
-	} else {
-		r0 = ret.Error(0)
+	var r0 []models.RecurringInvoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]models.RecurringInvoice, error)); ok {
+		return rf(now)
 	}
-
-	return r0
-}
-
-// UpdateInvoice provides a mock function with given fields: id, invoice
-func (_m *Repository) UpdateInvoice(id uuid.UUID, invoice *models.Invoice) error {
-	ret := _m.Called(id, invoice)
-
-	if len(ret) == 0 {
-		panic("no return value specified for UpdateInvoice")
+	if rf, ok := ret.Get(0).(func(time.Time) []models.RecurringInvoice); ok {
+		r0 = rf(now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.RecurringInvoice)
+		}
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.Invoice) error); ok {
-		r0 = rf(id, invoice)
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(now)
 	} else {
-		r0 = ret.Error(0)
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
-// UpdateInvoiceItem provides a mock function with given fields: id, item
-func (_m *Repository) UpdateInvoiceItem(id uuid.UUID, item *models.InvoiceItem) error {
-	ret := _m.Called(id, item)
+// GetInvoiceByID provides a mock function with given fields: id
+func (_m *Repository) GetInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateInvoiceItem")
+		panic("no return value specified for GetInvoiceByID")
+	}
+
+	var r0 *models.Invoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Invoice, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Invoice); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Invoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceDeliveries provides a mock function with given fields: filters
+func (_m *Repository) GetInvoiceDeliveries(filters map[string]interface{}) ([]models.InvoiceDelivery, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceDeliveries")
+	}
+
+	var r0 []models.InvoiceDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.InvoiceDelivery, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.InvoiceDelivery); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InvoiceDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceItems provides a mock function with given fields: invoiceID
+func (_m *Repository) GetInvoiceItems(invoiceID uuid.UUID) ([]models.InvoiceItem, error) {
+	ret := _m.Called(invoiceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceItems")
+	}
+
+	var r0 []models.InvoiceItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.InvoiceItem, error)); ok {
+		return rf(invoiceID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.InvoiceItem); ok {
+		r0 = rf(invoiceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InvoiceItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(invoiceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceRevision provides a mock function with given fields: invoiceID, number
+func (_m *Repository) GetInvoiceRevision(invoiceID uuid.UUID, number int) (*models.InvoiceRevision, error) {
+	ret := _m.Called(invoiceID, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceRevision")
+	}
+
+	var r0 *models.InvoiceRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) (*models.InvoiceRevision, error)); ok {
+		return rf(invoiceID, number)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) *models.InvoiceRevision); ok {
+		r0 = rf(invoiceID, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InvoiceRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(invoiceID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceRevisions provides a mock function with given fields: invoiceID
+func (_m *Repository) GetInvoiceRevisions(invoiceID uuid.UUID) ([]models.InvoiceRevision, error) {
+	ret := _m.Called(invoiceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceRevisions")
+	}
+
+	var r0 []models.InvoiceRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]models.InvoiceRevision, error)); ok {
+		return rf(invoiceID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []models.InvoiceRevision); ok {
+		r0 = rf(invoiceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InvoiceRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(invoiceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceSequence provides a mock function with given fields: userID
+func (_m *Repository) GetInvoiceSequence(userID uuid.UUID) (*models.InvoiceSequence, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceSequence")
+	}
+
+	var r0 *models.InvoiceSequence
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.InvoiceSequence, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.InvoiceSequence); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InvoiceSequence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoiceTemplateSettings provides a mock function with given fields: userID
+func (_m *Repository) GetInvoiceTemplateSettings(userID uuid.UUID) (*models.InvoiceTemplateSettings, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoiceTemplateSettings")
+	}
+
+	var r0 *models.InvoiceTemplateSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.InvoiceTemplateSettings, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.InvoiceTemplateSettings); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InvoiceTemplateSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvoices provides a mock function with given fields: filters
+func (_m *Repository) GetInvoices(filters map[string]interface{}) ([]models.Invoice, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvoices")
+	}
+
+	var r0 []models.Invoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Invoice, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Invoice); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Invoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLateFeeByID provides a mock function with given fields: id
+func (_m *Repository) GetLateFeeByID(id uuid.UUID) (*models.LateFee, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLateFeeByID")
+	}
+
+	var r0 *models.LateFee
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.LateFee, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.LateFee); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LateFee)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLateFeePolicy provides a mock function with given fields: userID, invoiceID
+func (_m *Repository) GetLateFeePolicy(userID uuid.UUID, invoiceID *uuid.UUID) (*models.LateFeePolicy, error) {
+	ret := _m.Called(userID, invoiceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLateFeePolicy")
+	}
+
+	var r0 *models.LateFeePolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *uuid.UUID) (*models.LateFeePolicy, error)); ok {
+		return rf(userID, invoiceID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *uuid.UUID) *models.LateFeePolicy); ok {
+		r0 = rf(userID, invoiceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LateFeePolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *uuid.UUID) error); ok {
+		r1 = rf(userID, invoiceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLateFees provides a mock function with given fields: filters
+func (_m *Repository) GetLateFees(filters map[string]interface{}) ([]models.LateFee, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLateFees")
+	}
+
+	var r0 []models.LateFee
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.LateFee, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.LateFee); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LateFee)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOutstandingInvoices provides a mock function with given fields: dueBefore
+func (_m *Repository) GetOutstandingInvoices(dueBefore time.Time) ([]models.Invoice, error) {
+	ret := _m.Called(dueBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOutstandingInvoices")
+	}
+
+	var r0 []models.Invoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]models.Invoice, error)); ok {
+		return rf(dueBefore)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []models.Invoice); ok {
+		r0 = rf(dueBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Invoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(dueBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByID provides a mock function with given fields: id
+func (_m *Repository) GetPaymentByID(id uuid.UUID) (*models.Payment, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPaymentByID")
+	}
+
+	var r0 *models.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Payment, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Payment); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentDetailsByInvoiceID provides a mock function with given fields: invoiceID
+func (_m *Repository) GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models.PaymentDetails, error) {
+	ret := _m.Called(invoiceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPaymentDetailsByInvoiceID")
+	}
+
+	var r0 *models.PaymentDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.PaymentDetails, error)); ok {
+		return rf(invoiceID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.PaymentDetails); ok {
+		r0 = rf(invoiceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PaymentDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(invoiceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPayments provides a mock function with given fields: filters
+func (_m *Repository) GetPayments(filters map[string]interface{}) ([]models.Payment, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPayments")
+	}
+
+	var r0 []models.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Payment, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Payment); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuoteByID provides a mock function with given fields: id
+func (_m *Repository) GetQuoteByID(id uuid.UUID) (*models.Quote, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuoteByID")
+	}
+
+	var r0 *models.Quote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Quote, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Quote); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Quote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuoteByTokenHash provides a mock function with given fields: hash
+func (_m *Repository) GetQuoteByTokenHash(hash string) (*models.Quote, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuoteByTokenHash")
+	}
+
+	var r0 *models.Quote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.Quote, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.Quote); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Quote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuoteSequence provides a mock function with given fields: userID
+func (_m *Repository) GetQuoteSequence(userID uuid.UUID) (*models.QuoteSequence, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuoteSequence")
+	}
+
+	var r0 *models.QuoteSequence
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.QuoteSequence, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.QuoteSequence); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.QuoteSequence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuotes provides a mock function with given fields: filters
+func (_m *Repository) GetQuotes(filters map[string]interface{}) ([]models.Quote, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuotes")
+	}
+
+	var r0 []models.Quote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.Quote, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.Quote); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Quote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecurringInvoiceByID provides a mock function with given fields: id
+func (_m *Repository) GetRecurringInvoiceByID(id uuid.UUID) (*models.RecurringInvoice, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecurringInvoiceByID")
+	}
+
+	var r0 *models.RecurringInvoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.RecurringInvoice, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.RecurringInvoice); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RecurringInvoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecurringInvoices provides a mock function with given fields: filters
+func (_m *Repository) GetRecurringInvoices(filters map[string]interface{}) ([]models.RecurringInvoice, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecurringInvoices")
+	}
+
+	var r0 []models.RecurringInvoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.RecurringInvoice, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.RecurringInvoice); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.RecurringInvoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRefreshTokenByHash provides a mock function with given fields: hash
+func (_m *Repository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRefreshTokenByHash")
+	}
+
+	var r0 *models.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.RefreshToken, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.RefreshToken); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReminderSettings provides a mock function with given fields: userID
+func (_m *Repository) GetReminderSettings(userID uuid.UUID) (*models.ReminderSettings, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReminderSettings")
+	}
+
+	var r0 *models.ReminderSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.ReminderSettings, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.ReminderSettings); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ReminderSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessionByID provides a mock function with given fields: id
+func (_m *Repository) GetSessionByID(id uuid.UUID) (*models.Session, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionByID")
+	}
+
+	var r0 *models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Session, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Session); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShareLinkByTokenHash provides a mock function with given fields: hash
+func (_m *Repository) GetShareLinkByTokenHash(hash string) (*models.ShareLink, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShareLinkByTokenHash")
+	}
+
+	var r0 *models.ShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.ShareLink, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.ShareLink); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShareLinks provides a mock function with given fields: filters
+func (_m *Repository) GetShareLinks(filters map[string]interface{}) ([]models.ShareLink, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShareLinks")
+	}
+
+	var r0 []models.ShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.ShareLink, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.ShareLink); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTaxRateByID provides a mock function with given fields: id
+func (_m *Repository) GetTaxRateByID(id uuid.UUID) (*models.TaxRate, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTaxRateByID")
+	}
+
+	var r0 *models.TaxRate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.TaxRate, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.TaxRate); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.TaxRate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTaxRates provides a mock function with given fields: filters
+func (_m *Repository) GetTaxRates(filters map[string]interface{}) ([]models.TaxRate, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTaxRates")
+	}
+
+	var r0 []models.TaxRate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.TaxRate, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.TaxRate); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TaxRate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByID provides a mock function with given fields: id
+func (_m *Repository) GetUserByID(id uuid.UUID) (*models.User, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.User, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.User); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTokenByHash provides a mock function with given fields: hash
+func (_m *Repository) GetUserTokenByHash(hash string) (*models.UserToken, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTokenByHash")
+	}
+
+	var r0 *models.UserToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.UserToken, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.UserToken); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: filters
+func (_m *Repository) GetUsers(filters map[string]interface{}) ([]models.User, error) {
+	ret := _m.Called(filters)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsers")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) ([]models.User, error)); ok {
+		return rf(filters)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) []models.User); ok {
+		r0 = rf(filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) error); ok {
+		r1 = rf(filters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockInvoice provides a mock function with given fields: id
+func (_m *Repository) LockInvoice(id uuid.UUID) (*models.Invoice, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockInvoice")
+	}
+
+	var r0 *models.Invoice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*models.Invoice, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Invoice); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Invoice)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NumberInvoice provides a mock function with given fields: invoice
+func (_m *Repository) NumberInvoice(invoice *models.Invoice) error {
+	ret := _m.Called(invoice)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NumberInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Invoice) error); ok {
+		r0 = rf(invoice)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordShareLinkView provides a mock function with given fields: id, at, since
+func (_m *Repository) RecordShareLinkView(id uuid.UUID, at time.Time, since time.Time) (bool, error) {
+	ret := _m.Called(id, at, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordShareLinkView")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, time.Time) (bool, error)); ok {
+		return rf(id, at, since)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, time.Time) bool); ok {
+		r0 = rf(id, at, since)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time, time.Time) error); ok {
+		r1 = rf(id, at, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseQuoteConversion provides a mock function with given fields: id
+func (_m *Repository) ReleaseQuoteConversion(id uuid.UUID) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseQuoteConversion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceInvoiceItemTaxes provides a mock function with given fields: itemID, taxes
+func (_m *Repository) ReplaceInvoiceItemTaxes(itemID uuid.UUID, taxes []models.InvoiceItemTax) error {
+	ret := _m.Called(itemID, taxes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceInvoiceItemTaxes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.InvoiceItemTax) error); ok {
+		r0 = rf(itemID, taxes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceInvoiceTaxes provides a mock function with given fields: invoiceID, taxes
+func (_m *Repository) ReplaceInvoiceTaxes(invoiceID uuid.UUID, taxes []models.InvoiceTax) error {
+	ret := _m.Called(invoiceID, taxes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceInvoiceTaxes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.InvoiceTax) error); ok {
+		r0 = rf(invoiceID, taxes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceQuoteItems provides a mock function with given fields: quoteID, items
+func (_m *Repository) ReplaceQuoteItems(quoteID uuid.UUID, items []models.QuoteItem) error {
+	ret := _m.Called(quoteID, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceQuoteItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.QuoteItem) error); ok {
+		r0 = rf(quoteID, items)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceRecurringInvoiceItems provides a mock function with given fields: recurringID, items
+func (_m *Repository) ReplaceRecurringInvoiceItems(recurringID uuid.UUID, items []models.RecurringInvoiceItem) error {
+	ret := _m.Called(recurringID, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceRecurringInvoiceItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, []models.RecurringInvoiceItem) error); ok {
+		r0 = rf(recurringID, items)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreInvoice provides a mock function with given fields: id, deletedAt
+func (_m *Repository) RestoreInvoice(id uuid.UUID, deletedAt time.Time) error {
+	ret := _m.Called(id, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, deletedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReverseLateFee provides a mock function with given fields: id, at, reason
+func (_m *Repository) ReverseLateFee(id uuid.UUID, at time.Time, reason string) error {
+	ret := _m.Called(id, at, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReverseLateFee")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, string) error); ok {
+		r0 = rf(id, at, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeOtherSessions provides a mock function with given fields: userID, keepID, at, reason
+func (_m *Repository) RevokeOtherSessions(userID uuid.UUID, keepID uuid.UUID, at time.Time, reason string) (int, error) {
+	ret := _m.Called(userID, keepID, at, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOtherSessions")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, time.Time, string) (int, error)); ok {
+		return rf(userID, keepID, at, reason)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID, time.Time, string) int); ok {
+		r0 = rf(userID, keepID, at, reason)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, uuid.UUID, time.Time, string) error); ok {
+		r1 = rf(userID, keepID, at, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: id, at, reason
+func (_m *Repository) RevokeSession(id uuid.UUID, at time.Time, reason string) error {
+	ret := _m.Called(id, at, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, string) error); ok {
+		r0 = rf(id, at, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeShareLink provides a mock function with given fields: id, at
+func (_m *Repository) RevokeShareLink(id uuid.UUID, at time.Time) error {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeShareLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeUserSessions provides a mock function with given fields: userID, at, reason
+func (_m *Repository) RevokeUserSessions(userID uuid.UUID, at time.Time, reason string) (int, error) {
+	ret := _m.Called(userID, at, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeUserSessions")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, string) (int, error)); ok {
+		return rf(userID, at, reason)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time, string) int); ok {
+		r0 = rf(userID, at, reason)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time, string) error); ok {
+		r1 = rf(userID, at, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveCreditNoteSequence provides a mock function with given fields: seq
+func (_m *Repository) SaveCreditNoteSequence(seq *models.CreditNoteSequence) error {
+	ret := _m.Called(seq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCreditNoteSequence")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.CreditNoteSequence) error); ok {
+		r0 = rf(seq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveInvoiceSequence provides a mock function with given fields: seq
+func (_m *Repository) SaveInvoiceSequence(seq *models.InvoiceSequence) error {
+	ret := _m.Called(seq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveInvoiceSequence")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.InvoiceSequence) error); ok {
+		r0 = rf(seq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveInvoiceTemplateSettings provides a mock function with given fields: settings
+func (_m *Repository) SaveInvoiceTemplateSettings(settings *models.InvoiceTemplateSettings) error {
+	ret := _m.Called(settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveInvoiceTemplateSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.InvoiceTemplateSettings) error); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLateFeePolicy provides a mock function with given fields: policy
+func (_m *Repository) SaveLateFeePolicy(policy *models.LateFeePolicy) error {
+	ret := _m.Called(policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveLateFeePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.LateFeePolicy) error); ok {
+		r0 = rf(policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveQuoteSequence provides a mock function with given fields: seq
+func (_m *Repository) SaveQuoteSequence(seq *models.QuoteSequence) error {
+	ret := _m.Called(seq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveQuoteSequence")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.QuoteSequence) error); ok {
+		r0 = rf(seq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveReminderSettings provides a mock function with given fields: settings
+func (_m *Repository) SaveReminderSettings(settings *models.ReminderSettings) error {
+	ret := _m.Called(settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveReminderSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.ReminderSettings) error); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetRecurringInvoiceRunInvoice provides a mock function with given fields: runID, invoiceID
+func (_m *Repository) SetRecurringInvoiceRunInvoice(runID uuid.UUID, invoiceID uuid.UUID) error {
+	ret := _m.Called(runID, invoiceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRecurringInvoiceRunInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(runID, invoiceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchSession provides a mock function with given fields: id, at
+func (_m *Repository) TouchSession(id uuid.UUID, at time.Time) error {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransitionQuote provides a mock function with given fields: quote, from
+func (_m *Repository) TransitionQuote(quote *models.Quote, from ...string) (bool, error) {
+	ret := _m.Called(quote, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TransitionQuote")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.Quote, ...string) (bool, error)); ok {
+		return rf(quote, from...)
+	}
+	if rf, ok := ret.Get(0).(func(*models.Quote, ...string) bool); ok {
+		r0 = rf(quote, from...)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.Quote, ...string) error); ok {
+		r1 = rf(quote, from...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCustomer provides a mock function with given fields: id, customer
+func (_m *Repository) UpdateCustomer(id uuid.UUID, customer *models.Customer) error {
+	ret := _m.Called(id, customer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCustomer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.Customer) error); ok {
+		r0 = rf(id, customer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateInvoice provides a mock function with given fields: id, invoice
+func (_m *Repository) UpdateInvoice(id uuid.UUID, invoice *models.Invoice) error {
+	ret := _m.Called(id, invoice)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.Invoice) error); ok {
+		r0 = rf(id, invoice)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateInvoiceDelivery provides a mock function with given fields: id, delivery
+func (_m *Repository) UpdateInvoiceDelivery(id uuid.UUID, delivery *models.InvoiceDelivery) error {
+	ret := _m.Called(id, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateInvoiceDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.InvoiceDelivery) error); ok {
+		r0 = rf(id, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateInvoiceItem provides a mock function with given fields: id, item
+func (_m *Repository) UpdateInvoiceItem(id uuid.UUID, item *models.InvoiceItem) error {
+	ret := _m.Called(id, item)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateInvoiceItem")
 	}
 
 	var r0 error
@@ -554,6 +2490,60 @@ func (_m *Repository) UpdatePaymentDetails(id uuid.UUID, details *models.Payment
 	return r0
 }
 
+// UpdateQuote provides a mock function with given fields: id, quote
+func (_m *Repository) UpdateQuote(id uuid.UUID, quote *models.Quote) error {
+	ret := _m.Called(id, quote)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateQuote")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.Quote) error); ok {
+		r0 = rf(id, quote)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRecurringInvoice provides a mock function with given fields: id, recurring
+func (_m *Repository) UpdateRecurringInvoice(id uuid.UUID, recurring *models.RecurringInvoice) error {
+	ret := _m.Called(id, recurring)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRecurringInvoice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.RecurringInvoice) error); ok {
+		r0 = rf(id, recurring)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTaxRate provides a mock function with given fields: id, rate
+func (_m *Repository) UpdateTaxRate(id uuid.UUID, rate *models.TaxRate) error {
+	ret := _m.Called(id, rate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTaxRate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *models.TaxRate) error); ok {
+		r0 = rf(id, rate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateUser provides a mock function with given fields: id, user
 func (_m *Repository) UpdateUser(id uuid.UUID, user *models.User) error {
 	ret := _m.Called(id, user)
@@ -572,6 +2562,60 @@ func (_m *Repository) UpdateUser(id uuid.UUID, user *models.User) error {
 	return r0
 }
 
+// UpdateUserPassword provides a mock function with given fields: id, hash
+func (_m *Repository) UpdateUserPassword(id uuid.UUID, hash string) error {
+	ret := _m.Called(id, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(id, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyUserEmail provides a mock function with given fields: id, at
+func (_m *Repository) VerifyUserEmail(id uuid.UUID, at time.Time) error {
+	ret := _m.Called(id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyUserEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) error); ok {
+		r0 = rf(id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WithTx provides a mock function with given fields: fn
+func (_m *Repository) WithTx(fn func(repo repository.Repository) error) error {
+	ret := _m.Called(fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(func(repo repository.Repository) error) error); ok {
+		r0 = rf(fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
@@ -7,14 +7,128 @@ import (
 	"gorm.io/gorm"
 )
 
+// ActivityAction names something that happened in an account.
+type ActivityAction string
+
+const (
+	ActivityLogin              ActivityAction = "LOGIN"
+	ActivityLogout             ActivityAction = "LOGOUT"
+	ActivityLogoutAll          ActivityAction = "LOGOUT_ALL"
+	ActivityRefreshTokenReused ActivityAction = "REFRESH_TOKEN_REUSED"
+	ActivityEmailVerified      ActivityAction = "EMAIL_VERIFIED"
+	ActivityPasswordChanged    ActivityAction = "PASSWORD_CHANGED"
+	ActivityPasswordReset      ActivityAction = "PASSWORD_RESET"
+
+	ActivityInvoiceCreated        ActivityAction = "INVOICE_CREATED"
+	ActivityInvoiceUpdated        ActivityAction = "INVOICE_UPDATED"
+	ActivityInvoiceDeleted        ActivityAction = "INVOICE_DELETED"
+	ActivityInvoiceRestored       ActivityAction = "INVOICE_RESTORED"
+	ActivityInvoiceSent           ActivityAction = "INVOICE_SENT"
+	ActivityInvoiceViewed         ActivityAction = "INVOICE_VIEWED"
+	ActivityInvoicePartiallyPaid  ActivityAction = "INVOICE_PARTIALLY_PAID"
+	ActivityInvoicePaid           ActivityAction = "INVOICE_PAID"
+	ActivityInvoiceOverdue        ActivityAction = "INVOICE_OVERDUE"
+	ActivityInvoiceVoided         ActivityAction = "INVOICE_VOIDED"
+	ActivityInvoiceWrittenOff     ActivityAction = "INVOICE_WRITTEN_OFF"
+	ActivityInvoiceReopened       ActivityAction = "INVOICE_REOPENED"
+	ActivityInvoiceEmailed        ActivityAction = "INVOICE_EMAILED"
+	ActivityInvoiceDeliveryFailed ActivityAction = "INVOICE_DELIVERY_FAILED"
+
+	ActivityPaymentRecorded ActivityAction = "PAYMENT_RECORDED"
+	ActivityPaymentRefunded ActivityAction = "PAYMENT_REFUNDED"
+	ActivityPaymentDeleted  ActivityAction = "PAYMENT_DELETED"
+
+	ActivityPaymentDetailsAdded   ActivityAction = "PAYMENT_DETAILS_ADDED"
+	ActivityPaymentDetailsUpdated ActivityAction = "PAYMENT_DETAILS_UPDATED"
+	ActivityPaymentDetailsRemoved ActivityAction = "PAYMENT_DETAILS_REMOVED"
+
+	ActivityCreditNoteApplied ActivityAction = "CREDIT_NOTE_APPLIED"
+	ActivityLateFeeCharged    ActivityAction = "LATE_FEE_CHARGED"
+	ActivityLateFeeReversed   ActivityAction = "LATE_FEE_REVERSED"
+	ActivityReminderSent      ActivityAction = "REMINDER_SENT"
+	ActivityShareLinkCreated  ActivityAction = "SHARE_LINK_CREATED"
+	ActivityShareLinkRevoked  ActivityAction = "SHARE_LINK_REVOKED"
+	ActivityQuoteConverted    ActivityAction = "QUOTE_CONVERTED"
+)
+
+// ActivityActions lists every action the activity log records.
+var ActivityActions = []ActivityAction{
+	ActivityLogin,
+	ActivityLogout,
+	ActivityLogoutAll,
+	ActivityRefreshTokenReused,
+	ActivityEmailVerified,
+	ActivityPasswordChanged,
+	ActivityPasswordReset,
+	ActivityInvoiceCreated,
+	ActivityInvoiceUpdated,
+	ActivityInvoiceDeleted,
+	ActivityInvoiceRestored,
+	ActivityInvoiceSent,
+	ActivityInvoiceViewed,
+	ActivityInvoicePartiallyPaid,
+	ActivityInvoicePaid,
+	ActivityInvoiceOverdue,
+	ActivityInvoiceVoided,
+	ActivityInvoiceWrittenOff,
+	ActivityInvoiceReopened,
+	ActivityInvoiceEmailed,
+	ActivityInvoiceDeliveryFailed,
+	ActivityPaymentRecorded,
+	ActivityPaymentRefunded,
+	ActivityPaymentDeleted,
+	ActivityPaymentDetailsAdded,
+	ActivityPaymentDetailsUpdated,
+	ActivityPaymentDetailsRemoved,
+	ActivityCreditNoteApplied,
+	ActivityLateFeeCharged,
+	ActivityLateFeeReversed,
+	ActivityReminderSent,
+	ActivityShareLinkCreated,
+	ActivityShareLinkRevoked,
+	ActivityQuoteConverted,
+}
+
+// Entity types an activity log entry can point at
+const (
+	EntityUser            = "user"
+	EntitySession         = "session"
+	EntityInvoice         = "invoice"
+	EntityPayment         = "payment"
+	EntityCreditNote      = "credit_note"
+	EntityLateFee         = "late_fee"
+	EntityShareLink       = "share_link"
+	EntityInvoiceDelivery = "invoice_delivery"
+	EntityInvoiceReminder = "invoice_reminder"
+	EntityQuote           = "quote"
+)
+
+// ActivityLog records one action in a user's account. EntityType and EntityID
+// name what the action was taken on; InvoiceID is set as well whenever that
+// thing belongs to an invoice, so an invoice's history includes its payments,
+// fees and deliveries.
 type ActivityLog struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null"`
-	User      User      `gorm:"foreignKey:UserID"`
-	InvoiceID *uuid.UUID `gorm:"type:uuid"`
-	Invoice   *Invoice   `gorm:"foreignKey:InvoiceID"`
-	Action    string    `gorm:"type:varchar(255);not null"`
-	Timestamp time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	ID         uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uuid.UUID        `gorm:"type:uuid;not null;index:idx_activity_logs_user_timestamp,priority:1"`
+	User       User             `gorm:"foreignKey:UserID"`
+	InvoiceID  *uuid.UUID       `gorm:"type:uuid;index"`
+	Invoice    *Invoice         `gorm:"foreignKey:InvoiceID"`
+	EntityType string           `gorm:"type:varchar(50);index:idx_activity_logs_entity,priority:1"`
+	EntityID   *uuid.UUID       `gorm:"type:uuid;index:idx_activity_logs_entity,priority:2"`
+	Action     ActivityAction   `gorm:"type:varchar(255);not null;index"`
+	Metadata   ActivityMetadata `gorm:"serializer:json;type:text"`
+	Timestamp  time.Time        `gorm:"default:CURRENT_TIMESTAMP;index:idx_activity_logs_user_timestamp,priority:2"`
+}
+
+// ActivityMetadata is the detail kept with an activity log entry: the values
+// the action changed and, for actions taken over the API, where the request
+// came from.
+type ActivityMetadata struct {
+	OldValues map[string]interface{} `json:",omitempty"`
+	NewValues map[string]interface{} `json:",omitempty"`
+	IPAddress string                 `json:",omitempty"`
+	UserAgent string                 `json:",omitempty"`
+	RequestID string                 `json:",omitempty"`
 }
 
 // TableName specifies the table name for the ActivityLog model
@@ -28,4 +142,4 @@ func (a *ActivityLog) BeforeCreate(tx *gorm.DB) error {
 		a.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// CreditNote corrects an issued invoice without changing it, e.g. for
+// returned goods or a billing mistake. Its lines copy the invoice lines they
+// credit with negative amounts, so its totals are negative too, and its total
+// comes off the invoice's balance due.
+type CreditNote struct {
+	ID               uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID           uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_credit_notes_user_number"`
+	InvoiceID        uuid.UUID        `gorm:"type:uuid;not null;index"`
+	CreditNoteNumber string           `gorm:"type:varchar(50);not null;uniqueIndex:idx_credit_notes_user_number"`
+	IssueDate        time.Time        `gorm:"not null"`
+	Currency         string           `gorm:"type:varchar(10);not null"`
+	Reason           string           `gorm:"type:text"`
+	SubTotal         money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	Discount         money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	TaxTotal         money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	TotalAmount      money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	Taxes            []CreditNoteTax  `gorm:"serializer:json;type:text"`
+	CreatedAt        time.Time        `gorm:"autoCreateTime"`
+	Items            []CreditNoteItem `gorm:"foreignKey:CreditNoteID"`
+}
+
+// CreditNoteItem credits Quantity units of one invoice line.
+type CreditNoteItem struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreditNoteID  uuid.UUID     `gorm:"type:uuid;not null;index"`
+	InvoiceItemID uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Description   string        `gorm:"type:text;not null"`
+	Quantity      int           `gorm:"not null"`
+	UnitPrice     money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Amount        money.Decimal `gorm:"type:decimal(19,4);not null"`
+	TaxAmount     money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Position      int           `gorm:"not null"`
+}
+
+// CreditNoteTax is the tax credited at one rate.
+type CreditNoteTax struct {
+	TaxRateID uuid.UUID
+	Name      string
+	Rate      money.Decimal
+	Amount    money.Decimal
+}
+
+func (CreditNote) TableName() string {
+	return "credit_notes"
+}
+
+func (n *CreditNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+func (CreditNoteItem) TableName() string {
+	return "credit_note_items"
+}
+
+func (i *CreditNoteItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
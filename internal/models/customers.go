@@ -8,14 +8,16 @@ import (
 )
 
 type Customer struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null"`
-	User      User      `gorm:"foreignKey:UserID"`
-	Name      string    `gorm:"type:varchar(255);not null"`
-	Email     string    `gorm:"type:varchar(255);not null"`
-	Address   string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null"`
+	User          User      `gorm:"foreignKey:UserID"`
+	Name          string    `gorm:"type:varchar(255);not null"`
+	Email         string    `gorm:"type:varchar(255);not null"`
+	Address       string    `gorm:"type:text"`
+	TaxNumber     string    `gorm:"type:varchar(50)"`
+	ReverseCharge bool      `gorm:"default:false"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
 }
 
 // TableName specifies the table name for the Customer model
@@ -29,4 +31,4 @@ func (c *Customer) BeforeCreate(tx *gorm.DB) error {
 		c.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -4,29 +4,72 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
 	"gorm.io/gorm"
 )
 
 type Invoice struct {
-	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null"`
-	User          User      `gorm:"foreignKey:UserID"`
-	CustomerID    uuid.UUID `gorm:"type:uuid;not null"`
-	Customer      Customer  `gorm:"foreignKey:CustomerID"`
-	InvoiceNumber string    `gorm:"type:varchar(50);not null"`
-	IssueDate     time.Time `gorm:"not null"`
-	DueDate       time.Time `gorm:"not null"`
-	Currency      string    `gorm:"type:varchar(10);not null"`
-	SubTotal      float64   `gorm:"type:decimal(10,2);not null"`
-	Discount      float64   `gorm:"type:decimal(10,2)"`
-	TotalAmount   float64   `gorm:"type:decimal(10,2);not null"`
-	Status        string    `gorm:"type:varchar(20);default:'pending'"`
-	Note          string    `gorm:"type:text"`
-	CreatedAt     time.Time `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
-	Items         []InvoiceItem `gorm:"foreignKey:InvoiceID"`
+	ID               uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID           uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_invoices_user_issued_number"`
+	User             User          `gorm:"foreignKey:UserID"`
+	CustomerID       uuid.UUID     `gorm:"type:uuid;not null"`
+	Customer         Customer      `gorm:"foreignKey:CustomerID"`
+	InvoiceNumber    string        `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_invoices_user_issued_number,where:invoice_number <> ''"` // empty until the invoice is issued
+	IssueDate        time.Time     `gorm:"not null"`
+	DueDate          time.Time     `gorm:"not null"`
+	Currency         string        `gorm:"type:varchar(10);not null"`
+	SubTotal         money.Decimal `gorm:"type:decimal(19,4);not null"`
+	DiscountType     string        `gorm:"type:varchar(20);default:'fixed'"`
+	DiscountValue    money.Decimal `gorm:"type:decimal(19,4)"`
+	Discount         money.Decimal `gorm:"type:decimal(19,4)"`
+	TaxTotal         money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	TotalAmount      money.Decimal `gorm:"type:decimal(19,4);not null"`
+	LateFeeTotal     money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	CreditTotal      money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	AmountPaid       money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	BalanceDue       money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	PricesIncludeTax bool          `gorm:"default:false"`
+	ReverseCharge    bool          `gorm:"default:false"`
+	Status           string        `gorm:"type:varchar(20);default:'draft'"`
+	Note             string        `gorm:"type:text"`
+	QuoteID          *uuid.UUID    `gorm:"type:uuid;index"` // the quote the invoice was converted from
+	SentAt           *time.Time
+	ViewedAt         *time.Time
+	PaidAt           *time.Time
+	VoidedAt         *time.Time
+	WrittenOffAt     *time.Time
+	CreatedAt        time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"` // only drafts are deleted; issued invoices are voided
+	Items            []InvoiceItem  `gorm:"foreignKey:InvoiceID"`
+	Taxes            []InvoiceTax   `gorm:"foreignKey:InvoiceID"`
+	LateFees         []LateFee      `gorm:"foreignKey:InvoiceID"`
 }
 
+// Discount types. A fixed discount is an amount in the invoice currency, a
+// percentage discount is taken from the subtotal.
+const (
+	DiscountTypeFixed      = "fixed"
+	DiscountTypePercentage = "percentage"
+)
+
+// Invoice statuses. Allowed transitions between them are enforced by the
+// service layer.
+const (
+	InvoiceStatusDraft         = "draft"
+	InvoiceStatusSent          = "sent"
+	InvoiceStatusViewed        = "viewed"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusOverdue       = "overdue"
+	InvoiceStatusVoid          = "void"
+	InvoiceStatusWrittenOff    = "written_off"
+
+	// InvoiceStatusPending is the status invoices were created with before
+	// the lifecycle existed. It behaves like InvoiceStatusSent.
+	InvoiceStatusPending = "pending"
+)
+
 func (Invoice) TableName() string {
 	return "invoices"
 }
@@ -36,4 +79,4 @@ func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
 		i.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
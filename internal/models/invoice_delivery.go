@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvoiceDelivery is one attempt to email an invoice to its customer, and how
+// it went. Error holds the mail server's answer when the attempt failed.
+type InvoiceDelivery struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null"`
+	To          string     `gorm:"type:varchar(255);not null"`
+	Cc          []string   `gorm:"serializer:json;type:text"`
+	Bcc         []string   `gorm:"serializer:json;type:text"`
+	Subject     string     `gorm:"type:varchar(255);not null"`
+	Message     string     `gorm:"type:text"`
+	ShareLinkID *uuid.UUID `gorm:"type:uuid"`
+	Status      string     `gorm:"type:varchar(20);not null"`
+	Error       string     `gorm:"type:text"`
+	AttemptedAt time.Time  `gorm:"not null"`
+	DeliveredAt *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// Delivery statuses. An attempt is pending while the mail server is being
+// contacted.
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+func (InvoiceDelivery) TableName() string {
+	return "invoice_deliveries"
+}
+
+func (d *InvoiceDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
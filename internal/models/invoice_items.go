@@ -2,17 +2,22 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
 	"gorm.io/gorm"
 )
 
 type InvoiceItem struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	InvoiceID   uuid.UUID `gorm:"type:uuid;not null"`
-	Invoice     Invoice   `gorm:"foreignKey:InvoiceID"`
-	Description string    `gorm:"type:text;not null"`
-	Quantity    int       `gorm:"not null"`
-	UnitPrice   float64   `gorm:"type:decimal(10,2);not null"`
-	Amount      float64   `gorm:"type:decimal(10,2);not null"`
+	ID          uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID   uuid.UUID        `gorm:"type:uuid;not null"`
+	Invoice     Invoice          `gorm:"foreignKey:InvoiceID"`
+	Description string           `gorm:"type:text;not null"`
+	Quantity    int              `gorm:"not null"`
+	UnitPrice   money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	Amount      money.Decimal    `gorm:"type:decimal(19,4);not null"`
+	TaxAmount   money.Decimal    `gorm:"type:decimal(19,4);not null;default:0"`
+	Taxes       []InvoiceItemTax `gorm:"foreignKey:InvoiceItemID"`
+	Position    int              `gorm:"not null;default:0"`
+	DeletedAt   gorm.DeletedAt   `gorm:"index"`
 }
 
 func (InvoiceItem) TableName() string {
@@ -24,4 +29,4 @@ func (i *InvoiceItem) BeforeCreate(tx *gorm.DB) error {
 		i.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
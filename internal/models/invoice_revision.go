@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// InvoiceRevision is a copy of an invoice, its items and its payment details
+// taken after each change to them. Revisions are numbered from 1 per invoice
+// and never change once written.
+type InvoiceRevision struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_invoice_revisions_number"`
+	Number    int             `gorm:"not null;uniqueIndex:idx_invoice_revisions_number"`
+	ActorID   *uuid.UUID      `gorm:"type:uuid"` // the user who made the change; nil for the system or the customer
+	Action    ActivityAction  `gorm:"type:varchar(50);not null"`
+	Snapshot  InvoiceSnapshot `gorm:"serializer:json;type:text;not null"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+func (InvoiceRevision) TableName() string {
+	return "invoice_revisions"
+}
+
+func (r *InvoiceRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// InvoiceSnapshot is the content of an invoice at one revision. The customer
+// is copied as it was billed, so later edits to the customer do not rewrite
+// history.
+type InvoiceSnapshot struct {
+	InvoiceNumber    string
+	Status           string
+	Customer         CustomerSnapshot
+	IssueDate        time.Time
+	DueDate          time.Time
+	Currency         string
+	DiscountType     string
+	DiscountValue    money.Decimal
+	PricesIncludeTax bool
+	ReverseCharge    bool
+	SubTotal         money.Decimal
+	Discount         money.Decimal
+	TaxTotal         money.Decimal
+	TotalAmount      money.Decimal
+	LateFeeTotal     money.Decimal
+	CreditTotal      money.Decimal
+	AmountPaid       money.Decimal
+	BalanceDue       money.Decimal
+	Note             string
+	Items            []InvoiceItemSnapshot
+	Taxes            []TaxSnapshot
+	PaymentDetails   *PaymentDetailsSnapshot
+}
+
+type CustomerSnapshot struct {
+	ID        uuid.UUID
+	Name      string
+	Email     string
+	Address   string
+	TaxNumber string
+}
+
+type InvoiceItemSnapshot struct {
+	ID          uuid.UUID
+	Description string
+	Quantity    int
+	UnitPrice   money.Decimal
+	Amount      money.Decimal
+	TaxAmount   money.Decimal
+	Taxes       []TaxSnapshot
+}
+
+// TaxSnapshot is a tax charged on a line, or, with its amounts, one rate of
+// the invoice's tax breakdown.
+type TaxSnapshot struct {
+	TaxRateID     uuid.UUID
+	Name          string
+	Rate          money.Decimal
+	Compound      bool
+	TaxableAmount money.Decimal
+	Amount        money.Decimal
+}
+
+type PaymentDetailsSnapshot struct {
+	AccountName    string
+	AccountNumber  string
+	BankName       string
+	BankAddress    string
+	RoutingNumber  string
+	PaymentDueDate time.Time
+}
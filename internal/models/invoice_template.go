@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvoiceTemplateSettings is how a user's invoices look as PDF: the layout,
+// a logo (PNG or JPEG), the accent colour and a footer line.
+type InvoiceTemplateSettings struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	User        User      `gorm:"foreignKey:UserID"`
+	Template    string    `gorm:"type:varchar(30);not null;default:'classic'"`
+	AccentColor string    `gorm:"type:varchar(7);not null;default:'#1F4E79'"`
+	FooterText  string    `gorm:"type:text"`
+	Logo        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (InvoiceTemplateSettings) TableName() string {
+	return "invoice_template_settings"
+}
+
+func (s *InvoiceTemplateSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
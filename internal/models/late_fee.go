@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// LateFeePolicy is what a user charges when an invoice is paid late. The
+// policy without an InvoiceID is the user's default; one with an InvoiceID
+// replaces it for that invoice.
+//
+// Rate is the fee in the invoice currency for a flat fee, and a percentage of
+// the unpaid amount for the other kinds. Fees start GraceDays after the due
+// date, and MaxAmount, when not zero, caps the total charged on an invoice.
+type LateFeePolicy struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID     `gorm:"type:uuid;not null;index"`
+	InvoiceID *uuid.UUID    `gorm:"type:uuid;uniqueIndex"`
+	Enabled   bool          `gorm:"not null"`
+	Kind      string        `gorm:"type:varchar(20);not null"`
+	Rate      money.Decimal `gorm:"type:decimal(19,4);not null"`
+	GraceDays int           `gorm:"not null"`
+	MaxAmount money.Decimal `gorm:"type:decimal(19,4);not null"`
+	CreatedAt time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `gorm:"autoUpdateTime"`
+}
+
+// Late fee kinds. Flat and percentage fees are charged once; interest is
+// charged for every day or whole month the invoice stays unpaid.
+const (
+	LateFeeFlat            = "flat"
+	LateFeePercentage      = "percentage"
+	LateFeeDailyInterest   = "daily_interest"
+	LateFeeMonthlyInterest = "monthly_interest"
+)
+
+func (LateFeePolicy) TableName() string {
+	return "late_fee_policies"
+}
+
+func (p *LateFeePolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// LateFee is one charge added to an invoice for paying late, covering the
+// days from PeriodStart up to PeriodEnd. The unique index means each period
+// is charged once. A reversed fee is kept for the record but no longer owed.
+type LateFee struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID      uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_late_fees_period"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null"`
+	Kind           string        `gorm:"type:varchar(20);not null"`
+	Rate           money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Description    string        `gorm:"type:varchar(255);not null"`
+	Amount         money.Decimal `gorm:"type:decimal(19,4);not null"`
+	PeriodStart    time.Time     `gorm:"not null;uniqueIndex:idx_late_fees_period"`
+	PeriodEnd      time.Time     `gorm:"not null"`
+	ChargedAt      time.Time     `gorm:"not null"`
+	ReversedAt     *time.Time
+	ReversalReason string `gorm:"type:text"`
+}
+
+func (LateFee) TableName() string {
+	return "late_fees"
+}
+
+func (f *LateFee) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// Payment is money received against an invoice, or returned to the customer
+// when Kind is PaymentKindRefund. Amount is always positive; refunds point at
+// the payment they return money from.
+type Payment struct {
+	ID                uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID         uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Invoice           *Invoice      `gorm:"foreignKey:InvoiceID"`
+	UserID            uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Kind              string        `gorm:"type:varchar(20);not null;default:'payment'"`
+	RefundedPaymentID *uuid.UUID    `gorm:"type:uuid;index"`
+	Amount            money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Currency          string        `gorm:"type:varchar(10);not null"`
+	PaidAt            time.Time     `gorm:"not null"`
+	Method            string        `gorm:"type:varchar(30);not null"`
+	Reference         string        `gorm:"type:varchar(100)"`
+	Note              string        `gorm:"type:text"`
+	CreatedAt         time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time     `gorm:"autoUpdateTime"`
+}
+
+// Payment kinds
+const (
+	PaymentKindPayment = "payment"
+	PaymentKindRefund  = "refund"
+)
+
+// Payment methods
+const (
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodCard         = "card"
+	PaymentMethodCash         = "cash"
+	PaymentMethodCheque       = "cheque"
+	PaymentMethodOther        = "other"
+)
+
+func (Payment) TableName() string {
+	return "payments"
+}
+
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ type PaymentDetails struct {
 	PaymentDueDate  time.Time `gorm:"not null"`
 	CreatedAt       time.Time `gorm:"autoCreateTime"`
 	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
 }
 
 func (PaymentDetails) TableName() string {
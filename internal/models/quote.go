@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// Quote is an estimate sent to a customer before invoicing. It is priced like
+// an invoice and stays open for acceptance until ExpiryDate. The customer
+// accepts or declines it through a link holding a random token, of which
+// only a SHA-256 hash is stored. An accepted quote can be converted into an
+// invoice once; InvoiceID then points at that invoice.
+type Quote struct {
+	ID                  uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID              uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_quotes_user_number"`
+	User                User          `gorm:"foreignKey:UserID"`
+	CustomerID          uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Customer            Customer      `gorm:"foreignKey:CustomerID"`
+	QuoteNumber         string        `gorm:"type:varchar(50);not null;uniqueIndex:idx_quotes_user_number"`
+	IssueDate           time.Time     `gorm:"not null"`
+	ExpiryDate          time.Time     `gorm:"not null"`
+	Currency            string        `gorm:"type:varchar(10);not null"`
+	SubTotal            money.Decimal `gorm:"type:decimal(19,4);not null"`
+	DiscountType        string        `gorm:"type:varchar(20);not null;default:'fixed'"`
+	DiscountValue       money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	Discount            money.Decimal `gorm:"type:decimal(19,4)"`
+	TaxTotal            money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	TotalAmount         money.Decimal `gorm:"type:decimal(19,4);not null"`
+	PricesIncludeTax    bool          `gorm:"not null;default:false"`
+	ReverseCharge       bool          `gorm:"not null;default:false"`
+	Taxes               []QuoteTax    `gorm:"serializer:json;type:text"`
+	Status              string        `gorm:"type:varchar(20);not null;default:'draft'"`
+	Note                string        `gorm:"type:text"`
+	AcceptanceTokenHash *string       `gorm:"type:varchar(64);uniqueIndex"`
+	SentAt              *time.Time
+	AcceptedAt          *time.Time
+	DeclinedAt          *time.Time
+	SignerName          string `gorm:"type:varchar(255)"`
+	Signature           string `gorm:"type:text"`
+	DeclineReason       string `gorm:"type:text"`
+	RespondedFromIP     string `gorm:"type:varchar(45)"`
+	ConvertedAt         *time.Time
+	InvoiceID           *uuid.UUID  `gorm:"type:uuid"`
+	CreatedAt           time.Time   `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time   `gorm:"autoUpdateTime"`
+	Items               []QuoteItem `gorm:"foreignKey:QuoteID"`
+}
+
+// Quote statuses. Only a sent quote can be accepted or declined, and it
+// expires once its expiry date has passed without either.
+const (
+	QuoteStatusDraft    = "draft"
+	QuoteStatusSent     = "sent"
+	QuoteStatusAccepted = "accepted"
+	QuoteStatusDeclined = "declined"
+	QuoteStatusExpired  = "expired"
+)
+
+func (Quote) TableName() string {
+	return "quotes"
+}
+
+func (q *Quote) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// QuoteItem is a line of a quote, with the same amounts as an InvoiceItem.
+// Tax rates are kept by ID, as on recurring invoices, and resolved again when
+// the quote is converted.
+type QuoteItem struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	QuoteID     uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Description string        `gorm:"type:text;not null"`
+	Quantity    int           `gorm:"not null"`
+	UnitPrice   money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Amount      money.Decimal `gorm:"type:decimal(19,4);not null"`
+	TaxAmount   money.Decimal `gorm:"type:decimal(19,4);not null;default:0"`
+	TaxRateIDs  []uuid.UUID   `gorm:"serializer:json;type:text"`
+	Position    int           `gorm:"not null"`
+}
+
+func (QuoteItem) TableName() string {
+	return "quote_items"
+}
+
+func (i *QuoteItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// QuoteTax is the tax quoted at one rate.
+type QuoteTax struct {
+	TaxRateID uuid.UUID
+	Name      string
+	Rate      money.Decimal
+	Amount    money.Decimal
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// RecurringInvoice is a template the scheduler turns into a real invoice on
+// every occurrence of its cadence, from StartDate until EndDate. NextRunAt is
+// the next occurrence still to be invoiced.
+type RecurringInvoice struct {
+	ID               uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID           uuid.UUID     `gorm:"type:uuid;not null;index"`
+	User             User          `gorm:"foreignKey:UserID"`
+	CustomerID       uuid.UUID     `gorm:"type:uuid;not null"`
+	Customer         Customer      `gorm:"foreignKey:CustomerID"`
+	Name             string        `gorm:"type:varchar(100);not null"`
+	Currency         string        `gorm:"type:varchar(10);not null"`
+	DiscountType     string        `gorm:"type:varchar(20);default:'fixed'"`
+	Discount         money.Decimal `gorm:"type:decimal(19,4)"`
+	PricesIncludeTax bool          `gorm:"default:false"`
+	Note             string        `gorm:"type:text"`
+	PaymentTermsDays int           `gorm:"not null"`
+	Cadence          string        `gorm:"type:varchar(20);not null"`
+	CronRule         string        `gorm:"type:varchar(100)"`
+	StartDate        time.Time     `gorm:"not null"`
+	EndDate          *time.Time
+	NextRunAt        time.Time              `gorm:"not null;index"`
+	AutoSend         bool                   `gorm:"default:false"`
+	Active           bool                   `gorm:"default:true"`
+	CreatedAt        time.Time              `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time              `gorm:"autoUpdateTime"`
+	Items            []RecurringInvoiceItem `gorm:"foreignKey:RecurringInvoiceID"`
+}
+
+// Cadences. A custom cadence follows CronRule, a five field cron expression
+// evaluated in UTC.
+const (
+	CadenceWeekly    = "weekly"
+	CadenceMonthly   = "monthly"
+	CadenceQuarterly = "quarterly"
+	CadenceYearly    = "yearly"
+	CadenceCustom    = "custom"
+)
+
+func (RecurringInvoice) TableName() string {
+	return "recurring_invoices"
+}
+
+func (r *RecurringInvoice) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RecurringInvoiceItem is a line copied onto every generated invoice. Tax
+// rates are kept by ID and resolved when the invoice is generated, so rate
+// changes apply from the next invoice on.
+type RecurringInvoiceItem struct {
+	ID                 uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RecurringInvoiceID uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Description        string        `gorm:"type:text;not null"`
+	Quantity           int           `gorm:"not null"`
+	UnitPrice          money.Decimal `gorm:"type:decimal(19,4);not null"`
+	TaxRateIDs         []uuid.UUID   `gorm:"serializer:json;type:text"`
+	Position           int           `gorm:"not null"`
+}
+
+func (RecurringInvoiceItem) TableName() string {
+	return "recurring_invoice_items"
+}
+
+func (i *RecurringInvoiceItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// RecurringInvoiceRun records that the period starting at PeriodStart has been
+// invoiced. The unique index makes claiming a period atomic, so a restarted or
+// second scheduler never bills the same period twice.
+type RecurringInvoiceRun struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RecurringInvoiceID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_recurring_runs_period"`
+	PeriodStart        time.Time  `gorm:"not null;uniqueIndex:idx_recurring_runs_period"`
+	InvoiceID          *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime"`
+}
+
+func (RecurringInvoiceRun) TableName() string {
+	return "recurring_invoice_runs"
+}
+
+func (r *RecurringInvoiceRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReminderSettings is when and how a user's customers are reminded about
+// unpaid invoices. Each template is one step of the schedule.
+type ReminderSettings struct {
+	ID        uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex"`
+	User      User               `gorm:"foreignKey:UserID"`
+	Enabled   bool               `gorm:"not null"`
+	Templates []ReminderTemplate `gorm:"serializer:json;type:text"`
+	CreatedAt time.Time          `gorm:"autoCreateTime"`
+	UpdatedAt time.Time          `gorm:"autoUpdateTime"`
+}
+
+// ReminderTemplate is a reminder sent DaysOffset days after the due date;
+// negative offsets are sent before it. Subject and Body are text/template
+// templates, see the service for the fields they can use.
+type ReminderTemplate struct {
+	DaysOffset int
+	Subject    string
+	Body       string
+}
+
+const defaultReminderBody = `Hi {{.CustomerName}},
+
+{{if lt .DaysOverdue 1}}This is a friendly reminder that invoice {{.InvoiceNumber}} for {{.Currency}} {{.AmountDue}} is due on {{.DueDate}}.{{else}}Invoice {{.InvoiceNumber}} for {{.Currency}} {{.AmountDue}} was due on {{.DueDate}} and is now {{.DaysOverdue}} days overdue.{{end}}
+
+If you have already paid, please ignore this email.
+
+Thank you,
+{{.SenderName}}
+`
+
+// DefaultReminderSettings is the schedule used until a user configures their
+// own: 3 days before the due date, on it, and 7, 14 and 30 days after.
+func DefaultReminderSettings(userID uuid.UUID) *ReminderSettings {
+	return &ReminderSettings{
+		UserID:  userID,
+		Enabled: true,
+		Templates: []ReminderTemplate{
+			{DaysOffset: -3, Subject: "Invoice {{.InvoiceNumber}} is due in {{.DaysUntilDue}} days", Body: defaultReminderBody},
+			{DaysOffset: 0, Subject: "Invoice {{.InvoiceNumber}} is due today", Body: defaultReminderBody},
+			{DaysOffset: 7, Subject: "Invoice {{.InvoiceNumber}} is overdue", Body: defaultReminderBody},
+			{DaysOffset: 14, Subject: "Invoice {{.InvoiceNumber}} is overdue", Body: defaultReminderBody},
+			{DaysOffset: 30, Subject: "Final reminder: invoice {{.InvoiceNumber}} is overdue", Body: defaultReminderBody},
+		},
+	}
+}
+
+func (ReminderSettings) TableName() string {
+	return "reminder_settings"
+}
+
+func (s *ReminderSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// InvoiceReminder records that the reminder for one step of the schedule was
+// sent for an invoice. The unique index means each step goes out once.
+type InvoiceReminder struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_invoice_reminders_step"`
+	DaysOffset int       `gorm:"not null;uniqueIndex:idx_invoice_reminders_step"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null"`
+	SentTo     string    `gorm:"type:varchar(255);not null"`
+	SentAt     time.Time `gorm:"not null"`
+}
+
+func (InvoiceReminder) TableName() string {
+	return "invoice_reminders"
+}
+
+func (r *InvoiceReminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
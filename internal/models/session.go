@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Why a session was revoked
+const (
+	SessionRevokedLogout     = "logout"
+	SessionRevokedLogoutAll  = "logout_all"
+	SessionRevokedTokenReuse = "token_reuse"
+	SessionRevokedPassword   = "password_changed"
+)
+
+// Session is one login on one device. Its refresh tokens form a family: each
+// refresh replaces the token used with a new one, and revoking the session
+// revokes them all along with the access tokens issued for it.
+type Session struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	IPAddress     string    `gorm:"size:64"`
+	UserAgent     string    `gorm:"size:512"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
+	RevokedReason string    `gorm:"size:32"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// RefreshToken can be exchanged once for a new access token and a new
+// refresh token. Only a SHA-256 hash of the token is stored. UsedAt is set
+// when it is exchanged, so a second use shows the token was stolen.
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
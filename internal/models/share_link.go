@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShareLink gives anyone holding its token read access to one invoice. Only
+// a SHA-256 hash of the token is stored; TokenPrefix helps users tell their
+// links apart.
+type ShareLink struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Invoice      *Invoice  `gorm:"foreignKey:InvoiceID"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null"`
+	TokenHash    string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	TokenPrefix  string    `gorm:"type:varchar(8);not null"`
+	ExpiresAt    *time.Time
+	RevokedAt    *time.Time
+	ViewCount    int `gorm:"not null;default:0"`
+	LastViewedAt *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// Active reports whether the link can still be used at the given time.
+func (l *ShareLink) Active(at time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	return l.ExpiresAt == nil || at.Before(*l.ExpiresAt)
+}
+
+func (ShareLink) TableName() string {
+	return "share_links"
+}
+
+func (l *ShareLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"gorm.io/gorm"
+)
+
+// TaxRate is a named tax a user can attach to invoice lines, e.g. "VAT 20%".
+// Rate is a percentage. A compound tax is charged on the line amount plus the
+// non-compound taxes before it.
+type TaxRate struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID     `gorm:"type:uuid;not null;index"`
+	User      User          `gorm:"foreignKey:UserID"`
+	Name      string        `gorm:"type:varchar(100);not null"`
+	Rate      money.Decimal `gorm:"type:decimal(9,4);not null"`
+	Compound  bool          `gorm:"default:false"`
+	Active    bool          `gorm:"default:true"`
+	CreatedAt time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `gorm:"autoUpdateTime"`
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+func (t *TaxRate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// InvoiceItemTax is a copy of a tax rate as it was when applied to a line, so
+// later edits to the rate do not change issued invoices. Taxes are applied in
+// Position order.
+type InvoiceItemTax struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceItemID uuid.UUID     `gorm:"type:uuid;not null;index"`
+	TaxRateID     uuid.UUID     `gorm:"type:uuid;not null"`
+	Name          string        `gorm:"type:varchar(100);not null"`
+	Rate          money.Decimal `gorm:"type:decimal(9,4);not null"`
+	Compound      bool          `gorm:"default:false"`
+	Position      int           `gorm:"not null"`
+}
+
+func (InvoiceItemTax) TableName() string {
+	return "invoice_item_taxes"
+}
+
+func (t *InvoiceItemTax) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// InvoiceTax is one row of an invoice's tax breakdown: the total charged for
+// a single rate across all lines.
+type InvoiceTax struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InvoiceID     uuid.UUID     `gorm:"type:uuid;not null;index"`
+	TaxRateID     uuid.UUID     `gorm:"type:uuid;not null"`
+	Name          string        `gorm:"type:varchar(100);not null"`
+	Rate          money.Decimal `gorm:"type:decimal(9,4);not null"`
+	Compound      bool          `gorm:"default:false"`
+	TaxableAmount money.Decimal `gorm:"type:decimal(19,4);not null"`
+	Amount        money.Decimal `gorm:"type:decimal(19,4);not null"`
+}
+
+func (InvoiceTax) TableName() string {
+	return "invoice_taxes"
+}
+
+func (t *InvoiceTax) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -12,12 +12,14 @@ type User struct {
 	FirstName string    `gorm:"size:100;not null"`
 	LastName  string    `gorm:"size:100;not null"`
 	Email     string    `gorm:"size:255;uniqueIndex;not null"`
-	Password  string    `gorm:"not null"`
+	Password  string    `gorm:"not null" json:"-"`
 	Active    bool      `gorm:"default:true"`
 	Address   string    `gorm:"size:100;not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// EmailVerifiedAt is when the user proved they own Email, nil until then
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
 }
 
 func (u *User) TableName() string {
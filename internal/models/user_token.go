@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// What a user token can be used for
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+)
+
+// UserToken is a single-use token emailed to a user to prove they own their
+// address, either to verify it or to reset their password. Only a SHA-256
+// hash of the token is stored, and UsedAt is set once it has been used.
+type UserToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Purpose   string    `gorm:"size:32;not null"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Usable reports whether the token can still be used for purpose at the
+// given time.
+func (t *UserToken) Usable(purpose string, at time.Time) bool {
+	return t.Purpose == purpose && t.UsedAt == nil && at.Before(t.ExpiresAt)
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
+
+func (t *UserToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
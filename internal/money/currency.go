@@ -0,0 +1,22 @@
+package money
+
+import "strings"
+
+// currencyDecimals lists the ISO 4217 currencies whose minor unit is not
+// hundredths. Every other currency uses two decimal places.
+var currencyDecimals = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyDecimals returns the number of minor unit digits for an ISO 4217
+// currency code, e.g. 2 for USD, 0 for JPY and 3 for KWD.
+func CurrencyDecimals(currency string) int32 {
+	if places, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return 2
+}
@@ -0,0 +1,390 @@
+// Package money provides an exact decimal type for monetary amounts and rates.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of fractional digits a Decimal keeps. It covers every
+// ISO 4217 minor unit and leaves room for unit prices and rates.
+const Scale = 4
+
+const unit = 10000 // 10^Scale
+
+var (
+	ErrInvalidDecimal = errors.New("money: invalid decimal")
+	ErrOverflow       = errors.New("money: value out of range")
+)
+
+// Decimal is an exact fixed-point number with Scale fractional digits. The zero
+// value is 0. Arithmetic that does not fit in the representable range
+// (about ±922 trillion) panics with ErrOverflow; Parse rejects such values.
+type Decimal struct {
+	units int64
+}
+
+// Zero is the zero Decimal.
+var Zero = Decimal{}
+
+// New returns value * 10^exp, e.g. New(1999, -2) is 19.99. Digits beyond
+// Scale are rounded half away from zero.
+func New(value int64, exp int32) Decimal {
+	v := new(big.Int).SetInt64(value)
+	return fromBig(v, exp)
+}
+
+// NewFromInt returns the whole number i.
+func NewFromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// Parse reads a plain decimal string such as "-1234.5678".
+func Parse(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, ErrInvalidDecimal
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Zero, ErrInvalidDecimal
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return Zero, ErrInvalidDecimal
+			}
+		}
+	}
+
+	digits, ok := new(big.Int).SetString(whole+frac+"0", 10)
+	if !ok {
+		return Zero, ErrInvalidDecimal
+	}
+	if negative {
+		digits.Neg(digits)
+	}
+
+	// The extra "0" keeps SetString happy for inputs like "5." and shifts
+	// the exponent by one.
+	exp := -int32(len(frac)) - 1
+	if !fits(digits, exp) {
+		return Zero, ErrOverflow
+	}
+	return fromBig(digits, exp), nil
+}
+
+// MustParse is like Parse but panics on error. It is intended for constants
+// and tests.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: MustParse(%q): %v", s, err))
+	}
+	return d
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	r := d.units + o.units
+	if (r > d.units) != (o.units > 0) {
+		panic(ErrOverflow)
+	}
+	return Decimal{units: r}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns d * o rounded half away from zero to Scale digits.
+func (d Decimal) Mul(o Decimal) Decimal {
+	v := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(o.units))
+	return fromBig(v, -2*Scale)
+}
+
+// MulDiv returns d * num / den rounded half away from zero to Scale digits.
+// Only the result has to fit, so it scales an amount by a ratio of two large
+// amounts without overflowing. It panics if den is zero.
+func (d Decimal) MulDiv(num, den Decimal) Decimal {
+	if den.units == 0 {
+		panic("money: division by zero")
+	}
+	v := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(num.units))
+	return Decimal{units: divRound(v, big.NewInt(den.units))}
+}
+
+// MulInt returns d * n.
+func (d Decimal) MulInt(n int64) Decimal {
+	return d.Mul(NewFromInt(n))
+}
+
+// Div returns d / o rounded half away from zero to Scale digits. It panics
+// if o is zero.
+func (d Decimal) Div(o Decimal) Decimal {
+	if o.units == 0 {
+		panic("money: division by zero")
+	}
+	num := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(unit))
+	return Decimal{units: divRound(num, big.NewInt(o.units))}
+}
+
+// Percent returns pct percent of d, e.g. MustParse("200").Percent(MustParse("7.5")) is 15.
+func (d Decimal) Percent(pct Decimal) Decimal {
+	return d.MulDiv(pct, NewFromInt(100))
+}
+
+func (d Decimal) Neg() Decimal {
+	if d.units == math.MinInt64 {
+		panic(ErrOverflow)
+	}
+	return Decimal{units: -d.units}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is less than, equal to or
+// greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.units < o.units:
+		return -1
+	case d.units > o.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) Equal(o Decimal) bool       { return d.units == o.units }
+func (d Decimal) LessThan(o Decimal) bool    { return d.units < o.units }
+func (d Decimal) GreaterThan(o Decimal) bool { return d.units > o.units }
+func (d Decimal) IsZero() bool               { return d.units == 0 }
+func (d Decimal) IsNegative() bool           { return d.units < 0 }
+func (d Decimal) IsPositive() bool           { return d.units > 0 }
+
+// Min returns the smaller of d and o.
+func (d Decimal) Min(o Decimal) Decimal {
+	if o.units < d.units {
+		return o
+	}
+	return d
+}
+
+// Max returns the larger of d and o.
+func (d Decimal) Max(o Decimal) Decimal {
+	if o.units > d.units {
+		return o
+	}
+	return d
+}
+
+// Round rounds d half away from zero to the given number of fractional digits.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= Scale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := int64(math.Pow10(int(Scale - places)))
+	return Decimal{units: divRound(big.NewInt(d.units), big.NewInt(step)) * step}
+}
+
+// RoundCurrency rounds d to the minor unit of the currency, e.g. cents for
+// USD, whole yen for JPY and fils for KWD.
+func (d Decimal) RoundCurrency(currency string) Decimal {
+	return d.Round(CurrencyDecimals(currency))
+}
+
+// MinorUnits returns d in the minor unit of the currency, rounding first.
+func (d Decimal) MinorUnits(currency string) int64 {
+	places := CurrencyDecimals(currency)
+	return d.Round(places).units / int64(math.Pow10(int(Scale-places)))
+}
+
+// String returns the shortest exact representation, e.g. "12.5" or "-3".
+func (d Decimal) String() string {
+	s := d.StringFixed(Scale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// StringFixed formats d with exactly places fractional digits, rounding if needed.
+func (d Decimal) StringFixed(places int32) string {
+	if places > Scale {
+		places = Scale
+	}
+	r := d.Round(places)
+	sign := ""
+	u := r.units
+	if u < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(u)).String()
+	for len(abs) <= Scale {
+		abs = "0" + abs
+	}
+	whole, frac := abs[:len(abs)-Scale], abs[len(abs)-Scale:]
+	if places == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + frac[:places]
+}
+
+// Format renders d with the currency's number of minor digits, e.g. "10.50"
+// for USD and "1050" for JPY.
+func (d Decimal) Format(currency string) string {
+	return d.StringFixed(CurrencyDecimals(currency))
+}
+
+// Float64 returns the nearest float64. Use it for display only.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// MarshalJSON encodes d as a JSON number with no binary rounding.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a quoted decimal string or null.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = Zero
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := parseNumber(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores d as an exact decimal string.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a NUMERIC/DECIMAL column.
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case float64:
+		return d.scanString(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Errorf("money: cannot scan %T into Decimal", src)
+	}
+}
+
+func (d *Decimal) scanString(s string) error {
+	parsed, err := parseNumber(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// parseNumber is Parse plus exponent notation, which JSON and some drivers use.
+func parseNumber(s string) (Decimal, error) {
+	mantissa, exponent, found := strings.Cut(strings.ToLower(s), "e")
+	if !found {
+		return Parse(s)
+	}
+	exp, err := strconv.ParseInt(exponent, 10, 32)
+	if err != nil {
+		return Zero, ErrInvalidDecimal
+	}
+	m, err := Parse(mantissa)
+	if err != nil {
+		return Zero, err
+	}
+	v := big.NewInt(m.units)
+	if !fits(v, int32(exp)-Scale) {
+		return Zero, ErrOverflow
+	}
+	return fromBig(v, int32(exp)-Scale), nil
+}
+
+// fromBig converts v * 10^exp to a Decimal, rounding to Scale digits.
+func fromBig(v *big.Int, exp int32) Decimal {
+	if !fits(v, exp) {
+		panic(ErrOverflow)
+	}
+	shift := exp + Scale
+	if shift >= 0 {
+		p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil)
+		return Decimal{units: new(big.Int).Mul(v, p).Int64()}
+	}
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil)
+	return Decimal{units: divRound(v, p)}
+}
+
+// fits reports whether v * 10^exp is representable once rounded to Scale digits.
+func fits(v *big.Int, exp int32) bool {
+	shift := exp + Scale
+	scaled := new(big.Int).Set(v)
+	if shift >= 0 {
+		scaled.Mul(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else {
+		p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil)
+		scaled.Quo(scaled, p)
+	}
+	// Leave headroom for rounding up by one unit.
+	return scaled.IsInt64() && scaled.Int64() != math.MaxInt64 && scaled.Int64() != math.MinInt64
+}
+
+// divRound divides num by den, rounding half away from zero.
+func divRound(num, den *big.Int) int64 {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return q.Int64()
+}
@@ -0,0 +1,139 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"100", "100"},
+		{"19.99", "19.99"},
+		{"-0.5", "-0.5"},
+		{"+3.", "3"},
+		{".25", "0.25"},
+		{"1.00005", "1.0001"},
+		{"-1.00005", "-1.0001"},
+		{"123456789012.3456", "123456789012.3456"},
+	}
+
+	for _, tt := range tests {
+		d, err := money.Parse(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, d.String(), tt.in)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, in := range []string{"", "-", ".", "1.2.3", "abc", "1e5", "10,00"} {
+		_, err := money.Parse(in)
+		assert.ErrorIs(t, err, money.ErrInvalidDecimal, in)
+	}
+
+	_, err := money.Parse("1000000000000000")
+	assert.ErrorIs(t, err, money.ErrOverflow)
+}
+
+func TestArithmetic(t *testing.T) {
+	a := money.MustParse("0.1")
+	b := money.MustParse("0.2")
+
+	assert.True(t, a.Add(b).Equal(money.MustParse("0.3")))
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "100.005", money.MustParse("33.335").MulInt(3).String())
+	assert.Equal(t, "3.3333", money.NewFromInt(10).Div(money.NewFromInt(3)).String())
+	assert.Equal(t, "15", money.NewFromInt(200).Percent(money.MustParse("7.5")).String())
+	assert.Equal(t, "33.3333", money.NewFromInt(100).MulDiv(money.NewFromInt(1), money.NewFromInt(3)).String())
+	assert.Equal(t, 1, b.Cmp(a))
+	assert.Equal(t, "19.99", money.New(1999, -2).String())
+}
+
+func TestRoundCurrency(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"100.005", "USD", "100.01"},
+		{"-100.005", "USD", "-100.01"},
+		{"100.004", "usd", "100"},
+		{"1050.5", "JPY", "1051"},
+		{"1.2345", "KWD", "1.235"},
+		{"1.2344", "KWD", "1.234"},
+	}
+
+	for _, tt := range tests {
+		got := money.MustParse(tt.amount).RoundCurrency(tt.currency)
+		assert.Equal(t, tt.want, got.String(), tt.amount+" "+tt.currency)
+	}
+}
+
+func TestFormatAndMinorUnits(t *testing.T) {
+	d := money.MustParse("1234.5")
+
+	assert.Equal(t, "1234.50", d.Format("USD"))
+	assert.Equal(t, "1235", d.Format("JPY"))
+	assert.Equal(t, "1234.500", d.Format("KWD"))
+	assert.Equal(t, int64(123450), d.MinorUnits("USD"))
+	assert.Equal(t, int64(1235), d.MinorUnits("JPY"))
+	assert.Equal(t, int64(1234500), d.MinorUnits("KWD"))
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Number money.Decimal
+		String money.Decimal
+		Exp    money.Decimal
+		Null   money.Decimal
+	}
+	err := json.Unmarshal([]byte(`{"Number": 10.25, "String": "0.1", "Exp": 1.5e3, "Null": null}`), &v)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.25", v.Number.String())
+	assert.Equal(t, "0.1", v.String.String())
+	assert.Equal(t, "1500", v.Exp.String())
+	assert.True(t, v.Null.IsZero())
+
+	out, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Number": 10.25, "String": 0.1, "Exp": 1500, "Null": 0}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"Number": "ten"}`), &v))
+}
+
+func TestScanAndValue(t *testing.T) {
+	var d money.Decimal
+
+	assert.NoError(t, d.Scan("99999999.99"))
+	assert.Equal(t, "99999999.99", d.String())
+
+	assert.NoError(t, d.Scan([]byte("123456789.5")))
+	assert.Equal(t, "123456789.5", d.String())
+
+	assert.NoError(t, d.Scan(int64(7)))
+	assert.Equal(t, "7", d.String())
+
+	assert.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+
+	value, err := money.MustParse("12.3400").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "12.34", value)
+}
+
+func TestOverflowPanics(t *testing.T) {
+	big := money.MustParse("900000000000000")
+
+	assert.Panics(t, func() { big.Add(big) })
+	assert.Panics(t, func() { big.MulInt(2) })
+
+	// Only the result of MulDiv has to fit, not the product
+	assert.True(t, big.MulDiv(big, big).Equal(big))
+	assert.True(t, big.Percent(money.NewFromInt(100)).Equal(big))
+}
@@ -0,0 +1,48 @@
+package pdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is an RGB colour.
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+)
+
+// ParseHexColor parses a colour written as #RRGGBB.
+func ParseHexColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 || len(s) != 7 {
+		return Color{}, fmt.Errorf("invalid colour %q, expected #RRGGBB", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid colour %q, expected #RRGGBB", s)
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// Hex returns the colour as #RRGGBB.
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// Tint mixes the colour with white; 0 leaves it unchanged and 1 gives white.
+func (c Color) Tint(amount float64) Color {
+	mix := func(v uint8) uint8 {
+		return uint8(float64(v) + (255-float64(v))*amount)
+	}
+	return Color{mix(c.R), mix(c.G), mix(c.B)}
+}
+
+// fill returns the colour's components as PDF operands.
+func (c Color) fill() string {
+	return fmt.Sprintf("%s %s %s", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
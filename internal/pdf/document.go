@@ -0,0 +1,342 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// fonts, lines, filled rectangles and raster images. It has no dependencies
+// outside the standard library.
+//
+// Coordinates are in points (1/72 inch) measured from the top left corner of
+// the page, with y growing downwards.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard PDF fonts, which every reader provides.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{
+	Helvetica:     "Helvetica",
+	HelveticaBold: "Helvetica-Bold",
+}
+
+// Document is a PDF being built page by page.
+type Document struct {
+	title     string
+	pages     []*bytes.Buffer
+	images    []*pdfImage
+	font      Font
+	fontSize  float64
+	textColor Color
+	fillColor Color
+	lineColor Color
+	lineWidth float64
+}
+
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+// New returns an empty document with the given title in its metadata.
+func New(title string) *Document {
+	return &Document{
+		title:     title,
+		fontSize:  10,
+		lineColor: Black,
+		lineWidth: 0.5,
+	}
+}
+
+// AddPage starts a new page; drawing calls go to the newest page.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+}
+
+// PageCount returns the number of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) SetFont(font Font, size float64) {
+	d.font = font
+	d.fontSize = size
+}
+
+func (d *Document) SetTextColor(c Color) { d.textColor = c }
+func (d *Document) SetFillColor(c Color) { d.fillColor = c }
+func (d *Document) SetLineColor(c Color) { d.lineColor = c }
+func (d *Document) SetLineWidth(w float64) {
+	d.lineWidth = w
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y float64, s string) {
+	page := d.page()
+	fmt.Fprintf(page, "BT %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		d.textColor.fill(), d.font+1, num(d.fontSize), num(x), num(PageHeight-y), escape(encode(s)))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y float64, s string) {
+	d.Text(x-d.StringWidth(s), y, s)
+}
+
+// TextCenter draws s centred on x.
+func (d *Document) TextCenter(x, y float64, s string) {
+	d.Text(x-d.StringWidth(s)/2, y, s)
+}
+
+// Rect fills the rectangle whose top left corner is at (x, y).
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.page(), "q %s rg %s %s %s %s re f Q\n",
+		d.fillColor.fill(), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line draws a straight line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "q %s RG %s w %s %s m %s %s l S Q\n",
+		d.lineColor.fill(), num(d.lineWidth), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Image draws img scaled into the box whose top left corner is at (x, y).
+// Transparent pixels are drawn over white.
+func (d *Document) Image(img image.Image, x, y, w, h float64) error {
+	data, err := rgbStream(img)
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	d.images = append(d.images, &pdfImage{width: bounds.Dx(), height: bounds.Dy(), data: data})
+
+	fmt.Fprintf(d.page(), "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		num(w), num(h), num(x), num(PageHeight-y-h), len(d.images))
+	return nil
+}
+
+// StringWidth returns the width of s in the current font and size.
+func (d *Document) StringWidth(s string) float64 {
+	widths := &fontWidths[d.font]
+	encoded := encode(s)
+	total := 0
+	for i := 0; i < len(encoded); i++ {
+		total += widths.width(encoded[i])
+	}
+	return float64(total) * d.fontSize / 1000
+}
+
+// WrapText splits s into lines no wider than width in the current font,
+// breaking at spaces where possible and keeping existing line breaks.
+func (d *Document) WrapText(s string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && d.StringWidth(candidate) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			// Break words that are too long on their own
+			for d.StringWidth(candidate) > width && len([]rune(candidate)) > 1 {
+				runes := []rune(candidate)
+				cut := len(runes) - 1
+				for cut > 1 && d.StringWidth(string(runes[:cut])) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				candidate = string(runes[cut:])
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	out := &writer{}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are fixed; images and then pages follow.
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		fontObj    = 3 // and 4 for the bold font
+		infoObj    = 5
+		firstImage = 6
+	)
+	firstPage := firstImage + len(d.images)
+
+	out.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	out.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	for i, name := range fontNames {
+		out.object(fontObj+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	out.object(infoObj, fmt.Sprintf("<< /Title (%s) /Producer (numeris) >>", escape(encode(d.title))))
+
+	for i, img := range d.images {
+		out.stream(firstImage+i, fmt.Sprintf(
+			"/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.data)
+	}
+
+	var xobjects strings.Builder
+	for i := range d.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i+1, firstImage+i)
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject <<%s >> >>", fontObj, fontObj+1, xobjects.String())
+
+	for i, content := range d.pages {
+		pageObj := firstPage + 2*i
+		out.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pagesObj, num(PageWidth), num(PageHeight), resources, pageObj+1))
+
+		compressed, err := deflate(content.Bytes())
+		if err != nil {
+			return 0, err
+		}
+		out.stream(pageObj+1, "/Filter /FlateDecode", compressed)
+	}
+
+	out.finish(catalogObj, infoObj)
+	return out.buf.WriteTo(w)
+}
+
+// Bytes returns the finished document.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writer lays out numbered objects and remembers where each one starts for
+// the cross-reference table.
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+func (w *writer) begin(id int) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	w.printf("%d 0 obj\n", id)
+}
+
+func (w *writer) object(id int, body string) {
+	w.begin(id)
+	w.printf("%s\nendobj\n", body)
+}
+
+func (w *writer) stream(id int, dict string, data []byte) {
+	w.begin(id)
+	w.printf("<< %s /Length %d >>\nstream\n", dict, len(data))
+	w.buf.Write(data)
+	w.printf("\nendstream\nendobj\n")
+}
+
+func (w *writer) finish(root, info int) {
+	start := w.buf.Len()
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		w.printf("%010d 00000 n \n", offset)
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, root, info, start)
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rgbStream returns the compressed RGB samples of img, blending any alpha
+// channel onto a white background.
+func rgbStream(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	raw := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			raw = append(raw, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	return deflate(raw)
+}
+
+// num formats a coordinate without needless digits.
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// escape makes s safe inside a PDF string literal.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iyiola-dev/numeris/internal/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var streamPattern = regexp.MustCompile(`(?s)/Length (\d+) >>\nstream\n`)
+
+// inflateStreams checks the cross-reference table of a document and returns
+// the content of its streams.
+func inflateStreams(t *testing.T, doc []byte) []string {
+	t.Helper()
+
+	require.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+
+	tail := doc[bytes.LastIndex(doc, []byte("startxref\n"))+len("startxref\n"):]
+	start, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(string(tail), "%%EOF\n")))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(doc[start:], []byte("xref\n")))
+
+	lines := strings.Split(string(doc[start:]), "\n")
+	count, err := strconv.Atoi(strings.Fields(lines[1])[1])
+	require.NoError(t, err)
+	for id := 1; id < count; id++ {
+		offset, err := strconv.Atoi(strings.Fields(lines[2+id])[0])
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(doc[offset:], []byte(strconv.Itoa(id)+" 0 obj\n")), "object %d", id)
+	}
+
+	var streams []string
+	for _, m := range streamPattern.FindAllSubmatchIndex(doc, -1) {
+		length, _ := strconv.Atoi(string(doc[m[2]:m[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(doc[m[1] : m[1]+length]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		streams = append(streams, string(content))
+	}
+	return streams
+}
+
+func TestDocument(t *testing.T) {
+	doc := pdf.New("Test (1)")
+	doc.AddPage()
+	doc.SetFont(pdf.HelveticaBold, 12)
+	doc.SetTextColor(pdf.Color{R: 255})
+	doc.Text(50, 60, `Total (USD) \ 100`)
+	doc.SetFillColor(pdf.Black)
+	doc.Rect(50, 70, 100, 20)
+	doc.Line(50, 100, 200, 100)
+
+	logo := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	logo.Set(0, 0, color.RGBA{R: 255, A: 255})
+	require.NoError(t, doc.Image(logo, 400, 40, 40, 40))
+
+	doc.AddPage()
+	doc.Text(50, 60, "Page two – €5")
+
+	out, err := doc.Bytes()
+	require.NoError(t, err)
+
+	streams := inflateStreams(t, out)
+	require.Len(t, streams, 3) // the image and two pages
+
+	// Text is placed from the bottom of the page and escaped
+	assert.Contains(t, streams[1], "BT 1 0 0 rg /F2 12 Tf 50 781.89 Td (Total \\(USD\\) \\\\ 100) Tj ET")
+	assert.Contains(t, streams[1], "/Im1 Do")
+	assert.Contains(t, streams[2], "(Page two \x96 \x805) Tj")
+	assert.Equal(t, "\xff\x00\x00\xff\xff\xff", streams[0][:6])
+
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/Title (Test \\(1\\))")
+}
+
+func TestStringWidth(t *testing.T) {
+	doc := pdf.New("")
+	doc.SetFont(pdf.Helvetica, 10)
+	assert.InDelta(t, 31.68, doc.StringWidth("Invoice"), 0.001)
+
+	doc.SetFont(pdf.HelveticaBold, 20)
+	assert.InDelta(t, 82.24, doc.StringWidth("INVOICE"), 0.001)
+}
+
+func TestWrapText(t *testing.T) {
+	doc := pdf.New("")
+	doc.SetFont(pdf.Helvetica, 10)
+
+	lines := doc.WrapText("Website design and development, phase one\nHosting", 100)
+	assert.Equal(t, []string{"Website design and", "development, phase", "one", "Hosting"}, lines)
+
+	for _, line := range doc.WrapText(strings.Repeat("x", 80), 100) {
+		assert.LessOrEqual(t, doc.StringWidth(line), 100.0)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, err := pdf.ParseHexColor("#1f4E79")
+	assert.NoError(t, err)
+	assert.Equal(t, pdf.Color{R: 0x1F, G: 0x4E, B: 0x79}, c)
+	assert.Equal(t, "#1F4E79", c.Hex())
+
+	for _, bad := range []string{"1F4E79", "#1F4E7", "#GGGGGG", "#1F4E79FF"} {
+		_, err := pdf.ParseHexColor(bad)
+		assert.Error(t, err, bad)
+	}
+}
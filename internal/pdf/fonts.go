@@ -0,0 +1,78 @@
+package pdf
+
+import "strings"
+
+// metrics holds a font's glyph widths in thousandths of the font size.
+type metrics struct {
+	ascii    [95]int // printable ASCII, from the space up to '~'
+	fallback int     // used for the rest of WinAnsiEncoding
+}
+
+func (m *metrics) width(b byte) int {
+	if b >= 32 && b <= 126 {
+		return m.ascii[b-32]
+	}
+	return m.fallback
+}
+
+// Widths from the Adobe font metrics of the standard fonts.
+var fontWidths = [...]metrics{
+	Helvetica: {
+		ascii: [95]int{
+			278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // ' ' to '/'
+			556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // '0' to '9'
+			278, 278, 584, 584, 584, 556, 1015, // ':' to '@'
+			667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // 'A' to 'M'
+			722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // 'N' to 'Z'
+			278, 278, 278, 469, 556, 333, // '[' to '`'
+			556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // 'a' to 'm'
+			556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // 'n' to 'z'
+			334, 260, 334, 584, // '{' to '~'
+		},
+		fallback: 556,
+	},
+	HelveticaBold: {
+		ascii: [95]int{
+			278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+			556, 556, 556, 556, 556, 556, 556, 556, 556, 556,
+			333, 333, 584, 584, 584, 611, 975,
+			722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833,
+			722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611,
+			333, 278, 333, 584, 556, 333,
+			556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889,
+			611, 611, 611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500,
+			389, 280, 389, 584,
+		},
+		fallback: 611,
+	},
+}
+
+// winAnsiExtras are the characters WinAnsiEncoding places between 0x80 and
+// 0x9F. From 0xA0 up it matches Latin-1.
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts s to WinAnsiEncoding, the character set of the standard
+// fonts. Characters outside it are replaced with '?'.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r <= 0x7E, r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsiExtras[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
@@ -1,11 +1,14 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-
 // User implementations
 func (r *repository) CreateUser(user *models.User) error {
 	return r.db.Create(user).Error
@@ -27,6 +30,47 @@ func (r *repository) DeleteUser(id uuid.UUID) error {
 	return r.db.Delete(&models.User{}, "id = ?", id).Error
 }
 
+func (r *repository) UpdateUserPassword(id uuid.UUID, hash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("password", hash).Error
+}
+
+func (r *repository) VerifyUserEmail(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", id).
+		Update("email_verified_at", at).Error
+}
+
+// UserToken implementations
+func (r *repository) CreateUserToken(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repository) GetUserTokenByHash(hash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	return &token, err
+}
+
+// ClaimUserToken marks a user token used. It reports false when the token was
+// used before, so a token works once even when used twice at the same time.
+func (r *repository) ClaimUserToken(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpireUserTokens uses up a user's unused tokens for purpose, so only the
+// newest one sent works.
+func (r *repository) ExpireUserTokens(userID uuid.UUID, purpose string, at time.Time) error {
+	return r.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
 // Customer implementations
 func (r *repository) CreateCustomer(customer *models.Customer) error {
 	return r.db.Create(customer).Error
@@ -49,15 +93,73 @@ func (r *repository) DeleteCustomer(id uuid.UUID) error {
 }
 
 // Invoice implementations
+// CreateInvoice inserts an invoice. Drafts are inserted without a number; one
+// created already issued is numbered from the user's sequence, unless it
+// already has a number, in the same transaction.
 func (r *repository) CreateInvoice(invoice *models.Invoice) error {
-	return r.db.Create(invoice).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if invoice.InvoiceNumber == "" && invoice.Status != models.InvoiceStatusDraft {
+			number, err := allocateInvoiceNumber(tx, invoice.UserID, invoice.IssueDate)
+			if err != nil {
+				return err
+			}
+			invoice.InvoiceNumber = number
+		}
+		return tx.Create(invoice).Error
+	})
+}
+
+// NumberInvoice gives an invoice the next number in the user's sequence. The
+// invoice row is locked first, so an invoice issued twice at once is only
+// numbered once; one that already has a number keeps it. Call it in the
+// transaction that issues the invoice, so a failed issue gives the number
+// back.
+func (r *repository) NumberInvoice(invoice *models.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Invoice
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "invoice_number").
+			First(&current, "id = ?", invoice.ID).Error
+		if err != nil {
+			return err
+		}
+		if current.InvoiceNumber != "" {
+			invoice.InvoiceNumber = current.InvoiceNumber
+			return nil
+		}
+
+		number, err := allocateInvoiceNumber(tx, invoice.UserID, invoice.IssueDate)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).
+			Update("invoice_number", number).Error
+		if err != nil {
+			return err
+		}
+		invoice.InvoiceNumber = number
+		return nil
+	})
 }
 
 func (r *repository) GetInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
+	return findInvoice(r.db, id)
+}
+
+// LockInvoice loads an invoice like GetInvoiceByID and locks its row with
+// SELECT ... FOR UPDATE until the surrounding transaction ends.
+func (r *repository) LockInvoice(id uuid.UUID) (*models.Invoice, error) {
+	return findInvoice(r.db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func findInvoice(db *gorm.DB, id uuid.UUID) (*models.Invoice, error) {
 	var invoice models.Invoice
-	err := r.db.Preload("Customer").
+	err := db.Preload("Customer").
 		Preload("User").
-		Preload("Items").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Items.Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Taxes").
+		Preload("LateFees", func(db *gorm.DB) *gorm.DB { return db.Where("reversed_at IS NULL").Order("period_start") }).
 		First(&invoice, "id = ?", id).Error
 	return &invoice, err
 }
@@ -66,15 +168,123 @@ func (r *repository) GetInvoices(filters map[string]interface{}) ([]models.Invoi
 	var invoices []models.Invoice
 	err := r.db.Preload("Customer").
 		Preload("User").
-		Preload("Items").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Items.Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Taxes").
+		Preload("LateFees", func(db *gorm.DB) *gorm.DB { return db.Where("reversed_at IS NULL").Order("period_start") }).
 		Where(filters).
 		Order("created_at DESC").
 		Find(&invoices).Error
 	return invoices, err
 }
 
+// GetOutstandingInvoices returns every invoice, across users, that is still
+// awaiting payment and due before the given time.
+func (r *repository) GetOutstandingInvoices(dueBefore time.Time) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Preload("User").Preload("Customer").
+		Where("status IN ? AND due_date < ?", []string{
+			models.InvoiceStatusPending,
+			models.InvoiceStatusSent,
+			models.InvoiceStatusViewed,
+			models.InvoiceStatusPartiallyPaid,
+			models.InvoiceStatusOverdue,
+		}, dueBefore).
+		Order("due_date").Find(&invoices).Error
+	return invoices, err
+}
+
+// GetDeletedInvoices returns soft deleted invoices, most recently deleted
+// first.
+func (r *repository) GetDeletedInvoices(filters map[string]interface{}) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Unscoped().
+		Preload("Customer").
+		Where(filters).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *repository) GetDeletedInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&invoice, "id = ?", id).Error
+	return &invoice, err
+}
+
+// DeleteInvoice soft deletes an invoice together with its items and payment
+// details. They are all stamped with the same time, so RestoreInvoice brings
+// back exactly what was deleted with the invoice.
 func (r *repository) DeleteInvoice(id uuid.UUID) error {
-	return r.db.Delete(&models.Invoice{}, "id = ?", id).Error
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.InvoiceItem{}).Where("invoice_id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PaymentDetails{}).Where("invoice_id = ?", id).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Invoice{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
+}
+
+// RestoreInvoice undoes DeleteInvoice for an invoice deleted at deletedAt.
+func (r *repository) RestoreInvoice(id uuid.UUID, deletedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.InvoiceItem{}).
+			Where("invoice_id = ? AND deleted_at = ?", id, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.PaymentDetails{}).
+			Where("invoice_id = ? AND deleted_at = ?", id, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Invoice{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+}
+
+// InvoiceRevision implementations
+
+// CreateInvoiceRevision stores a revision under the invoice's next number.
+// The invoice row is locked so concurrent changes get consecutive numbers.
+func (r *repository) CreateInvoiceRevision(revision *models.InvoiceRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&models.Invoice{}, "id = ?", revision.InvoiceID).Error
+		if err != nil {
+			return err
+		}
+
+		var last int
+		err = tx.Model(&models.InvoiceRevision{}).
+			Where("invoice_id = ?", revision.InvoiceID).
+			Select("COALESCE(MAX(number), 0)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		revision.Number = last + 1
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *repository) GetInvoiceRevisions(invoiceID uuid.UUID) ([]models.InvoiceRevision, error) {
+	var revisions []models.InvoiceRevision
+	err := r.db.Where("invoice_id = ?", invoiceID).Order("number").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *repository) GetInvoiceRevision(invoiceID uuid.UUID, number int) (*models.InvoiceRevision, error) {
+	var revision models.InvoiceRevision
+	err := r.db.First(&revision, "invoice_id = ? AND number = ?", invoiceID, number).Error
+	return &revision, err
 }
 
 // InvoiceItem implementations
@@ -92,14 +302,663 @@ func (r *repository) DeleteInvoiceItem(id uuid.UUID) error {
 	return r.db.Delete(&models.InvoiceItem{}, "id = ?", id).Error
 }
 
+// ReplaceInvoiceItemTaxes swaps the taxes charged on a line for new ones
+func (r *repository) ReplaceInvoiceItemTaxes(itemID uuid.UUID, taxes []models.InvoiceItemTax) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("invoice_item_id = ?", itemID).Delete(&models.InvoiceItemTax{}).Error; err != nil {
+			return err
+		}
+		if len(taxes) == 0 {
+			return nil
+		}
+		for i := range taxes {
+			taxes[i].InvoiceItemID = itemID
+		}
+		return tx.Create(&taxes).Error
+	})
+}
+
+// TaxRate implementations
+func (r *repository) CreateTaxRate(rate *models.TaxRate) error {
+	return r.db.Create(rate).Error
+}
+
+func (r *repository) GetTaxRateByID(id uuid.UUID) (*models.TaxRate, error) {
+	var rate models.TaxRate
+	err := r.db.First(&rate, "id = ?", id).Error
+	return &rate, err
+}
+
+func (r *repository) GetTaxRates(filters map[string]interface{}) ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+	err := r.db.Where(filters).Order("name").Find(&rates).Error
+	return rates, err
+}
+
+func (r *repository) DeleteTaxRate(id uuid.UUID) error {
+	return r.db.Delete(&models.TaxRate{}, "id = ?", id).Error
+}
+
+// ReplaceInvoiceTaxes swaps an invoice's tax breakdown for a freshly computed one
+func (r *repository) ReplaceInvoiceTaxes(invoiceID uuid.UUID, taxes []models.InvoiceTax) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("invoice_id = ?", invoiceID).Delete(&models.InvoiceTax{}).Error; err != nil {
+			return err
+		}
+		if len(taxes) == 0 {
+			return nil
+		}
+		return tx.Create(&taxes).Error
+	})
+}
+
+// Payment implementations
+func (r *repository) CreatePayment(payment *models.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *repository) GetPaymentByID(id uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.First(&payment, "id = ?", id).Error
+	return &payment, err
+}
+
+func (r *repository) GetPayments(filters map[string]interface{}) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Where(filters).Order("paid_at, created_at").Find(&payments).Error
+	return payments, err
+}
+
+func (r *repository) DeletePayment(id uuid.UUID) error {
+	return r.db.Delete(&models.Payment{}, "id = ?", id).Error
+}
+
+// Session implementations
+func (r *repository) CreateSession(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *repository) GetSessionByID(id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, "id = ?", id).Error
+	return &session, err
+}
+
+func (r *repository) TouchSession(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+func (r *repository) RevokeSession(id uuid.UUID, at time.Time, reason string) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason}).Error
+}
+
+// RevokeUserSessions revokes every session of a user still open and returns
+// how many there were.
+func (r *repository) RevokeUserSessions(userID uuid.UUID, at time.Time, reason string) (int, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, at).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason})
+	return int(result.RowsAffected), result.Error
+}
+
+// RevokeOtherSessions revokes every open session of a user except keepID and
+// returns how many there were.
+func (r *repository) RevokeOtherSessions(userID, keepID uuid.UUID, at time.Time, reason string) (int, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, keepID, at).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_reason": reason})
+	return int(result.RowsAffected), result.Error
+}
+
+func (r *repository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	return &token, err
+}
+
+// ClaimRefreshToken marks a refresh token used. It reports false when the
+// token was used before, so only one of two concurrent refreshes wins.
+func (r *repository) ClaimRefreshToken(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ShareLink implementations
+func (r *repository) CreateShareLink(link *models.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *repository) GetShareLinkByTokenHash(hash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := r.db.First(&link, "token_hash = ?", hash).Error
+	return &link, err
+}
+
+func (r *repository) GetShareLinks(filters map[string]interface{}) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := r.db.Where(filters).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *repository) RevokeShareLink(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// RecordShareLinkView counts a view at time at, unless the link was last
+// viewed after since. It reports whether the view was counted; of several
+// views at once, only one is.
+func (r *repository) RecordShareLinkView(id uuid.UUID, at, since time.Time) (bool, error) {
+	result := r.db.Model(&models.ShareLink{}).
+		Where("id = ? AND (last_viewed_at IS NULL OR last_viewed_at <= ?)", id, since).
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": at,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RecurringInvoice implementations
+func (r *repository) CreateRecurringInvoice(recurring *models.RecurringInvoice) error {
+	return r.db.Create(recurring).Error
+}
+
+func (r *repository) GetRecurringInvoiceByID(id uuid.UUID) (*models.RecurringInvoice, error) {
+	var recurring models.RecurringInvoice
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&recurring, "id = ?", id).Error
+	return &recurring, err
+}
+
+func (r *repository) GetRecurringInvoices(filters map[string]interface{}) ([]models.RecurringInvoice, error) {
+	var recurring []models.RecurringInvoice
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where(filters).Order("created_at").Find(&recurring).Error
+	return recurring, err
+}
+
+// GetDueRecurringInvoices returns the active templates with an occurrence at
+// or before now that has not been invoiced yet.
+func (r *repository) GetDueRecurringInvoices(now time.Time) ([]models.RecurringInvoice, error) {
+	var recurring []models.RecurringInvoice
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at").Find(&recurring).Error
+	return recurring, err
+}
+
+func (r *repository) UpdateRecurringInvoice(id uuid.UUID, recurring *models.RecurringInvoice) error {
+	return r.db.Model(&models.RecurringInvoice{}).Where("id = ?", id).
+		Select("customer_id", "name", "currency", "discount_type", "discount", "prices_include_tax",
+			"note", "payment_terms_days", "cadence", "cron_rule", "start_date", "end_date",
+			"next_run_at", "auto_send", "active").
+		Updates(recurring).Error
+}
+
+func (r *repository) ReplaceRecurringInvoiceItems(recurringID uuid.UUID, items []models.RecurringInvoiceItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecurringInvoiceItem{}, "recurring_invoice_id = ?", recurringID).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *repository) DeleteRecurringInvoice(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecurringInvoiceItem{}, "recurring_invoice_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.RecurringInvoice{}, "id = ?", id).Error
+	})
+}
+
+// ClaimRecurringInvoiceRun records a period as being invoiced. It reports
+// false when the period was already claimed, by this or another process.
+func (r *repository) ClaimRecurringInvoiceRun(run *models.RecurringInvoiceRun) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recurring_invoice_id"}, {Name: "period_start"}},
+		DoNothing: true,
+	}).Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) SetRecurringInvoiceRunInvoice(runID, invoiceID uuid.UUID) error {
+	return r.db.Model(&models.RecurringInvoiceRun{}).
+		Where("id = ?", runID).
+		Update("invoice_id", invoiceID).Error
+}
+
+// CreditNote implementations
+
+// CreateCreditNote numbers the credit note from the user's credit note
+// sequence and inserts it with its items in the same transaction.
+func (r *repository) CreateCreditNote(note *models.CreditNote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		number, err := allocateCreditNoteNumber(tx, note.UserID, note.IssueDate)
+		if err != nil {
+			return err
+		}
+		note.CreditNoteNumber = number
+		return tx.Create(note).Error
+	})
+}
+
+func (r *repository) GetCreditNoteByID(id uuid.UUID) (*models.CreditNote, error) {
+	var note models.CreditNote
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&note, "id = ?", id).Error
+	return &note, err
+}
+
+func (r *repository) GetCreditNotes(filters map[string]interface{}) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where(filters).
+		Order("created_at DESC").
+		Find(&notes).Error
+	return notes, err
+}
+
+// Quote implementations
+
+// CreateQuote numbers the quote from the user's quote sequence and inserts it
+// with its items in the same transaction.
+func (r *repository) CreateQuote(quote *models.Quote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		number, err := allocateQuoteNumber(tx, quote.UserID, quote.IssueDate)
+		if err != nil {
+			return err
+		}
+		quote.QuoteNumber = number
+		return tx.Create(quote).Error
+	})
+}
+
+func (r *repository) GetQuoteByID(id uuid.UUID) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.Preload("Customer").
+		Preload("User").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&quote, "id = ?", id).Error
+	return &quote, err
+}
+
+func (r *repository) GetQuoteByTokenHash(hash string) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.Preload("Customer").
+		Preload("User").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&quote, "acceptance_token_hash = ?", hash).Error
+	return &quote, err
+}
+
+func (r *repository) GetQuotes(filters map[string]interface{}) ([]models.Quote, error) {
+	var quotes []models.Quote
+	err := r.db.Preload("Customer").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where(filters).
+		Order("created_at DESC").
+		Find(&quotes).Error
+	return quotes, err
+}
+
+// quoteColumns are the columns saved when a quote is updated.
+var quoteColumns = []string{"customer_id", "issue_date", "expiry_date", "currency", "sub_total", "discount_type",
+	"discount_value", "discount", "tax_total", "total_amount", "prices_include_tax", "reverse_charge",
+	"taxes", "status", "note", "acceptance_token_hash", "sent_at", "accepted_at", "declined_at",
+	"signer_name", "signature", "decline_reason", "responded_from_ip", "invoice_id"}
+
+func (r *repository) UpdateQuote(id uuid.UUID, quote *models.Quote) error {
+	return r.db.Model(&models.Quote{}).Where("id = ?", id).
+		Select(quoteColumns).Updates(quote).Error
+}
+
+// TransitionQuote saves a quote only if it is still in one of the given
+// statuses. It reports false when the quote had moved on, e.g. because the
+// customer answered it in the meantime.
+func (r *repository) TransitionQuote(quote *models.Quote, from ...string) (bool, error) {
+	result := r.db.Model(&models.Quote{}).Where("id = ? AND status IN ?", quote.ID, from).
+		Select(quoteColumns).Updates(quote)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) ReplaceQuoteItems(quoteID uuid.UUID, items []models.QuoteItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.QuoteItem{}, "quote_id = ?", quoteID).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *repository) DeleteQuote(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.QuoteItem{}, "quote_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Quote{}, "id = ?", id).Error
+	})
+}
+
+// ExpireQuotes marks every sent quote that expired before the given time as
+// expired and returns how many there were.
+func (r *repository) ExpireQuotes(expiredBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.Quote{}).
+		Where("status = ? AND expiry_date < ?", models.QuoteStatusSent, expiredBefore).
+		Update("status", models.QuoteStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// ClaimQuoteConversion marks a quote as being converted. It reports false
+// when the quote was already converted, by this or another request.
+func (r *repository) ClaimQuoteConversion(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.Quote{}).
+		Where("id = ? AND converted_at IS NULL", id).
+		Update("converted_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) ReleaseQuoteConversion(id uuid.UUID) error {
+	return r.db.Model(&models.Quote{}).Where("id = ?", id).
+		Update("converted_at", nil).Error
+}
+
+// InvoiceDelivery implementations
+func (r *repository) CreateInvoiceDelivery(delivery *models.InvoiceDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *repository) GetInvoiceDeliveries(filters map[string]interface{}) ([]models.InvoiceDelivery, error) {
+	var deliveries []models.InvoiceDelivery
+	err := r.db.Where(filters).Order("attempted_at DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *repository) UpdateInvoiceDelivery(id uuid.UUID, delivery *models.InvoiceDelivery) error {
+	return r.db.Model(&models.InvoiceDelivery{}).Where("id = ?", id).
+		Select("status", "error", "delivered_at").
+		Updates(delivery).Error
+}
+
+// InvoiceSequence implementations
+func (r *repository) GetInvoiceSequence(userID uuid.UUID) (*models.InvoiceSequence, error) {
+	var seq models.InvoiceSequence
+	err := r.db.First(&seq, "user_id = ?", userID).Error
+	return &seq, err
+}
+
+func (r *repository) SaveInvoiceSequence(seq *models.InvoiceSequence) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSequence(tx, models.DefaultInvoiceSequence(seq.UserID)); err != nil {
+			return err
+		}
+		return tx.Model(&models.InvoiceSequence{}).
+			Where("user_id = ?", seq.UserID).
+			Select("prefix", "separator", "include_year", "reset_yearly", "padding", "next_number").
+			Updates(seq).Error
+	})
+}
+
+func (r *repository) GetCreditNoteSequence(userID uuid.UUID) (*models.CreditNoteSequence, error) {
+	var seq models.CreditNoteSequence
+	err := r.db.First(&seq, "user_id = ?", userID).Error
+	return &seq, err
+}
+
+func (r *repository) SaveCreditNoteSequence(seq *models.CreditNoteSequence) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSequence(tx, models.DefaultCreditNoteSequence(seq.UserID)); err != nil {
+			return err
+		}
+		return tx.Model(&models.CreditNoteSequence{}).
+			Where("user_id = ?", seq.UserID).
+			Select("prefix", "separator", "include_year", "reset_yearly", "padding", "next_number").
+			Updates(seq).Error
+	})
+}
+
+func (r *repository) GetQuoteSequence(userID uuid.UUID) (*models.QuoteSequence, error) {
+	var seq models.QuoteSequence
+	err := r.db.First(&seq, "user_id = ?", userID).Error
+	return &seq, err
+}
+
+func (r *repository) SaveQuoteSequence(seq *models.QuoteSequence) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSequence(tx, models.DefaultQuoteSequence(seq.UserID)); err != nil {
+			return err
+		}
+		return tx.Model(&models.QuoteSequence{}).
+			Where("user_id = ?", seq.UserID).
+			Select("prefix", "separator", "include_year", "reset_yearly", "padding", "next_number").
+			Updates(seq).Error
+	})
+}
+
+// numberSequence is a user's invoice or credit note numbering sequence.
+type numberSequence interface {
+	Allocate(issued time.Time) string
+}
+
+// ensureSequence creates a user's sequence with the default scheme if it does
+// not exist yet.
+func ensureSequence(tx *gorm.DB, defaults numberSequence) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoNothing: true,
+	}).Create(defaults).Error
+}
+
+func allocateInvoiceNumber(tx *gorm.DB, userID uuid.UUID, issued time.Time) (string, error) {
+	return allocateNumber(tx, &models.InvoiceSequence{}, models.DefaultInvoiceSequence(userID), userID, issued)
+}
+
+func allocateCreditNoteNumber(tx *gorm.DB, userID uuid.UUID, issued time.Time) (string, error) {
+	return allocateNumber(tx, &models.CreditNoteSequence{}, models.DefaultCreditNoteSequence(userID), userID, issued)
+}
+
+func allocateQuoteNumber(tx *gorm.DB, userID uuid.UUID, issued time.Time) (string, error) {
+	return allocateNumber(tx, &models.QuoteSequence{}, models.DefaultQuoteSequence(userID), userID, issued)
+}
+
+// allocateNumber takes the next number from a user's sequence, loaded into
+// seq, creating the sequence from defaults first if needed. The sequence row
+// stays locked until the surrounding transaction ends, so concurrent
+// documents wait their turn and a failed insert gives the number back instead
+// of leaving a gap.
+func allocateNumber(tx *gorm.DB, seq, defaults numberSequence, userID uuid.UUID, issued time.Time) (string, error) {
+	if err := ensureSequence(tx, defaults); err != nil {
+		return "", err
+	}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(seq, "user_id = ?", userID).Error
+	if err != nil {
+		return "", err
+	}
+
+	number := seq.Allocate(issued)
+	err = tx.Model(seq).
+		Select("next_number", "year").
+		Updates(seq).Error
+	if err != nil {
+		return "", err
+	}
+
+	return number, nil
+}
+
+// InvoiceTemplateSettings implementations
+func (r *repository) GetInvoiceTemplateSettings(userID uuid.UUID) (*models.InvoiceTemplateSettings, error) {
+	var settings models.InvoiceTemplateSettings
+	err := r.db.First(&settings, "user_id = ?", userID).Error
+	return &settings, err
+}
+
+func (r *repository) SaveInvoiceTemplateSettings(settings *models.InvoiceTemplateSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"template", "accent_color", "footer_text", "logo", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// Reminder implementations
+func (r *repository) GetReminderSettings(userID uuid.UUID) (*models.ReminderSettings, error) {
+	var settings models.ReminderSettings
+	err := r.db.First(&settings, "user_id = ?", userID).Error
+	return &settings, err
+}
+
+func (r *repository) SaveReminderSettings(settings *models.ReminderSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "templates", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// ClaimInvoiceReminder records a reminder as sent. It reports false when that
+// step was already sent for the invoice.
+func (r *repository) ClaimInvoiceReminder(reminder *models.InvoiceReminder) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "invoice_id"}, {Name: "days_offset"}},
+		DoNothing: true,
+	}).Create(reminder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) DeleteInvoiceReminder(id uuid.UUID) error {
+	return r.db.Delete(&models.InvoiceReminder{}, "id = ?", id).Error
+}
+
+// LateFee implementations
+
+// GetLateFeePolicy returns the policy set for an invoice, or the user's
+// default policy when invoiceID is nil.
+func (r *repository) GetLateFeePolicy(userID uuid.UUID, invoiceID *uuid.UUID) (*models.LateFeePolicy, error) {
+	var policy models.LateFeePolicy
+	query := r.db.Where("user_id = ?", userID)
+	if invoiceID == nil {
+		query = query.Where("invoice_id IS NULL")
+	} else {
+		query = query.Where("invoice_id = ?", *invoiceID)
+	}
+	err := query.First(&policy).Error
+	return &policy, err
+}
+
+func (r *repository) SaveLateFeePolicy(policy *models.LateFeePolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *repository) DeleteLateFeePolicy(id uuid.UUID) error {
+	return r.db.Delete(&models.LateFeePolicy{}, "id = ?", id).Error
+}
+
+// ClaimLateFee records a late fee. It reports false when the fee's period was
+// already charged on the invoice.
+func (r *repository) ClaimLateFee(fee *models.LateFee) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "invoice_id"}, {Name: "period_start"}},
+		DoNothing: true,
+	}).Create(fee)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) GetLateFeeByID(id uuid.UUID) (*models.LateFee, error) {
+	var fee models.LateFee
+	err := r.db.First(&fee, "id = ?", id).Error
+	return &fee, err
+}
+
+func (r *repository) GetLateFees(filters map[string]interface{}) ([]models.LateFee, error) {
+	var fees []models.LateFee
+	err := r.db.Where(filters).Order("period_start").Find(&fees).Error
+	return fees, err
+}
+
+func (r *repository) ReverseLateFee(id uuid.UUID, at time.Time, reason string) error {
+	return r.db.Model(&models.LateFee{}).
+		Where("id = ? AND reversed_at IS NULL", id).
+		Updates(map[string]interface{}{"reversed_at": at, "reversal_reason": reason}).Error
+}
+
 // ActivityLog implementations
 func (r *repository) CreateActivityLog(log *models.ActivityLog) error {
 	return r.db.Create(log).Error
 }
 
-func (r *repository) GetActivityLogs(filters map[string]interface{}) ([]models.ActivityLog, error) {
+func (r *repository) GetActivityLogs(filter ActivityLogFilter) ([]models.ActivityLog, error) {
+	query := r.db.Preload("User").Preload("Invoice").Where("user_id = ?", filter.UserID)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.InvoiceID != nil {
+		query = query.Where("invoice_id = ?", *filter.InvoiceID)
+	}
+	if filter.From != nil {
+		query = query.Where(`"timestamp" >= ?`, *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where(`"timestamp" < ?`, *filter.To)
+	}
+
+	// The ID breaks ties between entries logged at the same instant, so a
+	// cursor always lands between two distinct entries
+	order, after := "DESC", "<"
+	if filter.Ascending {
+		order, after = "ASC", ">"
+	}
+	if filter.After != nil {
+		query = query.Where(`("timestamp", id) `+after+` (?, ?)`, filter.After.Timestamp, filter.After.ID)
+	}
+
 	var logs []models.ActivityLog
-	err := r.db.Preload("User").Preload("Invoice").Where(filters).Find(&logs).Error
+	err := query.Order(`"timestamp" ` + order).Order("id " + order).Limit(filter.Limit).Find(&logs).Error
 	return logs, err
 }
 
@@ -124,17 +983,34 @@ func (r *repository) UpdateUser(id uuid.UUID, user *models.User) error {
 }
 
 func (r *repository) UpdateCustomer(id uuid.UUID, customer *models.Customer) error {
-	return r.db.Model(&models.Customer{}).Where("id = ?", id).Updates(customer).Error
+	return r.db.Model(&models.Customer{}).Where("id = ?", id).
+		Select("name", "email", "address", "tax_number", "reverse_charge").
+		Updates(customer).Error
 }
 
+// UpdateInvoice writes every column, so amounts recalculated down to zero are
+// saved too. Associations are left alone, and so is the number, which only
+// NumberInvoice assigns.
 func (r *repository) UpdateInvoice(id uuid.UUID, invoice *models.Invoice) error {
-	return r.db.Model(&models.Invoice{}).Where("id = ?", id).Updates(invoice).Error
+	return r.db.Model(&models.Invoice{}).Where("id = ?", id).
+		Select("*").
+		Omit(clause.Associations, "id", "invoice_number", "created_at", "deleted_at").
+		Updates(invoice).Error
 }
 
 func (r *repository) UpdateInvoiceItem(id uuid.UUID, item *models.InvoiceItem) error {
-	return r.db.Model(&models.InvoiceItem{}).Where("id = ?", id).Updates(item).Error
+	return r.db.Model(&models.InvoiceItem{}).Where("id = ?", id).
+		Select("*").
+		Omit(clause.Associations, "id").
+		Updates(item).Error
 }
 
 func (r *repository) UpdatePaymentDetails(id uuid.UUID, details *models.PaymentDetails) error {
 	return r.db.Model(&models.PaymentDetails{}).Where("id = ?", id).Updates(details).Error
-}
\ No newline at end of file
+}
+
+func (r *repository) UpdateTaxRate(id uuid.UUID, rate *models.TaxRate) error {
+	return r.db.Model(&models.TaxRate{}).Where("id = ?", id).
+		Select("name", "rate", "compound", "active").
+		Updates(rate).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/iyiola-dev/numeris/internal/db"
 	"github.com/iyiola-dev/numeris/internal/models"
@@ -13,6 +15,25 @@ type Repository interface {
 	GetUserByID(id uuid.UUID) (*models.User, error)
 	GetUsers(filters map[string]interface{}) ([]models.User, error)
 	DeleteUser(id uuid.UUID) error
+	UpdateUserPassword(id uuid.UUID, hash string) error
+	VerifyUserEmail(id uuid.UUID, at time.Time) error
+
+	// UserToken
+	CreateUserToken(token *models.UserToken) error
+	GetUserTokenByHash(hash string) (*models.UserToken, error)
+	ClaimUserToken(id uuid.UUID, at time.Time) (bool, error)
+	ExpireUserTokens(userID uuid.UUID, purpose string, at time.Time) error
+
+	// Session
+	CreateSession(session *models.Session) error
+	GetSessionByID(id uuid.UUID) (*models.Session, error)
+	TouchSession(id uuid.UUID, at time.Time) error
+	RevokeSession(id uuid.UUID, at time.Time, reason string) error
+	RevokeUserSessions(userID uuid.UUID, at time.Time, reason string) (int, error)
+	RevokeOtherSessions(userID, keepID uuid.UUID, at time.Time, reason string) (int, error)
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	ClaimRefreshToken(id uuid.UUID, at time.Time) (bool, error)
 
 	// Customer
 	CreateCustomer(customer *models.Customer) error
@@ -22,31 +43,152 @@ type Repository interface {
 
 	// Invoice
 	CreateInvoice(invoice *models.Invoice) error
+	NumberInvoice(invoice *models.Invoice) error
 	GetInvoiceByID(id uuid.UUID) (*models.Invoice, error)
+	LockInvoice(id uuid.UUID) (*models.Invoice, error)
 	GetInvoices(filters map[string]interface{}) ([]models.Invoice, error)
+	GetOutstandingInvoices(dueBefore time.Time) ([]models.Invoice, error)
 	DeleteInvoice(id uuid.UUID) error
+	GetDeletedInvoices(filters map[string]interface{}) ([]models.Invoice, error)
+	GetDeletedInvoiceByID(id uuid.UUID) (*models.Invoice, error)
+	RestoreInvoice(id uuid.UUID, deletedAt time.Time) error
+
+	// InvoiceRevision
+	CreateInvoiceRevision(revision *models.InvoiceRevision) error
+	GetInvoiceRevisions(invoiceID uuid.UUID) ([]models.InvoiceRevision, error)
+	GetInvoiceRevision(invoiceID uuid.UUID, number int) (*models.InvoiceRevision, error)
 
 	// InvoiceItem
 	CreateInvoiceItem(item *models.InvoiceItem) error
 	GetInvoiceItems(invoiceID uuid.UUID) ([]models.InvoiceItem, error)
 	DeleteInvoiceItem(id uuid.UUID) error
+	ReplaceInvoiceItemTaxes(itemID uuid.UUID, taxes []models.InvoiceItemTax) error
+
+	// TaxRate
+	CreateTaxRate(rate *models.TaxRate) error
+	GetTaxRateByID(id uuid.UUID) (*models.TaxRate, error)
+	GetTaxRates(filters map[string]interface{}) ([]models.TaxRate, error)
+	DeleteTaxRate(id uuid.UUID) error
+	ReplaceInvoiceTaxes(invoiceID uuid.UUID, taxes []models.InvoiceTax) error
+
+	// Payment
+	CreatePayment(payment *models.Payment) error
+	GetPaymentByID(id uuid.UUID) (*models.Payment, error)
+	GetPayments(filters map[string]interface{}) ([]models.Payment, error)
+	DeletePayment(id uuid.UUID) error
+
+	// ShareLink
+	CreateShareLink(link *models.ShareLink) error
+	GetShareLinkByTokenHash(hash string) (*models.ShareLink, error)
+	GetShareLinks(filters map[string]interface{}) ([]models.ShareLink, error)
+	RevokeShareLink(id uuid.UUID, at time.Time) error
+	RecordShareLinkView(id uuid.UUID, at, since time.Time) (bool, error)
+
+	// RecurringInvoice
+	CreateRecurringInvoice(recurring *models.RecurringInvoice) error
+	GetRecurringInvoiceByID(id uuid.UUID) (*models.RecurringInvoice, error)
+	GetRecurringInvoices(filters map[string]interface{}) ([]models.RecurringInvoice, error)
+	GetDueRecurringInvoices(now time.Time) ([]models.RecurringInvoice, error)
+	UpdateRecurringInvoice(id uuid.UUID, recurring *models.RecurringInvoice) error
+	ReplaceRecurringInvoiceItems(recurringID uuid.UUID, items []models.RecurringInvoiceItem) error
+	DeleteRecurringInvoice(id uuid.UUID) error
+	ClaimRecurringInvoiceRun(run *models.RecurringInvoiceRun) (bool, error)
+	SetRecurringInvoiceRunInvoice(runID, invoiceID uuid.UUID) error
+
+	// CreditNote
+	CreateCreditNote(note *models.CreditNote) error
+	GetCreditNoteByID(id uuid.UUID) (*models.CreditNote, error)
+	GetCreditNotes(filters map[string]interface{}) ([]models.CreditNote, error)
+
+	// Quote
+	CreateQuote(quote *models.Quote) error
+	GetQuoteByID(id uuid.UUID) (*models.Quote, error)
+	GetQuoteByTokenHash(hash string) (*models.Quote, error)
+	GetQuotes(filters map[string]interface{}) ([]models.Quote, error)
+	UpdateQuote(id uuid.UUID, quote *models.Quote) error
+	TransitionQuote(quote *models.Quote, from ...string) (bool, error)
+	ReplaceQuoteItems(quoteID uuid.UUID, items []models.QuoteItem) error
+	DeleteQuote(id uuid.UUID) error
+	ExpireQuotes(expiredBefore time.Time) (int64, error)
+	ClaimQuoteConversion(id uuid.UUID, at time.Time) (bool, error)
+	ReleaseQuoteConversion(id uuid.UUID) error
+
+	// InvoiceDelivery
+	CreateInvoiceDelivery(delivery *models.InvoiceDelivery) error
+	GetInvoiceDeliveries(filters map[string]interface{}) ([]models.InvoiceDelivery, error)
+	UpdateInvoiceDelivery(id uuid.UUID, delivery *models.InvoiceDelivery) error
+
+	// InvoiceSequence
+	GetInvoiceSequence(userID uuid.UUID) (*models.InvoiceSequence, error)
+	SaveInvoiceSequence(seq *models.InvoiceSequence) error
+	GetCreditNoteSequence(userID uuid.UUID) (*models.CreditNoteSequence, error)
+	SaveCreditNoteSequence(seq *models.CreditNoteSequence) error
+	GetQuoteSequence(userID uuid.UUID) (*models.QuoteSequence, error)
+	SaveQuoteSequence(seq *models.QuoteSequence) error
+
+	// InvoiceTemplateSettings
+	GetInvoiceTemplateSettings(userID uuid.UUID) (*models.InvoiceTemplateSettings, error)
+	SaveInvoiceTemplateSettings(settings *models.InvoiceTemplateSettings) error
+
+	// Reminders
+	GetReminderSettings(userID uuid.UUID) (*models.ReminderSettings, error)
+	SaveReminderSettings(settings *models.ReminderSettings) error
+	ClaimInvoiceReminder(reminder *models.InvoiceReminder) (bool, error)
+	DeleteInvoiceReminder(id uuid.UUID) error
+
+	// LateFees
+	GetLateFeePolicy(userID uuid.UUID, invoiceID *uuid.UUID) (*models.LateFeePolicy, error)
+	SaveLateFeePolicy(policy *models.LateFeePolicy) error
+	DeleteLateFeePolicy(id uuid.UUID) error
+	ClaimLateFee(fee *models.LateFee) (bool, error)
+	GetLateFeeByID(id uuid.UUID) (*models.LateFee, error)
+	GetLateFees(filters map[string]interface{}) ([]models.LateFee, error)
+	ReverseLateFee(id uuid.UUID, at time.Time, reason string) error
 
 	// ActivityLog
 	CreateActivityLog(log *models.ActivityLog) error
-	GetActivityLogs(filters map[string]interface{}) ([]models.ActivityLog, error)
+	GetActivityLogs(filter ActivityLogFilter) ([]models.ActivityLog, error)
 
 	// PaymentDetails
 	CreatePaymentDetails(details *models.PaymentDetails) error
 	GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models.PaymentDetails, error)
 	DeletePaymentDetails(id uuid.UUID) error
 
+	// Update methods
+	UpdateUser(id uuid.UUID, user *models.User) error
+	UpdateCustomer(id uuid.UUID, customer *models.Customer) error
+	UpdateInvoice(id uuid.UUID, invoice *models.Invoice) error
+	UpdateInvoiceItem(id uuid.UUID, item *models.InvoiceItem) error
+	UpdatePaymentDetails(id uuid.UUID, details *models.PaymentDetails) error
+	UpdateTaxRate(id uuid.UUID, rate *models.TaxRate) error
+
+	// Unit of work
 
-    // Update methods
-    UpdateUser(id uuid.UUID, user *models.User) error
-    UpdateCustomer(id uuid.UUID, customer *models.Customer) error
-    UpdateInvoice(id uuid.UUID, invoice *models.Invoice) error
-    UpdateInvoiceItem(id uuid.UUID, item *models.InvoiceItem) error
-    UpdatePaymentDetails(id uuid.UUID, details *models.PaymentDetails) error
+	// WithTx runs fn against a repository bound to a single transaction. The
+	// transaction commits when fn returns nil and rolls back otherwise.
+	WithTx(fn func(repo Repository) error) error
+}
+
+// ActivityLogFilter selects a page of a user's activity log. Zero fields do
+// not filter. Entries come newest first unless Ascending is set; After, when
+// set, skips everything up to and including that entry.
+type ActivityLogFilter struct {
+	UserID     uuid.UUID
+	Action     models.ActivityAction
+	EntityType string
+	EntityID   *uuid.UUID
+	InvoiceID  *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	Ascending  bool
+	After      *ActivityLogCursor
+	Limit      int
+}
+
+// ActivityLogCursor is the position of an entry in the activity log.
+type ActivityLogCursor struct {
+	Timestamp time.Time
+	ID        uuid.UUID
 }
 
 type repository struct {
@@ -56,3 +198,9 @@ type repository struct {
 func NewRepository() Repository {
 	return &repository{db: db.DB}
 }
+
+func (r *repository) WithTx(fn func(repo Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&repository{db: tx})
+	})
+}
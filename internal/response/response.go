@@ -1,8 +1,179 @@
 package response
 
-import "github.com/iyiola-dev/numeris/internal/models"
+import (
+	"time"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+)
 
 type LoginResponse struct {
-	User  *models.User `json:"user"`
-	Token string      `json:"token"`
-}
\ No newline at end of file
+	User *models.User `json:"user"`
+	TokenPair
+}
+
+// TokenPair is a short lived access token and the refresh token that
+// replaces it. A refresh token can be used once.
+type TokenPair struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// ActivityLogPage is one page of the activity log. NextCursor is empty on the
+// last page.
+type ActivityLogPage struct {
+	Logs       []models.ActivityLog `json:"logs"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// CustomerResponse is a customer together with its invoice history and the
+// amount still owed to the user, grouped by invoice currency.
+type CustomerResponse struct {
+	Customer           *models.Customer         `json:"customer"`
+	Invoices           []models.Invoice         `json:"invoices"`
+	OutstandingBalance map[string]money.Decimal `json:"outstanding_balance"`
+}
+
+// InvoicePDF is a rendered invoice and the file name to offer it under.
+type InvoicePDF struct {
+	FileName string
+	Content  []byte
+}
+
+// InvoiceRevisionDiff lists the fields that differ between two revisions of
+// an invoice.
+type InvoiceRevisionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange is one field that differs between two revisions. Field is its
+// path in the invoice snapshot, e.g. Items[1].Quantity; From or To is null
+// when the field only exists in one of them.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// InvoiceSequenceResponse is a user's numbering scheme together with the
+// number the next invoice issued today would get.
+type InvoiceSequenceResponse struct {
+	Sequence          *models.InvoiceSequence `json:"sequence"`
+	NextInvoiceNumber string                  `json:"next_invoice_number"`
+}
+
+// CreditNoteSequenceResponse is a user's credit note numbering scheme together
+// with the number the next credit note issued today would get.
+type CreditNoteSequenceResponse struct {
+	Sequence             *models.CreditNoteSequence `json:"sequence"`
+	NextCreditNoteNumber string                     `json:"next_credit_note_number"`
+}
+
+// QuoteSequenceResponse is a user's quote numbering scheme together with the
+// number the next quote issued today would get.
+type QuoteSequenceResponse struct {
+	Sequence        *models.QuoteSequence `json:"sequence"`
+	NextQuoteNumber string                `json:"next_quote_number"`
+}
+
+// ShareLinkResponse is a newly created share link. The token is only
+// returned here; afterwards just its prefix is known.
+type ShareLinkResponse struct {
+	Link  *models.ShareLink `json:"link"`
+	Token string            `json:"token"`
+	Path  string            `json:"path"`
+}
+
+// SharedInvoice is the customer facing view of an invoice opened through a
+// share link. It leaves out internal IDs, activity and account data.
+type SharedInvoice struct {
+	InvoiceNumber  string                `json:"invoice_number"`
+	Status         string                `json:"status"`
+	IssueDate      time.Time             `json:"issue_date"`
+	DueDate        time.Time             `json:"due_date"`
+	Currency       string                `json:"currency"`
+	From           SharedParty           `json:"from"`
+	BillTo         SharedParty           `json:"bill_to"`
+	Items          []SharedItem          `json:"items"`
+	SubTotal       money.Decimal         `json:"sub_total"`
+	Discount       money.Decimal         `json:"discount"`
+	Taxes          []SharedTax           `json:"taxes"`
+	TaxTotal       money.Decimal         `json:"tax_total"`
+	TotalAmount    money.Decimal         `json:"total_amount"`
+	LateFees       []SharedLateFee       `json:"late_fees,omitempty"`
+	LateFeeTotal   money.Decimal         `json:"late_fee_total"`
+	CreditTotal    money.Decimal         `json:"credit_total"`
+	AmountPaid     money.Decimal         `json:"amount_paid"`
+	BalanceDue     money.Decimal         `json:"balance_due"`
+	Note           string                `json:"note,omitempty"`
+	PaymentDetails *SharedPaymentDetails `json:"payment_details,omitempty"`
+}
+
+// QuoteLinkResponse is returned when a quote is sent. Token is shown only
+// here; Path is where the customer accepts or declines the quote.
+type QuoteLinkResponse struct {
+	Quote *models.Quote `json:"quote"`
+	Token string        `json:"token"`
+	Path  string        `json:"path"`
+}
+
+// SharedQuote is the customer facing view of a quote opened through its
+// acceptance link.
+type SharedQuote struct {
+	QuoteNumber   string        `json:"quote_number"`
+	Status        string        `json:"status"`
+	IssueDate     time.Time     `json:"issue_date"`
+	ExpiryDate    time.Time     `json:"expiry_date"`
+	Currency      string        `json:"currency"`
+	From          SharedParty   `json:"from"`
+	BillTo        SharedParty   `json:"bill_to"`
+	Items         []SharedItem  `json:"items"`
+	SubTotal      money.Decimal `json:"sub_total"`
+	Discount      money.Decimal `json:"discount"`
+	Taxes         []SharedTax   `json:"taxes"`
+	TaxTotal      money.Decimal `json:"tax_total"`
+	TotalAmount   money.Decimal `json:"total_amount"`
+	Note          string        `json:"note,omitempty"`
+	SignerName    string        `json:"signer_name,omitempty"`
+	AcceptedAt    *time.Time    `json:"accepted_at,omitempty"`
+	DeclinedAt    *time.Time    `json:"declined_at,omitempty"`
+	DeclineReason string        `json:"decline_reason,omitempty"`
+}
+
+type SharedParty struct {
+	Name      string `json:"name"`
+	Email     string `json:"email,omitempty"`
+	Address   string `json:"address,omitempty"`
+	TaxNumber string `json:"tax_number,omitempty"`
+}
+
+type SharedItem struct {
+	Description string        `json:"description"`
+	Quantity    int           `json:"quantity"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Amount      money.Decimal `json:"amount"`
+}
+
+type SharedLateFee struct {
+	Description string        `json:"description"`
+	Amount      money.Decimal `json:"amount"`
+}
+
+type SharedTax struct {
+	Name   string        `json:"name"`
+	Rate   money.Decimal `json:"rate"`
+	Amount money.Decimal `json:"amount"`
+}
+
+type SharedPaymentDetails struct {
+	AccountName    string    `json:"account_name"`
+	AccountNumber  string    `json:"account_number"`
+	BankName       string    `json:"bank_name,omitempty"`
+	BankAddress    string    `json:"bank_address,omitempty"`
+	RoutingNumber  string    `json:"routing_number,omitempty"`
+	PaymentDueDate time.Time `json:"payment_due_date"`
+}
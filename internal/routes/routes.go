@@ -2,46 +2,163 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/handlers"
 	"github.com/iyiola-dev/numeris/internal/repository"
 	"github.com/iyiola-dev/numeris/internal/service"
 	"github.com/iyiola-dev/numeris/internal/util"
 )
 
-func SetupRouter() *gin.Engine {
+func SetupRouter(repo repository.Repository, svc service.Service, tokens *auth.Tokens) *gin.Engine {
 	router := gin.Default()
-	
-	// Initialize dependencies
-	repo := repository.NewRepository()
-	svc := service.NewService(repo)
-	h := handlers.NewHandler(svc)
+	router.Use(util.RequestIDMiddleware())
+
+	h := handlers.NewHandler(svc, tokens)
 
 	// Public routes
 	router.POST("/api/auth/register", h.Register)
 	router.POST("/api/auth/login", h.Login)
-	router.GET("/api/invoices/shared/:invoice_number", h.GetInvoiceByShareableLink)
+	router.POST("/api/auth/refresh", h.Refresh)
+	router.POST("/api/auth/verify-email", h.RequestEmailVerification)
+	router.GET("/api/auth/verify-email", h.VerifyEmail)
+	router.POST("/api/auth/forgot-password", h.ForgotPassword)
+	router.POST("/api/auth/reset-password", h.ResetPassword)
+	router.GET("/.well-known/jwks.json", h.GetJWKS)
+	router.GET("/api/shared/:token", h.GetSharedInvoice)
+	router.GET("/api/shared/:token/pdf", h.GetSharedInvoicePDF)
+	router.GET("/api/shared-quotes/:token", h.GetSharedQuote)
+	router.POST("/api/shared-quotes/:token/accept", h.AcceptQuote)
+	router.POST("/api/shared-quotes/:token/decline", h.DeclineQuote)
 
 	// Protected routes
 	api := router.Group("/api")
-	api.Use(util.AuthMiddleware(repo))
+	api.Use(util.AuthMiddleware(repo, tokens))
 	{
+		// Session routes
+		api.POST("/auth/logout", h.Logout)
+		api.POST("/auth/logout-all", h.LogoutAll)
+		api.POST("/auth/change-password", h.ChangePassword)
+
 		// Invoice routes
 		invoices := api.Group("/invoices")
 		{
 			invoices.POST("", h.CreateInvoice)
 			invoices.GET("", h.GetInvoices)
+			invoices.GET("/trash", h.GetDeletedInvoices)
 			invoices.GET("/:id", h.GetInvoice)
 			invoices.PUT("/:id", h.UpdateInvoice)
 			invoices.DELETE("/:id", h.DeleteInvoice)
+			invoices.POST("/:id/restore", h.RestoreInvoice)
+			invoices.POST("/:id/void", h.VoidInvoice)
+			invoices.GET("/:id/pdf", h.GetInvoicePDF)
+			invoices.POST("/:id/send", h.SendInvoice)
+			invoices.GET("/:id/deliveries", h.GetInvoiceDeliveries)
+
+			// Revision routes
+			invoices.GET("/:id/revisions", h.GetInvoiceRevisions)
+			invoices.GET("/:id/revisions/diff", h.DiffInvoiceRevisions)
+			invoices.GET("/:id/revisions/:number", h.GetInvoiceRevision)
+			invoices.GET("/:id/revisions/:number/pdf", h.GetInvoiceRevisionPDF)
+
+			// Share link routes
+			invoices.POST("/:id/share-links", h.CreateShareLink)
+			invoices.GET("/:id/share-links", h.GetShareLinks)
+			invoices.DELETE("/:id/share-links/:link_id", h.RevokeShareLink)
 
 			// Payment details routes
-			invoices.POST("/:invoice_id/payment", h.CreatePaymentDetails)
-			invoices.GET("/:invoice_id/payment", h.GetPaymentDetails)
-			invoices.PUT("/:invoice_id/payment", h.UpdatePaymentDetails)
-			invoices.DELETE("/:invoice_id/payment", h.DeletePaymentDetails)
+			invoices.POST("/:id/payment", h.CreatePaymentDetails)
+			invoices.GET("/:id/payment", h.GetPaymentDetails)
+			invoices.PUT("/:id/payment", h.UpdatePaymentDetails)
+			invoices.DELETE("/:id/payment", h.DeletePaymentDetails)
+
+			// Payment routes
+			invoices.POST("/:id/payments", h.RecordPayment)
+			invoices.GET("/:id/payments", h.GetPayments)
+			invoices.POST("/:id/payments/:payment_id/refunds", h.RefundPayment)
+			invoices.DELETE("/:id/payments/:payment_id", h.DeletePayment)
+
+			// Late fee routes
+			invoices.GET("/:id/late-fee-policy", h.GetInvoiceLateFeePolicy)
+			invoices.PUT("/:id/late-fee-policy", h.UpdateInvoiceLateFeePolicy)
+			invoices.DELETE("/:id/late-fee-policy", h.DeleteInvoiceLateFeePolicy)
+			invoices.GET("/:id/late-fees", h.GetLateFees)
+			invoices.POST("/:id/late-fees/:fee_id/reverse", h.ReverseLateFee)
+
+			// Credit note routes
+			invoices.POST("/:id/credit-notes", h.CreateCreditNote)
+			invoices.GET("/:id/credit-notes", h.GetInvoiceCreditNotes)
 		}
+
+		// Credit note routes
+		creditNotes := api.Group("/credit-notes")
+		{
+			creditNotes.GET("", h.GetCreditNotes)
+			creditNotes.GET("/:id", h.GetCreditNote)
+			creditNotes.GET("/:id/pdf", h.GetCreditNotePDF)
+		}
+
+		// Quote routes
+		quotes := api.Group("/quotes")
+		{
+			quotes.POST("", h.CreateQuote)
+			quotes.GET("", h.GetQuotes)
+			quotes.GET("/:id", h.GetQuote)
+			quotes.PUT("/:id", h.UpdateQuote)
+			quotes.DELETE("/:id", h.DeleteQuote)
+			quotes.POST("/:id/send", h.SendQuote)
+			quotes.POST("/:id/convert", h.ConvertQuote)
+		}
+
+		// Customer routes
+		customers := api.Group("/customers")
+		{
+			customers.POST("", h.CreateCustomer)
+			customers.GET("", h.GetCustomers)
+			customers.GET("/:id", h.GetCustomer)
+			customers.PUT("/:id", h.UpdateCustomer)
+			customers.DELETE("/:id", h.DeleteCustomer)
+		}
+
+		// Tax rate routes
+		taxRates := api.Group("/tax-rates")
+		{
+			taxRates.POST("", h.CreateTaxRate)
+			taxRates.GET("", h.GetTaxRates)
+			taxRates.GET("/:id", h.GetTaxRate)
+			taxRates.PUT("/:id", h.UpdateTaxRate)
+			taxRates.DELETE("/:id", h.DeleteTaxRate)
+		}
+
+		// Recurring invoice routes
+		recurring := api.Group("/recurring-invoices")
+		{
+			recurring.POST("", h.CreateRecurringInvoice)
+			recurring.GET("", h.GetRecurringInvoices)
+			recurring.GET("/:id", h.GetRecurringInvoice)
+			recurring.PUT("/:id", h.UpdateRecurringInvoice)
+			recurring.DELETE("/:id", h.DeleteRecurringInvoice)
+		}
+
+		// Settings routes
+		settings := api.Group("/settings")
+		{
+			settings.GET("/invoice-numbering", h.GetInvoiceSequence)
+			settings.PUT("/invoice-numbering", h.UpdateInvoiceSequence)
+			settings.GET("/credit-note-numbering", h.GetCreditNoteSequence)
+			settings.PUT("/credit-note-numbering", h.UpdateCreditNoteSequence)
+			settings.GET("/quote-numbering", h.GetQuoteSequence)
+			settings.PUT("/quote-numbering", h.UpdateQuoteSequence)
+			settings.GET("/invoice-template", h.GetInvoiceTemplateSettings)
+			settings.PUT("/invoice-template", h.UpdateInvoiceTemplateSettings)
+			settings.GET("/reminders", h.GetReminderSettings)
+			settings.PUT("/reminders", h.UpdateReminderSettings)
+			settings.GET("/late-fees", h.GetLateFeePolicy)
+			settings.PUT("/late-fees", h.UpdateLateFeePolicy)
+		}
+
+		// Activity log routes
+		api.GET("/activity", h.GetActivityLogs)
 	}
 
 	return router
 }
-
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupRouter(t *testing.T) {
+	// gin panics on conflicting route patterns, so building the router is the test
+	repo := new(mocks.Repository)
+	router := SetupRouter(repo, service.NewService(repo), nil)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	assert.True(t, registered[http.MethodGet+" /api/invoices/:id"])
+	assert.True(t, registered[http.MethodGet+" /api/invoices/trash"])
+	assert.True(t, registered[http.MethodGet+" /api/invoices/:id/revisions/diff"])
+	assert.True(t, registered[http.MethodGet+" /api/invoices/:id/revisions/:number/pdf"])
+	assert.True(t, registered[http.MethodPost+" /api/invoices/:id/payment"])
+	assert.True(t, registered[http.MethodPost+" /api/invoices/:id/payments"])
+	assert.True(t, registered[http.MethodGet+" /api/shared/:token/pdf"])
+	assert.True(t, registered[http.MethodPut+" /api/recurring-invoices/:id"])
+	assert.True(t, registered[http.MethodGet+" /api/activity"])
+	assert.True(t, registered[http.MethodGet+" /.well-known/jwks.json"])
+	assert.True(t, registered[http.MethodPost+" /api/auth/refresh"])
+	assert.True(t, registered[http.MethodPost+" /api/auth/logout-all"])
+	assert.True(t, registered[http.MethodGet+" /api/auth/verify-email"])
+	assert.True(t, registered[http.MethodPost+" /api/auth/reset-password"])
+	assert.True(t, registered[http.MethodPost+" /api/auth/change-password"])
+	assert.False(t, registered[http.MethodGet+" /api/invoices/shared/:invoice_number"])
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2026, time.January, 30, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/20 * * * *", time.Date(2026, time.January, 30, 10, 20, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 * *", time.Date(2026, time.March, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2026, time.February, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month and day of week both restricted: either may match
+		{"0 0 15 * 1", time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+
+			next, err := cron.Next(from)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+
+	_, err = cron.Next(time.Now())
+	assert.Error(t, err)
+}
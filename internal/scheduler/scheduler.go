@@ -0,0 +1,124 @@
+// Package scheduler runs background jobs inside the API process on a fixed
+// interval, and parses the cron expressions used by recurring schedules.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Job is work the scheduler runs on every tick. now is the tick time, so a
+// job can be tested without waiting for the clock.
+type Job interface {
+	Name() string
+	Run(ctx context.Context, now time.Time) error
+}
+
+// JobFunc adapts a function to the Job interface.
+func JobFunc(name string, run func(ctx context.Context, now time.Time) error) Job {
+	return &funcJob{name: name, run: run}
+}
+
+type funcJob struct {
+	name string
+	run  func(ctx context.Context, now time.Time) error
+}
+
+func (j *funcJob) Name() string { return j.name }
+
+func (j *funcJob) Run(ctx context.Context, now time.Time) error {
+	return j.run(ctx, now)
+}
+
+// Scheduler runs its jobs one after the other on every tick. A tick that
+// comes while the previous one is still running is skipped.
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+	now      func() time.Time
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// New returns a scheduler that runs jobs every interval once started.
+func New(interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jobs:     jobs,
+		now:      time.Now,
+	}
+}
+
+// Start runs the jobs immediately and then on every tick until ctx is done
+// or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.stopped = make(chan struct{})
+
+	go func() {
+		defer close(s.stopped)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.RunOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for a running tick to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, stopped := s.cancel, s.stopped
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-stopped
+	}
+}
+
+// RunOnce runs every job once. A failing or panicking job is logged and does
+// not stop the jobs after it.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	now := s.now()
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := runJob(ctx, job, now); err != nil {
+			log.Printf("scheduler: %s failed: %v", job.Name(), err)
+		}
+	}
+}
+
+// runJob runs job, turning a panic into an error so that one bad job cannot
+// take the process down.
+func runJob(ctx context.Context, job Job, now time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return job.Run(ctx, now)
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunOnceRunsEveryJob(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var ran []string
+
+	s := New(time.Minute,
+		JobFunc("failing", func(ctx context.Context, at time.Time) error {
+			ran = append(ran, "failing")
+			return errors.New("boom")
+		}),
+		JobFunc("next", func(ctx context.Context, at time.Time) error {
+			assert.Equal(t, now, at)
+			ran = append(ran, "next")
+			return nil
+		}),
+	)
+	s.now = func() time.Time { return now }
+
+	s.RunOnce(context.Background())
+
+	assert.Equal(t, []string{"failing", "next"}, ran)
+}
+
+func TestRunOnceRecoversFromPanic(t *testing.T) {
+	var ran []string
+
+	s := New(time.Minute,
+		JobFunc("panicking", func(ctx context.Context, at time.Time) error {
+			ran = append(ran, "panicking")
+			panic("money: value out of range")
+		}),
+		JobFunc("next", func(ctx context.Context, at time.Time) error {
+			ran = append(ran, "next")
+			return nil
+		}),
+	)
+
+	assert.NotPanics(t, func() { s.RunOnce(context.Background()) })
+	assert.Equal(t, []string{"panicking", "next"}, ran)
+}
+
+func TestStartAndStop(t *testing.T) {
+	runs := make(chan struct{}, 10)
+	s := New(time.Millisecond, JobFunc("tick", func(ctx context.Context, at time.Time) error {
+		select {
+		case runs <- struct{}{}:
+		case <-ctx.Done():
+		}
+		return nil
+	}))
+
+	s.Start(context.Background())
+	<-runs
+	<-runs
+	s.Stop()
+
+	// Stop waits for the loop, so nothing runs afterwards
+	drained := len(runs)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, drained, len(runs))
+}
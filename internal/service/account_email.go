@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+)
+
+// accountEmailData is what the verification and password reset emails show.
+type accountEmailData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+var verificationEmailText = template.Must(template.New("verification").Parse(`Hi {{.Name}},
+
+Please confirm your email address by opening this link:
+
+{{.Link}}
+
+The link works once and expires in {{.ExpiresIn}}. If you did not create an account, you can ignore this email.
+`))
+
+var passwordResetEmailText = template.Must(template.New("reset").Parse(`Hi {{.Name}},
+
+We received a request to reset your password. Choose a new one here:
+
+{{.Link}}
+
+The link works once and expires in {{.ExpiresIn}}. If you did not ask to reset your password, you can ignore this email; your password has not been changed.
+`))
+
+// renderAccountEmail fills in an account email for user.
+func renderAccountEmail(tmpl *template.Template, user *models.User, link string, ttl time.Duration) (string, error) {
+	data := accountEmailData{
+		Name:      strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Link:      link,
+		ExpiresIn: formatTTL(ttl),
+	}
+	if data.Name == "" {
+		data.Name = "there"
+	}
+
+	var body strings.Builder
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// formatTTL writes a token lifetime the way people say it, e.g. "1 hour".
+func formatTTL(ttl time.Duration) string {
+	unit, size := "hour", time.Hour
+	if ttl < time.Hour {
+		unit, size = "minute", time.Minute
+	}
+	n := int(ttl / size)
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
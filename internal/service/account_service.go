@@ -0,0 +1,294 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidUserToken is returned for unknown, used and expired verification
+// and password reset tokens alike.
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+var ErrEmailNotVerified = errors.New("email address has not been verified")
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+	minPasswordLength    = 8
+)
+
+// Where the links in account email lead. Verification is done by the API
+// itself; the reset page collects the new password and posts it to
+// /api/auth/reset-password.
+const (
+	verifyEmailPath   = "/api/auth/verify-email"
+	resetPasswordPath = "/reset-password"
+)
+
+// RequestEmailVerification emails a new verification link to the user with
+// the given address. Unknown and already verified addresses are ignored, and
+// the link is issued and sent in the background, so neither the answer nor
+// the time it takes reveals which addresses have accounts.
+func (s *service) RequestEmailVerification(input inputs.EmailInput) error {
+	if s.mailer == nil {
+		return ErrMailerNotConfigured
+	}
+	user, err := s.findUserByEmail(input.Email)
+	if err != nil || user == nil || user.EmailVerifiedAt != nil {
+		return err
+	}
+	s.background(func() { s.sendAccountEmail(user, models.UserTokenEmailVerification) })
+	return nil
+}
+
+// VerifyEmail marks the address a verification token was sent to as
+// verified.
+func (s *service) VerifyEmail(token string) error {
+	stored, err := s.openUserToken(token, models.UserTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := claimUserToken(repo, stored, now); err != nil {
+			return err
+		}
+		return repo.VerifyUserEmail(stored.UserID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logActivity(accountActivity(stored.UserID, models.ActivityEmailVerified, models.ActivityMetadata{}))
+	return nil
+}
+
+// RequestPasswordReset emails a password reset link to the user with the
+// given address. Like RequestEmailVerification, it answers the same, and as
+// quickly, whether or not the address has an account.
+func (s *service) RequestPasswordReset(input inputs.EmailInput) error {
+	if s.mailer == nil {
+		return ErrMailerNotConfigured
+	}
+	user, err := s.findUserByEmail(input.Email)
+	if err != nil || user == nil || !user.Active {
+		return err
+	}
+	s.background(func() { s.sendAccountEmail(user, models.UserTokenPasswordReset) })
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and logs the user out
+// everywhere, since whoever knew the old password may still be signed in.
+func (s *service) ResetPassword(input inputs.ResetPasswordInput) error {
+	if err := validatePassword(input.NewPassword); err != nil {
+		return err
+	}
+	stored, err := s.openUserToken(input.Token, models.UserTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	revoked := 0
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := claimUserToken(repo, stored, now); err != nil {
+			return err
+		}
+		if err := repo.UpdateUserPassword(stored.UserID, string(hash)); err != nil {
+			return err
+		}
+		if err := repo.ExpireUserTokens(stored.UserID, models.UserTokenPasswordReset, now); err != nil {
+			return err
+		}
+		revoked, err = repo.RevokeUserSessions(stored.UserID, now, models.SessionRevokedPassword)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logActivity(accountActivity(stored.UserID, models.ActivityPasswordReset, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"sessions_revoked": revoked},
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		RequestID: input.RequestID,
+	}))
+	return nil
+}
+
+// ChangePassword replaces the principal's password once they have confirmed
+// the current one. Every other session is logged out; the one making the
+// change stays signed in.
+func (s *service) ChangePassword(principal auth.Principal, input inputs.ChangePasswordInput) error {
+	user, err := s.repo.GetUserByID(principal.UserID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.CurrentPassword)) != nil {
+		return errors.New("current password is incorrect")
+	}
+	if err := validatePassword(input.NewPassword); err != nil {
+		return err
+	}
+	if input.NewPassword == input.CurrentPassword {
+		return errors.New("new password must be different from the current one")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	revoked := 0
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := repo.UpdateUserPassword(user.ID, string(hash)); err != nil {
+			return err
+		}
+		if err := repo.ExpireUserTokens(user.ID, models.UserTokenPasswordReset, now); err != nil {
+			return err
+		}
+		revoked, err = repo.RevokeOtherSessions(user.ID, principal.SessionID, now, models.SessionRevokedPassword)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logActivity(accountActivity(user.ID, models.ActivityPasswordChanged, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"sessions_revoked": revoked},
+		IPAddress: principal.IPAddress,
+		UserAgent: principal.UserAgent,
+		RequestID: principal.RequestID,
+	}))
+	return nil
+}
+
+// findUserByEmail returns the user with address email, or nil if there is
+// none.
+func (s *service) findUserByEmail(email string) (*models.User, error) {
+	users, err := s.repo.GetUsers(map[string]interface{}{
+		"email": email,
+	})
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+// sendAccountEmail issues a token for purpose and emails its link to user.
+// Earlier tokens for the same purpose stop working. Failures are logged
+// rather than returned, since callers must not reveal whether an address has
+// an account.
+func (s *service) sendAccountEmail(user *models.User, purpose string) {
+	if s.mailer == nil {
+		return
+	}
+
+	tmpl, path, subject, ttl := verificationEmailText, verifyEmailPath, "Confirm your email address", emailVerificationTTL
+	if purpose == models.UserTokenPasswordReset {
+		tmpl, path, subject, ttl = passwordResetEmailText, resetPasswordPath, "Reset your password", passwordResetTTL
+	}
+
+	token, err := s.issueUserToken(user.ID, purpose, ttl)
+	if err != nil {
+		log.Printf("Failed to issue %s token for user %s: %v", purpose, user.ID, err)
+		return
+	}
+	body, err := renderAccountEmail(tmpl, user, s.publicURL+path+"?token="+url.QueryEscape(token), ttl)
+	if err == nil {
+		err = s.mailer.Send(mailer.Message{To: user.Email, Subject: subject, Body: body})
+	}
+	if err != nil {
+		log.Printf("Failed to send %s email to user %s: %v", purpose, user.ID, err)
+	}
+}
+
+// issueUserToken stores a new token for purpose, replacing any the user has
+// not used yet, and returns it. Only its hash is kept.
+func (s *service) issueUserToken(userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := repo.ExpireUserTokens(userID, purpose, now); err != nil {
+			return err
+		}
+		return repo.CreateUserToken(&models.UserToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hashShareToken(token),
+			ExpiresAt: now.Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// openUserToken returns the stored token behind token if it can still be
+// used for purpose.
+func (s *service) openUserToken(token, purpose string) (*models.UserToken, error) {
+	stored, err := s.repo.GetUserTokenByHash(hashShareToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidUserToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !stored.Usable(purpose, time.Now()) {
+		return nil, ErrInvalidUserToken
+	}
+	return stored, nil
+}
+
+// claimUserToken uses up a token, failing if another request got there first.
+func claimUserToken(repo repository.Repository, token *models.UserToken, at time.Time) error {
+	claimed, err := repo.ClaimUserToken(token.ID, at)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidUserToken
+	}
+	return nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+func accountActivity(userID uuid.UUID, action models.ActivityAction, metadata models.ActivityMetadata) *models.ActivityLog {
+	return &models.ActivityLog{
+		UserID:     userID,
+		EntityType: models.EntityUser,
+		EntityID:   &userID,
+		Action:     action,
+		Metadata:   metadata,
+	}
+}
@@ -0,0 +1,364 @@
+package service_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// emailedToken returns the token in the link of an account email.
+func emailedToken(t *testing.T, body, path string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "https://invoices.example.com"+path+"?token=") {
+			link, err := url.Parse(line)
+			require.NoError(t, err)
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no %s link in %q", path, body)
+	return ""
+}
+
+func expectUserToken(mockRepo *mocks.Repository, purpose string, stored **models.UserToken) {
+	mockRepo.On("ExpireUserTokens", mock.Anything, purpose, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateUserToken", mock.AnythingOfType("*models.UserToken")).Run(func(args mock.Arguments) {
+		*stored = args.Get(0).(*models.UserToken)
+	}).Return(nil)
+}
+
+func userToken(userID uuid.UUID, purpose, token string) *models.UserToken {
+	return &models.UserToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestRegister_SendsVerificationEmail(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender), service.WithPublicURL("https://invoices.example.com"))
+
+	input := inputs.RegisterInput{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "password123"}
+
+	var stored *models.UserToken
+	mockRepo.On("GetUsers", map[string]interface{}{"email": input.Email}).Return([]models.User{}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(nil)
+	expectTx(mockRepo)
+	expectUserToken(mockRepo, models.UserTokenEmailVerification, &stored)
+
+	user, err := svc.Register(input)
+
+	require.NoError(t, err)
+	assert.Nil(t, user.EmailVerifiedAt)
+	require.Len(t, sender.Sent(), 1)
+	msg := sender.Sent()[0]
+	assert.Equal(t, "ada@example.com", msg.To)
+	assert.Equal(t, "Confirm your email address", msg.Subject)
+	assert.Contains(t, msg.Body, "Hi Ada Lovelace")
+	assert.Contains(t, msg.Body, "expires in 48 hours")
+
+	token := emailedToken(t, msg.Body, "/api/auth/verify-email")
+	assert.Equal(t, hashToken(token), stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, user.ID, stored.UserID)
+	assert.Equal(t, models.UserTokenEmailVerification, stored.Purpose)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), stored.ExpiresAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	stored := userToken(userID, models.UserTokenEmailVerification, "verify-token")
+
+	var entry *models.ActivityLog
+	mockRepo.On("GetUserTokenByHash", hashToken("verify-token")).Return(stored, nil)
+	expectTx(mockRepo)
+	mockRepo.On("ClaimUserToken", stored.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("VerifyUserEmail", userID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*models.ActivityLog)
+	}).Return(nil)
+
+	err := svc.VerifyEmail("verify-token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ActivityEmailVerified, entry.Action)
+	assert.Equal(t, userID, entry.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmail_Rejected(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		setup func(token *models.UserToken)
+	}{
+		{"used", func(token *models.UserToken) { token.UsedAt = &usedAt }},
+		{"expired", func(token *models.UserToken) { token.ExpiresAt = time.Now().Add(-time.Minute) }},
+		{"password reset token", func(token *models.UserToken) { token.Purpose = models.UserTokenPasswordReset }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			stored := userToken(uuid.New(), models.UserTokenEmailVerification, "verify-token")
+			tt.setup(stored)
+			mockRepo.On("GetUserTokenByHash", hashToken("verify-token")).Return(stored, nil)
+
+			err := svc.VerifyEmail("verify-token")
+
+			assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+			mockRepo.AssertExpectations(t)
+			mockRepo.AssertNotCalled(t, "VerifyUserEmail", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		svc := service.NewService(mockRepo)
+		mockRepo.On("GetUserTokenByHash", hashToken("unknown")).Return(nil, gorm.ErrRecordNotFound)
+
+		assert.ErrorIs(t, svc.VerifyEmail("unknown"), service.ErrInvalidUserToken)
+	})
+
+	// Two requests with the same token: the one that loses the claim fails
+	t.Run("claimed concurrently", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		svc := service.NewService(mockRepo)
+
+		stored := userToken(uuid.New(), models.UserTokenEmailVerification, "verify-token")
+		mockRepo.On("GetUserTokenByHash", hashToken("verify-token")).Return(stored, nil)
+		expectTx(mockRepo)
+		mockRepo.On("ClaimUserToken", stored.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+		assert.ErrorIs(t, svc.VerifyEmail("verify-token"), service.ErrInvalidUserToken)
+		mockRepo.AssertNotCalled(t, "VerifyUserEmail", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	var background []func()
+	svc := service.NewService(mockRepo,
+		service.WithMailer(sender),
+		service.WithPublicURL("https://invoices.example.com"),
+		service.WithBackground(func(fn func()) { background = append(background, fn) }))
+
+	user := models.User{ID: uuid.New(), FirstName: "Ada", Email: "ada@example.com", Active: true}
+
+	var stored *models.UserToken
+	mockRepo.On("GetUsers", map[string]interface{}{"email": user.Email}).Return([]models.User{user}, nil)
+	expectTx(mockRepo)
+	expectUserToken(mockRepo, models.UserTokenPasswordReset, &stored)
+
+	err := svc.RequestPasswordReset(inputs.EmailInput{Email: user.Email})
+
+	// The answer does not wait for the token or the email, so it takes as
+	// long as for an unknown address
+	require.NoError(t, err)
+	assert.Empty(t, sender.Sent())
+	mockRepo.AssertNotCalled(t, "CreateUserToken", mock.Anything)
+	require.Len(t, background, 1)
+	background[0]()
+
+	require.Len(t, sender.Sent(), 1)
+	msg := sender.Sent()[0]
+	assert.Equal(t, "Reset your password", msg.Subject)
+	assert.Contains(t, msg.Body, "expires in 1 hour.")
+	token := emailedToken(t, msg.Body, "/reset-password")
+	assert.Equal(t, hashToken(token), stored.TokenHash)
+	assert.Equal(t, models.UserTokenPasswordReset, stored.Purpose)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+}
+
+// Unknown and inactive addresses get the same answer as real ones, but no
+// email.
+func TestRequestPasswordReset_NoAccount(t *testing.T) {
+	tests := map[string][]models.User{
+		"unknown":  {},
+		"inactive": {{ID: uuid.New(), Email: "ada@example.com", Active: false}},
+	}
+
+	for name, users := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			sender := mailer.NewMemory()
+			var background []func()
+			svc := service.NewService(mockRepo,
+				service.WithMailer(sender),
+				service.WithBackground(func(fn func()) { background = append(background, fn) }))
+
+			mockRepo.On("GetUsers", map[string]interface{}{"email": "ada@example.com"}).Return(users, nil)
+
+			err := svc.RequestPasswordReset(inputs.EmailInput{Email: "ada@example.com"})
+
+			assert.NoError(t, err)
+			assert.Empty(t, background)
+			assert.Empty(t, sender.Sent())
+			mockRepo.AssertNotCalled(t, "CreateUserToken", mock.Anything)
+		})
+	}
+}
+
+func TestRequestPasswordReset_NoMailer(t *testing.T) {
+	svc := service.NewService(new(mocks.Repository))
+
+	err := svc.RequestPasswordReset(inputs.EmailInput{Email: "ada@example.com"})
+
+	assert.ErrorIs(t, err, service.ErrMailerNotConfigured)
+}
+
+func TestRequestEmailVerification_AlreadyVerified(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+	verifiedAt := time.Now()
+	user := models.User{ID: uuid.New(), Email: "ada@example.com", Active: true, EmailVerifiedAt: &verifiedAt}
+	mockRepo.On("GetUsers", map[string]interface{}{"email": user.Email}).Return([]models.User{user}, nil)
+
+	err := svc.RequestEmailVerification(inputs.EmailInput{Email: user.Email})
+
+	assert.NoError(t, err)
+	assert.Empty(t, sender.Sent())
+}
+
+func TestResetPassword(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	stored := userToken(userID, models.UserTokenPasswordReset, "reset-token")
+
+	var hash string
+	var entry *models.ActivityLog
+	mockRepo.On("GetUserTokenByHash", hashToken("reset-token")).Return(stored, nil)
+	expectTx(mockRepo)
+	mockRepo.On("ClaimUserToken", stored.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("UpdateUserPassword", userID, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		hash = args.String(1)
+	}).Return(nil)
+	mockRepo.On("ExpireUserTokens", userID, models.UserTokenPasswordReset, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("RevokeUserSessions", userID, mock.AnythingOfType("time.Time"), models.SessionRevokedPassword).Return(2, nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*models.ActivityLog)
+	}).Return(nil)
+
+	err := svc.ResetPassword(inputs.ResetPasswordInput{Token: "reset-token", NewPassword: "new-password", RequestID: "request-1"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+	assert.Equal(t, models.ActivityPasswordReset, entry.Action)
+	assert.Equal(t, 2, entry.Metadata.NewValues["sessions_revoked"])
+	assert.Equal(t, "request-1", entry.Metadata.RequestID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_Rejected(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	err := svc.ResetPassword(inputs.ResetPasswordInput{Token: "reset-token", NewPassword: "short"})
+	assert.EqualError(t, err, "password must be at least 8 characters")
+
+	// A verification token cannot reset a password
+	stored := userToken(uuid.New(), models.UserTokenEmailVerification, "verify-token")
+	mockRepo.On("GetUserTokenByHash", hashToken("verify-token")).Return(stored, nil)
+
+	err = svc.ResetPassword(inputs.ResetPasswordInput{Token: "verify-token", NewPassword: "new-password"})
+	assert.ErrorIs(t, err, service.ErrInvalidUserToken)
+	mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.DefaultCost)
+	user := &models.User{ID: uuid.New(), Password: string(hashed), Active: true}
+	principal := auth.Principal{UserID: user.ID, SessionID: uuid.New()}
+
+	var hash string
+	mockRepo.On("GetUserByID", user.ID).Return(user, nil)
+	expectTx(mockRepo)
+	mockRepo.On("UpdateUserPassword", user.ID, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		hash = args.String(1)
+	}).Return(nil)
+	mockRepo.On("ExpireUserTokens", user.ID, models.UserTokenPasswordReset, mock.AnythingOfType("time.Time")).Return(nil)
+	// The session making the change stays signed in
+	mockRepo.On("RevokeOtherSessions", user.ID, principal.SessionID, mock.AnythingOfType("time.Time"), models.SessionRevokedPassword).Return(1, nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	err := svc.ChangePassword(principal, inputs.ChangePasswordInput{CurrentPassword: "old-password", NewPassword: "new-password"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChangePassword_Rejected(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.DefaultCost)
+	user := &models.User{ID: uuid.New(), Password: string(hashed), Active: true}
+
+	tests := []struct {
+		name    string
+		input   inputs.ChangePasswordInput
+		wantErr string
+	}{
+		{"wrong current password", inputs.ChangePasswordInput{CurrentPassword: "guess", NewPassword: "new-password"}, "current password is incorrect"},
+		{"too short", inputs.ChangePasswordInput{CurrentPassword: "old-password", NewPassword: "short"}, "password must be at least 8 characters"},
+		{"unchanged", inputs.ChangePasswordInput{CurrentPassword: "old-password", NewPassword: "old-password"}, "new password must be different from the current one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+			mockRepo.On("GetUserByID", user.ID).Return(user, nil)
+
+			err := svc.ChangePassword(auth.Principal{UserID: user.ID}, tt.input)
+
+			assert.EqualError(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "WithTx", mock.Anything)
+		})
+	}
+}
+
+func TestLogin_UnverifiedEmail(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)), service.WithEmailVerificationRequired())
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := models.User{ID: uuid.New(), Email: "ada@example.com", Password: string(hashed), Active: true}
+	mockRepo.On("GetUsers", map[string]interface{}{"email": user.Email}).Return([]models.User{user}, nil)
+
+	resp, err := svc.Login(inputs.LoginInput{Email: user.Email, Password: "password123"})
+
+	assert.ErrorIs(t, err, service.ErrEmailNotVerified)
+	assert.Nil(t, resp)
+	mockRepo.AssertNotCalled(t, "CreateSession", mock.Anything)
+}
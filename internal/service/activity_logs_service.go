@@ -1,21 +1,179 @@
 package service
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/models"
-	"sort"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+)
+
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
 )
 
-func (s *service) GetActivityLogs(filters map[string]interface{}) ([]models.ActivityLog, error) {
-	// Get logs with preloaded relationships
-	logs, err := s.repo.GetActivityLogs(filters)
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// GetActivityLogs returns a page of the user's activity log, newest first
+// unless input.Sort is "asc". Pass the page's NextCursor back as input.Cursor
+// to read the next one.
+func (s *service) GetActivityLogs(principal auth.Principal, input inputs.ActivityLogsInput) (*response.ActivityLogPage, error) {
+	filter := repository.ActivityLogFilter{
+		UserID:     principal.UserID,
+		EntityType: input.EntityType,
+		EntityID:   input.EntityID,
+		InvoiceID:  input.InvoiceID,
+		From:       input.From,
+		To:         input.To,
+		Limit:      input.Limit,
+	}
+
+	if input.Action != "" {
+		filter.Action = models.ActivityAction(strings.ToUpper(input.Action))
+		if !isActivityAction(filter.Action) {
+			return nil, errors.New("unknown action")
+		}
+	}
+
+	switch strings.ToLower(input.Sort) {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return nil, errors.New("sort must be asc or desc")
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultActivityPageSize
+	}
+	if filter.Limit > maxActivityPageSize {
+		filter.Limit = maxActivityPageSize
+	}
+
+	if input.Cursor != "" {
+		cursor, err := decodeActivityCursor(input.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	// Reading one more than a page tells whether there is another page
+	filter.Limit++
+	logs, err := s.repo.GetActivityLogs(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Sort logs by timestamp in descending order (newest first)
-	sort.Slice(logs, func(i, j int) bool {
-		return logs[i].Timestamp.After(logs[j].Timestamp)
-	})
+	page := &response.ActivityLogPage{Logs: logs}
+	if len(logs) == filter.Limit {
+		page.Logs = logs[:len(logs)-1]
+		last := page.Logs[len(page.Logs)-1]
+		page.NextCursor = encodeActivityCursor(repository.ActivityLogCursor{
+			Timestamp: last.Timestamp,
+			ID:        last.ID,
+		})
+	}
+	if page.Logs == nil {
+		page.Logs = []models.ActivityLog{}
+	}
+	return page, nil
+}
+
+func isActivityAction(action models.ActivityAction) bool {
+	for _, known := range models.ActivityActions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Cursors are opaque to clients; they hold the position of the last entry on
+// a page.
+func encodeActivityCursor(cursor repository.ActivityLogCursor) string {
+	raw := cursor.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeActivityCursor(value string) (*repository.ActivityLogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	timestamp, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor repository.ActivityLogCursor
+	if cursor.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// logActivity records an entry in the activity log. Logging is best effort and
+// never fails the operation being logged.
+func (s *service) logActivity(entry *models.ActivityLog) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	_ = s.repo.CreateActivityLog(entry)
+}
+
+// logInvoiceActivity records an action taken on an invoice. actor is who took
+// it, nil for the system or the customer.
+func (s *service) logInvoiceActivity(actor *auth.Principal, invoice *models.Invoice, action models.ActivityAction) {
+	s.logActivity(invoiceActivity(actor, invoice, action))
+}
+
+// logStatusChange records an invoice moving on from status from.
+func (s *service) logStatusChange(actor *auth.Principal, invoice *models.Invoice, action models.ActivityAction, from string) {
+	s.logActivity(statusActivity(actor, invoice, action, from))
+}
+
+// logInvoiceEntityActivity records an action taken on something that belongs
+// to an invoice, such as a payment or a late fee.
+func (s *service) logInvoiceEntityActivity(actor *auth.Principal, invoice *models.Invoice, action models.ActivityAction, entityType string, entityID uuid.UUID, metadata models.ActivityMetadata) {
+	entry := invoiceActivity(actor, invoice, action)
+	entry.EntityType = entityType
+	entry.EntityID = &entityID
+	entry.Metadata.OldValues = metadata.OldValues
+	entry.Metadata.NewValues = metadata.NewValues
+	s.logActivity(entry)
+}
+
+func invoiceActivity(actor *auth.Principal, invoice *models.Invoice, action models.ActivityAction) *models.ActivityLog {
+	entry := &models.ActivityLog{
+		UserID:     invoice.UserID,
+		InvoiceID:  &invoice.ID,
+		EntityType: models.EntityInvoice,
+		EntityID:   &invoice.ID,
+		Action:     action,
+		Timestamp:  time.Now(),
+	}
+	if actor != nil {
+		entry.Metadata.IPAddress = actor.IPAddress
+		entry.Metadata.UserAgent = actor.UserAgent
+		entry.Metadata.RequestID = actor.RequestID
+	}
+	return entry
+}
 
-	return logs, nil
+func statusActivity(actor *auth.Principal, invoice *models.Invoice, action models.ActivityAction, from string) *models.ActivityLog {
+	entry := invoiceActivity(actor, invoice, action)
+	entry.Metadata.OldValues = map[string]interface{}{"status": from}
+	entry.Metadata.NewValues = map[string]interface{}{"status": invoice.Status}
+	return entry
 }
@@ -5,10 +5,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/mocks"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
 	"github.com/iyiola-dev/numeris/internal/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetActivityLogs(t *testing.T) {
@@ -26,38 +31,30 @@ func TestGetActivityLogs(t *testing.T) {
 			ID:        uuid.New(),
 			UserID:    userID,
 			InvoiceID: &invoiceID,
-			Action:    "INVOICE_CREATED",
-			Timestamp: now.Add(-time.Hour), // 1 hour ago
+			Action:    models.ActivityInvoiceUpdated,
+			Timestamp: now,
 		},
 		{
 			ID:        uuid.New(),
 			UserID:    userID,
 			InvoiceID: &invoiceID,
-			Action:    "INVOICE_UPDATED",
-			Timestamp: now, // current time
+			Action:    models.ActivityInvoiceCreated,
+			Timestamp: now.Add(-time.Hour),
 		},
 	}
 
-	filters := map[string]interface{}{
-		"user_id": userID,
-	}
-
-	// Set up expectations
-	mockRepo.On("GetActivityLogs", filters).Return(testLogs, nil)
+	// Newest first, one more than the default page size
+	mockRepo.On("GetActivityLogs", repository.ActivityLogFilter{
+		UserID: userID,
+		Limit:  51,
+	}).Return(testLogs, nil)
 
-	// Execute the service method
-	logs, err := svc.GetActivityLogs(filters)
+	page, err := svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{})
 
-	// Assertions
 	assert.NoError(t, err)
-	assert.NotNil(t, logs)
-	assert.Len(t, logs, 2)
-
-	// Verify logs are sorted by timestamp (newest first)
-	assert.Equal(t, "INVOICE_UPDATED", logs[0].Action)
-	assert.Equal(t, "INVOICE_CREATED", logs[1].Action)
-
-	// Verify mock expectations were met
+	require.NotNil(t, page)
+	assert.Len(t, page.Logs, 2)
+	assert.Empty(t, page.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -65,55 +62,101 @@ func TestGetActivityLogs_WithError(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	svc := service.NewService(mockRepo)
 
-	filters := map[string]interface{}{
-		"user_id": uuid.New(),
-	}
-
-	// Set up expectations for error case
-	mockRepo.On("GetActivityLogs", filters).Return(nil, assert.AnError)
+	mockRepo.On("GetActivityLogs", mock.AnythingOfType("repository.ActivityLogFilter")).Return(nil, assert.AnError)
 
-	// Execute the service method
-	logs, err := svc.GetActivityLogs(filters)
+	page, err := svc.GetActivityLogs(auth.Principal{UserID: uuid.New()}, inputs.ActivityLogsInput{})
 
-	// Assertions
 	assert.Error(t, err)
-	assert.Nil(t, logs)
+	assert.Nil(t, page)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetActivityLogs_WithInvoiceFilter(t *testing.T) {
+func TestGetActivityLogs_WithFilters(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	svc := service.NewService(mockRepo)
 
 	userID := uuid.New()
 	invoiceID := uuid.New()
-	now := time.Now()
+	paymentID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetActivityLogs", repository.ActivityLogFilter{
+		UserID:     userID,
+		Action:     models.ActivityPaymentRecorded,
+		EntityType: models.EntityPayment,
+		EntityID:   &paymentID,
+		InvoiceID:  &invoiceID,
+		From:       &from,
+		To:         &to,
+		Ascending:  true,
+		Limit:      11,
+	}).Return(nil, nil)
+
+	page, err := svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{
+		Action:     "payment_recorded",
+		EntityType: models.EntityPayment,
+		EntityID:   &paymentID,
+		InvoiceID:  &invoiceID,
+		From:       &from,
+		To:         &to,
+		Sort:       "asc",
+		Limit:      10,
+	})
 
-	testLogs := []models.ActivityLog{
-		{
-			ID:        uuid.New(),
-			UserID:    userID,
-			InvoiceID: &invoiceID,
-			Action:    "INVOICE_CREATED",
-			Timestamp: now,
-		},
-	}
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Logs)
+	assert.Empty(t, page.Logs)
+	mockRepo.AssertExpectations(t)
+}
 
-	filters := map[string]interface{}{
-		"user_id":    userID,
-		"invoice_id": invoiceID,
+func TestGetActivityLogs_Paginates(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+	logs := []models.ActivityLog{
+		{ID: uuid.New(), UserID: userID, Action: models.ActivityLogin, Timestamp: now},
+		{ID: uuid.New(), UserID: userID, Action: models.ActivityLogin, Timestamp: now.Add(-time.Minute)},
+		{ID: uuid.New(), UserID: userID, Action: models.ActivityLogin, Timestamp: now.Add(-2 * time.Minute)},
 	}
 
-	// Set up expectations
-	mockRepo.On("GetActivityLogs", filters).Return(testLogs, nil)
+	mockRepo.On("GetActivityLogs", mock.MatchedBy(func(f repository.ActivityLogFilter) bool {
+		return f.After == nil
+	})).Return(logs, nil).Once()
 
-	// Execute the service method
-	logs, err := svc.GetActivityLogs(filters)
+	first, err := svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, first.Logs, 2)
+	require.NotEmpty(t, first.NextCursor)
 
-	// Assertions
-	assert.NoError(t, err)
-	assert.NotNil(t, logs)
-	assert.Len(t, logs, 1)
-	assert.Equal(t, invoiceID, *logs[0].InvoiceID)
+	// The next page starts after the last entry shown
+	mockRepo.On("GetActivityLogs", mock.MatchedBy(func(f repository.ActivityLogFilter) bool {
+		return f.After != nil && f.After.ID == logs[1].ID && f.After.Timestamp.Equal(logs[1].Timestamp)
+	})).Return(logs[2:], nil).Once()
+
+	second, err := svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, second.Logs, 1)
+	assert.Empty(t, second.NextCursor)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetActivityLogs_InvalidInput(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+
+	_, err := svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{Action: "NOT_AN_ACTION"})
+	assert.Error(t, err)
+
+	_, err = svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{Sort: "sideways"})
+	assert.Error(t, err)
+
+	_, err = svc.GetActivityLogs(auth.Principal{UserID: userID}, inputs.ActivityLogsInput{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+
+	mockRepo.AssertNotCalled(t, "GetActivityLogs", mock.Anything)
+}
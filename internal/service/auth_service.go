@@ -9,7 +9,6 @@ import (
 	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/models"
 	"github.com/iyiola-dev/numeris/internal/response"
-	"github.com/iyiola-dev/numeris/internal/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -49,6 +48,9 @@ func (s *service) Register(input inputs.RegisterInput) (*models.User, error) {
 		return nil, err
 	}
 
+	// Ask the user to confirm their email address
+	s.sendAccountEmail(user, models.UserTokenEmailVerification)
+
 	return user, nil
 }
 
@@ -61,7 +63,7 @@ func (s *service) Login(input inputs.LoginInput) (*response.LoginResponse, error
 		return nil, err
 	}
 	if len(users) == 0 {
-		return nil, errors.New("invalid email or password")
+		return nil, errors.New("invalid credentials")
 	}
 
 	user := &users[0]
@@ -69,24 +71,34 @@ func (s *service) Login(input inputs.LoginInput) (*response.LoginResponse, error
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, errors.New("invalid credentials")
 	}
 
 	// Check if user is active
 	if !user.Active {
 		return nil, errors.New("account is inactive")
 	}
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
 
-	// Generate JWT token
-	token, err := util.GenerateToken(user.ID)
+	// Open a session and issue its tokens
+	tokens, err := s.startSession(user, input.IPAddress, input.UserAgent)
 	if err != nil {
-		return nil, errors.New("error generating authentication token")
+		return nil, err
 	}
 
 	// Create activity log
 	activityLog := &models.ActivityLog{
-		UserID:    user.ID,
-		Action:    "LOGIN",
+		UserID:     user.ID,
+		EntityType: models.EntityUser,
+		EntityID:   &user.ID,
+		Action:     models.ActivityLogin,
+		Metadata: models.ActivityMetadata{
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+			RequestID: input.RequestID,
+		},
 		Timestamp: time.Now(),
 	}
 	
@@ -97,7 +109,7 @@ func (s *service) Login(input inputs.LoginInput) (*response.LoginResponse, error
 	}
 
 	return &response.LoginResponse{
-		User:  user,
-		Token: token,
+		User:      user,
+		TokenPair: *tokens,
 	}, nil
 }
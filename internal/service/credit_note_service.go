@@ -0,0 +1,322 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/invoicepdf"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+)
+
+var ErrCreditNoteNotFound = errors.New("credit note not found")
+
+const maxCreditNoteReasonSize = 1000
+
+// CreateCreditNote credits some or all of an issued invoice's lines. Each line
+// may be credited across several notes until its whole quantity is used up,
+// and an input without items credits everything not yet credited. The note's
+// total comes off the invoice's balance due, so it cannot be more than what
+// is still owed; a paid invoice is refunded before it is credited. The note
+// is checked, numbered and applied with the invoice row locked.
+func (s *service) CreateCreditNote(principal auth.Principal, input inputs.CreateCreditNoteInput) (*models.CreditNote, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if len(reason) > maxCreditNoteReasonSize {
+		return nil, fmt.Errorf("reason must be at most %d characters", maxCreditNoteReasonSize)
+	}
+
+	var (
+		invoice      *models.Invoice
+		note         *models.CreditNote
+		from         string
+		statusAction models.ActivityAction
+	)
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, input.InvoiceID)
+		if err != nil {
+			return err
+		}
+		previous, err := repo.GetCreditNotes(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+		note, err = buildCreditNote(invoice, previous, input, reason)
+		if err != nil {
+			return err
+		}
+
+		payments, err := repo.GetPayments(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+		invoice.CreditTotal = creditTotal(previous)
+		if owed := amountOwed(invoice).Sub(amountPaid(payments)); note.TotalAmount.Neg().GreaterThan(owed) {
+			return fmt.Errorf("credit of %s is more than the %s still owed on the invoice, refund a payment first",
+				note.TotalAmount.Neg().Format(invoice.Currency), owed.Format(invoice.Currency))
+		}
+
+		if err := repo.CreateCreditNote(note); err != nil {
+			return err
+		}
+		invoice.CreditTotal = creditTotal(append(previous, *note))
+		from = invoice.Status
+		statusAction, err = settleInvoice(repo, invoice, payments, &principal, models.ActivityCreditNoteApplied)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityCreditNoteApplied, models.EntityCreditNote, note.ID, models.ActivityMetadata{
+		NewValues: map[string]interface{}{
+			"credit_note_number": note.CreditNoteNumber,
+			"amount":             note.TotalAmount.String(),
+		},
+	})
+	if statusAction != "" {
+		s.logStatusChange(&principal, invoice, statusAction, from)
+	}
+
+	return note, nil
+}
+
+// buildCreditNote works out the note crediting input's lines of invoice,
+// given the notes already issued against it.
+func buildCreditNote(invoice *models.Invoice, previous []models.CreditNote, input inputs.CreateCreditNoteInput, reason string) (*models.CreditNote, error) {
+	switch normalizeStatus(invoice.Status) {
+	case models.InvoiceStatusDraft:
+		return nil, errors.New("draft invoices can be edited instead of credited")
+	case models.InvoiceStatusVoid, models.InvoiceStatusWrittenOff:
+		return nil, fmt.Errorf("cannot credit an invoice that is %s", invoice.Status)
+	}
+
+	credited := make(map[uuid.UUID]int)
+	for _, note := range previous {
+		for _, item := range note.Items {
+			credited[item.InvoiceItemID] += item.Quantity
+		}
+	}
+
+	requested := input.Items
+	if len(requested) == 0 {
+		for _, item := range invoice.Items {
+			if remaining := item.Quantity - credited[item.ID]; remaining > 0 {
+				requested = append(requested, inputs.CreditNoteItemInput{InvoiceItemID: item.ID, Quantity: remaining})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, errors.New("invoice has already been credited in full")
+		}
+	}
+
+	invoiceItems := make(map[uuid.UUID]models.InvoiceItem, len(invoice.Items))
+	for _, item := range invoice.Items {
+		invoiceItems[item.ID] = item
+	}
+
+	items := make([]models.InvoiceItem, len(requested))
+	lines := make([]invoiceLine, len(requested))
+	seen := make(map[uuid.UUID]bool, len(requested))
+	for i, r := range requested {
+		item, ok := invoiceItems[r.InvoiceItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %d: not a line on this invoice", i+1)
+		}
+		if seen[item.ID] {
+			return nil, fmt.Errorf("item %d: line is listed more than once", i+1)
+		}
+		seen[item.ID] = true
+		remaining := item.Quantity - credited[item.ID]
+		if remaining <= 0 {
+			return nil, fmt.Errorf("item %d: line has already been credited in full", i+1)
+		}
+		if r.Quantity < 1 || r.Quantity > remaining {
+			return nil, fmt.Errorf("item %d: quantity must be between 1 and %d", i+1, remaining)
+		}
+		credited[item.ID] += r.Quantity
+
+		items[i] = item
+		lines[i] = invoiceLine{Quantity: r.Quantity, UnitPrice: item.UnitPrice, Taxes: item.Taxes}
+	}
+
+	totals, err := creditTotals(invoice, lines)
+	if err != nil {
+		return nil, err
+	}
+	if creditsEverything(invoice, credited) {
+		settleRemainder(invoice, previous, totals)
+	} else if creditTotal(previous).Add(totals.TotalAmount).GreaterThan(invoice.TotalAmount) {
+		return nil, errors.New("credit notes cannot add up to more than the invoice total")
+	}
+
+	issueDate := input.IssueDate
+	if issueDate.IsZero() {
+		issueDate = time.Now()
+	}
+
+	note := &models.CreditNote{
+		ID:          uuid.New(),
+		UserID:      invoice.UserID,
+		InvoiceID:   invoice.ID,
+		IssueDate:   issueDate,
+		Currency:    invoice.Currency,
+		Reason:      reason,
+		SubTotal:    totals.SubTotal.Neg(),
+		Discount:    totals.Discount.Neg(),
+		TaxTotal:    totals.TaxTotal.Neg(),
+		TotalAmount: totals.TotalAmount.Neg(),
+	}
+	for _, tax := range totals.Taxes {
+		note.Taxes = append(note.Taxes, models.CreditNoteTax{
+			TaxRateID: tax.TaxRateID,
+			Name:      tax.Name,
+			Rate:      tax.Rate,
+			Amount:    tax.Amount.Neg(),
+		})
+	}
+	for i, item := range items {
+		note.Items = append(note.Items, models.CreditNoteItem{
+			InvoiceItemID: item.ID,
+			Description:   item.Description,
+			Quantity:      lines[i].Quantity,
+			UnitPrice:     item.UnitPrice.Neg(),
+			Amount:        totals.Amounts[i].Neg(),
+			TaxAmount:     totals.TaxAmounts[i].Neg(),
+			Position:      i,
+		})
+	}
+	return note, nil
+}
+
+func (s *service) GetCreditNotes(principal auth.Principal) ([]models.CreditNote, error) {
+	return s.repo.GetCreditNotes(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+func (s *service) GetInvoiceCreditNotes(principal auth.Principal, invoiceID uuid.UUID) ([]models.CreditNote, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCreditNotes(map[string]interface{}{
+		"invoice_id": invoice.ID,
+	})
+}
+
+func (s *service) GetCreditNote(principal auth.Principal, id uuid.UUID) (*models.CreditNote, error) {
+	return s.getOwnedCreditNote(principal.UserID, id)
+}
+
+// GetCreditNotePDF renders a credit note with the user's invoice template.
+func (s *service) GetCreditNotePDF(principal auth.Principal, id uuid.UUID) (*response.InvoicePDF, error) {
+	note, err := s.getOwnedCreditNote(principal.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := s.repo.GetInvoiceByID(note.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.renderPDF(invoice.UserID, invoicepdf.Data{
+		Invoice:    invoice,
+		CreditNote: note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.InvoicePDF{
+		FileName: note.CreditNoteNumber + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+// getOwnedCreditNote loads a credit note and hides notes owned by other users.
+func (s *service) getOwnedCreditNote(userID, id uuid.UUID) (*models.CreditNote, error) {
+	note, err := s.repo.GetCreditNoteByID(id)
+	if err != nil || note.UserID != userID {
+		return nil, ErrCreditNoteNotFound
+	}
+	return note, nil
+}
+
+// creditTotals works out the amounts credited for some invoice lines with the
+// invoice's own pricing. A fixed discount is shared out by subtotal, so
+// crediting half the invoice credits half the discount.
+func creditTotals(invoice *models.Invoice, lines []invoiceLine) (*invoiceTotals, error) {
+	pricing := pricingOf(invoice)
+	if pricing.DiscountType == "" || pricing.DiscountType == models.DiscountTypeFixed {
+		subTotal := money.Zero
+		for _, line := range lines {
+			subTotal = subTotal.Add(line.UnitPrice.MulInt(int64(line.Quantity)).RoundCurrency(invoice.Currency))
+		}
+		pricing.DiscountValue = money.Zero
+		if invoice.SubTotal.IsPositive() {
+			pricing.DiscountValue = invoice.Discount.MulDiv(subTotal, invoice.SubTotal)
+		}
+	}
+	return calculateTotals(pricing, lines)
+}
+
+// creditsEverything reports whether every line of an invoice is fully
+// credited once the quantities in credited are.
+func creditsEverything(invoice *models.Invoice, credited map[uuid.UUID]int) bool {
+	for _, item := range invoice.Items {
+		if credited[item.ID] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// settleRemainder gives the note that finishes crediting an invoice exactly
+// what earlier notes left, so rounding on each note cannot leave a few cents
+// owing or credit a few cents too many.
+func settleRemainder(invoice *models.Invoice, previous []models.CreditNote, totals *invoiceTotals) {
+	totals.SubTotal = invoice.SubTotal
+	totals.Discount = invoice.Discount
+	totals.TaxTotal = invoice.TaxTotal
+	totals.TotalAmount = invoice.TotalAmount
+	taxes := make(map[uuid.UUID]money.Decimal, len(invoice.Taxes))
+	for _, tax := range invoice.Taxes {
+		taxes[tax.TaxRateID] = tax.Amount
+	}
+
+	// Earlier notes hold negative amounts
+	for _, note := range previous {
+		totals.SubTotal = totals.SubTotal.Add(note.SubTotal)
+		totals.Discount = totals.Discount.Add(note.Discount)
+		totals.TaxTotal = totals.TaxTotal.Add(note.TaxTotal)
+		totals.TotalAmount = totals.TotalAmount.Add(note.TotalAmount)
+		for _, tax := range note.Taxes {
+			taxes[tax.TaxRateID] = taxes[tax.TaxRateID].Add(tax.Amount)
+		}
+	}
+	for i := range totals.Taxes {
+		totals.Taxes[i].Amount = taxes[totals.Taxes[i].TaxRateID]
+	}
+}
+
+// creditTotal is the positive amount a set of credit notes takes off an
+// invoice.
+func creditTotal(notes []models.CreditNote) money.Decimal {
+	total := money.Zero
+	for _, note := range notes {
+		total = total.Sub(note.TotalAmount)
+	}
+	return total
+}
@@ -0,0 +1,286 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// creditableInvoice is a sent invoice for 2 x 100 and 1 x 300 with a fixed
+// discount of 50, totalling 450.
+func creditableInvoice(userID uuid.UUID) *models.Invoice {
+	invoice := sentInvoice(userID, "450")
+	invoice.InvoiceNumber = "INV-2026-00007"
+	invoice.SubTotal = money.NewFromInt(500)
+	invoice.DiscountType = models.DiscountTypeFixed
+	invoice.DiscountValue = money.NewFromInt(50)
+	invoice.Discount = money.NewFromInt(50)
+	invoice.Items = []models.InvoiceItem{
+		{ID: uuid.New(), InvoiceID: invoice.ID, Description: "Chairs", Quantity: 2, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(200)},
+		{ID: uuid.New(), InvoiceID: invoice.ID, Description: "Desk", Quantity: 1, UnitPrice: money.NewFromInt(300), Amount: money.NewFromInt(300)},
+	}
+	return invoice
+}
+
+func expectCreditNote(mockRepo *mocks.Repository, invoice *models.Invoice, previous []models.CreditNote, actions ...models.ActivityAction) {
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetCreditNotes", map[string]interface{}{"invoice_id": invoice.ID}).Return(previous, nil)
+	mockRepo.On("CreateCreditNote", mock.AnythingOfType("*models.CreditNote")).Return(nil)
+	mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return([]models.Payment{}, nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	for _, action := range actions {
+		mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+			return log.Action == action
+		})).Return(nil).Once()
+	}
+}
+
+func TestCreateCreditNote_WholeInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := creditableInvoice(userID)
+	expectCreditNote(mockRepo, invoice, nil, "CREDIT_NOTE_APPLIED", "INVOICE_PAID")
+
+	note, err := svc.CreateCreditNote(auth.Principal{UserID: userID}, inputs.CreateCreditNoteInput{
+		InvoiceID: invoice.ID,
+		Reason:    "  Order cancelled  ",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, invoice.ID, note.InvoiceID)
+	assert.Equal(t, "Order cancelled", note.Reason)
+	assert.False(t, note.IssueDate.IsZero())
+	assert.Equal(t, money.NewFromInt(-500), note.SubTotal)
+	assert.Equal(t, money.NewFromInt(-50), note.Discount)
+	assert.Equal(t, money.NewFromInt(-450), note.TotalAmount)
+	require.Len(t, note.Items, 2)
+	assert.Equal(t, 2, note.Items[0].Quantity)
+	assert.Equal(t, money.NewFromInt(-100), note.Items[0].UnitPrice)
+	assert.Equal(t, money.NewFromInt(-200), note.Items[0].Amount)
+	assert.Equal(t, money.NewFromInt(-300), note.Items[1].Amount)
+
+	assert.Equal(t, money.NewFromInt(450), invoice.CreditTotal)
+	assert.True(t, invoice.BalanceDue.IsZero())
+	assert.Equal(t, models.InvoiceStatusPaid, invoice.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_PartialLine(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := creditableInvoice(userID)
+	expectCreditNote(mockRepo, invoice, nil, "CREDIT_NOTE_APPLIED")
+
+	note, err := svc.CreateCreditNote(auth.Principal{UserID: userID}, inputs.CreateCreditNoteInput{
+		InvoiceID: invoice.ID,
+		Reason:    "One chair returned",
+		Items:     []inputs.CreditNoteItemInput{{InvoiceItemID: invoice.Items[0].ID, Quantity: 1}},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, note.Items, 1)
+	assert.Equal(t, invoice.Items[0].ID, note.Items[0].InvoiceItemID)
+	// A fifth of the subtotal takes a fifth of the fixed discount
+	assert.Equal(t, money.NewFromInt(-100), note.SubTotal)
+	assert.Equal(t, money.NewFromInt(-10), note.Discount)
+	assert.Equal(t, money.NewFromInt(-90), note.TotalAmount)
+
+	assert.Equal(t, money.NewFromInt(90), invoice.CreditTotal)
+	assert.Equal(t, money.NewFromInt(360), invoice.BalanceDue)
+	assert.Equal(t, models.InvoiceStatusSent, invoice.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_SettlesRoundingOnLastNote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	// 3 x 10 less 10 leaves 20, which does not split evenly into thirds
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "20")
+	invoice.SubTotal = money.NewFromInt(30)
+	invoice.DiscountType = models.DiscountTypeFixed
+	invoice.DiscountValue = money.NewFromInt(10)
+	invoice.Discount = money.NewFromInt(10)
+	invoice.Items = []models.InvoiceItem{
+		{ID: uuid.New(), Description: "Licence", Quantity: 3, UnitPrice: money.NewFromInt(10), Amount: money.NewFromInt(30)},
+	}
+	third := models.CreditNote{
+		SubTotal:    money.NewFromInt(-10),
+		Discount:    money.MustParse("-3.33"),
+		TotalAmount: money.MustParse("-6.67"),
+		Items:       []models.CreditNoteItem{{InvoiceItemID: invoice.Items[0].ID, Quantity: 1}},
+	}
+	expectCreditNote(mockRepo, invoice, []models.CreditNote{third, third}, "CREDIT_NOTE_APPLIED", "INVOICE_PAID")
+
+	note, err := svc.CreateCreditNote(auth.Principal{UserID: userID}, inputs.CreateCreditNoteInput{
+		InvoiceID: invoice.ID,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, note.Items[0].Quantity)
+	assert.Equal(t, money.MustParse("-3.34"), note.Discount)
+	assert.Equal(t, money.MustParse("-6.66"), note.TotalAmount)
+	assert.Equal(t, money.NewFromInt(20), invoice.CreditTotal)
+	assert.True(t, invoice.BalanceDue.IsZero())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCreditNote_Rejected(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		status   string
+		previous func(invoice *models.Invoice) []models.CreditNote
+		items    func(invoice *models.Invoice) []inputs.CreditNoteItemInput
+		wantErr  string
+	}{
+		{
+			name:    "draft invoice",
+			status:  models.InvoiceStatusDraft,
+			wantErr: "draft invoices can be edited instead of credited",
+		},
+		{
+			name:    "void invoice",
+			status:  models.InvoiceStatusVoid,
+			wantErr: "cannot credit an invoice that is void",
+		},
+		{
+			name: "more than was invoiced",
+			items: func(invoice *models.Invoice) []inputs.CreditNoteItemInput {
+				return []inputs.CreditNoteItemInput{{InvoiceItemID: invoice.Items[0].ID, Quantity: 3}}
+			},
+			wantErr: "item 1: quantity must be between 1 and 2",
+		},
+		{
+			name: "line from another invoice",
+			items: func(invoice *models.Invoice) []inputs.CreditNoteItemInput {
+				return []inputs.CreditNoteItemInput{{InvoiceItemID: uuid.New(), Quantity: 1}}
+			},
+			wantErr: "item 1: not a line on this invoice",
+		},
+		{
+			name: "line listed twice",
+			items: func(invoice *models.Invoice) []inputs.CreditNoteItemInput {
+				return []inputs.CreditNoteItemInput{
+					{InvoiceItemID: invoice.Items[0].ID, Quantity: 1},
+					{InvoiceItemID: invoice.Items[0].ID, Quantity: 1},
+				}
+			},
+			wantErr: "item 2: line is listed more than once",
+		},
+		{
+			name: "line already credited",
+			previous: func(invoice *models.Invoice) []models.CreditNote {
+				return []models.CreditNote{{
+					TotalAmount: money.NewFromInt(-270),
+					Items:       []models.CreditNoteItem{{InvoiceItemID: invoice.Items[1].ID, Quantity: 1}},
+				}}
+			},
+			items: func(invoice *models.Invoice) []inputs.CreditNoteItemInput {
+				return []inputs.CreditNoteItemInput{{InvoiceItemID: invoice.Items[1].ID, Quantity: 1}}
+			},
+			wantErr: "item 1: line has already been credited in full",
+		},
+		{
+			name: "everything already credited",
+			previous: func(invoice *models.Invoice) []models.CreditNote {
+				return []models.CreditNote{{
+					TotalAmount: money.NewFromInt(-450),
+					Items: []models.CreditNoteItem{
+						{InvoiceItemID: invoice.Items[0].ID, Quantity: 2},
+						{InvoiceItemID: invoice.Items[1].ID, Quantity: 1},
+					},
+				}}
+			},
+			wantErr: "invoice has already been credited in full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := creditableInvoice(userID)
+			if tt.status != "" {
+				invoice.Status = tt.status
+			}
+			var previous []models.CreditNote
+			if tt.previous != nil {
+				previous = tt.previous(invoice)
+			}
+			var items []inputs.CreditNoteItemInput
+			if tt.items != nil {
+				items = tt.items(invoice)
+			}
+			expectTx(mockRepo)
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetCreditNotes", map[string]interface{}{"invoice_id": invoice.ID}).Return(previous, nil).Maybe()
+
+			_, err := svc.CreateCreditNote(auth.Principal{UserID: userID}, inputs.CreateCreditNoteInput{
+				InvoiceID: invoice.ID,
+				Items:     items,
+			})
+
+			assert.EqualError(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "CreateCreditNote", mock.Anything)
+			mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateCreditNote_MoreThanOwed(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	// 400 of the 450 is paid, so only 50 can still be credited
+	userID := uuid.New()
+	invoice := creditableInvoice(userID)
+	invoice.Status = models.InvoiceStatusPartiallyPaid
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetCreditNotes", map[string]interface{}{"invoice_id": invoice.ID}).Return(nil, nil)
+	mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return([]models.Payment{
+		{ID: uuid.New(), InvoiceID: invoice.ID, Kind: models.PaymentKindPayment, Amount: money.NewFromInt(400)},
+	}, nil)
+
+	_, err := svc.CreateCreditNote(auth.Principal{UserID: userID}, inputs.CreateCreditNoteInput{
+		InvoiceID: invoice.ID,
+		Items:     []inputs.CreditNoteItemInput{{InvoiceItemID: invoice.Items[0].ID, Quantity: 1}},
+	})
+
+	assert.EqualError(t, err, "credit of 90.00 is more than the 50.00 still owed on the invoice, refund a payment first")
+	mockRepo.AssertNotCalled(t, "CreateCreditNote", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+}
+
+func TestGetCreditNote_OtherUsersNote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	note := &models.CreditNote{ID: uuid.New(), UserID: uuid.New()}
+	mockRepo.On("GetCreditNoteByID", note.ID).Return(note, nil)
+
+	_, err := svc.GetCreditNote(auth.Principal{UserID: uuid.New()}, note.ID)
+
+	assert.ErrorIs(t, err, service.ErrCreditNoteNotFound)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/response"
+)
+
+var (
+	ErrCustomerNotFound    = errors.New("customer not found")
+	ErrCustomerHasInvoices = errors.New("customer has invoices and cannot be deleted")
+	ErrCustomerInUse       = errors.New("customer has quotes or recurring invoices and cannot be deleted")
+)
+
+func (s *service) CreateCustomer(principal auth.Principal, input inputs.CreateCustomerInput) (*models.Customer, error) {
+	customer := &models.Customer{
+		ID:            uuid.New(),
+		UserID:        principal.UserID,
+		Name:          strings.TrimSpace(input.Name),
+		Email:         strings.TrimSpace(input.Email),
+		Address:       strings.TrimSpace(input.Address),
+		TaxNumber:     strings.TrimSpace(input.TaxNumber),
+		ReverseCharge: input.ReverseCharge,
+	}
+
+	if err := validateCustomer(customer); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.CreateCustomer(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (s *service) GetCustomers(principal auth.Principal) ([]models.Customer, error) {
+	return s.repo.GetCustomers(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+func (s *service) GetCustomer(principal auth.Principal, id uuid.UUID) (*response.CustomerResponse, error) {
+	customer, err := s.getOwnedCustomer(principal.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.repo.GetInvoices(map[string]interface{}{
+		"user_id":     principal.UserID,
+		"customer_id": customer.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sum what is still owed on every issued, unpaid invoice, per currency
+	outstanding := make(map[string]money.Decimal)
+	for _, invoice := range invoices {
+		if !isOutstanding(invoice.Status) {
+			continue
+		}
+		outstanding[invoice.Currency] = outstanding[invoice.Currency].Add(invoice.BalanceDue)
+	}
+
+	return &response.CustomerResponse{
+		Customer:           customer,
+		Invoices:           invoices,
+		OutstandingBalance: outstanding,
+	}, nil
+}
+
+func (s *service) UpdateCustomer(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error {
+	customer, err := s.getOwnedCustomer(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		if key == "reverse_charge" {
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			customer.ReverseCharge = flag
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value for %s", key)
+		}
+		switch key {
+		case "name":
+			customer.Name = strings.TrimSpace(str)
+		case "email":
+			customer.Email = strings.TrimSpace(str)
+		case "address":
+			customer.Address = strings.TrimSpace(str)
+		case "tax_number":
+			customer.TaxNumber = strings.TrimSpace(str)
+		}
+	}
+
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateCustomer(id, customer)
+}
+
+func (s *service) DeleteCustomer(principal auth.Principal, id uuid.UUID) error {
+	customer, err := s.getOwnedCustomer(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+
+	// Invoices, trashed ones included, quotes and recurring invoices reference
+	// their customer, so it cannot be removed while any of them exist
+	filters := map[string]interface{}{
+		"user_id":     principal.UserID,
+		"customer_id": customer.ID,
+	}
+	invoices, err := s.repo.GetInvoices(filters)
+	if err != nil {
+		return err
+	}
+	if len(invoices) > 0 {
+		return ErrCustomerHasInvoices
+	}
+	deleted, err := s.repo.GetDeletedInvoices(filters)
+	if err != nil {
+		return err
+	}
+	if len(deleted) > 0 {
+		return ErrCustomerHasInvoices
+	}
+	quotes, err := s.repo.GetQuotes(filters)
+	if err != nil {
+		return err
+	}
+	recurring, err := s.repo.GetRecurringInvoices(filters)
+	if err != nil {
+		return err
+	}
+	if len(quotes) > 0 || len(recurring) > 0 {
+		return ErrCustomerInUse
+	}
+
+	return s.repo.DeleteCustomer(id)
+}
+
+// getOwnedCustomer loads a customer and hides customers owned by other users.
+func (s *service) getOwnedCustomer(userID, id uuid.UUID) (*models.Customer, error) {
+	customer, err := s.repo.GetCustomerByID(id)
+	if err != nil || customer.UserID != userID {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+func validateCustomer(customer *models.Customer) error {
+	if customer.Name == "" {
+		return errors.New("customer name is required")
+	}
+	if customer.Email == "" {
+		return errors.New("customer email is required")
+	}
+	if _, err := mail.ParseAddress(customer.Email); err != nil {
+		return errors.New("invalid customer email")
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	input := inputs.CreateCustomerInput{
+		Name:    " Acme Ltd ",
+		Email:   "billing@acme.test",
+		Address: "1 Market Street",
+	}
+
+	mockRepo.On("CreateCustomer", mock.AnythingOfType("*models.Customer")).Return(nil)
+
+	customer, err := svc.CreateCustomer(auth.Principal{UserID: userID}, input)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, customer)
+	assert.Equal(t, userID, customer.UserID)
+	assert.Equal(t, "Acme Ltd", customer.Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCustomer_InvalidEmail(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	input := inputs.CreateCustomerInput{
+		Name:  "Acme Ltd",
+		Email: "not-an-email",
+	}
+
+	customer, err := svc.CreateCustomer(auth.Principal{UserID: uuid.New()}, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, customer)
+	assert.Equal(t, "invalid customer email", err.Error())
+	mockRepo.AssertNotCalled(t, "CreateCustomer", mock.Anything)
+}
+
+func TestGetCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, UserID: userID, Name: "Acme Ltd"}
+	invoices := []models.Invoice{
+		{ID: uuid.New(), Currency: "USD", TotalAmount: money.NewFromInt(100), BalanceDue: money.NewFromInt(100), Status: "pending"},
+		{ID: uuid.New(), Currency: "USD", TotalAmount: money.NewFromInt(50), AmountPaid: money.NewFromInt(20), BalanceDue: money.NewFromInt(30), Status: "partially_paid"},
+		{ID: uuid.New(), Currency: "USD", TotalAmount: money.NewFromInt(75), AmountPaid: money.NewFromInt(75), Status: "paid"},
+		{ID: uuid.New(), Currency: "EUR", TotalAmount: money.NewFromInt(20), BalanceDue: money.NewFromInt(20), Status: "pending"},
+	}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(customer, nil)
+	mockRepo.On("GetInvoices", map[string]interface{}{
+		"user_id":     userID,
+		"customer_id": customerID,
+	}).Return(invoices, nil)
+
+	resp, err := svc.GetCustomer(auth.Principal{UserID: userID}, customerID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, customer, resp.Customer)
+	assert.Len(t, resp.Invoices, 4)
+	assert.Equal(t, map[string]money.Decimal{
+		"USD": money.NewFromInt(130),
+		"EUR": money.NewFromInt(20),
+	}, resp.OutstandingBalance)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetCustomer_OtherUser(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, UserID: uuid.New()}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(customer, nil)
+
+	resp, err := svc.GetCustomer(auth.Principal{UserID: uuid.New()}, customerID)
+
+	assert.ErrorIs(t, err, service.ErrCustomerNotFound)
+	assert.Nil(t, resp)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	existing := &models.Customer{ID: customerID, UserID: userID, Name: "Acme", Email: "old@acme.test"}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(existing, nil)
+	mockRepo.On("UpdateCustomer", customerID, mock.MatchedBy(func(c *models.Customer) bool {
+		return c.Email == "new@acme.test" && c.Name == "Acme"
+	})).Return(nil)
+
+	err := svc.UpdateCustomer(auth.Principal{UserID: userID}, customerID, map[string]interface{}{
+		"email": "new@acme.test",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateCustomer_InvalidValue(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	existing := &models.Customer{ID: customerID, UserID: userID, Name: "Acme", Email: "old@acme.test"}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(existing, nil)
+
+	err := svc.UpdateCustomer(auth.Principal{UserID: userID}, customerID, map[string]interface{}{
+		"name": 42,
+	})
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateCustomer", mock.Anything, mock.Anything)
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	existing := &models.Customer{ID: customerID, UserID: userID}
+
+	filters := map[string]interface{}{
+		"user_id":     userID,
+		"customer_id": customerID,
+	}
+	mockRepo.On("GetCustomerByID", customerID).Return(existing, nil)
+	mockRepo.On("GetInvoices", filters).Return([]models.Invoice{}, nil)
+	mockRepo.On("GetDeletedInvoices", filters).Return([]models.Invoice{}, nil)
+	mockRepo.On("GetQuotes", filters).Return([]models.Quote{}, nil)
+	mockRepo.On("GetRecurringInvoices", filters).Return([]models.RecurringInvoice{}, nil)
+	mockRepo.On("DeleteCustomer", customerID).Return(nil)
+
+	err := svc.DeleteCustomer(auth.Principal{UserID: userID}, customerID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteCustomer_HasInvoices(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	existing := &models.Customer{ID: customerID, UserID: userID}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(existing, nil)
+	mockRepo.On("GetInvoices", map[string]interface{}{
+		"user_id":     userID,
+		"customer_id": customerID,
+	}).Return([]models.Invoice{{ID: uuid.New()}}, nil)
+
+	err := svc.DeleteCustomer(auth.Principal{UserID: userID}, customerID)
+
+	assert.ErrorIs(t, err, service.ErrCustomerHasInvoices)
+	mockRepo.AssertNotCalled(t, "DeleteCustomer", mock.Anything)
+}
+
+// Trashed invoices, quotes and recurring invoices would be left pointing at a
+// missing customer, breaking restore and the recurring generator
+func TestDeleteCustomer_InUse(t *testing.T) {
+	userID := uuid.New()
+	customerID := uuid.New()
+	filters := map[string]interface{}{
+		"user_id":     userID,
+		"customer_id": customerID,
+	}
+
+	tests := []struct {
+		name      string
+		deleted   []models.Invoice
+		quotes    []models.Quote
+		recurring []models.RecurringInvoice
+		wantErr   error
+	}{
+		{"trashed invoice", []models.Invoice{{ID: uuid.New()}}, nil, nil, service.ErrCustomerHasInvoices},
+		{"quote", nil, []models.Quote{{ID: uuid.New()}}, nil, service.ErrCustomerInUse},
+		{"recurring invoice", nil, nil, []models.RecurringInvoice{{ID: uuid.New()}}, service.ErrCustomerInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+			mockRepo.On("GetInvoices", filters).Return([]models.Invoice{}, nil)
+			mockRepo.On("GetDeletedInvoices", filters).Return(tt.deleted, nil)
+			mockRepo.On("GetQuotes", filters).Return(tt.quotes, nil).Maybe()
+			mockRepo.On("GetRecurringInvoices", filters).Return(tt.recurring, nil).Maybe()
+
+			err := svc.DeleteCustomer(auth.Principal{UserID: userID}, customerID)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "DeleteCustomer", mock.Anything)
+		})
+	}
+}
+
+func TestDeleteCustomer_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	customerID := uuid.New()
+	mockRepo.On("GetCustomerByID", customerID).Return(nil, errors.New("not found"))
+
+	err := svc.DeleteCustomer(auth.Principal{UserID: uuid.New()}, customerID)
+
+	assert.ErrorIs(t, err, service.ErrCustomerNotFound)
+	mockRepo.AssertExpectations(t)
+}
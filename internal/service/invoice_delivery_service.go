@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+)
+
+var (
+	ErrMailerNotConfigured   = errors.New("email delivery is not configured")
+	ErrInvoiceDeliveryFailed = errors.New("invoice could not be delivered")
+)
+
+// Limits on what a user can add to an invoice email.
+const (
+	maxDeliveryCopies      = 10
+	maxDeliveryMessageSize = 2000
+)
+
+// SendInvoice emails an invoice to its customer with the PDF attached and a
+// share link to view it online. A draft invoice becomes sent once the email
+// is accepted by the mail server. Every attempt is recorded, failed ones
+// included.
+func (s *service) SendInvoice(principal auth.Principal, input inputs.SendInvoiceInput) (*models.InvoiceDelivery, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, input.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliverInvoice(invoice, &principal, input.Cc, input.Bcc, input.Message)
+}
+
+func (s *service) GetInvoiceDeliveries(principal auth.Principal, invoiceID uuid.UUID) ([]models.InvoiceDelivery, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInvoiceDeliveries(map[string]interface{}{
+		"invoice_id": invoiceID,
+	})
+}
+
+// deliverInvoice emails an invoice. actor is the user sending it, nil when
+// it is sent automatically.
+func (s *service) deliverInvoice(invoice *models.Invoice, actor *auth.Principal, cc, bcc []string, message string) (*models.InvoiceDelivery, error) {
+	if s.mailer == nil {
+		return nil, ErrMailerNotConfigured
+	}
+	switch invoice.Status {
+	case models.InvoiceStatusVoid, models.InvoiceStatusWrittenOff:
+		return nil, fmt.Errorf("%s invoices cannot be sent", strings.ReplaceAll(invoice.Status, "_", " "))
+	}
+	if invoice.Customer.Email == "" {
+		return nil, errors.New("customer has no email address")
+	}
+
+	cc, err := emailAddresses("cc", cc)
+	if err != nil {
+		return nil, err
+	}
+	bcc, err = emailAddresses("bcc", bcc)
+	if err != nil {
+		return nil, err
+	}
+	if len(cc)+len(bcc) > maxDeliveryCopies {
+		return nil, fmt.Errorf("at most %d cc and bcc addresses are allowed", maxDeliveryCopies)
+	}
+	if len(message) > maxDeliveryMessageSize {
+		return nil, fmt.Errorf("message can be at most %d characters", maxDeliveryMessageSize)
+	}
+
+	// The customer gets the invoice as sent, not as a draft; the change is
+	// only saved once the email is out. The number it goes out with is kept
+	// even if the email fails, so such a draft can only be voided, not
+	// deleted.
+	now := time.Now()
+	var statusAction models.ActivityAction
+	from := invoice.Status
+	if invoice.Status == models.InvoiceStatusDraft {
+		statusAction, err = transitionInvoice(invoice, models.InvoiceStatusSent, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.NumberInvoice(invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := s.renderInvoicePDF(invoice)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.newShareLink(invoice, nil)
+	if err != nil {
+		return nil, err
+	}
+	subject, text, html, err := renderInvoiceEmail(invoice, s.publicURL+link.Path, message)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.InvoiceDelivery{
+		ID:          uuid.New(),
+		InvoiceID:   invoice.ID,
+		UserID:      invoice.UserID,
+		To:          invoice.Customer.Email,
+		Cc:          cc,
+		Bcc:         bcc,
+		Subject:     subject,
+		Message:     message,
+		ShareLinkID: &link.Link.ID,
+		Status:      models.DeliveryStatusPending,
+		AttemptedAt: now,
+	}
+	if err := s.repo.CreateInvoiceDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	sendErr := s.mailer.Send(mailer.Message{
+		To:       invoice.Customer.Email,
+		Cc:       cc,
+		Bcc:      bcc,
+		ReplyTo:  invoice.User.Email,
+		Subject:  subject,
+		Body:     text,
+		HTMLBody: html,
+		Attachments: []mailer.Attachment{
+			{FileName: file.FileName, ContentType: "application/pdf", Content: file.Content},
+		},
+	})
+	if sendErr != nil {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+		_ = s.repo.UpdateInvoiceDelivery(delivery.ID, delivery)
+		// Nobody received the link, so it should not stay usable
+		_ = s.repo.RevokeShareLink(link.Link.ID, now)
+		s.logInvoiceEntityActivity(actor, invoice, models.ActivityInvoiceDeliveryFailed, models.EntityInvoiceDelivery, delivery.ID, models.ActivityMetadata{
+			NewValues: map[string]interface{}{"error": delivery.Error},
+		})
+		return delivery, fmt.Errorf("%w: %v", ErrInvoiceDeliveryFailed, sendErr)
+	}
+
+	delivery.Status = models.DeliveryStatusSent
+	delivery.DeliveredAt = &now
+	if err := s.repo.UpdateInvoiceDelivery(delivery.ID, delivery); err != nil {
+		return nil, err
+	}
+
+	if statusAction != "" {
+		// Payments or fees may have been saved while the email went out, so
+		// the invoice is sent as it now stands rather than as it was read
+		err := s.repo.WithTx(func(repo repository.Repository) error {
+			locked, err := repo.LockInvoice(invoice.ID)
+			if err != nil {
+				return err
+			}
+			invoice = locked
+			from = locked.Status
+			if locked.Status != models.InvoiceStatusDraft {
+				statusAction = ""
+				return nil
+			}
+			statusAction, err = transitionInvoice(locked, models.InvoiceStatusSent, now)
+			if err != nil {
+				return err
+			}
+			return writeInvoice(repo, locked, actor, statusAction)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if statusAction != "" {
+			s.logStatusChange(actor, invoice, statusAction, from)
+		}
+	}
+	s.logInvoiceEntityActivity(actor, invoice, models.ActivityInvoiceEmailed, models.EntityInvoiceDelivery, delivery.ID, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"to": delivery.To},
+	})
+
+	return delivery, nil
+}
+
+// emailAddresses checks a list of addresses and returns them without blanks.
+func emailAddresses(field string, addresses []string) ([]string, error) {
+	var result []string
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(address); err != nil {
+			return nil, fmt.Errorf("invalid %s address %q", field, address)
+		}
+		result = append(result, address)
+	}
+	return result, nil
+}
@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// deliverableInvoice is a draft invoice that can be rendered and emailed. It
+// has no number until it is sent.
+func deliverableInvoice(userID uuid.UUID) *models.Invoice {
+	return &models.Invoice{
+		ID:        uuid.New(),
+		UserID:    userID,
+		User:      models.User{ID: userID, FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"},
+		Customer:  models.Customer{Name: "Acme Ltd", Email: "billing@acme.test"},
+		IssueDate: time.Now(),
+		DueDate:   time.Date(2026, time.April, 30, 0, 0, 0, 0, time.UTC),
+		Currency:  "USD",
+		Status:    models.InvoiceStatusDraft,
+		Items: []models.InvoiceItem{
+			{Description: "Design", Quantity: 2, UnitPrice: money.NewFromInt(60), Amount: money.NewFromInt(120)},
+		},
+		SubTotal:    money.NewFromInt(120),
+		TotalAmount: money.NewFromInt(120),
+		BalanceDue:  money.NewFromInt(120),
+	}
+}
+
+func expectInvoiceRendering(mockRepo *mocks.Repository, invoice *models.Invoice) {
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	expectNumbering(mockRepo, invoice.ID, "INV-2026-00003")
+	mockRepo.On("GetInvoiceTemplateSettings", invoice.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreateShareLink", mock.AnythingOfType("*models.ShareLink")).Return(nil)
+	mockRepo.On("CreateInvoiceDelivery", mock.AnythingOfType("*models.InvoiceDelivery")).Return(nil)
+}
+
+func TestSendInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender), service.WithPublicURL("https://invoices.example.com/"))
+
+	userID := uuid.New()
+	invoice := deliverableInvoice(userID)
+	expectInvoiceRendering(mockRepo, invoice)
+	mockRepo.On("UpdateInvoiceDelivery", mock.AnythingOfType("uuid.UUID"), mock.MatchedBy(func(delivery *models.InvoiceDelivery) bool {
+		return delivery.Status == models.DeliveryStatusSent && delivery.DeliveredAt != nil
+	})).Return(nil)
+	// The stored row is still the draft that was read
+	stored := *invoice
+	mockRepo.On("LockInvoice", invoice.ID).Return(&stored, nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, mock.MatchedBy(func(saved *models.Invoice) bool {
+		return saved.Status == models.InvoiceStatusSent && saved.SentAt != nil
+	})).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_SENT"
+	})).Return(nil).Once()
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_EMAILED"
+	})).Return(nil).Once()
+
+	delivery, err := svc.SendInvoice(auth.Principal{UserID: userID}, inputs.SendInvoiceInput{
+		InvoiceID: invoice.ID,
+		Cc:        []string{"accounts@acme.test", " "},
+		Bcc:       []string{"archive@example.com"},
+		Message:   "Thanks for the <great> work.\n\nSee you next month.",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryStatusSent, delivery.Status)
+	assert.Equal(t, "billing@acme.test", delivery.To)
+	assert.Equal(t, []string{"accounts@acme.test"}, delivery.Cc)
+	assert.NotNil(t, delivery.ShareLinkID)
+	mockRepo.AssertExpectations(t)
+
+	messages := sender.Sent()
+	require.Len(t, messages, 1)
+	msg := messages[0]
+	assert.Equal(t, "Invoice INV-2026-00003 from Jane Doe", msg.Subject)
+	assert.Equal(t, "jane@example.com", msg.ReplyTo)
+	assert.Equal(t, []string{"archive@example.com"}, msg.Bcc)
+	assert.Contains(t, msg.Body, "Please find invoice INV-2026-00003 for USD 120.00 attached, due on 30 April 2026.")
+	assert.Contains(t, msg.Body, "https://invoices.example.com/api/shared/")
+	assert.Contains(t, msg.HTMLBody, "Thanks for the &lt;great&gt; work.")
+	assert.Contains(t, msg.HTMLBody, `href="https://invoices.example.com/api/shared/`)
+
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "INV-2026-00003.pdf", msg.Attachments[0].FileName)
+	assert.True(t, bytes.HasPrefix(msg.Attachments[0].Content, []byte("%PDF-")))
+}
+
+func TestSendInvoice_SavesInvoiceAsItNowStands(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithMailer(mailer.NewMemory()))
+
+	userID := uuid.New()
+	invoice := deliverableInvoice(userID)
+	expectInvoiceRendering(mockRepo, invoice)
+	mockRepo.On("UpdateInvoiceDelivery", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("*models.InvoiceDelivery")).Return(nil)
+	expectTx(mockRepo)
+	// A credit was applied while the email went out
+	current := *invoice
+	current.InvoiceNumber = "INV-2026-00003"
+	current.BalanceDue = money.NewFromInt(70)
+	mockRepo.On("LockInvoice", invoice.ID).Return(&current, nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, mock.MatchedBy(func(saved *models.Invoice) bool {
+		return saved.Status == models.InvoiceStatusSent && saved.BalanceDue.Equal(money.NewFromInt(70))
+	})).Return(nil)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	_, err := svc.SendInvoice(auth.Principal{UserID: userID}, inputs.SendInvoiceInput{InvoiceID: invoice.ID})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendInvoice_NoLongerDraft(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithMailer(mailer.NewMemory()))
+
+	userID := uuid.New()
+	invoice := deliverableInvoice(userID)
+	expectInvoiceRendering(mockRepo, invoice)
+	mockRepo.On("UpdateInvoiceDelivery", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("*models.InvoiceDelivery")).Return(nil)
+	expectTx(mockRepo)
+	// The invoice was voided while the email went out
+	current := *invoice
+	current.Status = models.InvoiceStatusVoid
+	mockRepo.On("LockInvoice", invoice.ID).Return(&current, nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_EMAILED"
+	})).Return(nil).Once()
+
+	_, err := svc.SendInvoice(auth.Principal{UserID: userID}, inputs.SendInvoiceInput{InvoiceID: invoice.ID})
+
+	require.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendInvoice_DeliveryFails(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithMailer(failingSender{}))
+
+	userID := uuid.New()
+	invoice := deliverableInvoice(userID)
+	expectInvoiceRendering(mockRepo, invoice)
+	mockRepo.On("UpdateInvoiceDelivery", mock.AnythingOfType("uuid.UUID"), mock.MatchedBy(func(delivery *models.InvoiceDelivery) bool {
+		return delivery.Status == models.DeliveryStatusFailed && delivery.Error == "connection refused"
+	})).Return(nil)
+	mockRepo.On("RevokeShareLink", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_DELIVERY_FAILED"
+	})).Return(nil).Once()
+
+	delivery, err := svc.SendInvoice(auth.Principal{UserID: userID}, inputs.SendInvoiceInput{InvoiceID: invoice.ID})
+
+	assert.ErrorIs(t, err, service.ErrInvoiceDeliveryFailed)
+	require.NotNil(t, delivery)
+	assert.Equal(t, models.DeliveryStatusFailed, delivery.Status)
+	// The invoice stays a draft, but keeps the number it went out with
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+	assert.Equal(t, "INV-2026-00003", invoice.InvoiceNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendInvoice_Rejected(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		modify  func(invoice *models.Invoice, input *inputs.SendInvoiceInput)
+		wantErr string
+	}{
+		{"void invoice", func(invoice *models.Invoice, input *inputs.SendInvoiceInput) {
+			invoice.Status = models.InvoiceStatusVoid
+		}, "void invoices cannot be sent"},
+		{"customer without email", func(invoice *models.Invoice, input *inputs.SendInvoiceInput) {
+			invoice.Customer.Email = ""
+		}, "customer has no email address"},
+		{"invalid cc", func(invoice *models.Invoice, input *inputs.SendInvoiceInput) {
+			input.Cc = []string{"not an address"}
+		}, "invalid cc address"},
+		{"too many copies", func(invoice *models.Invoice, input *inputs.SendInvoiceInput) {
+			for i := 0; i < 11; i++ {
+				input.Bcc = append(input.Bcc, "copy@example.com")
+			}
+		}, "at most 10 cc and bcc addresses"},
+		{"message too long", func(invoice *models.Invoice, input *inputs.SendInvoiceInput) {
+			input.Message = strings.Repeat("a", 2001)
+		}, "message can be at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			sender := mailer.NewMemory()
+			svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+			invoice := deliverableInvoice(userID)
+			input := inputs.SendInvoiceInput{InvoiceID: invoice.ID}
+			tt.modify(invoice, &input)
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+
+			_, err := svc.SendInvoice(auth.Principal{UserID: userID}, input)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Empty(t, sender.Sent())
+			mockRepo.AssertNotCalled(t, "CreateInvoiceDelivery", mock.Anything)
+		})
+	}
+}
+
+func TestSendInvoice_WithoutMailer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := deliverableInvoice(userID)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+
+	_, err := svc.SendInvoice(auth.Principal{UserID: userID}, inputs.SendInvoiceInput{InvoiceID: invoice.ID})
+
+	assert.ErrorIs(t, err, service.ErrMailerNotConfigured)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+)
+
+// invoiceEmailData is what the invoice email shows.
+type invoiceEmailData struct {
+	CustomerName  string
+	InvoiceNumber string
+	Currency      string
+	AmountDue     string
+	DueDate       string
+	Link          string
+	Message       []string // the sender's note, split into paragraphs
+	SenderName    string
+}
+
+var invoiceEmailText = template.Must(template.New("text").Parse(`Hi {{.CustomerName}},
+{{range .Message}}
+{{.}}
+{{end}}
+Please find invoice {{.InvoiceNumber}} for {{.Currency}} {{.AmountDue}} attached, due on {{.DueDate}}.
+
+You can also view it online: {{.Link}}
+
+Thank you,
+{{.SenderName}}
+`))
+
+var invoiceEmailHTML = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222; line-height: 1.5;">
+<p>Hi {{.CustomerName}},</p>
+{{range .Message}}<p style="white-space: pre-line;">{{.}}</p>
+{{end}}<p>Please find invoice <strong>{{.InvoiceNumber}}</strong> for <strong>{{.Currency}} {{.AmountDue}}</strong> attached, due on {{.DueDate}}.</p>
+<p><a href="{{.Link}}" style="display: inline-block; padding: 10px 18px; background: #1F4E79; color: #fff; text-decoration: none; border-radius: 4px;">View invoice</a></p>
+<p>Thank you,<br>{{.SenderName}}</p>
+</body>
+</html>
+`))
+
+// renderInvoiceEmail returns the subject and the plain text and HTML bodies
+// of the email that delivers an invoice.
+func renderInvoiceEmail(invoice *models.Invoice, link, message string) (string, string, string, error) {
+	sender := strings.TrimSpace(invoice.User.FirstName + " " + invoice.User.LastName)
+	data := invoiceEmailData{
+		CustomerName:  invoice.Customer.Name,
+		InvoiceNumber: invoice.InvoiceNumber,
+		Currency:      invoice.Currency,
+		AmountDue:     invoice.BalanceDue.Format(invoice.Currency),
+		DueDate:       invoice.DueDate.Format("2 January 2006"),
+		Link:          link,
+		SenderName:    sender,
+	}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			data.Message = append(data.Message, paragraph)
+		}
+	}
+
+	var text, html strings.Builder
+	if err := invoiceEmailText.Execute(&text, data); err != nil {
+		return "", "", "", err
+	}
+	if err := invoiceEmailHTML.Execute(&html, data); err != nil {
+		return "", "", "", err
+	}
+
+	subject := "Invoice " + invoice.InvoiceNumber
+	if sender != "" {
+		subject += " from " + sender
+	}
+	return subject, text.String(), html.String(), nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // logos may be JPEG
+	_ "image/png"  // or PNG
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/invoicepdf"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/pdf"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"gorm.io/gorm"
+)
+
+// Limits on uploaded logos, which are stored in the database and embedded in
+// every PDF.
+const (
+	maxLogoBytes     = 512 << 10
+	maxLogoDimension = 2000
+	maxFooterLength  = 500
+)
+
+func (s *service) GetInvoicePDF(principal auth.Principal, id uuid.UUID) (*response.InvoicePDF, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderInvoicePDF(invoice)
+}
+
+func (s *service) renderInvoicePDF(invoice *models.Invoice) (*response.InvoicePDF, error) {
+	// Payment details are optional
+	details, err := s.repo.GetPaymentDetailsByInvoiceID(invoice.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		details = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	content, err := s.renderPDF(invoice.UserID, invoicepdf.Data{
+		Invoice:        invoice,
+		PaymentDetails: details,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.InvoicePDF{
+		FileName: invoiceFileName(invoice) + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+// invoiceFileName names an invoice's PDF after its number. Drafts are not
+// numbered yet and go by their ID.
+func invoiceFileName(invoice *models.Invoice) string {
+	if invoice.InvoiceNumber == "" {
+		return "draft-" + invoice.ID.String()
+	}
+	return invoice.InvoiceNumber
+}
+
+// renderPDF draws a document with the user's template and branding.
+func (s *service) renderPDF(userID uuid.UUID, data invoicepdf.Data) ([]byte, error) {
+	settings, err := s.invoiceTemplateSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	branding, err := brandingOf(settings)
+	if err != nil {
+		return nil, err
+	}
+	return invoicepdf.Render(settings.Template, data, branding)
+}
+
+func (s *service) GetInvoiceTemplateSettings(principal auth.Principal) (*models.InvoiceTemplateSettings, error) {
+	return s.invoiceTemplateSettings(principal.UserID)
+}
+
+// UpdateInvoiceTemplateSettings changes how a user's invoices look. The logo
+// is sent base64 encoded; null or an empty string removes it.
+func (s *service) UpdateInvoiceTemplateSettings(principal auth.Principal, updates map[string]interface{}) error {
+	settings, err := s.invoiceTemplateSettings(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		if key == "logo" && value == nil {
+			settings.Logo = nil
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value for %s", key)
+		}
+		switch key {
+		case "template":
+			if _, ok := invoicepdf.Lookup(str); !ok {
+				return fmt.Errorf("unknown template %q, expected one of %s", str, strings.Join(invoicepdf.Names(), ", "))
+			}
+			settings.Template = str
+		case "accent_color":
+			color, err := pdf.ParseHexColor(str)
+			if err != nil {
+				return err
+			}
+			settings.AccentColor = color.Hex()
+		case "footer_text":
+			if utf8.RuneCountInString(str) > maxFooterLength {
+				return fmt.Errorf("footer text must be at most %d characters", maxFooterLength)
+			}
+			settings.FooterText = strings.TrimSpace(str)
+		case "logo":
+			logo, err := decodeLogo(str)
+			if err != nil {
+				return err
+			}
+			settings.Logo = logo
+		}
+	}
+
+	return s.repo.SaveInvoiceTemplateSettings(settings)
+}
+
+// invoiceTemplateSettings returns the user's template settings, or the
+// defaults if they have not changed them.
+func (s *service) invoiceTemplateSettings(userID uuid.UUID) (*models.InvoiceTemplateSettings, error) {
+	settings, err := s.repo.GetInvoiceTemplateSettings(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.InvoiceTemplateSettings{
+			UserID:      userID,
+			Template:    invoicepdf.DefaultTemplate,
+			AccentColor: invoicepdf.DefaultAccent.Hex(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func brandingOf(settings *models.InvoiceTemplateSettings) (invoicepdf.Branding, error) {
+	branding := invoicepdf.Branding{
+		Accent:     invoicepdf.DefaultAccent,
+		FooterText: settings.FooterText,
+	}
+	if color, err := pdf.ParseHexColor(settings.AccentColor); err == nil {
+		branding.Accent = color
+	}
+	if len(settings.Logo) > 0 {
+		logo, _, err := image.Decode(bytes.NewReader(settings.Logo))
+		if err != nil {
+			return branding, fmt.Errorf("stored logo is unreadable: %w", err)
+		}
+		branding.Logo = logo
+	}
+	return branding, nil
+}
+
+// decodeLogo checks an uploaded logo and returns its bytes.
+func decodeLogo(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	// Accept data URLs as produced by browsers
+	if i := strings.Index(encoded, ";base64,"); strings.HasPrefix(encoded, "data:") && i >= 0 {
+		encoded = encoded[i+len(";base64,"):]
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("logo must be base64 encoded")
+	}
+	if len(data) > maxLogoBytes {
+		return nil, fmt.Errorf("logo must be at most %d KB", maxLogoBytes>>10)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, errors.New("logo must be a PNG or JPEG image")
+	}
+	if config.Width > maxLogoDimension || config.Height > maxLogoDimension {
+		return nil, fmt.Errorf("logo must be at most %dx%d pixels", maxLogoDimension, maxLogoDimension)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetInvoicePDF(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := &models.Invoice{
+		ID:            uuid.New(),
+		UserID:        userID,
+		InvoiceNumber: "INV-2026-00001",
+		IssueDate:     time.Now(),
+		DueDate:       time.Now().AddDate(0, 0, 30),
+		Currency:      "USD",
+		Items: []models.InvoiceItem{
+			{Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(100)},
+		},
+		SubTotal:    money.NewFromInt(100),
+		TotalAmount: money.NewFromInt(100),
+	}
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceTemplateSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+
+	file, err := svc.GetInvoicePDF(auth.Principal{UserID: userID}, invoice.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2026-00001.pdf", file.FileName)
+	assert.True(t, bytes.HasPrefix(file.Content, []byte("%PDF-")))
+	mockRepo.AssertExpectations(t)
+
+	// Other users cannot render the invoice
+	_, err = svc.GetInvoicePDF(auth.Principal{UserID: uuid.New()}, invoice.ID)
+	assert.ErrorIs(t, err, service.ErrInvoiceNotFound)
+}
+
+func TestUpdateInvoiceTemplateSettings(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	var logo bytes.Buffer
+	require.NoError(t, png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	userID := uuid.New()
+	mockRepo.On("GetInvoiceTemplateSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveInvoiceTemplateSettings", mock.AnythingOfType("*models.InvoiceTemplateSettings")).Return(nil)
+
+	err := svc.UpdateInvoiceTemplateSettings(auth.Principal{UserID: userID}, map[string]interface{}{
+		"template":     "modern",
+		"accent_color": "#c0392b",
+		"footer_text":  "  Thanks!  ",
+		"logo":         "data:image/png;base64," + base64.StdEncoding.EncodeToString(logo.Bytes()),
+	})
+
+	assert.NoError(t, err)
+	saved := mockRepo.Calls[1].Arguments.Get(0).(*models.InvoiceTemplateSettings)
+	assert.Equal(t, userID, saved.UserID)
+	assert.Equal(t, "modern", saved.Template)
+	assert.Equal(t, "#C0392B", saved.AccentColor)
+	assert.Equal(t, "Thanks!", saved.FooterText)
+	assert.Equal(t, logo.Bytes(), saved.Logo)
+}
+
+func TestUpdateInvoiceTemplateSettings_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		wantErr string
+	}{
+		{"unknown template", map[string]interface{}{"template": "fancy"}, `unknown template "fancy", expected one of classic, modern`},
+		{"bad colour", map[string]interface{}{"accent_color": "blue"}, `invalid colour "blue", expected #RRGGBB`},
+		{"logo not base64", map[string]interface{}{"logo": "not an image!"}, "logo must be base64 encoded"},
+		{"logo not an image", map[string]interface{}{"logo": base64.StdEncoding.EncodeToString([]byte("GIF89a"))}, "logo must be a PNG or JPEG image"},
+		{"wrong type", map[string]interface{}{"footer_text": 5.0}, "invalid value for footer_text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			userID := uuid.New()
+			mockRepo.On("GetInvoiceTemplateSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+
+			err := svc.UpdateInvoiceTemplateSettings(auth.Principal{UserID: userID}, tt.updates)
+
+			assert.EqualError(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "SaveInvoiceTemplateSettings", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/invoicepdf"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"gorm.io/gorm"
+)
+
+var ErrInvoiceRevisionNotFound = errors.New("invoice revision not found")
+
+func (s *service) GetInvoiceRevisions(principal auth.Principal, invoiceID uuid.UUID) ([]models.InvoiceRevision, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetInvoiceRevisions(invoiceID)
+}
+
+func (s *service) GetInvoiceRevision(principal auth.Principal, invoiceID uuid.UUID, number int) (*models.InvoiceRevision, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+	revision, err := s.repo.GetInvoiceRevision(invoiceID, number)
+	if err != nil {
+		return nil, ErrInvoiceRevisionNotFound
+	}
+	return revision, nil
+}
+
+// DiffInvoiceRevisions lists every field that differs between two revisions
+// of an invoice.
+func (s *service) DiffInvoiceRevisions(principal auth.Principal, invoiceID uuid.UUID, from, to int) (*response.InvoiceRevisionDiff, error) {
+	before, err := s.GetInvoiceRevision(principal, invoiceID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.GetInvoiceRevision(principal, invoiceID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffSnapshots(before.Snapshot, after.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &response.InvoiceRevisionDiff{
+		From:    from,
+		To:      to,
+		Changes: changes,
+	}, nil
+}
+
+// GetInvoiceRevisionPDF renders an invoice as it stood at a revision.
+func (s *service) GetInvoiceRevisionPDF(principal auth.Principal, invoiceID uuid.UUID, number int) (*response.InvoicePDF, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.repo.GetInvoiceRevision(invoiceID, number)
+	if err != nil {
+		return nil, ErrInvoiceRevisionNotFound
+	}
+
+	historical, details := invoiceAt(invoice, revision.Snapshot)
+	content, err := s.renderPDF(principal.UserID, invoicepdf.Data{
+		Invoice:        historical,
+		PaymentDetails: details,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.InvoicePDF{
+		FileName: fmt.Sprintf("%s-revision-%d.pdf", invoiceFileName(historical), revision.Number),
+		Content:  content,
+	}, nil
+}
+
+// saveInvoice stores a changed invoice together with its new revision.
+// actor is who made the change, nil when the system or the customer did.
+func (s *service) saveInvoice(invoice *models.Invoice, actor *auth.Principal, action models.ActivityAction) error {
+	return s.repo.WithTx(func(repo repository.Repository) error {
+		return writeInvoice(repo, invoice, actor, action)
+	})
+}
+
+// writeInvoice is saveInvoice for a caller that already has a transaction,
+// so the invoice is saved together with the rest of the change.
+func writeInvoice(repo repository.Repository, invoice *models.Invoice, actor *auth.Principal, action models.ActivityAction) error {
+	if err := numberIssuedInvoice(repo, invoice); err != nil {
+		return err
+	}
+	if err := repo.UpdateInvoice(invoice.ID, invoice); err != nil {
+		return err
+	}
+	return recordRevision(repo, invoice.ID, actor, action)
+}
+
+// recordRevision stores a snapshot of an invoice as repo now sees it. Call it
+// in the transaction that made the change.
+func recordRevision(repo repository.Repository, invoiceID uuid.UUID, actor *auth.Principal, action models.ActivityAction) error {
+	invoice, err := repo.GetInvoiceByID(invoiceID)
+	if err != nil {
+		return err
+	}
+	details, err := repo.GetPaymentDetailsByInvoiceID(invoiceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		details = nil
+	} else if err != nil {
+		return err
+	}
+
+	revision := &models.InvoiceRevision{
+		InvoiceID: invoiceID,
+		Action:    action,
+		Snapshot:  snapshotInvoice(invoice, details),
+	}
+	if actor != nil {
+		revision.ActorID = &actor.UserID
+	}
+	return repo.CreateInvoiceRevision(revision)
+}
+
+func snapshotInvoice(invoice *models.Invoice, details *models.PaymentDetails) models.InvoiceSnapshot {
+	snapshot := models.InvoiceSnapshot{
+		InvoiceNumber: invoice.InvoiceNumber,
+		Status:        invoice.Status,
+		Customer: models.CustomerSnapshot{
+			ID:        invoice.Customer.ID,
+			Name:      invoice.Customer.Name,
+			Email:     invoice.Customer.Email,
+			Address:   invoice.Customer.Address,
+			TaxNumber: invoice.Customer.TaxNumber,
+		},
+		IssueDate:        invoice.IssueDate.UTC(),
+		DueDate:          invoice.DueDate.UTC(),
+		Currency:         invoice.Currency,
+		DiscountType:     invoice.DiscountType,
+		DiscountValue:    invoice.DiscountValue,
+		PricesIncludeTax: invoice.PricesIncludeTax,
+		ReverseCharge:    invoice.ReverseCharge,
+		SubTotal:         invoice.SubTotal,
+		Discount:         invoice.Discount,
+		TaxTotal:         invoice.TaxTotal,
+		TotalAmount:      invoice.TotalAmount,
+		LateFeeTotal:     invoice.LateFeeTotal,
+		CreditTotal:      invoice.CreditTotal,
+		AmountPaid:       invoice.AmountPaid,
+		BalanceDue:       invoice.BalanceDue,
+		Note:             invoice.Note,
+	}
+
+	for _, item := range invoice.Items {
+		line := models.InvoiceItemSnapshot{
+			ID:          item.ID,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+			TaxAmount:   item.TaxAmount,
+		}
+		for _, tax := range item.Taxes {
+			line.Taxes = append(line.Taxes, models.TaxSnapshot{
+				TaxRateID: tax.TaxRateID,
+				Name:      tax.Name,
+				Rate:      tax.Rate,
+				Compound:  tax.Compound,
+			})
+		}
+		snapshot.Items = append(snapshot.Items, line)
+	}
+	for _, tax := range invoice.Taxes {
+		snapshot.Taxes = append(snapshot.Taxes, models.TaxSnapshot{
+			TaxRateID:     tax.TaxRateID,
+			Name:          tax.Name,
+			Rate:          tax.Rate,
+			Compound:      tax.Compound,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
+		})
+	}
+
+	if details != nil {
+		snapshot.PaymentDetails = &models.PaymentDetailsSnapshot{
+			AccountName:    details.AccountName,
+			AccountNumber:  details.AccountNumber,
+			BankName:       details.BankName,
+			BankAddress:    details.BankAddress,
+			RoutingNumber:  details.RoutingNumber,
+			PaymentDueDate: details.PaymentDueDate.UTC(),
+		}
+	}
+
+	return snapshot
+}
+
+// invoiceAt rebuilds an invoice and its payment details from a snapshot. The
+// issuer comes from the current invoice; everything else is historical.
+func invoiceAt(current *models.Invoice, snapshot models.InvoiceSnapshot) (*models.Invoice, *models.PaymentDetails) {
+	invoice := &models.Invoice{
+		ID:     current.ID,
+		UserID: current.UserID,
+		User:   current.User,
+		Customer: models.Customer{
+			ID:        snapshot.Customer.ID,
+			Name:      snapshot.Customer.Name,
+			Email:     snapshot.Customer.Email,
+			Address:   snapshot.Customer.Address,
+			TaxNumber: snapshot.Customer.TaxNumber,
+		},
+		CustomerID:       snapshot.Customer.ID,
+		InvoiceNumber:    snapshot.InvoiceNumber,
+		Status:           snapshot.Status,
+		IssueDate:        snapshot.IssueDate,
+		DueDate:          snapshot.DueDate,
+		Currency:         snapshot.Currency,
+		DiscountType:     snapshot.DiscountType,
+		DiscountValue:    snapshot.DiscountValue,
+		PricesIncludeTax: snapshot.PricesIncludeTax,
+		ReverseCharge:    snapshot.ReverseCharge,
+		SubTotal:         snapshot.SubTotal,
+		Discount:         snapshot.Discount,
+		TaxTotal:         snapshot.TaxTotal,
+		TotalAmount:      snapshot.TotalAmount,
+		LateFeeTotal:     snapshot.LateFeeTotal,
+		CreditTotal:      snapshot.CreditTotal,
+		AmountPaid:       snapshot.AmountPaid,
+		BalanceDue:       snapshot.BalanceDue,
+		Note:             snapshot.Note,
+	}
+
+	for i, line := range snapshot.Items {
+		item := models.InvoiceItem{
+			ID:          line.ID,
+			InvoiceID:   invoice.ID,
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.Amount,
+			TaxAmount:   line.TaxAmount,
+			Position:    i,
+		}
+		for position, tax := range line.Taxes {
+			item.Taxes = append(item.Taxes, models.InvoiceItemTax{
+				InvoiceItemID: line.ID,
+				TaxRateID:     tax.TaxRateID,
+				Name:          tax.Name,
+				Rate:          tax.Rate,
+				Compound:      tax.Compound,
+				Position:      position,
+			})
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+	for _, tax := range snapshot.Taxes {
+		invoice.Taxes = append(invoice.Taxes, models.InvoiceTax{
+			InvoiceID:     invoice.ID,
+			TaxRateID:     tax.TaxRateID,
+			Name:          tax.Name,
+			Rate:          tax.Rate,
+			Compound:      tax.Compound,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
+		})
+	}
+
+	var details *models.PaymentDetails
+	if snapshot.PaymentDetails != nil {
+		details = &models.PaymentDetails{
+			InvoiceID:      invoice.ID,
+			AccountName:    snapshot.PaymentDetails.AccountName,
+			AccountNumber:  snapshot.PaymentDetails.AccountNumber,
+			BankName:       snapshot.PaymentDetails.BankName,
+			BankAddress:    snapshot.PaymentDetails.BankAddress,
+			RoutingNumber:  snapshot.PaymentDetails.RoutingNumber,
+			PaymentDueDate: snapshot.PaymentDetails.PaymentDueDate,
+		}
+	}
+
+	return invoice, details
+}
+
+// diffSnapshots compares two snapshots field by field. Fields are named by
+// their path in the snapshot, e.g. Items[1].Quantity, and listed in
+// alphabetical order.
+func diffSnapshots(before, after models.InvoiceSnapshot) ([]response.FieldChange, error) {
+	from, err := flattenSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flattenSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(from))
+	for field := range from {
+		fields = append(fields, field)
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []response.FieldChange{}
+	for _, field := range fields {
+		// A field missing on one side, such as a line that was added, reads
+		// as null there
+		if from[field] != to[field] {
+			changes = append(changes, response.FieldChange{
+				Field: field,
+				From:  from[field],
+				To:    to[field],
+			})
+		}
+	}
+	return changes, nil
+}
+
+// flattenSnapshot maps each value in a snapshot to its path. Numbers are kept
+// as written so that amounts compare exactly.
+func flattenSnapshot(snapshot models.InvoiceSnapshot) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	flatten("", tree, fields)
+	return fields, nil
+}
+
+func flatten(path string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if path != "" {
+				key = path + "." + key
+			}
+			flatten(key, child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	default:
+		if v != nil {
+			fields[path] = v
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDiffInvoiceRevisions(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := &models.Invoice{ID: uuid.New(), UserID: userID}
+	itemID := uuid.New()
+	due := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	before := models.InvoiceSnapshot{
+		InvoiceNumber: "INV-2026-00001",
+		Status:        models.InvoiceStatusDraft,
+		DueDate:       due,
+		Currency:      "USD",
+		Items: []models.InvoiceItemSnapshot{
+			{ID: itemID, Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(100)},
+		},
+		TotalAmount: money.NewFromInt(100),
+	}
+	after := before
+	after.DueDate = due.AddDate(0, 0, 14)
+	after.Items = []models.InvoiceItemSnapshot{
+		{ID: itemID, Description: "Design", Quantity: 2, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(200)},
+		{ID: uuid.New(), Description: "Hosting", Quantity: 1, UnitPrice: money.NewFromInt(50), Amount: money.NewFromInt(50)},
+	}
+	after.TotalAmount = money.NewFromInt(250)
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceRevision", invoice.ID, 1).Return(&models.InvoiceRevision{InvoiceID: invoice.ID, Number: 1, Snapshot: before}, nil)
+	mockRepo.On("GetInvoiceRevision", invoice.ID, 2).Return(&models.InvoiceRevision{InvoiceID: invoice.ID, Number: 2, Snapshot: after}, nil)
+
+	diff, err := svc.DiffInvoiceRevisions(auth.Principal{UserID: userID}, invoice.ID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+
+	changes := make(map[string][2]interface{})
+	for _, change := range diff.Changes {
+		changes[change.Field] = [2]interface{}{change.From, change.To}
+	}
+	assert.Contains(t, changes, "DueDate")
+	assert.Equal(t, json.Number("1"), changes["Items[0].Quantity"][0])
+	assert.Equal(t, json.Number("2"), changes["Items[0].Quantity"][1])
+	assert.Nil(t, changes["Items[1].Description"][0])
+	assert.Equal(t, "Hosting", changes["Items[1].Description"][1])
+	assert.Contains(t, changes, "TotalAmount")
+	assert.NotContains(t, changes, "Items[0].Description")
+	assert.NotContains(t, changes, "Currency")
+
+	// Other users cannot read the history
+	_, err = svc.DiffInvoiceRevisions(auth.Principal{UserID: uuid.New()}, invoice.ID, 1, 2)
+	assert.ErrorIs(t, err, service.ErrInvoiceNotFound)
+}
+
+func TestGetInvoiceRevision_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := &models.Invoice{ID: uuid.New(), UserID: userID}
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceRevision", invoice.ID, 7).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.GetInvoiceRevision(auth.Principal{UserID: userID}, invoice.ID, 7)
+	assert.ErrorIs(t, err, service.ErrInvoiceRevisionNotFound)
+}
+
+func TestGetInvoiceRevisionPDF(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := &models.Invoice{ID: uuid.New(), UserID: userID, InvoiceNumber: "INV-2026-00001", Note: "current"}
+	snapshot := models.InvoiceSnapshot{
+		InvoiceNumber: "INV-2026-00001",
+		IssueDate:     time.Now(),
+		DueDate:       time.Now().AddDate(0, 0, 30),
+		Currency:      "USD",
+		Items: []models.InvoiceItemSnapshot{
+			{Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(100)},
+		},
+		SubTotal:    money.NewFromInt(100),
+		TotalAmount: money.NewFromInt(100),
+	}
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceRevision", invoice.ID, 1).Return(&models.InvoiceRevision{InvoiceID: invoice.ID, Number: 1, Snapshot: snapshot}, nil)
+	mockRepo.On("GetInvoiceTemplateSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+
+	file, err := svc.GetInvoiceRevisionPDF(auth.Principal{UserID: userID}, invoice.ID, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2026-00001-revision-1.pdf", file.FileName)
+	assert.True(t, bytes.HasPrefix(file.Content, []byte("%PDF-")))
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"gorm.io/gorm"
+)
+
+const maxSequencePadding = 10
+
+var (
+	sequencePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_/-]{0,20}$`)
+	sequenceSeparators    = map[string]bool{"": true, "-": true, "/": true, "_": true, ".": true}
+)
+
+func (s *service) GetInvoiceSequence(principal auth.Principal) (*response.InvoiceSequenceResponse, error) {
+	seq, err := s.invoiceSequence(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, _ := seq.Peek(time.Now())
+	return &response.InvoiceSequenceResponse{
+		Sequence:          seq,
+		NextInvoiceNumber: next,
+	}, nil
+}
+
+// UpdateInvoiceSequence changes a user's numbering scheme. The next number may
+// be moved forward, e.g. to carry on from another system, but never back, as
+// that would hand out numbers that are already taken.
+func (s *service) UpdateInvoiceSequence(principal auth.Principal, updates map[string]interface{}) error {
+	seq, err := s.invoiceSequence(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateSequence(seq, updates, time.Now()); err != nil {
+		return err
+	}
+
+	return s.repo.SaveInvoiceSequence(seq)
+}
+
+func (s *service) GetCreditNoteSequence(principal auth.Principal) (*response.CreditNoteSequenceResponse, error) {
+	seq, err := s.creditNoteSequence(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, _ := seq.Peek(time.Now())
+	return &response.CreditNoteSequenceResponse{
+		Sequence:             seq,
+		NextCreditNoteNumber: next,
+	}, nil
+}
+
+// UpdateCreditNoteSequence changes how a user's credit notes are numbered,
+// with the same rules as UpdateInvoiceSequence.
+func (s *service) UpdateCreditNoteSequence(principal auth.Principal, updates map[string]interface{}) error {
+	seq, err := s.creditNoteSequence(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateSequence((*models.InvoiceSequence)(seq), updates, time.Now()); err != nil {
+		return err
+	}
+
+	return s.repo.SaveCreditNoteSequence(seq)
+}
+
+func (s *service) GetQuoteSequence(principal auth.Principal) (*response.QuoteSequenceResponse, error) {
+	seq, err := s.quoteSequence(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, _ := seq.Peek(time.Now())
+	return &response.QuoteSequenceResponse{
+		Sequence:        seq,
+		NextQuoteNumber: next,
+	}, nil
+}
+
+// UpdateQuoteSequence changes how a user's quotes are numbered, with the same
+// rules as UpdateInvoiceSequence.
+func (s *service) UpdateQuoteSequence(principal auth.Principal, updates map[string]interface{}) error {
+	seq, err := s.quoteSequence(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateSequence((*models.InvoiceSequence)(seq), updates, time.Now()); err != nil {
+		return err
+	}
+
+	return s.repo.SaveQuoteSequence(seq)
+}
+
+// updateSequence applies the changes in updates to a numbering scheme and
+// checks the result.
+func updateSequence(seq *models.InvoiceSequence, updates map[string]interface{}, now time.Time) error {
+	_, current := seq.Peek(now)
+
+	for key, value := range updates {
+		switch key {
+		case "prefix", "separator":
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if key == "prefix" {
+				seq.Prefix = text
+			} else {
+				seq.Separator = text
+			}
+		case "include_year", "reset_yearly":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if key == "include_year" {
+				seq.IncludeYear = flag
+			} else {
+				seq.ResetYearly = flag
+			}
+		case "padding":
+			n, ok := wholeNumber(value, 1)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			seq.Padding = int(n)
+		case "next_number":
+			n, ok := wholeNumber(value, 1)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if n < current {
+				return fmt.Errorf("next number cannot be lower than %d", current)
+			}
+			seq.NextNumber = n
+			seq.Year = now.Year()
+		}
+	}
+
+	return validateInvoiceSequence(seq)
+}
+
+// invoiceSequence returns the user's numbering scheme, or the default one if
+// they have not issued or configured anything yet.
+func (s *service) invoiceSequence(userID uuid.UUID) (*models.InvoiceSequence, error) {
+	seq, err := s.repo.GetInvoiceSequence(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultInvoiceSequence(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+// creditNoteSequence returns the user's credit note numbering scheme, or the
+// default one.
+func (s *service) creditNoteSequence(userID uuid.UUID) (*models.CreditNoteSequence, error) {
+	seq, err := s.repo.GetCreditNoteSequence(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultCreditNoteSequence(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+func (s *service) quoteSequence(userID uuid.UUID) (*models.QuoteSequence, error) {
+	seq, err := s.repo.GetQuoteSequence(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultQuoteSequence(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+func validateInvoiceSequence(seq *models.InvoiceSequence) error {
+	if !sequencePrefixPattern.MatchString(seq.Prefix) {
+		return errors.New("prefix may only contain letters, digits, '-', '_' and '/' and be at most 20 characters")
+	}
+	if !sequenceSeparators[seq.Separator] {
+		return errors.New("separator must be one of '-', '/', '_', '.' or empty")
+	}
+	if seq.Padding < 1 || seq.Padding > maxSequencePadding {
+		return fmt.Errorf("padding must be between 1 and %d", maxSequencePadding)
+	}
+	return nil
+}
+
+// wholeNumber reads an integer from a decoded JSON update, rejecting anything
+// below atLeast.
+func wholeNumber(value interface{}, atLeast int64) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v < float64(atLeast) || v != math.Trunc(v) || v > math.MaxInt32 {
+			return 0, false
+		}
+		return int64(v), true
+	case int:
+		return int64(v), int64(v) >= atLeast
+	case int64:
+		return v, v >= atLeast
+	default:
+		return 0, false
+	}
+}
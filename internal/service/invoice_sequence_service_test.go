@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetInvoiceSequence_Default(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.On("GetInvoiceSequence", userID).Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := svc.GetInvoiceSequence(auth.Principal{UserID: userID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("INV-%d-00001", time.Now().Year()), result.NextInvoiceNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInvoiceSequence_Formats(t *testing.T) {
+	issued := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		seq  models.InvoiceSequence
+		want string
+	}{
+		{
+			name: "default scheme",
+			seq:  *models.DefaultInvoiceSequence(uuid.Nil),
+			want: "INV-2026-00001",
+		},
+		{
+			name: "continues within the year",
+			seq:  models.InvoiceSequence{Prefix: "INV", Separator: "-", IncludeYear: true, ResetYearly: true, Padding: 5, NextNumber: 42, Year: 2026},
+			want: "INV-2026-00042",
+		},
+		{
+			name: "resets in a new year",
+			seq:  models.InvoiceSequence{Prefix: "INV", Separator: "-", IncludeYear: true, ResetYearly: true, Padding: 5, NextNumber: 42, Year: 2025},
+			want: "INV-2026-00001",
+		},
+		{
+			name: "runs on without a yearly reset",
+			seq:  models.InvoiceSequence{Prefix: "ACME", Separator: "/", Padding: 3, NextNumber: 1234, Year: 2025},
+			want: "ACME/1234",
+		},
+		{
+			name: "no prefix",
+			seq:  models.InvoiceSequence{Separator: "-", IncludeYear: true, Padding: 4, NextNumber: 7, Year: 2026},
+			want: "2026-0007",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq := tt.seq
+			assert.Equal(t, tt.want, seq.Allocate(issued))
+
+			// The following number is one higher
+			next, _ := seq.Peek(issued)
+			assert.NotEqual(t, tt.want, next)
+			assert.Equal(t, 2026, seq.Year)
+		})
+	}
+}
+
+func TestUpdateInvoiceSequence(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.On("GetInvoiceSequence", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveInvoiceSequence", mock.AnythingOfType("*models.InvoiceSequence")).Return(nil)
+
+	err := svc.UpdateInvoiceSequence(auth.Principal{UserID: userID}, map[string]interface{}{
+		"prefix":       "ACME",
+		"include_year": false,
+		"padding":      float64(6),
+		"next_number":  float64(1001),
+	})
+
+	assert.NoError(t, err)
+	saved := mockRepo.Calls[1].Arguments.Get(0).(*models.InvoiceSequence)
+	assert.Equal(t, userID, saved.UserID)
+	next, _ := saved.Peek(time.Now())
+	assert.Equal(t, "ACME-001001", next)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInvoiceSequence_Invalid(t *testing.T) {
+	userID := uuid.New()
+	current := &models.InvoiceSequence{
+		UserID:      userID,
+		Prefix:      "INV",
+		Separator:   "-",
+		IncludeYear: true,
+		ResetYearly: true,
+		Padding:     5,
+		NextNumber:  42,
+		Year:        time.Now().Year(),
+	}
+
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		wantErr string
+	}{
+		{"next number moved back", map[string]interface{}{"next_number": float64(10)}, "next number cannot be lower than 42"},
+		{"fractional next number", map[string]interface{}{"next_number": 42.5}, "invalid value for next_number"},
+		{"prefix with spaces", map[string]interface{}{"prefix": "MY INV"}, "prefix may only contain"},
+		{"unknown separator", map[string]interface{}{"separator": "#"}, "separator must be one of"},
+		{"padding too wide", map[string]interface{}{"padding": float64(20)}, "padding must be between 1 and 10"},
+		{"wrong type", map[string]interface{}{"reset_yearly": "yes"}, "invalid value for reset_yearly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			seq := *current
+			mockRepo.On("GetInvoiceSequence", userID).Return(&seq, nil)
+
+			err := svc.UpdateInvoiceSequence(auth.Principal{UserID: userID}, tt.updates)
+
+			assert.ErrorContains(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "SaveInvoiceSequence", mock.Anything)
+		})
+	}
+}
+
+func TestUpdateCreditNoteSequence(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.On("GetCreditNoteSequence", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveCreditNoteSequence", mock.AnythingOfType("*models.CreditNoteSequence")).Return(nil)
+
+	result, err := svc.GetCreditNoteSequence(auth.Principal{UserID: userID})
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("CN-%d-00001", time.Now().Year()), result.NextCreditNoteNumber)
+
+	err = svc.UpdateCreditNoteSequence(auth.Principal{UserID: userID}, map[string]interface{}{
+		"prefix":       "CR",
+		"include_year": false,
+		"padding":      float64(4),
+	})
+
+	assert.NoError(t, err)
+	saved := mockRepo.Calls[2].Arguments.Get(0).(*models.CreditNoteSequence)
+	next, _ := saved.Peek(time.Now())
+	assert.Equal(t, "CR-0001", next)
+	mockRepo.AssertExpectations(t)
+}
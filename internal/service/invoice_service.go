@@ -2,131 +2,516 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
 )
 
-func (s *service) CreateInvoice(input inputs.CreateInvoiceInput) (*models.Invoice, error) {
-	// Validate customer exists
-	customer, err := s.repo.GetCustomerByID(input.CustomerID)
+var (
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	// ErrInvoiceIssued is returned for deleting an invoice that has left
+	// draft. Issued invoices are kept for the record and voided instead.
+	ErrInvoiceIssued = errors.New("issued invoices cannot be deleted, void them instead")
+	// ErrInvoiceNumbered is returned for deleting a draft that was given a
+	// number, which happens when emailing it fails. Deleting it would leave a
+	// gap in the numbering.
+	ErrInvoiceNumbered = errors.New("this draft already has an invoice number, void it instead")
+	// ErrInvoiceLocked is returned for editing an invoice that has left draft.
+	ErrInvoiceLocked = errors.New("only draft invoices can be edited, issue a credit note to correct this one")
+)
+
+func (s *service) CreateInvoice(principal auth.Principal, input inputs.CreateInvoiceInput) (*models.Invoice, error) {
+	return s.createInvoice(principal, input, nil)
+}
+
+// createInvoice creates an invoice from the input. quoteID, when not nil, is
+// the quote the invoice was converted from.
+func (s *service) createInvoice(principal auth.Principal, input inputs.CreateInvoiceInput, quoteID *uuid.UUID) (*models.Invoice, error) {
+	invoice, err := s.prepareInvoice(principal, input, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The invoice, its items and activity log are written together or not at
+	// all
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		return insertInvoice(repo, invoice, &principal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// prepareInvoice checks the input for a new invoice and works out its
+// amounts. The invoice is returned with its items but is not stored yet;
+// insertInvoice does that.
+func (s *service) prepareInvoice(principal auth.Principal, input inputs.CreateInvoiceInput, quoteID *uuid.UUID) (*models.Invoice, error) {
+	customer, err := s.getOwnedCustomer(principal.UserID, input.CustomerID)
 	if err != nil {
 		return nil, errors.New("invalid customer")
 	}
 
-	// Create invoice
-	invoice := &models.Invoice{
-		ID:            uuid.New(),
-		UserID:        input.UserID,
-		CustomerID:    customer.ID,
-		InvoiceNumber: input.InvoiceNumber,
-		IssueDate:     input.IssueDate,
-		DueDate:       input.DueDate,
-		Currency:      input.Currency,
-		SubTotal:      input.SubTotal,
-		Discount:      input.Discount,
-		TotalAmount:   input.TotalAmount,
-		Status:        "pending",
-		Note:          input.Note,
-	}
-
-	err = s.repo.CreateInvoice(invoice)
+	if len(input.Items) == 0 {
+		return nil, errors.New("invoice must have at least one item")
+	}
+
+	itemTaxes, err := s.resolveItemTaxes(principal.UserID, input.Items)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create invoice items
-	for _, item := range input.Items {
-		invoiceItem := &models.InvoiceItem{
+	discountType := input.DiscountType
+	if discountType == "" {
+		discountType = models.DiscountTypeFixed
+	}
+	pricing := invoicePricing{
+		Currency:         input.Currency,
+		DiscountType:     discountType,
+		DiscountValue:    input.Discount,
+		PricesIncludeTax: input.PricesIncludeTax,
+		ReverseCharge:    customer.ReverseCharge,
+	}
+
+	// Derive every amount from the items rather than trusting the client
+	lines := make([]invoiceLine, len(input.Items))
+	for i, item := range input.Items {
+		lines[i] = invoiceLine{
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			ClientAmount: item.Amount,
+			Taxes:        itemTaxes[i],
+		}
+	}
+	totals, err := calculateTotals(pricing, lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClientTotal("subtotal", input.Currency, input.SubTotal, totals.SubTotal); err != nil {
+		return nil, err
+	}
+	if err := checkClientTotal("total amount", input.Currency, input.TotalAmount, totals.TotalAmount); err != nil {
+		return nil, err
+	}
+
+	// Create invoice; it is numbered when it is issued
+	invoice := &models.Invoice{
+		ID:               uuid.New(),
+		UserID:           principal.UserID,
+		CustomerID:       customer.ID,
+		IssueDate:        input.IssueDate,
+		DueDate:          input.DueDate,
+		Currency:         input.Currency,
+		SubTotal:         totals.SubTotal,
+		DiscountType:     discountType,
+		DiscountValue:    input.Discount,
+		Discount:         totals.Discount,
+		TaxTotal:         totals.TaxTotal,
+		TotalAmount:      totals.TotalAmount,
+		BalanceDue:       totals.TotalAmount,
+		PricesIncludeTax: input.PricesIncludeTax,
+		ReverseCharge:    customer.ReverseCharge,
+		Status:           models.InvoiceStatusDraft,
+		Note:             input.Note,
+		QuoteID:          quoteID,
+		Taxes:            totals.Taxes,
+	}
+
+	for i, item := range input.Items {
+		invoice.Items = append(invoice.Items, models.InvoiceItem{
 			InvoiceID:   invoice.ID,
 			Description: item.Description,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
-			Amount:      item.Amount,
-		}
-		err = s.repo.CreateInvoiceItem(invoiceItem)
-		if err != nil {
-			return nil, err
-		}
+			Amount:      totals.Amounts[i],
+			TaxAmount:   totals.TaxAmounts[i],
+			Taxes:       itemTaxes[i],
+			Position:    i,
+		})
 	}
 
-	// Create activity log
-	activityLog := &models.ActivityLog{
-		UserID:    input.UserID,
-		InvoiceID: &invoice.ID,
-		Action:    "INVOICE_CREATED",
-		Timestamp: time.Now(),
+	return invoice, nil
+}
+
+// insertInvoice stores an invoice from prepareInvoice with its items, activity
+// log and first revision, in the caller's transaction.
+func insertInvoice(repo repository.Repository, invoice *models.Invoice, actor *auth.Principal) error {
+	// The items are inserted one by one below rather than with the invoice
+	items := invoice.Items
+	invoice.Items = nil
+	if err := repo.CreateInvoice(invoice); err != nil {
+		return err
 	}
 
-	_ = s.repo.CreateActivityLog(activityLog)
+	for i := range items {
+		if err := repo.CreateInvoiceItem(&items[i]); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		invoice.Items = append(invoice.Items, items[i])
+	}
 
-	return invoice, nil
+	if err := repo.CreateActivityLog(invoiceActivity(actor, invoice, models.ActivityInvoiceCreated)); err != nil {
+		return err
+	}
+	return recordRevision(repo, invoice.ID, actor, models.ActivityInvoiceCreated)
 }
 
-func (s *service) GetInvoiceByID(id uuid.UUID) (*models.Invoice, error) {
-	return s.repo.GetInvoiceByID(id)
+// GetInvoice returns one of the user's invoices with its items, customer and
+// issuer.
+func (s *service) GetInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error) {
+	return s.getOwnedInvoice(principal.UserID, id)
 }
 
-func (s *service) GetInvoices(filters map[string]interface{}) ([]models.Invoice, error) {
-	return s.repo.GetInvoices(filters)
+func (s *service) GetInvoices(principal auth.Principal) ([]models.Invoice, error) {
+	return s.repo.GetInvoices(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
 }
 
-func (s *service) UpdateInvoice(id uuid.UUID, updates map[string]interface{}) error {
-	invoice, err := s.repo.GetInvoiceByID(id)
+// UpdateInvoice edits a draft invoice and recalculates its totals. Once an
+// invoice is issued only its status can change. The invoice is locked while
+// it changes, so a payment recorded at the same time is not written over.
+func (s *service) UpdateInvoice(principal auth.Principal, id uuid.UUID, input inputs.UpdateInvoiceInput) (*models.Invoice, error) {
+	edited := input.CustomerID != nil || input.IssueDate != nil || input.DueDate != nil ||
+		input.Currency != nil || input.DiscountType != nil || input.Discount != nil ||
+		input.PricesIncludeTax != nil || input.Note != nil || input.Items != nil
+
+	var invoice *models.Invoice
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, id)
+		if err != nil {
+			return err
+		}
+		if edited && invoice.Status != models.InvoiceStatusDraft {
+			return ErrInvoiceLocked
+		}
+
+		var removed []uuid.UUID
+		if edited {
+			removed, err = s.editInvoice(invoice, input)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Status changes go through the lifecycle
+		var statusAction models.ActivityAction
+		previousStatus := invoice.Status
+		if input.Status != nil && *input.Status != invoice.Status {
+			statusAction, err = transitionInvoice(invoice, *input.Status, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		if edited {
+			for _, itemID := range removed {
+				if err := repo.DeleteInvoiceItem(itemID); err != nil {
+					return err
+				}
+			}
+			for i := range invoice.Items {
+				item := &invoice.Items[i]
+				if item.ID == uuid.Nil {
+					if err := repo.CreateInvoiceItem(item); err != nil {
+						return fmt.Errorf("item %d: %w", i+1, err)
+					}
+					continue
+				}
+				if err := repo.UpdateInvoiceItem(item.ID, item); err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+				if err := repo.ReplaceInvoiceItemTaxes(item.ID, item.Taxes); err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+			}
+			if err := repo.ReplaceInvoiceTaxes(invoice.ID, invoice.Taxes); err != nil {
+				return err
+			}
+		}
+
+		if err := numberIssuedInvoice(repo, invoice); err != nil {
+			return err
+		}
+		if err := repo.UpdateInvoice(invoice.ID, invoice); err != nil {
+			return err
+		}
+
+		// Create activity logs
+		if edited {
+			if err := repo.CreateActivityLog(invoiceActivity(&principal, invoice, models.ActivityInvoiceUpdated)); err != nil {
+				return err
+			}
+		}
+		if statusAction != "" {
+			if err := repo.CreateActivityLog(statusActivity(&principal, invoice, statusAction, previousStatus)); err != nil {
+				return err
+			}
+		}
+
+		change := statusAction
+		if edited {
+			change = models.ActivityInvoiceUpdated
+		}
+		if change == "" {
+			return nil
+		}
+		return recordRevision(repo, invoice.ID, &principal, change)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for key, value := range updates {
-		switch key {
-		case "status":
-			invoice.Status = value.(string)
-		case "note":
-			invoice.Note = value.(string)
-		case "due_date":
-			invoice.DueDate = value.(time.Time)
+	return invoice, nil
+}
+
+// editInvoice applies the edits in input to a draft invoice and recalculates
+// it. It returns the IDs of the lines the edit removes.
+func (s *service) editInvoice(invoice *models.Invoice, input inputs.UpdateInvoiceInput) ([]uuid.UUID, error) {
+	if input.CustomerID != nil {
+		customer, err := s.getOwnedCustomer(invoice.UserID, *input.CustomerID)
+		if err != nil {
+			return nil, errors.New("invalid customer")
 		}
+		invoice.CustomerID = customer.ID
+		invoice.Customer = *customer
+		invoice.ReverseCharge = customer.ReverseCharge
 	}
-
-	// Create activity log
-	activityLog := &models.ActivityLog{
-		UserID:    invoice.UserID,
-		InvoiceID: &invoice.ID,
-		Action:    "INVOICE_UPDATED",
-		Timestamp: time.Now(),
+	if input.IssueDate != nil {
+		invoice.IssueDate = *input.IssueDate
+	}
+	if input.DueDate != nil {
+		invoice.DueDate = *input.DueDate
+	}
+	if input.Currency != nil {
+		if *input.Currency == "" {
+			return nil, errors.New("currency is required")
+		}
+		invoice.Currency = *input.Currency
+	}
+	if input.DiscountType != nil {
+		invoice.DiscountType = *input.DiscountType
+	}
+	if input.Discount != nil {
+		invoice.DiscountValue = *input.Discount
+	}
+	if input.PricesIncludeTax != nil {
+		invoice.PricesIncludeTax = *input.PricesIncludeTax
+	}
+	if input.Note != nil {
+		invoice.Note = *input.Note
 	}
 
-	_ = s.repo.CreateActivityLog(activityLog)
+	var removed []uuid.UUID
+	if input.Items != nil {
+		if len(input.Items) == 0 {
+			return nil, errors.New("invoice must have at least one item")
+		}
 
-	return s.repo.UpdateInvoice(id, invoice)
+		lines := make([]inputs.CreateInvoiceItemInput, len(input.Items))
+		for i, item := range input.Items {
+			lines[i] = inputs.CreateInvoiceItemInput{TaxRateIDs: item.TaxRateIDs}
+		}
+		itemTaxes, err := s.resolveItemTaxes(invoice.UserID, lines)
+		if err != nil {
+			return nil, err
+		}
+
+		existing := make(map[uuid.UUID]bool, len(invoice.Items))
+		for _, item := range invoice.Items {
+			existing[item.ID] = true
+		}
+		kept := make(map[uuid.UUID]bool)
+		items := make([]models.InvoiceItem, len(input.Items))
+		for i, item := range input.Items {
+			line := models.InvoiceItem{
+				InvoiceID:   invoice.ID,
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+				Taxes:       itemTaxes[i],
+				Position:    i,
+			}
+			if item.ID != nil {
+				if !existing[*item.ID] {
+					return nil, fmt.Errorf("item %d: not a line on this invoice", i+1)
+				}
+				if kept[*item.ID] {
+					return nil, fmt.Errorf("item %d: line is listed more than once", i+1)
+				}
+				kept[*item.ID] = true
+				line.ID = *item.ID
+			}
+			items[i] = line
+		}
+
+		for _, item := range invoice.Items {
+			if !kept[item.ID] {
+				removed = append(removed, item.ID)
+			}
+		}
+		invoice.Items = items
+	}
+
+	if err := recalculateInvoice(invoice); err != nil {
+		return nil, err
+	}
+	return removed, nil
 }
 
-func (s *service) DeleteInvoice(id uuid.UUID) error {
-	invoice, err := s.repo.GetInvoiceByID(id)
+// DeleteInvoice moves a draft invoice to the trash, together with its items
+// and payment details. It can be brought back with RestoreInvoice. Only
+// drafts without a number can be deleted, so the numbering has no gaps.
+func (s *service) DeleteInvoice(principal auth.Principal, id uuid.UUID) error {
+	var invoice *models.Invoice
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, id)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != models.InvoiceStatusDraft {
+			return ErrInvoiceIssued
+		}
+		if invoice.InvoiceNumber != "" {
+			return ErrInvoiceNumbered
+		}
+
+		// The revision is taken first, as the deleted draft is no longer
+		// found afterwards
+		if err := recordRevision(repo, invoice.ID, &principal, models.ActivityInvoiceDeleted); err != nil {
+			return err
+		}
+		return repo.DeleteInvoice(invoice.ID)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Create activity log
-	activityLog := &models.ActivityLog{
-		UserID:    invoice.UserID,
-		InvoiceID: &invoice.ID,
-		Action:    "INVOICE_DELETED",
-		Timestamp: time.Now(),
+	s.logInvoiceActivity(&principal, invoice, models.ActivityInvoiceDeleted)
+
+	return nil
+}
+
+// GetDeletedInvoices lists the drafts a user has deleted.
+func (s *service) GetDeletedInvoices(principal auth.Principal) ([]models.Invoice, error) {
+	return s.repo.GetDeletedInvoices(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+// RestoreInvoice takes a deleted draft out of the trash with the items and
+// payment details deleted along with it.
+func (s *service) RestoreInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error) {
+	deleted, err := s.repo.GetDeletedInvoiceByID(id)
+	if err != nil || deleted.UserID != principal.UserID {
+		return nil, ErrInvoiceNotFound
+	}
+	if _, err := s.getOwnedCustomer(principal.UserID, deleted.CustomerID); err != nil {
+		return nil, errors.New("the invoice's customer no longer exists")
 	}
 
-	_ = s.repo.CreateActivityLog(activityLog)
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := repo.RestoreInvoice(deleted.ID, deleted.DeletedAt.Time); err != nil {
+			return err
+		}
+		return recordRevision(repo, deleted.ID, &principal, models.ActivityInvoiceRestored)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return s.repo.DeleteInvoice(id)
+	invoice, err := s.repo.GetInvoiceByID(deleted.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.logInvoiceActivity(&principal, invoice, models.ActivityInvoiceRestored)
+
+	return invoice, nil
 }
 
-func (s *service) GetInvoiceWithItems(id uuid.UUID) (*models.Invoice, error) {
-	// GetInvoiceByID already preloads Items, User, and Customer
-	invoice, err := s.repo.GetInvoiceByID(id)
+// VoidInvoice cancels an invoice that should not have been issued. A void
+// invoice keeps its number and stays on record; nothing more is owed on it.
+func (s *service) VoidInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error) {
+	var (
+		invoice *models.Invoice
+		from    string
+		action  models.ActivityAction
+	)
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, id)
+		if err != nil {
+			return err
+		}
+		from = invoice.Status
+		action, err = transitionInvoice(invoice, models.InvoiceStatusVoid, time.Now())
+		if err != nil {
+			return err
+		}
+		return writeInvoice(repo, invoice, &principal, action)
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.logStatusChange(&principal, invoice, action, from)
+
 	return invoice, nil
 }
+
+// numberIssuedInvoice numbers an invoice that is leaving draft. Drafts, and
+// drafts voided before they were ever issued, stay unnumbered.
+func numberIssuedInvoice(repo repository.Repository, invoice *models.Invoice) error {
+	if invoice.InvoiceNumber != "" {
+		return nil
+	}
+	switch invoice.Status {
+	case models.InvoiceStatusDraft, models.InvoiceStatusVoid:
+		return nil
+	}
+	return repo.NumberInvoice(invoice)
+}
+
+// getOwnedInvoice loads an invoice and hides invoices owned by other users.
+func (s *service) getOwnedInvoice(userID, id uuid.UUID) (*models.Invoice, error) {
+	invoice, err := s.repo.GetInvoiceByID(id)
+	if err != nil || invoice.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// lockOwnedInvoice is getOwnedInvoice inside a transaction. The invoice row
+// stays locked until the transaction ends, so changes to what is owed on it
+// are checked and made one at a time.
+func lockOwnedInvoice(repo repository.Repository, userID, id uuid.UUID) (*models.Invoice, error) {
+	invoice, err := repo.LockInvoice(id)
+	if err != nil || invoice.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// decimalValue reads an amount from a decoded JSON update, which holds numbers
+// as float64 and may also carry them as strings.
+func decimalValue(value interface{}) (money.Decimal, error) {
+	switch v := value.(type) {
+	case float64:
+		return money.Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return money.Parse(v)
+	case money.Decimal:
+		return v, nil
+	default:
+		return money.Zero, fmt.Errorf("unsupported amount type %T", value)
+	}
+}
@@ -1,17 +1,22 @@
 package service_test
 
 import (
+	"errors"
 	"testing"
 	"time"
-	"errors"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/mocks"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
 	"github.com/iyiola-dev/numeris/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestCreateInvoice(t *testing.T) {
@@ -22,56 +27,496 @@ func TestCreateInvoice(t *testing.T) {
 	userID := uuid.New()
 
 	customer := &models.Customer{
-		ID: customerID,
+		ID:     customerID,
+		UserID: userID,
 	}
 
 	input := inputs.CreateInvoiceInput{
-		CustomerID:    customerID,
-		UserID:       userID,
-		InvoiceNumber: "INV-001",
-		IssueDate:    time.Now(),
-		DueDate:      time.Now().AddDate(0, 0, 30),
-		Currency:     "USD",
+		CustomerID: customerID,
+		IssueDate:  time.Now(),
+		DueDate:    time.Now().AddDate(0, 0, 30),
+		Currency:   "USD",
 		Items: []inputs.CreateInvoiceItemInput{
 			{
 				Description: "Test Item",
 				Quantity:    1,
-				UnitPrice:   100,
-				Amount:     100,
+				UnitPrice:   money.NewFromInt(100),
+				Amount:      money.NewFromInt(100),
 			},
 		},
-		SubTotal:    100,
-		TotalAmount: 100,
+		SubTotal:    money.NewFromInt(100),
+		TotalAmount: money.NewFromInt(100),
 	}
 
 	// Set up expectations
 	mockRepo.On("GetCustomerByID", customerID).Return(customer, nil)
+	expectTx(mockRepo)
 	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
 	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
 	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
 
 	// Execute
-	invoice, err := svc.CreateInvoice(input)
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, invoice)
 	assert.Equal(t, customerID, invoice.CustomerID)
 	assert.Equal(t, userID, invoice.UserID)
+	assert.Equal(t, models.InvoiceStatusDraft, invoice.Status)
 	mockRepo.AssertExpectations(t)
 }
 
+// expectTx makes WithTx run the unit of work against the mock itself.
+func expectTx(mockRepo *mocks.Repository) {
+	mockRepo.On("WithTx", mock.Anything).Return(func(fn func(repo repository.Repository) error) error {
+		return fn(mockRepo)
+	})
+}
+
+// expectRevision lets the mock record revisions of any invoice. Register it
+// after more specific expectations, since it matches every invoice.
+func expectRevision(mockRepo *mocks.Repository) {
+	mockRepo.On("GetPaymentDetailsByInvoiceID", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreateInvoiceRevision", mock.AnythingOfType("*models.InvoiceRevision")).Return(nil)
+}
+
+// expectNumbering gives the invoice with the given ID number when it is
+// issued, as the repository would.
+func expectNumbering(mockRepo *mocks.Repository, id uuid.UUID, number string) {
+	mockRepo.On("NumberInvoice", mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.ID == id
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Invoice).InvoiceNumber = number
+	}).Return(nil)
+}
+
+func TestCreateInvoice_ComputesTotals(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	input := inputs.CreateInvoiceInput{
+		CustomerID:   customerID,
+		Currency:     "USD",
+		DiscountType: models.DiscountTypePercentage,
+		Discount:     money.NewFromInt(10),
+		Items: []inputs.CreateInvoiceItemInput{
+			{Description: "Design", Quantity: 3, UnitPrice: money.MustParse("33.335")},
+			{Description: "Hosting", Quantity: 2, UnitPrice: money.MustParse("12.5")},
+		},
+	}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "100.01", invoice.Items[0].Amount.String())
+	assert.Equal(t, "25", invoice.Items[1].Amount.String())
+	assert.Equal(t, "125.01", invoice.SubTotal.String())
+	assert.Equal(t, "12.5", invoice.Discount.String())
+	assert.Equal(t, "112.51", invoice.TotalAmount.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateInvoice_RoundsToCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency string
+		price    string
+		want     string
+	}{
+		{"JPY", "333.5", "1001"},
+		{"KWD", "0.3335", "1.001"},
+		{"USD", "0.3335", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			customerID := uuid.New()
+			userID := uuid.New()
+			input := inputs.CreateInvoiceInput{
+				CustomerID: customerID,
+				Currency:   tt.currency,
+				Items: []inputs.CreateInvoiceItemInput{
+					{Description: "Item", Quantity: 3, UnitPrice: money.MustParse(tt.price)},
+				},
+			}
+
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+			expectTx(mockRepo)
+			mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+			mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+			mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+			mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+			expectRevision(mockRepo)
+
+			invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, invoice.TotalAmount.String())
+		})
+	}
+}
+
+func TestCreateInvoice_RejectsInconsistentAmounts(t *testing.T) {
+	customerID := uuid.New()
+	item := inputs.CreateInvoiceItemInput{Description: "Item", Quantity: 2, UnitPrice: money.NewFromInt(50)}
+
+	tests := []struct {
+		name  string
+		input inputs.CreateInvoiceInput
+	}{
+		{
+			name: "item amount",
+			input: inputs.CreateInvoiceInput{
+				Items: []inputs.CreateInvoiceItemInput{{Description: "Item", Quantity: 2, UnitPrice: money.NewFromInt(50), Amount: money.NewFromInt(90)}},
+			},
+		},
+		{
+			name: "subtotal",
+			input: inputs.CreateInvoiceInput{
+				Items:    []inputs.CreateInvoiceItemInput{item},
+				SubTotal: money.NewFromInt(120),
+			},
+		},
+		{
+			name: "total amount",
+			input: inputs.CreateInvoiceInput{
+				Items:       []inputs.CreateInvoiceItemInput{item},
+				Discount:    money.NewFromInt(10),
+				TotalAmount: money.NewFromInt(100),
+			},
+		},
+		{
+			name: "discount above subtotal",
+			input: inputs.CreateInvoiceInput{
+				Items:    []inputs.CreateInvoiceItemInput{item},
+				Discount: money.NewFromInt(150),
+			},
+		},
+		{
+			name: "percentage above 100",
+			input: inputs.CreateInvoiceInput{
+				Items:        []inputs.CreateInvoiceItemInput{item},
+				DiscountType: models.DiscountTypePercentage,
+				Discount:     money.NewFromInt(120),
+			},
+		},
+		{
+			name: "zero quantity",
+			input: inputs.CreateInvoiceInput{
+				Items: []inputs.CreateInvoiceItemInput{{Description: "Item", Quantity: 0, UnitPrice: money.NewFromInt(50)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			userID := uuid.New()
+			tt.input.CustomerID = customerID
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+
+			invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, tt.input)
+
+			assert.Error(t, err)
+			assert.Nil(t, invoice)
+			mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+		})
+	}
+}
+
+// Every line within its own bounds must not add up past what money.Decimal
+// holds: the totals are rejected as invalid input instead of panicking.
+func TestCreateInvoice_TotalsBounded(t *testing.T) {
+	maximal := inputs.CreateInvoiceItemInput{Description: "Item", Quantity: 1_000_000, UnitPrice: money.NewFromInt(100_000_000)}
+	large := inputs.CreateInvoiceItemInput{Description: "Item", Quantity: 1_000_000, UnitPrice: money.NewFromInt(1_000_000)}
+
+	tests := []struct {
+		name  string
+		items []inputs.CreateInvoiceItemInput
+		err   string
+	}{
+		{"many maximal lines", repeatItem(maximal, 20), "subtotal must not exceed 10000000000000"},
+		{"too many lines", repeatItem(large, 501), "an invoice can have at most 500 items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			userID := uuid.New()
+			customerID := uuid.New()
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+
+			var invoice *models.Invoice
+			var err error
+			assert.NotPanics(t, func() {
+				invoice, err = svc.CreateInvoice(auth.Principal{UserID: userID}, inputs.CreateInvoiceInput{
+					CustomerID: customerID,
+					Items:      tt.items,
+				})
+			})
+
+			assert.EqualError(t, err, tt.err)
+			assert.Nil(t, invoice)
+			mockRepo.AssertNotCalled(t, "WithTx", mock.Anything)
+		})
+	}
+}
+
+// A discount spread over lines near the limit does not overflow.
+func TestCreateInvoice_LargeDiscount(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+
+	item := inputs.CreateInvoiceItemInput{Description: "Item", Quantity: 50_000, UnitPrice: money.NewFromInt(100_000_000)}
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, inputs.CreateInvoiceInput{
+		CustomerID: customerID,
+		Items:      repeatItem(item, 2),
+		Discount:   money.NewFromInt(1_000_000_000_000),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "9000000000000", invoice.TotalAmount.String())
+}
+
+// Full percentages on the largest subtotal fit even though the amount times
+// the percentage does not.
+func TestCreateInvoice_FullPercentagesOnMaximalSubtotal(t *testing.T) {
+	userID := uuid.New()
+	tax := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "Tax", Rate: money.NewFromInt(100), Active: true}
+	// 500 lines of the largest quantity add up to exactly the largest subtotal
+	item := inputs.CreateInvoiceItemInput{Description: "Item", Quantity: 1_000_000, UnitPrice: money.NewFromInt(20_000)}
+
+	tests := []struct {
+		name      string
+		discount  money.Decimal
+		taxes     []uuid.UUID
+		wantTax   string
+		wantTotal string
+	}{
+		{"100% discount", money.NewFromInt(100), nil, "0", "0"},
+		{"100% tax", money.Zero, []uuid.UUID{tax.ID}, "10000000000000", "20000000000000"},
+		{"100% discount and 100% tax", money.NewFromInt(100), []uuid.UUID{tax.ID}, "0", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			customerID := uuid.New()
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+			mockRepo.On("GetTaxRates", map[string]interface{}{"user_id": userID}).Return([]models.TaxRate{tax}, nil)
+			expectTx(mockRepo)
+			mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+			mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+			mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+			mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+			expectRevision(mockRepo)
+
+			item := item
+			item.TaxRateIDs = tt.taxes
+			var invoice *models.Invoice
+			var err error
+			require.NotPanics(t, func() {
+				invoice, err = svc.CreateInvoice(auth.Principal{UserID: userID}, inputs.CreateInvoiceInput{
+					CustomerID:   customerID,
+					DiscountType: models.DiscountTypePercentage,
+					Discount:     tt.discount,
+					Items:        repeatItem(item, 500),
+				})
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, "10000000000000", invoice.SubTotal.String())
+			assert.Equal(t, tt.wantTax, invoice.TaxTotal.String())
+			assert.Equal(t, tt.wantTotal, invoice.TotalAmount.String())
+		})
+	}
+}
+
+func repeatItem(item inputs.CreateInvoiceItemInput, n int) []inputs.CreateInvoiceItemInput {
+	items := make([]inputs.CreateInvoiceItemInput, n)
+	for i := range items {
+		items[i] = item
+	}
+	return items
+}
+
+func TestCreateInvoice_Taxes(t *testing.T) {
+	userID := uuid.New()
+	vat := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "VAT", Rate: money.NewFromInt(20), Active: true}
+	gst := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "GST", Rate: money.NewFromInt(5), Active: true}
+	pst := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "PST", Rate: money.NewFromInt(7), Active: true}
+	qst := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "QST", Rate: money.MustParse("9.975"), Compound: true, Active: true}
+	rates := []models.TaxRate{vat, gst, pst, qst}
+
+	tests := []struct {
+		name          string
+		reverseCharge bool
+		inclusive     bool
+		discount      money.Decimal
+		taxes         []uuid.UUID
+		wantTax       string
+		wantTotal     string
+		wantBreakdown map[string]string
+	}{
+		{
+			name:          "exclusive VAT",
+			taxes:         []uuid.UUID{vat.ID},
+			wantTax:       "20",
+			wantTotal:     "120",
+			wantBreakdown: map[string]string{"VAT": "20"},
+		},
+		{
+			name:          "GST and PST",
+			taxes:         []uuid.UUID{gst.ID, pst.ID},
+			wantTax:       "12",
+			wantTotal:     "112",
+			wantBreakdown: map[string]string{"GST": "5", "PST": "7"},
+		},
+		{
+			name: "compound QST listed first still applies after GST",
+			// 100 * 5% = 5, (100 + 5) * 9.975% = 10.47375
+			taxes:         []uuid.UUID{qst.ID, gst.ID},
+			wantTax:       "15.47",
+			wantTotal:     "115.47",
+			wantBreakdown: map[string]string{"GST": "5", "QST": "10.47"},
+		},
+		{
+			name:          "inclusive VAT",
+			inclusive:     true,
+			taxes:         []uuid.UUID{vat.ID},
+			wantTax:       "16.67",
+			wantTotal:     "100",
+			wantBreakdown: map[string]string{"VAT": "16.67"},
+		},
+		{
+			name:          "discount reduces the taxable amount",
+			discount:      money.NewFromInt(10),
+			taxes:         []uuid.UUID{vat.ID},
+			wantTax:       "18",
+			wantTotal:     "108",
+			wantBreakdown: map[string]string{"VAT": "18"},
+		},
+		{
+			name:          "reverse charge customer",
+			reverseCharge: true,
+			taxes:         []uuid.UUID{vat.ID},
+			wantTax:       "0",
+			wantTotal:     "100",
+			wantBreakdown: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			customerID := uuid.New()
+			input := inputs.CreateInvoiceInput{
+				CustomerID:       customerID,
+				Currency:         "EUR",
+				Discount:         tt.discount,
+				PricesIncludeTax: tt.inclusive,
+				Items: []inputs.CreateInvoiceItemInput{
+					{Description: "Consulting", Quantity: 2, UnitPrice: money.NewFromInt(50), TaxRateIDs: tt.taxes},
+				},
+			}
+
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID, ReverseCharge: tt.reverseCharge}, nil)
+			mockRepo.On("GetTaxRates", map[string]interface{}{"user_id": userID}).Return(rates, nil)
+			expectTx(mockRepo)
+			mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+			mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+			mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+			mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+			expectRevision(mockRepo)
+
+			invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTax, invoice.TaxTotal.String())
+			assert.Equal(t, tt.wantTotal, invoice.TotalAmount.String())
+			assert.Equal(t, tt.reverseCharge, invoice.ReverseCharge)
+			breakdown := map[string]string{}
+			for _, tax := range invoice.Taxes {
+				breakdown[tax.Name] = tax.Amount.String()
+			}
+			assert.Equal(t, tt.wantBreakdown, breakdown)
+			assert.Len(t, invoice.Items[0].Taxes, len(tt.taxes))
+		})
+	}
+}
+
+func TestCreateInvoice_UnknownTaxRate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	inactive := models.TaxRate{ID: uuid.New(), UserID: userID, Name: "Old VAT", Rate: money.NewFromInt(19)}
+
+	input := inputs.CreateInvoiceInput{
+		CustomerID: customerID,
+		Currency:   "EUR",
+		Items: []inputs.CreateInvoiceItemInput{
+			{Description: "Item", Quantity: 1, UnitPrice: money.NewFromInt(10), TaxRateIDs: []uuid.UUID{inactive.ID}},
+		},
+	}
+
+	mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+	mockRepo.On("GetTaxRates", map[string]interface{}{"user_id": userID}).Return([]models.TaxRate{inactive}, nil)
+
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
+
+	assert.Error(t, err)
+	assert.Nil(t, invoice)
+	mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+}
+
 func TestCreateInvoice_CustomerNotFound(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	svc := service.NewService(mockRepo)
 
+	userID := uuid.New()
 	input := inputs.CreateInvoiceInput{
 		CustomerID: uuid.New(),
 	}
 
 	mockRepo.On("GetCustomerByID", input.CustomerID).Return(nil, errors.New("not found"))
 
-	invoice, err := svc.CreateInvoice(input)
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
 
 	assert.Error(t, err)
 	assert.Nil(t, invoice)
@@ -79,18 +524,78 @@ func TestCreateInvoice_CustomerNotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetInvoiceByID(t *testing.T) {
+func TestCreateInvoice_RollsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(txRepo *mocks.Repository)
+	}{
+		{
+			name: "item fails",
+			expect: func(txRepo *mocks.Repository) {
+				txRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil).Once()
+				txRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(errors.New("insert failed")).Once()
+			},
+		},
+		{
+			name: "activity log fails",
+			expect: func(txRepo *mocks.Repository) {
+				txRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+				txRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(errors.New("insert failed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			txRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			customerID := uuid.New()
+			userID := uuid.New()
+			input := inputs.CreateInvoiceInput{
+				CustomerID: customerID,
+				Currency:   "USD",
+				Items: []inputs.CreateInvoiceItemInput{
+					{Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100)},
+					{Description: "Build", Quantity: 2, UnitPrice: money.NewFromInt(50)},
+				},
+			}
+
+			mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+			mockRepo.On("WithTx", mock.Anything).Return(func(fn func(repo repository.Repository) error) error {
+				return fn(txRepo)
+			})
+			txRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+			tt.expect(txRepo)
+
+			invoice, err := svc.CreateInvoice(auth.Principal{UserID: userID}, input)
+
+			assert.Error(t, err)
+			assert.Nil(t, invoice)
+			// Every write goes through the transaction, so none survives it
+			mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+			mockRepo.AssertNotCalled(t, "CreateInvoiceItem", mock.Anything)
+			mockRepo.AssertNotCalled(t, "CreateActivityLog", mock.Anything)
+			txRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetInvoice(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	svc := service.NewService(mockRepo)
 
 	invoiceID := uuid.New()
+	userID := uuid.New()
 	expected := &models.Invoice{
-		ID: invoiceID,
+		ID:     invoiceID,
+		UserID: userID,
 	}
 
 	mockRepo.On("GetInvoiceByID", invoiceID).Return(expected, nil)
 
-	invoice, err := svc.GetInvoiceByID(invoiceID)
+	invoice, err := svc.GetInvoice(auth.Principal{UserID: userID}, invoiceID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, invoice)
@@ -104,26 +609,307 @@ func TestUpdateInvoice(t *testing.T) {
 	invoiceID := uuid.New()
 	userID := uuid.New()
 	existingInvoice := &models.Invoice{
-		ID:     invoiceID,
-		UserID: userID,
-		Status: "pending",
+		ID:       invoiceID,
+		UserID:   userID,
+		Status:   models.InvoiceStatusDraft,
+		Currency: "USD",
+		Items: []models.InvoiceItem{
+			{ID: uuid.New(), InvoiceID: invoiceID, Quantity: 1, UnitPrice: money.NewFromInt(100)},
+		},
 	}
 
-	updates := map[string]interface{}{
-		"status": "paid",
-		"note":   "Payment received",
+	note := "Payment within 14 days"
+	dueDate := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	input := inputs.UpdateInvoiceInput{
+		Note:    &note,
+		DueDate: &dueDate,
 	}
 
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(existingInvoice, nil)
 	mockRepo.On("GetInvoiceByID", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("UpdateInvoiceItem", mock.Anything, mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("ReplaceInvoiceItemTaxes", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("ReplaceInvoiceTaxes", invoiceID, mock.Anything).Return(nil)
 	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
 	mockRepo.On("UpdateInvoice", invoiceID, mock.AnythingOfType("*models.Invoice")).Return(nil)
+	expectRevision(mockRepo)
+
+	invoice, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, note, invoice.Note)
+	assert.Equal(t, dueDate, invoice.DueDate)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInvoice_RecalculatesTotals(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoiceID := uuid.New()
+	itemID := uuid.New()
+	userID := uuid.New()
+	existingInvoice := &models.Invoice{
+		ID:           invoiceID,
+		UserID:       userID,
+		Status:       models.InvoiceStatusDraft,
+		DiscountType: models.DiscountTypeFixed,
+		Currency:     "USD",
+		SubTotal:     money.NewFromInt(999),
+		TotalAmount:  money.NewFromInt(999),
+		Items: []models.InvoiceItem{
+			{ID: itemID, InvoiceID: invoiceID, Quantity: 4, UnitPrice: money.NewFromInt(25), Amount: money.NewFromInt(999)},
+		},
+	}
+
+	discountType := models.DiscountTypePercentage
+	discount := money.NewFromInt(25)
+	input := inputs.UpdateInvoiceInput{
+		DiscountType: &discountType,
+		Discount:     &discount,
+	}
 
-	err := svc.UpdateInvoice(invoiceID, updates)
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("GetInvoiceByID", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("UpdateInvoiceItem", itemID, mock.MatchedBy(func(item *models.InvoiceItem) bool {
+		return item.Amount.Equal(money.NewFromInt(100))
+	})).Return(nil)
+	mockRepo.On("ReplaceInvoiceItemTaxes", itemID, mock.Anything).Return(nil)
+	mockRepo.On("ReplaceInvoiceTaxes", invoiceID, mock.Anything).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("UpdateInvoice", invoiceID, mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.SubTotal.Equal(money.NewFromInt(100)) &&
+			invoice.Discount.Equal(money.NewFromInt(25)) &&
+			invoice.TotalAmount.Equal(money.NewFromInt(75))
+	})).Return(nil)
+	expectRevision(mockRepo)
+
+	_, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, input)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateInvoice_Items(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoiceID := uuid.New()
+	userID := uuid.New()
+	customerID := uuid.New()
+	kept, dropped := uuid.New(), uuid.New()
+	existingInvoice := &models.Invoice{
+		ID:       invoiceID,
+		UserID:   userID,
+		Status:   models.InvoiceStatusDraft,
+		Currency: "USD",
+		Items: []models.InvoiceItem{
+			{ID: dropped, InvoiceID: invoiceID, Description: "Hosting", Quantity: 1, UnitPrice: money.NewFromInt(40)},
+			{ID: kept, InvoiceID: invoiceID, Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100)},
+		},
+	}
+
+	currency := "EUR"
+	input := inputs.UpdateInvoiceInput{
+		CustomerID: &customerID,
+		Currency:   &currency,
+		Items: []inputs.UpdateInvoiceItemInput{
+			{Description: "Build", Quantity: 3, UnitPrice: money.NewFromInt(50)},
+			{ID: &kept, Description: "Design review", Quantity: 2, UnitPrice: money.NewFromInt(100)},
+		},
+	}
+
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("GetInvoiceByID", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+	mockRepo.On("DeleteInvoiceItem", dropped).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.MatchedBy(func(item *models.InvoiceItem) bool {
+		return item.Description == "Build" && item.Position == 0 && item.Amount.Equal(money.NewFromInt(150))
+	})).Return(nil)
+	mockRepo.On("UpdateInvoiceItem", kept, mock.MatchedBy(func(item *models.InvoiceItem) bool {
+		return item.Description == "Design review" && item.Position == 1 && item.Amount.Equal(money.NewFromInt(200))
+	})).Return(nil)
+	mockRepo.On("ReplaceInvoiceItemTaxes", kept, mock.Anything).Return(nil)
+	mockRepo.On("ReplaceInvoiceTaxes", invoiceID, mock.Anything).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("UpdateInvoice", invoiceID, mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.CustomerID == customerID &&
+			invoice.Currency == "EUR" &&
+			invoice.TotalAmount.Equal(money.NewFromInt(350))
+	})).Return(nil)
+	expectRevision(mockRepo)
+
+	invoice, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, input)
+
+	assert.NoError(t, err)
+	assert.Len(t, invoice.Items, 2)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInvoice_RejectsInvalidItems(t *testing.T) {
+	invoiceID := uuid.New()
+	itemID := uuid.New()
+	foreign := uuid.New()
+
+	tests := []struct {
+		name  string
+		items []inputs.UpdateInvoiceItemInput
+		err   string
+	}{
+		{
+			name:  "no items",
+			items: []inputs.UpdateInvoiceItemInput{},
+			err:   "invoice must have at least one item",
+		},
+		{
+			name: "line from another invoice",
+			items: []inputs.UpdateInvoiceItemInput{
+				{ID: &foreign, Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100)},
+			},
+			err: "item 1: not a line on this invoice",
+		},
+		{
+			name: "line listed twice",
+			items: []inputs.UpdateInvoiceItemInput{
+				{ID: &itemID, Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100)},
+				{ID: &itemID, Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100)},
+			},
+			err: "item 2: line is listed more than once",
+		},
+		{
+			name: "zero quantity",
+			items: []inputs.UpdateInvoiceItemInput{
+				{ID: &itemID, Description: "Design", Quantity: 0, UnitPrice: money.NewFromInt(100)},
+			},
+			err: "item 1: quantity must be between 1 and 1000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			userID := uuid.New()
+			expectTx(mockRepo)
+			mockRepo.On("LockInvoice", invoiceID).Return(&models.Invoice{
+				ID:       invoiceID,
+				UserID:   userID,
+				Status:   models.InvoiceStatusDraft,
+				Currency: "USD",
+				Items: []models.InvoiceItem{
+					{ID: itemID, InvoiceID: invoiceID, Quantity: 1, UnitPrice: money.NewFromInt(100)},
+				},
+			}, nil)
+
+			_, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, inputs.UpdateInvoiceInput{Items: tt.items})
+
+			assert.EqualError(t, err, tt.err)
+			mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateInvoice_Locked(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoiceID := uuid.New()
+	userID := uuid.New()
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(&models.Invoice{
+		ID:     invoiceID,
+		UserID: userID,
+		Status: models.InvoiceStatusSent,
+	}, nil)
+
+	note := "Changed after sending"
+	_, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, inputs.UpdateInvoiceInput{Note: &note})
+
+	assert.ErrorIs(t, err, service.ErrInvoiceLocked)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+}
+
+func TestUpdateInvoice_StatusTransition(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoiceID := uuid.New()
+	userID := uuid.New()
+	existingInvoice := &models.Invoice{
+		ID:       invoiceID,
+		UserID:   userID,
+		Currency: "USD",
+		Status:   models.InvoiceStatusDraft,
+	}
+
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("GetInvoiceByID", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_SENT"
+	})).Return(nil).Once()
+	// Issuing the draft takes the next number in the same transaction
+	expectNumbering(mockRepo, invoiceID, "INV-2026-00007")
+	mockRepo.On("UpdateInvoice", invoiceID, mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.Status == models.InvoiceStatusSent && invoice.SentAt != nil
+	})).Return(nil)
+	expectRevision(mockRepo)
+
+	status := models.InvoiceStatusSent
+	invoice, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, inputs.UpdateInvoiceInput{Status: &status})
+
+	require.NoError(t, err)
+	assert.Equal(t, "INV-2026-00007", invoice.InvoiceNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInvoice_InvalidStatusTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{models.InvoiceStatusPaid, models.InvoiceStatusSent},
+		{models.InvoiceStatusPaid, models.InvoiceStatusPending},
+		{models.InvoiceStatusVoid, models.InvoiceStatusPaid},
+		{models.InvoiceStatusDraft, models.InvoiceStatusPaid},
+		{models.InvoiceStatusPartiallyPaid, models.InvoiceStatusVoid},
+		// Only recorded payments make an invoice paid or partially paid
+		{models.InvoiceStatusSent, models.InvoiceStatusPaid},
+		{models.InvoiceStatusViewed, models.InvoiceStatusPartiallyPaid},
+		{models.InvoiceStatusOverdue, models.InvoiceStatusPaid},
+		{models.InvoiceStatusPartiallyPaid, models.InvoiceStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoiceID := uuid.New()
+			userID := uuid.New()
+			expectTx(mockRepo)
+			mockRepo.On("LockInvoice", invoiceID).Return(&models.Invoice{ID: invoiceID, UserID: userID, Status: tt.from}, nil)
+
+			status := tt.to
+			_, err := svc.UpdateInvoice(auth.Principal{UserID: userID}, invoiceID, inputs.UpdateInvoiceInput{Status: &status})
+
+			var transitionErr *service.InvalidTransitionError
+			if tt.to == models.InvoiceStatusPending {
+				assert.ErrorIs(t, err, service.ErrInvalidStatus)
+			} else {
+				assert.ErrorAs(t, err, &transitionErr)
+				assert.Equal(t, tt.from, transitionErr.From)
+				assert.Equal(t, tt.to, transitionErr.To)
+			}
+			mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestDeleteInvoice(t *testing.T) {
 	mockRepo := new(mocks.Repository)
 	svc := service.NewService(mockRepo)
@@ -133,14 +919,168 @@ func TestDeleteInvoice(t *testing.T) {
 	existingInvoice := &models.Invoice{
 		ID:     invoiceID,
 		UserID: userID,
+		Status: models.InvoiceStatusDraft,
 	}
 
+	var calls []string
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(existingInvoice, nil)
 	mockRepo.On("GetInvoiceByID", invoiceID).Return(existingInvoice, nil)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoiceID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(r *models.InvoiceRevision) bool {
+		return r.InvoiceID == invoiceID && r.Action == models.ActivityInvoiceDeleted && *r.ActorID == userID
+	})).Run(func(args mock.Arguments) { calls = append(calls, "revision") }).Return(nil)
+	mockRepo.On("DeleteInvoice", invoiceID).Run(func(args mock.Arguments) { calls = append(calls, "delete") }).Return(nil)
 	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
-	mockRepo.On("DeleteInvoice", invoiceID).Return(nil)
 
-	err := svc.DeleteInvoice(invoiceID)
+	err := svc.DeleteInvoice(auth.Principal{UserID: userID}, invoiceID)
 
 	assert.NoError(t, err)
+	// The deleted draft can no longer be read, so its revision comes first
+	assert.Equal(t, []string{"revision", "delete"}, calls)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeleteInvoice_Issued(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoiceID := uuid.New()
+	userID := uuid.New()
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(&models.Invoice{
+		ID:     invoiceID,
+		UserID: userID,
+		Status: models.InvoiceStatusSent,
+	}, nil)
+
+	err := svc.DeleteInvoice(auth.Principal{UserID: userID}, invoiceID)
+
+	assert.ErrorIs(t, err, service.ErrInvoiceIssued)
+	mockRepo.AssertNotCalled(t, "DeleteInvoice", mock.Anything)
+}
+
+func TestDeleteInvoice_NumberedDraft(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	// A draft whose email failed keeps the number it went out with; deleting
+	// it would leave a gap in the sequence
+	invoiceID := uuid.New()
+	userID := uuid.New()
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoiceID).Return(&models.Invoice{
+		ID:            invoiceID,
+		UserID:        userID,
+		InvoiceNumber: "INV-2026-00004",
+		Status:        models.InvoiceStatusDraft,
+	}, nil)
+
+	err := svc.DeleteInvoice(auth.Principal{UserID: userID}, invoiceID)
+
+	assert.ErrorIs(t, err, service.ErrInvoiceNumbered)
+	mockRepo.AssertNotCalled(t, "DeleteInvoice", mock.Anything)
+}
+
+func TestRestoreInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customerID := uuid.New()
+	deletedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deleted := &models.Invoice{
+		ID:         uuid.New(),
+		UserID:     userID,
+		CustomerID: customerID,
+		Status:     models.InvoiceStatusDraft,
+		DeletedAt:  gorm.DeletedAt{Time: deletedAt, Valid: true},
+	}
+	restored := *deleted
+	restored.DeletedAt = gorm.DeletedAt{}
+
+	mockRepo.On("GetDeletedInvoiceByID", deleted.ID).Return(deleted, nil)
+	mockRepo.On("GetCustomerByID", customerID).Return(&models.Customer{ID: customerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("RestoreInvoice", deleted.ID, deletedAt).Return(nil)
+	mockRepo.On("GetInvoiceByID", deleted.ID).Return(&restored, nil)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", deleted.ID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(r *models.InvoiceRevision) bool {
+		return r.InvoiceID == deleted.ID && r.Action == models.ActivityInvoiceRestored && *r.ActorID == userID
+	})).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(l *models.ActivityLog) bool {
+		return l.Action == "INVOICE_RESTORED"
+	})).Return(nil)
+
+	invoice, err := svc.RestoreInvoice(auth.Principal{UserID: userID}, deleted.ID)
+
+	assert.NoError(t, err)
+	assert.False(t, invoice.DeletedAt.Valid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreInvoice_OtherUser(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	deleted := &models.Invoice{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+	}
+	mockRepo.On("GetDeletedInvoiceByID", deleted.ID).Return(deleted, nil)
+
+	_, err := svc.RestoreInvoice(auth.Principal{UserID: uuid.New()}, deleted.ID)
+
+	assert.ErrorIs(t, err, service.ErrInvoiceNotFound)
+	mockRepo.AssertNotCalled(t, "RestoreInvoice", mock.Anything, mock.Anything)
+}
+
+func TestVoidInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	existing := &models.Invoice{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.InvoiceStatusSent,
+	}
+
+	mockRepo.On("LockInvoice", existing.ID).Return(existing, nil)
+	mockRepo.On("GetInvoiceByID", existing.ID).Return(existing, nil)
+	mockRepo.On("UpdateInvoice", existing.ID, mock.MatchedBy(func(i *models.Invoice) bool {
+		return i.Status == models.InvoiceStatusVoid
+	})).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(l *models.ActivityLog) bool {
+		return l.Action == "INVOICE_VOIDED"
+	})).Return(nil)
+
+	invoice, err := svc.VoidInvoice(auth.Principal{UserID: userID}, existing.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.InvoiceStatusVoid, invoice.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVoidInvoice_Paid(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	existing := &models.Invoice{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.InvoiceStatusPaid,
+	}
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", existing.ID).Return(existing, nil)
+
+	_, err := svc.VoidInvoice(auth.Principal{UserID: userID}, existing.ID)
+
+	var transitionErr *service.InvalidTransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+)
+
+var ErrInvalidStatus = errors.New("invalid invoice status")
+
+// InvalidTransitionError is returned when an invoice cannot move from its
+// current status to the requested one.
+type InvalidTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot change invoice status from %s to %s", e.From, e.To)
+}
+
+// invoiceTransitions lists, for every status, the statuses it may move to.
+// Paid and partially paid are never targets: they follow from the payments
+// recorded, through applyPayments, so an invoice cannot be marked paid without
+// them.
+var invoiceTransitions = map[string][]string{
+	models.InvoiceStatusDraft: {
+		models.InvoiceStatusSent,
+		models.InvoiceStatusVoid,
+	},
+	models.InvoiceStatusSent: {
+		models.InvoiceStatusViewed,
+		models.InvoiceStatusOverdue,
+		models.InvoiceStatusVoid,
+		models.InvoiceStatusWrittenOff,
+	},
+	models.InvoiceStatusViewed: {
+		models.InvoiceStatusOverdue,
+		models.InvoiceStatusVoid,
+		models.InvoiceStatusWrittenOff,
+	},
+	models.InvoiceStatusPartiallyPaid: {
+		models.InvoiceStatusOverdue,
+		models.InvoiceStatusWrittenOff,
+	},
+	models.InvoiceStatusOverdue: {
+		models.InvoiceStatusVoid,
+		models.InvoiceStatusWrittenOff,
+	},
+	models.InvoiceStatusPaid:       {},
+	models.InvoiceStatusVoid:       {},
+	models.InvoiceStatusWrittenOff: {},
+}
+
+// statusActions is the activity log action recorded for entering each status.
+var statusActions = map[string]models.ActivityAction{
+	models.InvoiceStatusSent:          models.ActivityInvoiceSent,
+	models.InvoiceStatusViewed:        models.ActivityInvoiceViewed,
+	models.InvoiceStatusPartiallyPaid: models.ActivityInvoicePartiallyPaid,
+	models.InvoiceStatusPaid:          models.ActivityInvoicePaid,
+	models.InvoiceStatusOverdue:       models.ActivityInvoiceOverdue,
+	models.InvoiceStatusVoid:          models.ActivityInvoiceVoided,
+	models.InvoiceStatusWrittenOff:    models.ActivityInvoiceWrittenOff,
+}
+
+// normalizeStatus maps legacy statuses onto the current lifecycle.
+func normalizeStatus(status string) string {
+	if status == models.InvoiceStatusPending || status == "" {
+		return models.InvoiceStatusSent
+	}
+	return status
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range invoiceTransitions[normalizeStatus(from)] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionInvoice moves an invoice to a new status and stamps the matching
+// timestamp. It returns the activity log action for the change.
+func transitionInvoice(invoice *models.Invoice, to string, at time.Time) (models.ActivityAction, error) {
+	if _, ok := invoiceTransitions[to]; !ok {
+		return "", ErrInvalidStatus
+	}
+	if !canTransition(invoice.Status, to) {
+		return "", &InvalidTransitionError{From: normalizeStatus(invoice.Status), To: to}
+	}
+
+	invoice.Status = to
+	switch to {
+	case models.InvoiceStatusSent:
+		invoice.SentAt = &at
+	case models.InvoiceStatusViewed:
+		if invoice.ViewedAt == nil {
+			invoice.ViewedAt = &at
+		}
+	case models.InvoiceStatusVoid:
+		invoice.VoidedAt = &at
+	case models.InvoiceStatusWrittenOff:
+		invoice.WrittenOffAt = &at
+	}
+
+	return statusActions[to], nil
+}
+
+// isOutstanding reports whether the customer still owes money on an invoice.
+func isOutstanding(status string) bool {
+	switch normalizeStatus(status) {
+	case models.InvoiceStatusSent,
+		models.InvoiceStatusViewed,
+		models.InvoiceStatusPartiallyPaid,
+		models.InvoiceStatusOverdue:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyPayments refreshes an invoice's paid amount and balance from its
+// payments and moves it between unpaid, partially paid and paid to match;
+// credit notes count towards paying it off. These moves follow the money
+// rather than a user's request, so they do not go through invoiceTransitions;
+// a refund may take a paid invoice back to partially paid or unpaid. It
+// returns the activity log action for a status change, or "" when the status
+// stays as it is.
+func applyPayments(invoice *models.Invoice, payments []models.Payment, at time.Time) models.ActivityAction {
+	invoice.AmountPaid = amountPaid(payments)
+	invoice.BalanceDue = amountOwed(invoice).Sub(invoice.AmountPaid)
+
+	current := normalizeStatus(invoice.Status)
+	if !isOutstanding(current) && current != models.InvoiceStatusPaid {
+		return ""
+	}
+
+	// An invoice credited in full is settled even though nothing was paid
+	settled := invoice.AmountPaid.IsPositive() || invoice.CreditTotal.IsPositive()
+
+	var status string
+	switch {
+	case settled && !invoice.BalanceDue.IsPositive():
+		status = models.InvoiceStatusPaid
+	case invoice.AmountPaid.IsPositive():
+		status = models.InvoiceStatusPartiallyPaid
+	case current == models.InvoiceStatusPaid, current == models.InvoiceStatusPartiallyPaid:
+		status = unpaidStatus(invoice, at)
+	default:
+		return ""
+	}
+	if status == current {
+		return ""
+	}
+
+	invoice.Status = status
+	switch status {
+	case models.InvoiceStatusPaid:
+		invoice.PaidAt = &at
+		return statusActions[status]
+	case models.InvoiceStatusPartiallyPaid:
+		invoice.PaidAt = nil
+		return statusActions[status]
+	default:
+		invoice.PaidAt = nil
+		return models.ActivityInvoiceReopened
+	}
+}
+
+// amountOwed is everything charged on an invoice: its total plus the late fees
+// that have not been reversed, less what credit notes have taken off.
+func amountOwed(invoice *models.Invoice) money.Decimal {
+	return invoice.TotalAmount.Add(invoice.LateFeeTotal).Sub(invoice.CreditTotal)
+}
+
+// unpaidStatus is the status an invoice returns to once nothing is paid on it.
+func unpaidStatus(invoice *models.Invoice, at time.Time) string {
+	switch {
+	case invoice.DueDate.Before(at):
+		return models.InvoiceStatusOverdue
+	case invoice.ViewedAt != nil:
+		return models.InvoiceStatusViewed
+	default:
+		return models.InvoiceStatusSent
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+)
+
+// Bounds on each line, on the number of lines and taxes and on the subtotal
+// keep every amount on an invoice inside money.Decimal's range: with at most
+// maxLineTaxes taxes of up to 100% each, the tax on the subtotal is at most
+// 2^maxLineTaxes times it.
+const (
+	maxQuantity  = 1_000_000
+	maxItems     = 500
+	maxLineTaxes = 5
+)
+
+var (
+	maxUnitPrice = money.NewFromInt(100_000_000)
+	maxSubTotal  = money.NewFromInt(10_000_000_000_000)
+	hundred      = money.NewFromInt(100)
+)
+
+// invoicePricing holds the invoice level settings that affect its totals.
+type invoicePricing struct {
+	Currency         string
+	DiscountType     string
+	DiscountValue    money.Decimal
+	PricesIncludeTax bool
+	ReverseCharge    bool
+}
+
+// invoiceLine is the part of a line item that takes part in the totals.
+// ClientAmount is the amount the client sent, zero when it was omitted.
+type invoiceLine struct {
+	Quantity     int
+	UnitPrice    money.Decimal
+	ClientAmount money.Decimal
+	Taxes        []models.InvoiceItemTax
+}
+
+// invoiceTotals holds the amounts derived from an invoice's lines.
+type invoiceTotals struct {
+	Amounts     []money.Decimal
+	TaxAmounts  []money.Decimal
+	SubTotal    money.Decimal
+	Discount    money.Decimal
+	TaxTotal    money.Decimal
+	TotalAmount money.Decimal
+	Taxes       []models.InvoiceTax
+}
+
+// calculateTotals derives every amount on an invoice from its lines.
+//
+// Rounding rules: each line amount is Quantity * UnitPrice rounded half away
+// from zero to the currency's minor unit, the subtotal is the sum of the
+// rounded lines, and a percentage discount is rounded the same way after being
+// applied to the subtotal.
+//
+// Tax is charged on each line after its proportional share of the discount.
+// Non-compound taxes apply to that base, compound taxes to the base plus the
+// taxes before them. With tax inclusive prices the base is first divided by
+// the combined tax factor. Tax is rounded once per rate on the invoice, and
+// the tax total is the sum of those rounded amounts. Reverse charge invoices
+// carry no tax.
+//
+// The total is the subtotal less the discount, plus the tax total unless
+// prices already include it.
+func calculateTotals(pricing invoicePricing, lines []invoiceLine) (*invoiceTotals, error) {
+	currency := pricing.Currency
+	if len(lines) > maxItems {
+		return nil, fmt.Errorf("an invoice can have at most %d items", maxItems)
+	}
+	totals := &invoiceTotals{
+		Amounts:    make([]money.Decimal, len(lines)),
+		TaxAmounts: make([]money.Decimal, len(lines)),
+	}
+
+	for i, line := range lines {
+		if line.Quantity <= 0 || line.Quantity > maxQuantity {
+			return nil, fmt.Errorf("item %d: quantity must be between 1 and %d", i+1, maxQuantity)
+		}
+		if line.UnitPrice.IsNegative() || line.UnitPrice.GreaterThan(maxUnitPrice) {
+			return nil, fmt.Errorf("item %d: unit price must be between 0 and %s", i+1, maxUnitPrice)
+		}
+
+		amount := line.UnitPrice.MulInt(int64(line.Quantity)).RoundCurrency(currency)
+		if !line.ClientAmount.IsZero() && !line.ClientAmount.RoundCurrency(currency).Equal(amount) {
+			return nil, fmt.Errorf("item %d: amount %s does not match quantity x unit price (%s)",
+				i+1, line.ClientAmount, amount.Format(currency))
+		}
+
+		if amount.GreaterThan(maxSubTotal.Sub(totals.SubTotal)) {
+			return nil, fmt.Errorf("subtotal must not exceed %s", maxSubTotal)
+		}
+
+		totals.Amounts[i] = amount
+		totals.SubTotal = totals.SubTotal.Add(amount)
+	}
+
+	switch pricing.DiscountType {
+	case "", models.DiscountTypeFixed:
+		if pricing.DiscountValue.IsNegative() || pricing.DiscountValue.GreaterThan(totals.SubTotal) {
+			return nil, errors.New("discount must be between zero and the subtotal")
+		}
+		totals.Discount = pricing.DiscountValue.RoundCurrency(currency)
+	case models.DiscountTypePercentage:
+		if pricing.DiscountValue.IsNegative() || pricing.DiscountValue.GreaterThan(hundred) {
+			return nil, errors.New("discount percentage must be between 0 and 100")
+		}
+		totals.Discount = totals.SubTotal.Percent(pricing.DiscountValue).RoundCurrency(currency)
+	default:
+		return nil, fmt.Errorf("unknown discount type %q", pricing.DiscountType)
+	}
+
+	if err := calculateTaxes(pricing, lines, totals); err != nil {
+		return nil, err
+	}
+
+	totals.TotalAmount = totals.SubTotal.Sub(totals.Discount)
+	if !pricing.PricesIncludeTax {
+		totals.TotalAmount = totals.TotalAmount.Add(totals.TaxTotal)
+	}
+
+	return totals, nil
+}
+
+// calculateTaxes fills in the per line tax amounts and the per rate breakdown.
+func calculateTaxes(pricing invoicePricing, lines []invoiceLine, totals *invoiceTotals) error {
+	if pricing.ReverseCharge {
+		return nil
+	}
+
+	breakdown := make(map[uuid.UUID]*models.InvoiceTax)
+	var order []uuid.UUID
+	remainingDiscount := totals.Discount
+
+	for i, line := range lines {
+		// Spread the discount over the lines by amount; the last line takes
+		// whatever is left so the shares add up exactly.
+		share := remainingDiscount
+		if i < len(lines)-1 && !totals.SubTotal.IsZero() {
+			share = totals.Discount.MulDiv(totals.Amounts[i], totals.SubTotal)
+		}
+		remainingDiscount = remainingDiscount.Sub(share)
+
+		if len(line.Taxes) == 0 {
+			continue
+		}
+		if len(line.Taxes) > maxLineTaxes {
+			return fmt.Errorf("item %d: at most %d taxes can apply to a line", i+1, maxLineTaxes)
+		}
+		for _, tax := range line.Taxes {
+			if tax.Rate.IsNegative() || tax.Rate.GreaterThan(hundred) {
+				return fmt.Errorf("item %d: tax rate %s must be between 0 and 100", i+1, tax.Name)
+			}
+		}
+
+		simple, compound := splitCompound(line.Taxes)
+		base := totals.Amounts[i].Sub(share)
+
+		if pricing.PricesIncludeTax {
+			factor := money.NewFromInt(1)
+			simpleRate := money.Zero
+			for _, tax := range simple {
+				simpleRate = simpleRate.Add(tax.Rate)
+			}
+			factor = factor.Add(simpleRate.Div(hundred))
+			for _, tax := range compound {
+				factor = factor.Mul(money.NewFromInt(1).Add(tax.Rate.Div(hundred)))
+			}
+			base = base.Div(factor)
+		}
+
+		lineTax := money.Zero
+		running := base
+		apply := func(tax models.InvoiceItemTax, taxable money.Decimal) money.Decimal {
+			amount := taxable.Percent(tax.Rate)
+			entry, ok := breakdown[tax.TaxRateID]
+			if !ok {
+				entry = &models.InvoiceTax{
+					TaxRateID: tax.TaxRateID,
+					Name:      tax.Name,
+					Rate:      tax.Rate,
+					Compound:  tax.Compound,
+				}
+				breakdown[tax.TaxRateID] = entry
+				order = append(order, tax.TaxRateID)
+			}
+			entry.TaxableAmount = entry.TaxableAmount.Add(taxable)
+			entry.Amount = entry.Amount.Add(amount)
+			lineTax = lineTax.Add(amount)
+			return amount
+		}
+
+		for _, tax := range simple {
+			running = running.Add(apply(tax, base))
+		}
+		for _, tax := range compound {
+			running = running.Add(apply(tax, running))
+		}
+
+		totals.TaxAmounts[i] = lineTax.RoundCurrency(pricing.Currency)
+	}
+
+	for _, id := range order {
+		entry := breakdown[id]
+		entry.TaxableAmount = entry.TaxableAmount.RoundCurrency(pricing.Currency)
+		entry.Amount = entry.Amount.RoundCurrency(pricing.Currency)
+		totals.TaxTotal = totals.TaxTotal.Add(entry.Amount)
+		totals.Taxes = append(totals.Taxes, *entry)
+	}
+
+	return nil
+}
+
+// splitCompound separates a line's taxes, keeping their order, so that every
+// non-compound tax is applied before the compound ones.
+func splitCompound(taxes []models.InvoiceItemTax) (simple, compound []models.InvoiceItemTax) {
+	for _, tax := range taxes {
+		if tax.Compound {
+			compound = append(compound, tax)
+		} else {
+			simple = append(simple, tax)
+		}
+	}
+	return simple, compound
+}
+
+// checkClientTotal rejects a client supplied total that disagrees with the
+// computed one. A zero value means the client left it to the server.
+func checkClientTotal(name, currency string, client, computed money.Decimal) error {
+	if !client.IsZero() && !client.RoundCurrency(currency).Equal(computed) {
+		return fmt.Errorf("%s %s does not match the computed value %s", name, client, computed.Format(currency))
+	}
+	return nil
+}
+
+// pricingOf returns the pricing settings stored on an invoice.
+func pricingOf(invoice *models.Invoice) invoicePricing {
+	return invoicePricing{
+		Currency:         invoice.Currency,
+		DiscountType:     invoice.DiscountType,
+		DiscountValue:    invoice.DiscountValue,
+		PricesIncludeTax: invoice.PricesIncludeTax,
+		ReverseCharge:    invoice.ReverseCharge,
+	}
+}
+
+// recalculateInvoice refreshes the stored amounts of an invoice from its items.
+func recalculateInvoice(invoice *models.Invoice) error {
+	lines := make([]invoiceLine, len(invoice.Items))
+	for i, item := range invoice.Items {
+		lines[i] = invoiceLine{Quantity: item.Quantity, UnitPrice: item.UnitPrice, Taxes: item.Taxes}
+	}
+
+	totals, err := calculateTotals(pricingOf(invoice), lines)
+	if err != nil {
+		return err
+	}
+
+	for i := range invoice.Items {
+		invoice.Items[i].Amount = totals.Amounts[i]
+		invoice.Items[i].TaxAmount = totals.TaxAmounts[i]
+	}
+	invoice.SubTotal = totals.SubTotal
+	invoice.Discount = totals.Discount
+	invoice.TaxTotal = totals.TaxTotal
+	invoice.TotalAmount = totals.TotalAmount
+	invoice.BalanceDue = amountOwed(invoice).Sub(invoice.AmountPaid)
+	invoice.Taxes = totals.Taxes
+	for i := range invoice.Taxes {
+		invoice.Taxes[i].InvoiceID = invoice.ID
+	}
+
+	return nil
+}
@@ -0,0 +1,543 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLateFeeNotFound       = errors.New("late fee not found")
+	ErrLateFeePolicyNotFound = errors.New("late fee policy not found")
+	ErrLateFeeReversed       = errors.New("late fee is already reversed")
+)
+
+// Limits on late fee policies and reversals.
+const (
+	maxLateFeeGraceDays   = 365
+	maxReversalReasonSize = 1000
+)
+
+// lateFeeDateLayout is how dates appear in late fee descriptions.
+const lateFeeDateLayout = "2 Jan 2006"
+
+func (s *service) GetLateFeePolicy(principal auth.Principal) (*models.LateFeePolicy, error) {
+	return s.defaultLateFeePolicy(principal.UserID)
+}
+
+// UpdateLateFeePolicy changes the late fee policy for a user's invoices that
+// have no policy of their own.
+func (s *service) UpdateLateFeePolicy(principal auth.Principal, updates map[string]interface{}) error {
+	policy, err := s.defaultLateFeePolicy(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateLateFeePolicy(policy, updates); err != nil {
+		return err
+	}
+
+	return s.repo.SaveLateFeePolicy(policy)
+}
+
+// GetInvoiceLateFeePolicy returns the policy that applies to an invoice: its
+// own if it has one, otherwise the user's default.
+func (s *service) GetInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID) (*models.LateFeePolicy, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.invoiceLateFeePolicy(invoice, nil)
+}
+
+// UpdateInvoiceLateFeePolicy changes the late fee policy of one invoice. The
+// first change starts from a copy of the user's default, so keys that are not
+// given keep the default's values.
+func (s *service) UpdateInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID, updates map[string]interface{}) error {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	policy, err := s.repo.GetLateFeePolicy(principal.UserID, &invoice.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		defaults, err := s.defaultLateFeePolicy(principal.UserID)
+		if err != nil {
+			return err
+		}
+		policy = &models.LateFeePolicy{
+			ID:        uuid.New(),
+			UserID:    principal.UserID,
+			InvoiceID: &invoice.ID,
+			Enabled:   defaults.Enabled,
+			Kind:      defaults.Kind,
+			Rate:      defaults.Rate,
+			GraceDays: defaults.GraceDays,
+			MaxAmount: defaults.MaxAmount,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if err := updateLateFeePolicy(policy, updates); err != nil {
+		return err
+	}
+
+	return s.repo.SaveLateFeePolicy(policy)
+}
+
+// DeleteInvoiceLateFeePolicy removes an invoice's own policy so that the
+// user's default applies to it again.
+func (s *service) DeleteInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID) error {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	policy, err := s.repo.GetLateFeePolicy(principal.UserID, &invoice.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLateFeePolicyNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteLateFeePolicy(policy.ID)
+}
+
+// GetLateFees lists every late fee charged on an invoice, reversed ones
+// included, oldest first.
+func (s *service) GetLateFees(principal auth.Principal, invoiceID uuid.UUID) ([]models.LateFee, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLateFees(map[string]interface{}{
+		"invoice_id": invoiceID,
+	})
+}
+
+// ReverseLateFee waives a late fee. The fee is kept with the reason it was
+// reversed, and the invoice's balance no longer includes it. A reversed
+// period is not charged again.
+func (s *service) ReverseLateFee(principal auth.Principal, input inputs.ReverseLateFeeInput) (*models.LateFee, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if len(reason) > maxReversalReasonSize {
+		return nil, fmt.Errorf("reason must be at most %d characters", maxReversalReasonSize)
+	}
+
+	now := time.Now()
+	var invoice *models.Invoice
+	var fee *models.LateFee
+	var from string
+	var statusAction models.ActivityAction
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, input.InvoiceID)
+		if err != nil {
+			return err
+		}
+
+		fee, err = repo.GetLateFeeByID(input.LateFeeID)
+		if err != nil || fee.InvoiceID != invoice.ID {
+			return ErrLateFeeNotFound
+		}
+		if fee.ReversedAt != nil {
+			return ErrLateFeeReversed
+		}
+		// Once the customer has paid, the money has to go back through a refund
+		if !isOutstanding(invoice.Status) {
+			return fmt.Errorf("cannot reverse a late fee on a %s invoice", invoice.Status)
+		}
+
+		if err := repo.ReverseLateFee(fee.ID, now, reason); err != nil {
+			return err
+		}
+		fee.ReversedAt = &now
+		fee.ReversalReason = reason
+
+		from = invoice.Status
+		statusAction, err = refreshLateFees(repo, invoice, now, &principal, models.ActivityLateFeeReversed)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityLateFeeReversed, models.EntityLateFee, fee.ID, models.ActivityMetadata{
+		OldValues: map[string]interface{}{"amount": fee.Amount.String()},
+		NewValues: map[string]interface{}{"reversal_reason": reason},
+	})
+	if statusAction != "" {
+		s.logStatusChange(&principal, invoice, statusAction, from)
+	}
+
+	return fee, nil
+}
+
+// ChargeLateFees adds the late fees that have come due on unpaid invoices
+// following each invoice's policy, and returns how many fees were charged.
+//
+// Each fee covers a period that is charged at most once, so running this
+// more often than daily, or on several instances, charges nothing twice. An
+// invoice that was missed for a while gets one fee covering the whole gap.
+func (s *service) ChargeLateFees(now time.Time) (int, error) {
+	today := now.UTC().Truncate(dayLength)
+	invoices, err := s.repo.GetOutstandingInvoices(today)
+	if err != nil {
+		return 0, err
+	}
+
+	defaults := make(map[uuid.UUID]*models.LateFeePolicy)
+	charged := 0
+	var errs []error
+	for i := range invoices {
+		invoice := &invoices[i]
+
+		policy, err := s.invoiceLateFeePolicy(invoice, defaults)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", invoice.InvoiceNumber, err))
+			continue
+		}
+		if !policy.Enabled {
+			continue
+		}
+
+		ok, err := s.chargeLateFee(invoice, policy, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", invoice.InvoiceNumber, err))
+			continue
+		}
+		if ok {
+			charged++
+		}
+	}
+
+	return charged, errors.Join(errs...)
+}
+
+// chargeLateFee charges the late fee that is due on an invoice, if any. The
+// period is claimed in the transaction that adds the fee to the invoice, with
+// the invoice locked, so a fee that cannot be added leaves the period to be
+// charged on the next run.
+func (s *service) chargeLateFee(invoice *models.Invoice, policy *models.LateFeePolicy, now time.Time) (bool, error) {
+	var fee *models.LateFee
+	var from string
+	var statusAction models.ActivityAction
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		// The invoice may have been paid since it was listed
+		var err error
+		invoice, err = repo.LockInvoice(invoice.ID)
+		if err != nil {
+			return err
+		}
+		if !isOutstanding(invoice.Status) {
+			return nil
+		}
+
+		fees, err := repo.GetLateFees(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		due := dueLateFee(invoice, policy, fees, now.UTC().Truncate(dayLength))
+		if due == nil {
+			return nil
+		}
+		due.UserID = invoice.UserID
+		due.ChargedAt = now
+
+		claimed, err := repo.ClaimLateFee(due)
+		if err != nil || !claimed {
+			return err
+		}
+		fee = due
+
+		from = invoice.Status
+		statusAction, err = refreshLateFees(repo, invoice, now, nil, models.ActivityLateFeeCharged)
+		return err
+	})
+	if err != nil || fee == nil {
+		return false, err
+	}
+
+	s.logInvoiceEntityActivity(nil, invoice, models.ActivityLateFeeCharged, models.EntityLateFee, fee.ID, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"amount": fee.Amount.String()},
+	})
+	if statusAction != "" {
+		s.logStatusChange(nil, invoice, statusAction, from)
+	}
+
+	return true, nil
+}
+
+// refreshLateFees sums the late fees that stand on an invoice and brings its
+// balance, and with it its payment status, up to date, in the transaction
+// that changed the fees. It returns the activity log action for a status
+// change, or "" when the status stays as it is.
+func refreshLateFees(repo repository.Repository, invoice *models.Invoice, at time.Time, actor *auth.Principal, change models.ActivityAction) (models.ActivityAction, error) {
+	fees, err := repo.GetLateFees(map[string]interface{}{
+		"invoice_id":  invoice.ID,
+		"reversed_at": nil,
+	})
+	if err != nil {
+		return "", err
+	}
+	payments, err := repo.GetPayments(map[string]interface{}{
+		"invoice_id": invoice.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	invoice.LateFees = fees
+	invoice.LateFeeTotal = money.Zero
+	for _, fee := range fees {
+		invoice.LateFeeTotal = invoice.LateFeeTotal.Add(fee.Amount)
+	}
+	action := applyPayments(invoice, payments, at)
+
+	if err := writeInvoice(repo, invoice, actor, change); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// dueLateFee works out the next fee to charge on an invoice as of today, or
+// returns nil when nothing is due. fees holds every fee charged on the
+// invoice so far, reversed ones included.
+//
+// Fees start GraceDays after the first day the invoice is late. Interest is
+// simple interest on the part of the invoice total that is neither paid nor
+// credited, so it is not charged on earlier fees. Daily interest covers whole
+// days up to today and monthly interest whole months counted from the first
+// late day. One fee charges at most as many periods as it takes for the
+// interest to reach the unpaid amount; later runs charge the rest.
+func dueLateFee(invoice *models.Invoice, policy *models.LateFeePolicy, fees []models.LateFee, today time.Time) *models.LateFee {
+	// An invoice due on a day is late from the start of the next one
+	start := invoice.DueDate.UTC().Truncate(dayLength).AddDate(0, 0, 1+policy.GraceDays)
+	if today.Before(start) {
+		return nil
+	}
+
+	unpaid := invoice.TotalAmount.Sub(invoice.CreditTotal).Sub(invoice.AmountPaid)
+	if !unpaid.IsPositive() {
+		return nil
+	}
+
+	charged := money.Zero
+	from := start
+	oneOff := false
+	for _, fee := range fees {
+		if fee.ReversedAt == nil {
+			charged = charged.Add(fee.Amount)
+		}
+		if fee.PeriodEnd.After(from) {
+			from = fee.PeriodEnd
+		}
+		if fee.Kind == models.LateFeeFlat || fee.Kind == models.LateFeePercentage {
+			oneOff = true
+		}
+	}
+
+	currency := invoice.Currency
+	fee := &models.LateFee{
+		InvoiceID:   invoice.ID,
+		Kind:        policy.Kind,
+		Rate:        policy.Rate,
+		PeriodStart: from,
+	}
+
+	switch policy.Kind {
+	case models.LateFeeFlat, models.LateFeePercentage:
+		if oneOff {
+			return nil
+		}
+		fee.PeriodEnd = from.AddDate(0, 0, 1)
+		if policy.Kind == models.LateFeeFlat {
+			fee.Amount = policy.Rate
+			fee.Description = "Late fee"
+		} else {
+			fee.Amount = unpaid.Percent(policy.Rate)
+			fee.Description = fmt.Sprintf("Late fee (%s%% of %s)", policy.Rate, unpaid.Format(currency))
+		}
+	case models.LateFeeDailyInterest:
+		days := int(today.Sub(from) / dayLength)
+		if days < 1 {
+			return nil
+		}
+		days = interestPeriods(days, policy.Rate)
+		fee.PeriodEnd = from.AddDate(0, 0, days)
+		fee.Amount = unpaid.MulDiv(policy.Rate.MulInt(int64(days)), hundred)
+		fee.Description = fmt.Sprintf("Interest at %s%% a day on %s, %s",
+			policy.Rate, unpaid.Format(currency), periodLabel(from, fee.PeriodEnd))
+	case models.LateFeeMonthlyInterest:
+		past := wholeMonths(start, from)
+		months := wholeMonths(start, today) - past
+		if months < 1 {
+			return nil
+		}
+		months = interestPeriods(months, policy.Rate)
+		fee.PeriodEnd = addMonthsClamped(start, past+months)
+		fee.Amount = unpaid.MulDiv(policy.Rate.MulInt(int64(months)), hundred)
+		fee.Description = fmt.Sprintf("Interest at %s%% a month on %s, %s",
+			policy.Rate, unpaid.Format(currency), periodLabel(from, fee.PeriodEnd))
+	default:
+		return nil
+	}
+
+	fee.Amount = fee.Amount.RoundCurrency(currency)
+	if policy.MaxAmount.IsPositive() {
+		fee.Amount = fee.Amount.Min(policy.MaxAmount.Sub(charged))
+	}
+	// Interest too small to charge yet carries over to the next run
+	if !fee.Amount.IsPositive() {
+		return nil
+	}
+
+	return fee
+}
+
+// interestPeriods caps periods at the number whose interest at rate percent
+// a period adds up to no more than the amount it is charged on, which keeps
+// a long overdue, large balance inside money.Decimal's range.
+func interestPeriods(periods int, rate money.Decimal) int {
+	limit := int(hundred.Div(rate).Float64())
+	if limit < 1 {
+		limit = 1
+	}
+	return min(periods, limit)
+}
+
+// wholeMonths counts the complete months from from to to.
+func wholeMonths(from, to time.Time) int {
+	months := monthsBetween(from, to)
+	if months > 0 && addMonthsClamped(from, months).After(to) {
+		months--
+	}
+	return months
+}
+
+// periodLabel describes the days from start up to, but not including, end.
+func periodLabel(start, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	if !last.After(start) {
+		return start.Format(lateFeeDateLayout)
+	}
+	return start.Format(lateFeeDateLayout) + " to " + last.Format(lateFeeDateLayout)
+}
+
+// invoiceLateFeePolicy returns the policy for an invoice: its own, or else
+// its user's default. Defaults are looked up once per user when a cache is
+// given.
+func (s *service) invoiceLateFeePolicy(invoice *models.Invoice, defaults map[uuid.UUID]*models.LateFeePolicy) (*models.LateFeePolicy, error) {
+	policy, err := s.repo.GetLateFeePolicy(invoice.UserID, &invoice.ID)
+	if err == nil {
+		return policy, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if policy, ok := defaults[invoice.UserID]; ok {
+		return policy, nil
+	}
+	policy, err = s.defaultLateFeePolicy(invoice.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults != nil {
+		defaults[invoice.UserID] = policy
+	}
+	return policy, nil
+}
+
+// defaultLateFeePolicy returns a user's default policy. Users who have not
+// set one charge no late fees.
+func (s *service) defaultLateFeePolicy(userID uuid.UUID) (*models.LateFeePolicy, error) {
+	policy, err := s.repo.GetLateFeePolicy(userID, nil)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.LateFeePolicy{
+			UserID: userID,
+			Kind:   models.LateFeeFlat,
+		}, nil
+	}
+	return policy, err
+}
+
+// updateLateFeePolicy applies the changes in updates to a policy and checks
+// the result.
+func updateLateFeePolicy(policy *models.LateFeePolicy, updates map[string]interface{}) error {
+	for key, value := range updates {
+		switch key {
+		case "enabled":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			policy.Enabled = flag
+		case "kind":
+			kind, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			policy.Kind = kind
+		case "rate", "max_amount":
+			amount, err := decimalValue(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if key == "rate" {
+				policy.Rate = amount
+			} else {
+				policy.MaxAmount = amount
+			}
+		case "grace_days":
+			// 0 starts fees on the first day the invoice is late
+			days, ok := wholeNumber(value, 0)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			policy.GraceDays = int(days)
+		}
+	}
+
+	return validateLateFeePolicy(policy)
+}
+
+func validateLateFeePolicy(policy *models.LateFeePolicy) error {
+	switch policy.Kind {
+	case models.LateFeeFlat:
+		if policy.Rate.GreaterThan(maxUnitPrice) {
+			return fmt.Errorf("late fee must be at most %s", maxUnitPrice)
+		}
+	case models.LateFeePercentage, models.LateFeeDailyInterest, models.LateFeeMonthlyInterest:
+		if policy.Rate.GreaterThan(hundred) {
+			return errors.New("late fee rate must be between 0 and 100")
+		}
+	default:
+		return fmt.Errorf("unknown late fee kind %q", policy.Kind)
+	}
+	if policy.Rate.IsNegative() {
+		return errors.New("late fee rate cannot be negative")
+	}
+	if policy.Enabled && !policy.Rate.IsPositive() {
+		return errors.New("late fee rate must be greater than zero")
+	}
+	if policy.GraceDays < 0 || policy.GraceDays > maxLateFeeGraceDays {
+		return fmt.Errorf("grace days must be between 0 and %d", maxLateFeeGraceDays)
+	}
+	if policy.MaxAmount.IsNegative() {
+		return errors.New("maximum late fees cannot be negative")
+	}
+	return nil
+}
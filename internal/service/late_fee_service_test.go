@@ -0,0 +1,567 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func lateFeePolicy(userID uuid.UUID, kind, rate string) *models.LateFeePolicy {
+	return &models.LateFeePolicy{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Enabled: true,
+		Kind:    kind,
+		Rate:    money.MustParse(rate),
+	}
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestChargeLateFees(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	today := day(2026, time.March, 10)
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		total     string
+		dueDate   time.Time
+		policy    *models.LateFeePolicy
+		paid      string
+		existing  []models.LateFee
+		wantNone  bool
+		wantFee   string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantLabel string
+	}{
+		{
+			name:      "flat fee",
+			dueDate:   day(2026, time.February, 28),
+			policy:    lateFeePolicy(userID, models.LateFeeFlat, "25"),
+			wantFee:   "25",
+			wantStart: day(2026, time.March, 1),
+			wantEnd:   day(2026, time.March, 2),
+			wantLabel: "Late fee",
+		},
+		{
+			name:     "within the grace period",
+			dueDate:  day(2026, time.February, 28),
+			policy:   &models.LateFeePolicy{Enabled: true, Kind: models.LateFeeFlat, Rate: money.MustParse("25"), GraceDays: 14},
+			wantNone: true,
+		},
+		{
+			name:      "percentage of the unpaid amount",
+			dueDate:   day(2026, time.February, 28),
+			policy:    lateFeePolicy(userID, models.LateFeePercentage, "5"),
+			paid:      "200",
+			wantFee:   "40",
+			wantStart: day(2026, time.March, 1),
+			wantEnd:   day(2026, time.March, 2),
+			wantLabel: "Late fee (5% of 800.00)",
+		},
+		{
+			name:    "one off fee already charged and reversed",
+			dueDate: day(2026, time.February, 28),
+			policy:  lateFeePolicy(userID, models.LateFeeFlat, "25"),
+			existing: []models.LateFee{{
+				Kind: models.LateFeeFlat, Amount: money.MustParse("25"),
+				PeriodStart: day(2026, time.March, 1), PeriodEnd: day(2026, time.March, 2),
+				ReversedAt: &now,
+			}},
+			wantNone: true,
+		},
+		{
+			name:      "daily interest",
+			dueDate:   day(2026, time.February, 28),
+			policy:    lateFeePolicy(userID, models.LateFeeDailyInterest, "0.1"),
+			wantFee:   "9",
+			wantStart: day(2026, time.March, 1),
+			wantEnd:   today,
+			wantLabel: "Interest at 0.1% a day on 1000.00, 1 Mar 2026 to 9 Mar 2026",
+		},
+		{
+			name:    "daily interest since the last fee",
+			dueDate: day(2026, time.February, 28),
+			policy:  lateFeePolicy(userID, models.LateFeeDailyInterest, "0.1"),
+			existing: []models.LateFee{{
+				Kind: models.LateFeeDailyInterest, Amount: money.MustParse("7"),
+				PeriodStart: day(2026, time.March, 1), PeriodEnd: day(2026, time.March, 8),
+			}},
+			wantFee:   "2",
+			wantStart: day(2026, time.March, 8),
+			wantEnd:   today,
+			wantLabel: "Interest at 0.1% a day on 1000.00, 8 Mar 2026 to 9 Mar 2026",
+		},
+		{
+			name:    "capped",
+			dueDate: day(2026, time.February, 28),
+			policy: &models.LateFeePolicy{
+				Enabled: true, Kind: models.LateFeeDailyInterest,
+				Rate: money.MustParse("0.1"), MaxAmount: money.MustParse("7.5"),
+			},
+			existing: []models.LateFee{{
+				Kind: models.LateFeeDailyInterest, Amount: money.MustParse("7"),
+				PeriodStart: day(2026, time.March, 1), PeriodEnd: day(2026, time.March, 8),
+			}},
+			wantFee:   "0.5",
+			wantStart: day(2026, time.March, 8),
+			wantEnd:   today,
+			wantLabel: "Interest at 0.1% a day on 1000.00, 8 Mar 2026 to 9 Mar 2026",
+		},
+		{
+			name:    "cap reached",
+			dueDate: day(2026, time.February, 28),
+			policy: &models.LateFeePolicy{
+				Enabled: true, Kind: models.LateFeeDailyInterest,
+				Rate: money.MustParse("0.1"), MaxAmount: money.MustParse("7"),
+			},
+			existing: []models.LateFee{{
+				Kind: models.LateFeeDailyInterest, Amount: money.MustParse("7"),
+				PeriodStart: day(2026, time.March, 1), PeriodEnd: day(2026, time.March, 8),
+			}},
+			wantNone: true,
+		},
+		{
+			name:      "monthly interest for whole months",
+			dueDate:   day(2026, time.January, 15),
+			policy:    lateFeePolicy(userID, models.LateFeeMonthlyInterest, "1.5"),
+			wantFee:   "15",
+			wantStart: day(2026, time.January, 16),
+			wantEnd:   day(2026, time.February, 16),
+			wantLabel: "Interest at 1.5% a month on 1000.00, 16 Jan 2026 to 15 Feb 2026",
+		},
+		{
+			name:    "monthly interest before the month is over",
+			dueDate: day(2026, time.January, 15),
+			policy:  lateFeePolicy(userID, models.LateFeeMonthlyInterest, "1.5"),
+			existing: []models.LateFee{{
+				Kind: models.LateFeeMonthlyInterest, Amount: money.MustParse("15"),
+				PeriodStart: day(2026, time.January, 16), PeriodEnd: day(2026, time.February, 16),
+			}},
+			wantNone: true,
+		},
+		{
+			name:      "daily interest on a large balance overdue for years",
+			total:     "10000000000000",
+			dueDate:   day(2016, time.March, 9),
+			policy:    lateFeePolicy(userID, models.LateFeeDailyInterest, "1"),
+			wantFee:   "10000000000000",
+			wantStart: day(2016, time.March, 10),
+			wantEnd:   day(2016, time.June, 18),
+			wantLabel: "Interest at 1% a day on 10000000000000.00, 10 Mar 2016 to 17 Jun 2016",
+		},
+		{
+			name:      "monthly interest on a large balance overdue for years",
+			total:     "10000000000000",
+			dueDate:   day(2016, time.March, 9),
+			policy:    lateFeePolicy(userID, models.LateFeeMonthlyInterest, "1.5"),
+			wantFee:   "9900000000000",
+			wantStart: day(2016, time.March, 10),
+			wantEnd:   day(2021, time.September, 10),
+			wantLabel: "Interest at 1.5% a month on 10000000000000.00, 10 Mar 2016 to 9 Sep 2021",
+		},
+		{
+			name:    "large balance overdue for years, capped",
+			total:   "10000000000000",
+			dueDate: day(2016, time.March, 9),
+			policy: &models.LateFeePolicy{
+				Enabled: true, Kind: models.LateFeeDailyInterest,
+				Rate: money.MustParse("100"), MaxAmount: money.MustParse("5000"),
+			},
+			wantFee:   "5000",
+			wantStart: day(2016, time.March, 10),
+			wantEnd:   day(2016, time.March, 11),
+			wantLabel: "Interest at 100% a day on 10000000000000.00, 10 Mar 2016",
+		},
+		{
+			name:     "disabled",
+			dueDate:  day(2026, time.February, 28),
+			policy:   &models.LateFeePolicy{Kind: models.LateFeeFlat, Rate: money.MustParse("25")},
+			wantNone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			total := tt.total
+			if total == "" {
+				total = "1000"
+			}
+			invoice := sentInvoice(userID, total)
+			invoice.Status = models.InvoiceStatusOverdue
+			invoice.DueDate = tt.dueDate
+			var payments []models.Payment
+			if tt.paid != "" {
+				invoice.AmountPaid = money.MustParse(tt.paid)
+				payments = []models.Payment{{
+					InvoiceID: invoice.ID, Kind: models.PaymentKindPayment, Amount: money.MustParse(tt.paid),
+				}}
+			}
+
+			mockRepo.On("GetOutstandingInvoices", today).Return([]models.Invoice{*invoice}, nil)
+			mockRepo.On("GetLateFeePolicy", userID, mock.Anything).Return(tt.policy, nil)
+			mockRepo.On("GetLateFees", map[string]interface{}{"invoice_id": invoice.ID}).Return(tt.existing, nil)
+
+			var fee *models.LateFee
+			mockRepo.On("ClaimLateFee", mock.MatchedBy(func(f *models.LateFee) bool {
+				fee = f
+				return true
+			})).Return(true, nil)
+			var standing []models.LateFee
+			for _, existing := range tt.existing {
+				if existing.ReversedAt == nil {
+					standing = append(standing, existing)
+				}
+			}
+			mockRepo.On("GetLateFees", map[string]interface{}{"invoice_id": invoice.ID, "reversed_at": nil}).
+				Return(func(map[string]interface{}) []models.LateFee {
+					return append(standing, *fee)
+				}, nil)
+			mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return(payments, nil)
+			var updated *models.Invoice
+			mockRepo.On("UpdateInvoice", invoice.ID, mock.MatchedBy(func(i *models.Invoice) bool {
+				updated = i
+				return true
+			})).Return(nil)
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+			expectTx(mockRepo)
+			expectRevision(mockRepo)
+			mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+			charged, err := svc.ChargeLateFees(now)
+			require.NoError(t, err)
+
+			if tt.wantNone {
+				assert.Equal(t, 0, charged)
+				assert.Nil(t, fee)
+				mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, 1, charged)
+			require.NotNil(t, fee)
+			assert.Equal(t, invoice.ID, fee.InvoiceID)
+			assert.Equal(t, userID, fee.UserID)
+			assert.Equal(t, tt.policy.Kind, fee.Kind)
+			assert.True(t, money.MustParse(tt.wantFee).Equal(fee.Amount), "fee %s", fee.Amount)
+			assert.Equal(t, tt.wantStart, fee.PeriodStart)
+			assert.Equal(t, tt.wantEnd, fee.PeriodEnd)
+			assert.Equal(t, tt.wantLabel, fee.Description)
+
+			require.NotNil(t, updated)
+			wantTotal := fee.Amount
+			for _, existing := range standing {
+				wantTotal = wantTotal.Add(existing.Amount)
+			}
+			assert.True(t, wantTotal.Equal(updated.LateFeeTotal), "late fee total %s", updated.LateFeeTotal)
+			assert.True(t, updated.TotalAmount.Add(wantTotal).Sub(updated.AmountPaid).Equal(updated.BalanceDue),
+				"balance due %s", updated.BalanceDue)
+			mockRepo.AssertCalled(t, "CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+				return log.Action == models.ActivityLateFeeCharged && log.EntityType == models.EntityLateFee &&
+					*log.EntityID == fee.ID && *log.InvoiceID == updated.ID
+			}))
+		})
+	}
+}
+
+func TestChargeLateFees_UsesDefaultPolicy(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	first := sentInvoice(userID, "100")
+	first.DueDate = day(2026, time.March, 1)
+	second := sentInvoice(userID, "100")
+	second.DueDate = day(2026, time.March, 2)
+	defaults := lateFeePolicy(userID, models.LateFeeFlat, "10")
+	defaults.GraceDays = 30
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*first, *second}, nil)
+	mockRepo.On("GetLateFeePolicy", userID, &first.ID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetLateFeePolicy", userID, &second.ID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetLateFeePolicy", userID, (*uuid.UUID)(nil)).Return(defaults, nil).Once()
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", first.ID).Return(first, nil)
+	mockRepo.On("LockInvoice", second.ID).Return(second, nil)
+	mockRepo.On("GetLateFees", mock.Anything).Return(nil, nil)
+
+	charged, err := svc.ChargeLateFees(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, charged)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "ClaimLateFee", mock.Anything)
+}
+
+func TestChargeLateFees_AlreadyCharged(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	invoice := sentInvoice(uuid.New(), "100")
+	invoice.DueDate = day(2026, time.March, 1)
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetLateFeePolicy", invoice.UserID, &invoice.ID).
+		Return(lateFeePolicy(invoice.UserID, models.LateFeeFlat, "10"), nil)
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetLateFees", mock.Anything).Return(nil, nil)
+	// another instance charged the period first
+	mockRepo.On("ClaimLateFee", mock.AnythingOfType("*models.LateFee")).Return(false, nil)
+
+	charged, err := svc.ChargeLateFees(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, charged)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+}
+
+func TestChargeLateFees_FailureRollsBackClaim(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	invoice := sentInvoice(uuid.New(), "100")
+	invoice.DueDate = day(2026, time.March, 1)
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetLateFeePolicy", invoice.UserID, &invoice.ID).
+		Return(lateFeePolicy(invoice.UserID, models.LateFeeFlat, "10"), nil)
+	// The claim and the invoice update share a transaction, which gives the
+	// period back when it fails
+	var txErr error
+	mockRepo.On("WithTx", mock.Anything).Return(func(fn func(repo repository.Repository) error) error {
+		txErr = fn(mockRepo)
+		return txErr
+	})
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetLateFees", map[string]interface{}{"invoice_id": invoice.ID}).Return(nil, nil)
+	mockRepo.On("ClaimLateFee", mock.AnythingOfType("*models.LateFee")).Return(true, nil)
+	mockRepo.On("GetLateFees", map[string]interface{}{"invoice_id": invoice.ID, "reversed_at": nil}).
+		Return(nil, errors.New("connection reset"))
+
+	charged, err := svc.ChargeLateFees(now)
+
+	require.Error(t, err)
+	assert.EqualError(t, txErr, "connection reset")
+	assert.Equal(t, 0, charged)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateActivityLog", mock.Anything)
+}
+
+func TestChargeLateFees_PaidSinceListed(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	invoice := sentInvoice(uuid.New(), "100")
+	invoice.DueDate = day(2026, time.March, 1)
+	paid := *invoice
+	paid.Status = models.InvoiceStatusPaid
+
+	// A payment came in between listing the invoice and locking it
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetLateFeePolicy", invoice.UserID, &invoice.ID).
+		Return(lateFeePolicy(invoice.UserID, models.LateFeeFlat, "10"), nil)
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoice.ID).Return(&paid, nil)
+
+	charged, err := svc.ChargeLateFees(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, charged)
+	mockRepo.AssertNotCalled(t, "ClaimLateFee", mock.Anything)
+}
+
+func TestReverseLateFee(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoice := sentInvoice(uuid.New(), "1000")
+	invoice.Status = models.InvoiceStatusOverdue
+	invoice.DueDate = time.Now().AddDate(0, 0, -10)
+	invoice.LateFeeTotal = money.MustParse("25")
+	invoice.BalanceDue = money.MustParse("1025")
+	fee := &models.LateFee{
+		ID: uuid.New(), InvoiceID: invoice.ID, UserID: invoice.UserID,
+		Kind: models.LateFeeFlat, Amount: money.MustParse("25"),
+	}
+
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetLateFeeByID", fee.ID).Return(fee, nil)
+	mockRepo.On("ReverseLateFee", fee.ID, mock.AnythingOfType("time.Time"), "agreed with customer").Return(nil)
+	mockRepo.On("GetLateFees", map[string]interface{}{"invoice_id": invoice.ID, "reversed_at": nil}).Return(nil, nil)
+	mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return(nil, nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, mock.MatchedBy(func(i *models.Invoice) bool {
+		return i.LateFeeTotal.IsZero() && i.BalanceDue.Equal(money.MustParse("1000"))
+	})).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == models.ActivityLateFeeReversed && *log.EntityID == fee.ID &&
+			log.Metadata.NewValues["reversal_reason"] == "agreed with customer"
+	})).Return(nil)
+
+	reversed, err := svc.ReverseLateFee(auth.Principal{UserID: invoice.UserID}, inputs.ReverseLateFeeInput{
+		InvoiceID: invoice.ID,
+		LateFeeID: fee.ID,
+		Reason:    "  agreed with customer ",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, reversed.ReversedAt)
+	assert.Equal(t, "agreed with customer", reversed.ReversalReason)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseLateFee_Errors(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		status  string
+		fee     func(invoiceID uuid.UUID) *models.LateFee
+		wantErr error
+	}{
+		{
+			name:   "fee on another invoice",
+			status: models.InvoiceStatusOverdue,
+			fee: func(uuid.UUID) *models.LateFee {
+				return &models.LateFee{ID: uuid.New(), InvoiceID: uuid.New()}
+			},
+			wantErr: service.ErrLateFeeNotFound,
+		},
+		{
+			name:   "already reversed",
+			status: models.InvoiceStatusOverdue,
+			fee: func(invoiceID uuid.UUID) *models.LateFee {
+				return &models.LateFee{ID: uuid.New(), InvoiceID: invoiceID, ReversedAt: &now}
+			},
+			wantErr: service.ErrLateFeeReversed,
+		},
+		{
+			name:   "paid invoice",
+			status: models.InvoiceStatusPaid,
+			fee: func(invoiceID uuid.UUID) *models.LateFee {
+				return &models.LateFee{ID: uuid.New(), InvoiceID: invoiceID}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := sentInvoice(userID, "100")
+			invoice.Status = tt.status
+			fee := tt.fee(invoice.ID)
+			expectTx(mockRepo)
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetLateFeeByID", fee.ID).Return(fee, nil)
+
+			_, err := svc.ReverseLateFee(auth.Principal{UserID: userID}, inputs.ReverseLateFeeInput{
+				InvoiceID: invoice.ID,
+				LateFeeID: fee.ID,
+			})
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			mockRepo.AssertNotCalled(t, "ReverseLateFee", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateLateFeePolicy(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		wantErr bool
+	}{
+		{name: "monthly interest", updates: map[string]interface{}{
+			"enabled": true, "kind": "monthly_interest", "rate": 1.5, "grace_days": float64(0), "max_amount": "100",
+		}},
+		{name: "flat fee", updates: map[string]interface{}{"enabled": true, "kind": "flat", "rate": 25.0, "grace_days": 7.0}},
+		{name: "unknown kind", updates: map[string]interface{}{"kind": "weekly_interest"}, wantErr: true},
+		{name: "rate over 100 percent", updates: map[string]interface{}{"kind": "percentage", "rate": 150.0}, wantErr: true},
+		{name: "enabled without a rate", updates: map[string]interface{}{"enabled": true}, wantErr: true},
+		{name: "negative grace days", updates: map[string]interface{}{"grace_days": -1.0}, wantErr: true},
+		{name: "negative cap", updates: map[string]interface{}{"max_amount": "-5"}, wantErr: true},
+		{name: "wrong type", updates: map[string]interface{}{"enabled": "yes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			mockRepo.On("GetLateFeePolicy", userID, (*uuid.UUID)(nil)).Return(nil, gorm.ErrRecordNotFound)
+			mockRepo.On("SaveLateFeePolicy", mock.MatchedBy(func(policy *models.LateFeePolicy) bool {
+				return policy.UserID == userID && policy.InvoiceID == nil
+			})).Return(nil)
+
+			err := svc.UpdateLateFeePolicy(auth.Principal{UserID: userID}, tt.updates)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				mockRepo.AssertNotCalled(t, "SaveLateFeePolicy", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateInvoiceLateFeePolicy_StartsFromDefault(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoice := sentInvoice(uuid.New(), "100")
+	defaults := lateFeePolicy(invoice.UserID, models.LateFeeDailyInterest, "0.05")
+	defaults.GraceDays = 5
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetLateFeePolicy", invoice.UserID, &invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetLateFeePolicy", invoice.UserID, (*uuid.UUID)(nil)).Return(defaults, nil)
+	mockRepo.On("SaveLateFeePolicy", mock.MatchedBy(func(policy *models.LateFeePolicy) bool {
+		return policy.ID != defaults.ID &&
+			policy.InvoiceID != nil && *policy.InvoiceID == invoice.ID &&
+			policy.Kind == models.LateFeeDailyInterest &&
+			policy.GraceDays == 5 &&
+			!policy.Enabled
+	})).Return(nil)
+
+	err := svc.UpdateInvoiceLateFeePolicy(auth.Principal{UserID: invoice.UserID}, invoice.ID, map[string]interface{}{"enabled": false})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -5,16 +5,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
 )
 
+var ErrPaymentDetailsNotFound = errors.New("payment details not found")
 
-func (s *service) CreatePaymentDetails(input inputs.CreatePaymentDetailsInput) (*models.PaymentDetails, error) {
+func (s *service) CreatePaymentDetails(principal auth.Principal, input inputs.CreatePaymentDetailsInput) (*models.PaymentDetails, error) {
     // Validate invoice exists
-    invoice, err := s.repo.GetInvoiceByID(input.InvoiceID)
+    invoice, err := s.getOwnedInvoice(principal.UserID, input.InvoiceID)
     if err != nil {
-        return nil, errors.New("invalid invoice")
+        return nil, err
     }
 
     // Check if payment details already exist
@@ -34,7 +37,12 @@ func (s *service) CreatePaymentDetails(input inputs.CreatePaymentDetailsInput) (
         PaymentDueDate: input.PaymentDueDate,
     }
 
-    err = s.repo.CreatePaymentDetails(details)
+    err = s.repo.WithTx(func(repo repository.Repository) error {
+        if err := repo.CreatePaymentDetails(details); err != nil {
+            return err
+        }
+        return recordRevision(repo, invoice.ID, &principal, models.ActivityPaymentDetailsAdded)
+    })
     if err != nil {
         return nil, err
     }
@@ -42,15 +50,25 @@ func (s *service) CreatePaymentDetails(input inputs.CreatePaymentDetailsInput) (
     return details, nil
 }
 
-func (s *service) GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models.PaymentDetails, error) {
-    return s.repo.GetPaymentDetailsByInvoiceID(invoiceID)
+func (s *service) GetPaymentDetails(principal auth.Principal, invoiceID uuid.UUID) (*models.PaymentDetails, error) {
+    if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+        return nil, err
+    }
+    details, err := s.repo.GetPaymentDetailsByInvoiceID(invoiceID)
+    if err != nil {
+        return nil, ErrPaymentDetailsNotFound
+    }
+    return details, nil
 }
 
-func (s *service) UpdatePaymentDetails(id uuid.UUID, updates map[string]interface{}) error {
-    details, err := s.repo.GetPaymentDetailsByInvoiceID(id)
-    if err != nil {
+func (s *service) UpdatePaymentDetails(principal auth.Principal, invoiceID uuid.UUID, updates map[string]interface{}) error {
+    if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
         return err
     }
+    details, err := s.repo.GetPaymentDetailsByInvoiceID(invoiceID)
+    if err != nil {
+        return ErrPaymentDetailsNotFound
+    }
 
     for key, value := range updates {
         switch key {
@@ -69,13 +87,26 @@ func (s *service) UpdatePaymentDetails(id uuid.UUID, updates map[string]interfac
         }
     }
 
-    return s.repo.UpdatePaymentDetails(id, details)
+    return s.repo.WithTx(func(repo repository.Repository) error {
+        if err := repo.UpdatePaymentDetails(details.ID, details); err != nil {
+            return err
+        }
+        return recordRevision(repo, invoiceID, &principal, models.ActivityPaymentDetailsUpdated)
+    })
 }
 
-func (s *service) DeletePaymentDetails(id uuid.UUID) error {
-    _, err := s.repo.GetPaymentDetailsByInvoiceID(id)
-    if err != nil {
+func (s *service) DeletePaymentDetails(principal auth.Principal, invoiceID uuid.UUID) error {
+    if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
         return err
     }
-    return s.repo.DeletePaymentDetails(id)
+    details, err := s.repo.GetPaymentDetailsByInvoiceID(invoiceID)
+    if err != nil {
+        return ErrPaymentDetailsNotFound
+    }
+    return s.repo.WithTx(func(repo repository.Repository) error {
+        if err := repo.DeletePaymentDetails(details.ID); err != nil {
+            return err
+        }
+        return recordRevision(repo, invoiceID, &principal, models.ActivityPaymentDetailsRemoved)
+    })
 }
@@ -6,12 +6,14 @@ import (
     "errors"
 
     "github.com/google/uuid"
+    "github.com/iyiola-dev/numeris/internal/auth"
     "github.com/iyiola-dev/numeris/internal/inputs"
     "github.com/iyiola-dev/numeris/internal/mocks"
     "github.com/iyiola-dev/numeris/internal/models"
     "github.com/iyiola-dev/numeris/internal/service"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
 )
 
 func TestCreatePaymentDetails(t *testing.T) {
@@ -19,6 +21,7 @@ func TestCreatePaymentDetails(t *testing.T) {
     svc := service.NewService(mockRepo)
 
     invoiceID := uuid.New()
+    userID := uuid.New()
     input := inputs.CreatePaymentDetailsInput{
         InvoiceID:      invoiceID,
         AccountName:    "John Doe",
@@ -29,9 +32,16 @@ func TestCreatePaymentDetails(t *testing.T) {
         PaymentDueDate: time.Now().AddDate(0, 0, 30),
     }
 
+    expectTx(mockRepo)
+    mockRepo.On("GetInvoiceByID", invoiceID).Return(&models.Invoice{ID: invoiceID, UserID: userID}, nil)
+    mockRepo.On("GetPaymentDetailsByInvoiceID", invoiceID).Return(nil, errors.New("not found")).Once()
     mockRepo.On("CreatePaymentDetails", mock.AnythingOfType("*models.PaymentDetails")).Return(nil)
+    mockRepo.On("GetPaymentDetailsByInvoiceID", invoiceID).Return(&models.PaymentDetails{InvoiceID: invoiceID}, nil).Once()
+    mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(r *models.InvoiceRevision) bool {
+        return r.Action == "PAYMENT_DETAILS_ADDED" && *r.ActorID == userID && r.Snapshot.PaymentDetails != nil
+    })).Return(nil)
 
-    details, err := svc.CreatePaymentDetails(input)
+    details, err := svc.CreatePaymentDetails(auth.Principal{UserID: userID}, input)
 
     assert.NoError(t, err)
     assert.NotNil(t, details)
@@ -40,11 +50,12 @@ func TestCreatePaymentDetails(t *testing.T) {
     mockRepo.AssertExpectations(t)
 }
 
-func TestGetPaymentDetailsByInvoiceID(t *testing.T) {
+func TestGetPaymentDetails(t *testing.T) {
     mockRepo := new(mocks.Repository)
     svc := service.NewService(mockRepo)
 
     invoiceID := uuid.New()
+    userID := uuid.New()
     expected := &models.PaymentDetails{
         ID:           uuid.New(),
         InvoiceID:    invoiceID,
@@ -52,13 +63,18 @@ func TestGetPaymentDetailsByInvoiceID(t *testing.T) {
         AccountNumber: "1234567890",
     }
 
+    mockRepo.On("GetInvoiceByID", invoiceID).Return(&models.Invoice{ID: invoiceID, UserID: userID}, nil)
     mockRepo.On("GetPaymentDetailsByInvoiceID", invoiceID).Return(expected, nil)
 
-    details, err := svc.GetPaymentDetailsByInvoiceID(invoiceID)
+    details, err := svc.GetPaymentDetails(auth.Principal{UserID: userID}, invoiceID)
 
     assert.NoError(t, err)
     assert.Equal(t, expected, details)
     mockRepo.AssertExpectations(t)
+
+    // Another user's invoice looks the same as one that does not exist
+    _, err = svc.GetPaymentDetails(auth.Principal{UserID: uuid.New()}, invoiceID)
+    assert.ErrorIs(t, err, service.ErrInvoiceNotFound)
 }
 
 func TestUpdatePaymentDetails(t *testing.T) {
@@ -66,8 +82,10 @@ func TestUpdatePaymentDetails(t *testing.T) {
     svc := service.NewService(mockRepo)
 
     id := uuid.New()
+    userID := uuid.New()
     existingDetails := &models.PaymentDetails{
-        ID:           id,
+        ID:           uuid.New(),
+        InvoiceID:    id,
         AccountName:  "John Doe",
         AccountNumber: "1234567890",
     }
@@ -78,10 +96,15 @@ func TestUpdatePaymentDetails(t *testing.T) {
         "bank_name":      "New Bank",
     }
 
+    expectTx(mockRepo)
+    mockRepo.On("GetInvoiceByID", id).Return(&models.Invoice{ID: id, UserID: userID}, nil)
     mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(existingDetails, nil)
-    mockRepo.On("UpdatePaymentDetails", id, mock.AnythingOfType("*models.PaymentDetails")).Return(nil)
+    mockRepo.On("UpdatePaymentDetails", existingDetails.ID, mock.AnythingOfType("*models.PaymentDetails")).Return(nil)
+    mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(r *models.InvoiceRevision) bool {
+        return r.Action == "PAYMENT_DETAILS_UPDATED" && r.Snapshot.PaymentDetails.AccountName == "Jane Doe"
+    })).Return(nil)
 
-    err := svc.UpdatePaymentDetails(id, updates)
+    err := svc.UpdatePaymentDetails(auth.Principal{UserID: userID}, id, updates)
 
     assert.NoError(t, err)
     mockRepo.AssertExpectations(t)
@@ -92,11 +115,13 @@ func TestUpdatePaymentDetails_NotFound(t *testing.T) {
     svc := service.NewService(mockRepo)
 
     id := uuid.New()
+    userID := uuid.New()
+    mockRepo.On("GetInvoiceByID", id).Return(&models.Invoice{ID: id, UserID: userID}, nil)
     mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(nil, errors.New("not found"))
 
-    err := svc.UpdatePaymentDetails(id, map[string]interface{}{})
+    err := svc.UpdatePaymentDetails(auth.Principal{UserID: userID}, id, map[string]interface{}{})
 
-    assert.Error(t, err)
+    assert.ErrorIs(t, err, service.ErrPaymentDetailsNotFound)
     mockRepo.AssertExpectations(t)
 }
 
@@ -105,14 +130,22 @@ func TestDeletePaymentDetails(t *testing.T) {
     svc := service.NewService(mockRepo)
 
     id := uuid.New()
+    userID := uuid.New()
     existingDetails := &models.PaymentDetails{
-        ID: id,
+        ID:        uuid.New(),
+        InvoiceID: id,
     }
 
-    mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(existingDetails, nil)
-    mockRepo.On("DeletePaymentDetails", id).Return(nil)
+    expectTx(mockRepo)
+    mockRepo.On("GetInvoiceByID", id).Return(&models.Invoice{ID: id, UserID: userID}, nil)
+    mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(existingDetails, nil).Once()
+    mockRepo.On("DeletePaymentDetails", existingDetails.ID).Return(nil)
+    mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(nil, gorm.ErrRecordNotFound).Once()
+    mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(r *models.InvoiceRevision) bool {
+        return r.Action == "PAYMENT_DETAILS_REMOVED" && r.Snapshot.PaymentDetails == nil
+    })).Return(nil)
 
-    err := svc.DeletePaymentDetails(id)
+    err := svc.DeletePaymentDetails(auth.Principal{UserID: userID}, id)
 
     assert.NoError(t, err)
     mockRepo.AssertExpectations(t)
@@ -123,10 +156,12 @@ func TestDeletePaymentDetails_NotFound(t *testing.T) {
     svc := service.NewService(mockRepo)
 
     id := uuid.New()
+    userID := uuid.New()
+    mockRepo.On("GetInvoiceByID", id).Return(&models.Invoice{ID: id, UserID: userID}, nil)
     mockRepo.On("GetPaymentDetailsByInvoiceID", id).Return(nil, errors.New("not found"))
 
-    err := svc.DeletePaymentDetails(id)
+    err := svc.DeletePaymentDetails(auth.Principal{UserID: userID}, id)
 
-    assert.Error(t, err)
+    assert.ErrorIs(t, err, service.ErrPaymentDetailsNotFound)
     mockRepo.AssertExpectations(t)
 }
\ No newline at end of file
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
+)
+
+var (
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrPaymentHasRefunds = errors.New("payment has refunds; delete them first")
+)
+
+var paymentMethods = map[string]bool{
+	models.PaymentMethodBankTransfer: true,
+	models.PaymentMethodCard:         true,
+	models.PaymentMethodCash:         true,
+	models.PaymentMethodCheque:       true,
+	models.PaymentMethodOther:        true,
+}
+
+// RecordPayment records a payment on an invoice. The invoice stays locked
+// from the balance check until the payment is saved, so two payments at once
+// cannot together pay more than is owed.
+func (s *service) RecordPayment(principal auth.Principal, input inputs.RecordPaymentInput) (*models.Payment, error) {
+	method := input.Method
+	if method == "" {
+		method = models.PaymentMethodOther
+	}
+	if !paymentMethods[method] {
+		return nil, fmt.Errorf("unknown payment method %q", method)
+	}
+
+	paidAt := input.PaidAt
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+
+	var invoice *models.Invoice
+	var payment *models.Payment
+	var from string
+	var statusAction models.ActivityAction
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, input.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if !isOutstanding(invoice.Status) {
+			return fmt.Errorf("cannot record a payment on a %s invoice", invoice.Status)
+		}
+
+		payments, err := repo.GetPayments(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if input.Currency != "" && !strings.EqualFold(input.Currency, invoice.Currency) {
+			return fmt.Errorf("payment currency %s does not match the invoice currency %s", input.Currency, invoice.Currency)
+		}
+		if err := validatePaymentAmount(input.Amount, invoice.Currency); err != nil {
+			return err
+		}
+		balance := amountOwed(invoice).Sub(amountPaid(payments))
+		if input.Amount.GreaterThan(balance) {
+			return fmt.Errorf("payment of %s exceeds the balance due of %s",
+				input.Amount.Format(invoice.Currency), balance.Format(invoice.Currency))
+		}
+
+		payment = &models.Payment{
+			ID:        uuid.New(),
+			InvoiceID: invoice.ID,
+			UserID:    invoice.UserID,
+			Kind:      models.PaymentKindPayment,
+			Amount:    input.Amount,
+			Currency:  invoice.Currency,
+			PaidAt:    paidAt,
+			Method:    method,
+			Reference: strings.TrimSpace(input.Reference),
+			Note:      input.Note,
+		}
+		if err := repo.CreatePayment(payment); err != nil {
+			return err
+		}
+
+		from = invoice.Status
+		statusAction, err = settleInvoice(repo, invoice, append(payments, *payment), &principal, models.ActivityPaymentRecorded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityPaymentRecorded, models.EntityPayment, payment.ID, models.ActivityMetadata{
+		NewValues: paymentValues(payment),
+	})
+	if statusAction != "" {
+		s.logStatusChange(&principal, invoice, statusAction, from)
+	}
+
+	return payment, nil
+}
+
+func (s *service) GetPayments(principal auth.Principal, invoiceID uuid.UUID) ([]models.Payment, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPayments(map[string]interface{}{
+		"invoice_id": invoiceID,
+	})
+}
+
+// RefundPayment returns money from a recorded payment to the customer. Without
+// an amount, whatever has not been refunded from the payment yet is returned.
+func (s *service) RefundPayment(principal auth.Principal, input inputs.RefundPaymentInput) (*models.Payment, error) {
+	refundAt := input.RefundAt
+	if refundAt.IsZero() {
+		refundAt = time.Now()
+	}
+
+	var invoice *models.Invoice
+	var refund *models.Payment
+	var from string
+	var statusAction models.ActivityAction
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, input.InvoiceID)
+		if err != nil {
+			return err
+		}
+
+		payments, err := repo.GetPayments(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		original := findPayment(payments, input.PaymentID)
+		if original == nil || original.Kind != models.PaymentKindPayment {
+			return ErrPaymentNotFound
+		}
+
+		refundable := original.Amount
+		for _, p := range payments {
+			if p.RefundedPaymentID != nil && *p.RefundedPaymentID == original.ID {
+				refundable = refundable.Sub(p.Amount)
+			}
+		}
+
+		amount := input.Amount
+		if amount.IsZero() {
+			amount = refundable
+		}
+		if err := validatePaymentAmount(amount, invoice.Currency); err != nil {
+			return err
+		}
+		if amount.GreaterThan(refundable) {
+			return fmt.Errorf("refund of %s exceeds the %s left to refund on this payment",
+				amount.Format(invoice.Currency), refundable.Format(invoice.Currency))
+		}
+
+		refund = &models.Payment{
+			ID:                uuid.New(),
+			InvoiceID:         invoice.ID,
+			UserID:            invoice.UserID,
+			Kind:              models.PaymentKindRefund,
+			RefundedPaymentID: &original.ID,
+			Amount:            amount,
+			Currency:          invoice.Currency,
+			PaidAt:            refundAt,
+			Method:            original.Method,
+			Reference:         strings.TrimSpace(input.Reference),
+			Note:              input.Note,
+		}
+		if err := repo.CreatePayment(refund); err != nil {
+			return err
+		}
+
+		from = invoice.Status
+		statusAction, err = settleInvoice(repo, invoice, append(payments, *refund), &principal, models.ActivityPaymentRefunded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityPaymentRefunded, models.EntityPayment, refund.ID, models.ActivityMetadata{
+		NewValues: paymentValues(refund),
+	})
+	if statusAction != "" {
+		s.logStatusChange(&principal, invoice, statusAction, from)
+	}
+
+	return refund, nil
+}
+
+// DeletePayment removes a payment or refund recorded by mistake and reverses
+// its effect on the invoice.
+func (s *service) DeletePayment(principal auth.Principal, invoiceID, paymentID uuid.UUID) error {
+	var invoice *models.Invoice
+	var payment *models.Payment
+	var from string
+	var statusAction models.ActivityAction
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		invoice, err = lockOwnedInvoice(repo, principal.UserID, invoiceID)
+		if err != nil {
+			return err
+		}
+
+		payments, err := repo.GetPayments(map[string]interface{}{
+			"invoice_id": invoice.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		payment = findPayment(payments, paymentID)
+		if payment == nil {
+			return ErrPaymentNotFound
+		}
+
+		remaining := make([]models.Payment, 0, len(payments)-1)
+		for _, p := range payments {
+			if p.RefundedPaymentID != nil && *p.RefundedPaymentID == payment.ID {
+				return ErrPaymentHasRefunds
+			}
+			if p.ID != payment.ID {
+				remaining = append(remaining, p)
+			}
+		}
+
+		if err := repo.DeletePayment(payment.ID); err != nil {
+			return err
+		}
+
+		from = invoice.Status
+		statusAction, err = settleInvoice(repo, invoice, remaining, &principal, models.ActivityPaymentDeleted)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityPaymentDeleted, models.EntityPayment, payment.ID, models.ActivityMetadata{
+		OldValues: paymentValues(payment),
+	})
+	if statusAction != "" {
+		s.logStatusChange(&principal, invoice, statusAction, from)
+	}
+
+	return nil
+}
+
+// settleInvoice stores an invoice's balance and status after its payments
+// changed, in the transaction that changed them. change names what happened
+// for the invoice's revision history. It returns the activity log action for
+// a status change, or "" when the status stays as it is.
+func settleInvoice(repo repository.Repository, invoice *models.Invoice, payments []models.Payment, actor *auth.Principal, change models.ActivityAction) (models.ActivityAction, error) {
+	action := applyPayments(invoice, payments, time.Now())
+	if err := writeInvoice(repo, invoice, actor, change); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// paymentValues is how a payment appears in the activity log.
+func paymentValues(payment *models.Payment) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":   payment.Amount.String(),
+		"currency": payment.Currency,
+		"method":   payment.Method,
+	}
+}
+
+// amountPaid is what has been received on an invoice, net of refunds.
+func amountPaid(payments []models.Payment) money.Decimal {
+	total := money.Zero
+	for _, p := range payments {
+		if p.Kind == models.PaymentKindRefund {
+			total = total.Sub(p.Amount)
+		} else {
+			total = total.Add(p.Amount)
+		}
+	}
+	return total
+}
+
+func findPayment(payments []models.Payment, id uuid.UUID) *models.Payment {
+	for i := range payments {
+		if payments[i].ID == id {
+			return &payments[i]
+		}
+	}
+	return nil
+}
+
+func validatePaymentAmount(amount money.Decimal, currency string) error {
+	if !amount.IsPositive() {
+		return errors.New("amount must be greater than zero")
+	}
+	if !amount.RoundCurrency(currency).Equal(amount) {
+		return fmt.Errorf("amount %s has more decimal places than %s allows", amount, currency)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func sentInvoice(userID uuid.UUID, total string) *models.Invoice {
+	return &models.Invoice{
+		ID:            uuid.New(),
+		UserID:        userID,
+		InvoiceNumber: "INV-2026-00001",
+		Currency:      "USD",
+		DueDate:       time.Now().AddDate(0, 0, 30),
+		TotalAmount:   money.MustParse(total),
+		BalanceDue:    money.MustParse(total),
+		Status:        models.InvoiceStatusSent,
+	}
+}
+
+func TestRecordPayment(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		existing   []models.Payment
+		amount     string
+		wantStatus string
+		wantAction models.ActivityAction
+		wantPaid   money.Decimal
+		wantDue    money.Decimal
+	}{
+		{
+			name:       "partial payment",
+			amount:     "40",
+			wantStatus: models.InvoiceStatusPartiallyPaid,
+			wantAction: "INVOICE_PARTIALLY_PAID",
+			wantPaid:   money.NewFromInt(40),
+			wantDue:    money.NewFromInt(60),
+		},
+		{
+			name:       "final payment",
+			existing:   []models.Payment{{ID: uuid.New(), Kind: models.PaymentKindPayment, Amount: money.NewFromInt(40)}},
+			amount:     "60",
+			wantStatus: models.InvoiceStatusPaid,
+			wantAction: "INVOICE_PAID",
+			wantPaid:   money.NewFromInt(100),
+			wantDue:    money.Zero,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := sentInvoice(userID, "100")
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return(tt.existing, nil)
+			mockRepo.On("CreatePayment", mock.AnythingOfType("*models.Payment")).Return(nil)
+			mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+			expectTx(mockRepo)
+			expectRevision(mockRepo)
+			mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+				return log.Action == "PAYMENT_RECORDED"
+			})).Return(nil).Once()
+			mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+				return log.Action == tt.wantAction
+			})).Return(nil).Once()
+
+			payment, err := svc.RecordPayment(auth.Principal{UserID: userID}, inputs.RecordPaymentInput{
+				InvoiceID: invoice.ID,
+				Amount:    money.MustParse(tt.amount),
+				Method:    models.PaymentMethodBankTransfer,
+				Reference: "TRX-1",
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.PaymentKindPayment, payment.Kind)
+			assert.Equal(t, "USD", payment.Currency)
+			assert.Equal(t, tt.wantStatus, invoice.Status)
+			assert.Equal(t, tt.wantPaid, invoice.AmountPaid)
+			assert.Equal(t, tt.wantDue, invoice.BalanceDue)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecordPayment_Rejected(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		status  string
+		input   inputs.RecordPaymentInput
+		wantErr string
+	}{
+		{"overpayment", models.InvoiceStatusSent, inputs.RecordPaymentInput{Amount: money.NewFromInt(101)}, "payment of 101.00 exceeds the balance due of 100.00"},
+		{"zero amount", models.InvoiceStatusSent, inputs.RecordPaymentInput{}, "amount must be greater than zero"},
+		{"sub-cent amount", models.InvoiceStatusSent, inputs.RecordPaymentInput{Amount: money.MustParse("10.005")}, "amount 10.005 has more decimal places than USD allows"},
+		{"other currency", models.InvoiceStatusSent, inputs.RecordPaymentInput{Amount: money.NewFromInt(10), Currency: "EUR"}, "payment currency EUR does not match the invoice currency USD"},
+		{"unknown method", models.InvoiceStatusSent, inputs.RecordPaymentInput{Amount: money.NewFromInt(10), Method: "barter"}, `unknown payment method "barter"`},
+		{"draft invoice", models.InvoiceStatusDraft, inputs.RecordPaymentInput{Amount: money.NewFromInt(10)}, "cannot record a payment on a draft invoice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := sentInvoice(userID, "100")
+			invoice.Status = tt.status
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			expectTx(mockRepo)
+			mockRepo.On("GetPayments", mock.Anything).Return([]models.Payment{}, nil)
+
+			input := tt.input
+			input.InvoiceID = invoice.ID
+			_, err := svc.RecordPayment(auth.Principal{UserID: userID}, input)
+
+			assert.EqualError(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything)
+		})
+	}
+}
+
+func TestRecordPayment_OtherUsersInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	invoice := sentInvoice(uuid.New(), "100")
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	expectTx(mockRepo)
+
+	_, err := svc.RecordPayment(auth.Principal{UserID: uuid.New()}, inputs.RecordPaymentInput{
+		InvoiceID: invoice.ID,
+		Amount:    money.NewFromInt(10),
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvoiceNotFound)
+}
+
+func TestRecordPayment_SecondPaymentOverBalance(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+
+	// Payments are read back from the store after the invoice is locked, so
+	// the second payment sees the first one
+	var stored []models.Payment
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return(func(map[string]interface{}) []models.Payment {
+		return append([]models.Payment(nil), stored...)
+	}, nil)
+	mockRepo.On("CreatePayment", mock.AnythingOfType("*models.Payment")).Run(func(args mock.Arguments) {
+		stored = append(stored, *args.Get(0).(*models.Payment))
+	}).Return(nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	input := inputs.RecordPaymentInput{InvoiceID: invoice.ID, Amount: money.NewFromInt(60)}
+	_, err := svc.RecordPayment(auth.Principal{UserID: userID}, input)
+	require.NoError(t, err)
+
+	_, err = svc.RecordPayment(auth.Principal{UserID: userID}, input)
+
+	assert.EqualError(t, err, "payment of 60.00 exceeds the balance due of 40.00")
+	mockRepo.AssertNumberOfCalls(t, "CreatePayment", 1)
+	assert.Equal(t, money.NewFromInt(40), invoice.BalanceDue)
+
+	// Each balance check ran inside a transaction, after the invoice was locked
+	var order []string
+	for _, call := range mockRepo.Calls {
+		switch call.Method {
+		case "WithTx", "LockInvoice", "GetPayments":
+			order = append(order, call.Method)
+		}
+	}
+	assert.Equal(t, []string{"WithTx", "LockInvoice", "GetPayments", "WithTx", "LockInvoice", "GetPayments"}, order)
+}
+
+func TestRefundPayment(t *testing.T) {
+	userID := uuid.New()
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name       string
+		amount     string
+		wantStatus string
+		wantAction models.ActivityAction
+	}{
+		{"partial refund", "30", models.InvoiceStatusPartiallyPaid, "INVOICE_PARTIALLY_PAID"},
+		{"full refund", "0", models.InvoiceStatusSent, "INVOICE_REOPENED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := sentInvoice(userID, "100")
+			invoice.Status = models.InvoiceStatusPaid
+			payments := []models.Payment{
+				{ID: paymentID, Kind: models.PaymentKindPayment, Amount: money.NewFromInt(100), Method: models.PaymentMethodCard},
+			}
+
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetPayments", map[string]interface{}{"invoice_id": invoice.ID}).Return(payments, nil)
+			mockRepo.On("CreatePayment", mock.AnythingOfType("*models.Payment")).Return(nil)
+			mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+			expectTx(mockRepo)
+			expectRevision(mockRepo)
+			mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+				return log.Action == "PAYMENT_REFUNDED"
+			})).Return(nil).Once()
+			mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+				return log.Action == tt.wantAction
+			})).Return(nil).Once()
+
+			refund, err := svc.RefundPayment(auth.Principal{UserID: userID}, inputs.RefundPaymentInput{
+				InvoiceID: invoice.ID,
+				PaymentID: paymentID,
+				Amount:    money.MustParse(tt.amount),
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.PaymentKindRefund, refund.Kind)
+			assert.Equal(t, paymentID, *refund.RefundedPaymentID)
+			assert.Equal(t, models.PaymentMethodCard, refund.Method)
+			assert.Equal(t, tt.wantStatus, invoice.Status)
+			assert.Nil(t, invoice.PaidAt)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefundPayment_ExceedsPayment(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	paymentID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	payments := []models.Payment{
+		{ID: paymentID, Kind: models.PaymentKindPayment, Amount: money.NewFromInt(50)},
+		{ID: uuid.New(), Kind: models.PaymentKindRefund, RefundedPaymentID: &paymentID, Amount: money.NewFromInt(20)},
+	}
+
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	expectTx(mockRepo)
+	mockRepo.On("GetPayments", mock.Anything).Return(payments, nil)
+
+	_, err := svc.RefundPayment(auth.Principal{UserID: userID}, inputs.RefundPaymentInput{
+		InvoiceID: invoice.ID,
+		PaymentID: paymentID,
+		Amount:    money.NewFromInt(40),
+	})
+
+	assert.EqualError(t, err, "refund of 40.00 exceeds the 30.00 left to refund on this payment")
+	mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything)
+}
+
+func TestDeletePayment(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	invoice.Status = models.InvoiceStatusPartiallyPaid
+	invoice.DueDate = time.Now().AddDate(0, 0, -1)
+	payment := models.Payment{ID: uuid.New(), Kind: models.PaymentKindPayment, Amount: money.NewFromInt(40)}
+
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetPayments", mock.Anything).Return([]models.Payment{payment}, nil)
+	mockRepo.On("DeletePayment", payment.ID).Return(nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	err := svc.DeletePayment(auth.Principal{UserID: userID}, invoice.ID, payment.ID)
+
+	assert.NoError(t, err)
+	// Past its due date, an invoice with nothing paid on it is overdue again
+	assert.Equal(t, models.InvoiceStatusOverdue, invoice.Status)
+	assert.True(t, invoice.AmountPaid.IsZero())
+	assert.Equal(t, money.NewFromInt(100), invoice.BalanceDue)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeletePayment_WithRefunds(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	paymentID := uuid.New()
+	payments := []models.Payment{
+		{ID: paymentID, Kind: models.PaymentKindPayment, Amount: money.NewFromInt(50)},
+		{ID: uuid.New(), Kind: models.PaymentKindRefund, RefundedPaymentID: &paymentID, Amount: money.NewFromInt(20)},
+	}
+
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	expectTx(mockRepo)
+	mockRepo.On("GetPayments", mock.Anything).Return(payments, nil)
+
+	err := svc.DeletePayment(auth.Principal{UserID: userID}, invoice.ID, paymentID)
+
+	assert.ErrorIs(t, err, service.ErrPaymentHasRefunds)
+	mockRepo.AssertNotCalled(t, "DeletePayment", mock.Anything)
+}
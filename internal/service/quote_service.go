@@ -0,0 +1,559 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+)
+
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	// ErrQuoteLinkNotFound is returned for unknown tokens, including those of
+	// links replaced by sending the quote again.
+	ErrQuoteLinkNotFound = errors.New("quote link not found")
+	ErrQuoteClosed       = errors.New("quote is no longer open for acceptance")
+	ErrQuoteConverted    = errors.New("quote has already been converted to an invoice")
+
+	errQuoteNotDraft = errors.New("only draft quotes can be edited")
+)
+
+const (
+	defaultQuoteValidityDays = 30
+
+	// Limits on what a customer sends back with their answer. A signature
+	// may be a drawn image sent as a data URL.
+	maxSignerNameSize    = 255
+	maxSignatureSize     = 64 << 10
+	maxDeclineReasonSize = 1000
+)
+
+// sharedQuotePathPrefix is where the public routes serve quotes.
+const sharedQuotePathPrefix = "/api/shared-quotes/"
+
+func (s *service) CreateQuote(principal auth.Principal, input inputs.CreateQuoteInput) (*models.Quote, error) {
+	customer, err := s.getOwnedCustomer(principal.UserID, input.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	issueDate := input.IssueDate
+	if issueDate.IsZero() {
+		issueDate = time.Now()
+	}
+	expiryDate := input.ExpiryDate
+	if expiryDate.IsZero() {
+		expiryDate = issueDate.AddDate(0, 0, defaultQuoteValidityDays)
+	}
+	discountType := input.DiscountType
+	if discountType == "" {
+		discountType = models.DiscountTypeFixed
+	}
+
+	quote := &models.Quote{
+		ID:               uuid.New(),
+		UserID:           principal.UserID,
+		CustomerID:       customer.ID,
+		IssueDate:        issueDate,
+		ExpiryDate:       expiryDate,
+		Currency:         input.Currency,
+		DiscountType:     discountType,
+		DiscountValue:    input.Discount,
+		PricesIncludeTax: input.PricesIncludeTax,
+		ReverseCharge:    customer.ReverseCharge,
+		Status:           models.QuoteStatusDraft,
+		Note:             input.Note,
+	}
+	if err := validateQuote(quote); err != nil {
+		return nil, err
+	}
+	if err := s.priceQuote(quote, input.Items); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateQuote(quote); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+func (s *service) GetQuotes(principal auth.Principal) ([]models.Quote, error) {
+	return s.repo.GetQuotes(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+func (s *service) GetQuote(principal auth.Principal, id uuid.UUID) (*models.Quote, error) {
+	return s.getOwnedQuote(principal.UserID, id)
+}
+
+// UpdateQuote edits a draft quote. Once sent, a quote stays as the customer
+// saw it; to change it, create a new one.
+func (s *service) UpdateQuote(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error {
+	quote, err := s.getOwnedQuote(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+	if quote.Status != models.QuoteStatusDraft {
+		return errQuoteNotDraft
+	}
+
+	var items []inputs.CreateInvoiceItemInput
+	for key, value := range updates {
+		switch key {
+		case "note", "currency", "discount_type":
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			switch key {
+			case "note":
+				quote.Note = text
+			case "currency":
+				quote.Currency = text
+			case "discount_type":
+				quote.DiscountType = text
+			}
+		case "customer_id":
+			text, _ := value.(string)
+			customerID, err := uuid.Parse(text)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			customer, err := s.getOwnedCustomer(principal.UserID, customerID)
+			if err != nil {
+				return err
+			}
+			quote.CustomerID = customer.ID
+			quote.ReverseCharge = customer.ReverseCharge
+		case "discount":
+			discount, err := decimalValue(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			quote.DiscountValue = discount
+		case "prices_include_tax":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			quote.PricesIncludeTax = flag
+		case "issue_date", "expiry_date":
+			text, _ := value.(string)
+			date, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if key == "issue_date" {
+				quote.IssueDate = date
+			} else {
+				quote.ExpiryDate = date
+			}
+		case "items":
+			// Items use the same shape as when creating the quote
+			raw, err := json.Marshal(value)
+			if err != nil || json.Unmarshal(raw, &items) != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if items == nil {
+				items = []inputs.CreateInvoiceItemInput{}
+			}
+		}
+	}
+
+	if err := validateQuote(quote); err != nil {
+		return err
+	}
+	if items == nil {
+		items = quoteItemInputs(quote.Items)
+	}
+	// Line amounts depend on the whole quote, so the lines are always priced
+	// and stored again
+	if err := s.priceQuote(quote, items); err != nil {
+		return err
+	}
+
+	// The quote is only saved while it is still a draft, in case it was
+	// sent since it was read
+	return s.repo.WithTx(func(repo repository.Repository) error {
+		saved, err := repo.TransitionQuote(quote, models.QuoteStatusDraft)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			return errQuoteNotDraft
+		}
+		return repo.ReplaceQuoteItems(quote.ID, quote.Items)
+	})
+}
+
+func (s *service) DeleteQuote(principal auth.Principal, id uuid.UUID) error {
+	quote, err := s.getOwnedQuote(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+	if quote.ConvertedAt != nil {
+		return ErrQuoteConverted
+	}
+	return s.repo.DeleteQuote(quote.ID)
+}
+
+// SendQuote marks a quote as sent and creates the link the customer accepts
+// or declines it through. The token is returned once and only its hash is
+// stored. Sending a quote again replaces its link.
+func (s *service) SendQuote(principal auth.Principal, id uuid.UUID) (*response.QuoteLinkResponse, error) {
+	quote, err := s.getOwnedQuote(principal.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != models.QuoteStatusDraft && quote.Status != models.QuoteStatusSent {
+		return nil, ErrQuoteClosed
+	}
+	now := time.Now()
+	if quoteExpired(quote, now) {
+		return nil, errors.New("quote has passed its expiry date")
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashShareToken(token)
+	quote.AcceptanceTokenHash = &hash
+	quote.Status = models.QuoteStatusSent
+	quote.SentAt = &now
+
+	// The customer may have answered the quote since it was read
+	sent, err := s.repo.TransitionQuote(quote, models.QuoteStatusDraft, models.QuoteStatusSent)
+	if err != nil {
+		return nil, err
+	}
+	if !sent {
+		return nil, ErrQuoteClosed
+	}
+
+	return &response.QuoteLinkResponse{
+		Quote: quote,
+		Token: token,
+		Path:  sharedQuotePathPrefix + token,
+	}, nil
+}
+
+// GetSharedQuote returns the customer facing view of the quote behind an
+// acceptance link.
+func (s *service) GetSharedQuote(token string) (*response.SharedQuote, error) {
+	quote, err := s.openQuote(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return sharedQuoteView(quote), nil
+}
+
+// AcceptQuote records the customer's acceptance, signed with their name and
+// signature, of a sent quote that has not expired.
+func (s *service) AcceptQuote(input inputs.QuoteResponseInput) (*response.SharedQuote, error) {
+	return s.answerQuote(input, true)
+}
+
+// DeclineQuote records that the customer declined a sent quote, with an
+// optional reason.
+func (s *service) DeclineQuote(input inputs.QuoteResponseInput) (*response.SharedQuote, error) {
+	return s.answerQuote(input, false)
+}
+
+func (s *service) answerQuote(input inputs.QuoteResponseInput, accept bool) (*response.SharedQuote, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(name) > maxSignerNameSize {
+		return nil, fmt.Errorf("name must be at most %d characters", maxSignerNameSize)
+	}
+	signature := strings.TrimSpace(input.Signature)
+	reason := strings.TrimSpace(input.Reason)
+	if accept {
+		if signature == "" {
+			return nil, errors.New("a signature is required to accept a quote")
+		}
+		if len(signature) > maxSignatureSize {
+			return nil, fmt.Errorf("signature must be at most %d bytes", maxSignatureSize)
+		}
+	} else if len(reason) > maxDeclineReasonSize {
+		return nil, fmt.Errorf("reason must be at most %d characters", maxDeclineReasonSize)
+	}
+
+	now := time.Now()
+	quote, err := s.openQuote(input.Token, now)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != models.QuoteStatusSent {
+		return nil, ErrQuoteClosed
+	}
+
+	quote.SignerName = name
+	quote.RespondedFromIP = input.IPAddress
+	if accept {
+		quote.Status = models.QuoteStatusAccepted
+		quote.AcceptedAt = &now
+		quote.Signature = signature
+	} else {
+		quote.Status = models.QuoteStatusDeclined
+		quote.DeclinedAt = &now
+		quote.DeclineReason = reason
+	}
+
+	// Only one answer is kept when the customer answers twice at once
+	answered, err := s.repo.TransitionQuote(quote, models.QuoteStatusSent)
+	if err != nil {
+		return nil, err
+	}
+	if !answered {
+		return nil, ErrQuoteClosed
+	}
+
+	return sharedQuoteView(quote), nil
+}
+
+// ConvertQuote turns an accepted quote into a draft invoice through
+// CreateInvoice. The invoice must come to the same totals as the quote, so a
+// quote whose tax rates or customer changed in a way that alters its price is
+// rejected. A quote is converted at most once.
+func (s *service) ConvertQuote(principal auth.Principal, id uuid.UUID) (*models.Invoice, error) {
+	quote, err := s.getOwnedQuote(principal.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.ConvertedAt != nil {
+		return nil, ErrQuoteConverted
+	}
+	if quote.Status != models.QuoteStatusAccepted {
+		return nil, errors.New("only accepted quotes can be converted to an invoice")
+	}
+
+	now := time.Now()
+	claimed, err := s.repo.ClaimQuoteConversion(quote.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrQuoteConverted
+	}
+
+	invoice, err := s.createInvoice(principal, inputs.CreateInvoiceInput{
+		CustomerID:       quote.CustomerID,
+		IssueDate:        now,
+		DueDate:          now.AddDate(0, 0, defaultPaymentTermsDays),
+		Currency:         quote.Currency,
+		SubTotal:         quote.SubTotal,
+		DiscountType:     quote.DiscountType,
+		Discount:         quote.DiscountValue,
+		TotalAmount:      quote.TotalAmount,
+		PricesIncludeTax: quote.PricesIncludeTax,
+		Note:             quote.Note,
+		Items:            quoteItemInputs(quote.Items),
+	}, &quote.ID)
+	if err != nil {
+		_ = s.repo.ReleaseQuoteConversion(quote.ID)
+		return nil, fmt.Errorf("cannot convert quote: %w", err)
+	}
+
+	// The claim alone keeps the quote from being converted again and the
+	// invoice points back at it, so failing to link it is not an error
+	quote.InvoiceID = &invoice.ID
+	_ = s.repo.UpdateQuote(quote.ID, quote)
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityQuoteConverted, models.EntityQuote, quote.ID, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"quote_number": quote.QuoteNumber},
+	})
+
+	return invoice, nil
+}
+
+// ExpireQuotes marks sent quotes whose expiry date has passed as expired and
+// returns how many there were.
+func (s *service) ExpireQuotes(now time.Time) (int, error) {
+	expired, err := s.repo.ExpireQuotes(now.UTC().Truncate(dayLength))
+	return int(expired), err
+}
+
+func (s *service) getOwnedQuote(userID, id uuid.UUID) (*models.Quote, error) {
+	quote, err := s.repo.GetQuoteByID(id)
+	if err != nil || quote.UserID != userID {
+		return nil, ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+// openQuote resolves an acceptance link to its quote. A sent quote found past
+// its expiry date is marked expired there and then, rather than waiting for
+// the background job.
+func (s *service) openQuote(token string, now time.Time) (*models.Quote, error) {
+	quote, err := s.repo.GetQuoteByTokenHash(hashShareToken(token))
+	if err != nil {
+		return nil, ErrQuoteLinkNotFound
+	}
+
+	if quote.Status == models.QuoteStatusSent && quoteExpired(quote, now) {
+		quote.Status = models.QuoteStatusExpired
+		expired, err := s.repo.TransitionQuote(quote, models.QuoteStatusSent)
+		if err != nil {
+			return nil, err
+		}
+		// It was answered or sent again in the meantime, so it is read again
+		if !expired {
+			quote, err = s.repo.GetQuoteByTokenHash(hashShareToken(token))
+			if err != nil {
+				return nil, ErrQuoteLinkNotFound
+			}
+		}
+	}
+
+	return quote, nil
+}
+
+// priceQuote works out a quote's amounts from its lines the way CreateInvoice
+// does and replaces its items with them.
+func (s *service) priceQuote(quote *models.Quote, items []inputs.CreateInvoiceItemInput) error {
+	if len(items) == 0 {
+		return errors.New("quote must have at least one item")
+	}
+
+	itemTaxes, err := s.resolveItemTaxes(quote.UserID, items)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]invoiceLine, len(items))
+	for i, item := range items {
+		lines[i] = invoiceLine{
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			ClientAmount: item.Amount,
+			Taxes:        itemTaxes[i],
+		}
+	}
+	totals, err := calculateTotals(invoicePricing{
+		Currency:         quote.Currency,
+		DiscountType:     quote.DiscountType,
+		DiscountValue:    quote.DiscountValue,
+		PricesIncludeTax: quote.PricesIncludeTax,
+		ReverseCharge:    quote.ReverseCharge,
+	}, lines)
+	if err != nil {
+		return err
+	}
+
+	quote.SubTotal = totals.SubTotal
+	quote.Discount = totals.Discount
+	quote.TaxTotal = totals.TaxTotal
+	quote.TotalAmount = totals.TotalAmount
+	quote.Taxes = nil
+	for _, tax := range totals.Taxes {
+		quote.Taxes = append(quote.Taxes, models.QuoteTax{
+			TaxRateID: tax.TaxRateID,
+			Name:      tax.Name,
+			Rate:      tax.Rate,
+			Amount:    tax.Amount,
+		})
+	}
+	quote.Items = make([]models.QuoteItem, len(items))
+	for i, item := range items {
+		quote.Items[i] = models.QuoteItem{
+			ID:          uuid.New(),
+			QuoteID:     quote.ID,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      totals.Amounts[i],
+			TaxAmount:   totals.TaxAmounts[i],
+			TaxRateIDs:  item.TaxRateIDs,
+			Position:    i,
+		}
+	}
+
+	return nil
+}
+
+func validateQuote(quote *models.Quote) error {
+	if quote.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if quote.ExpiryDate.Before(quote.IssueDate) {
+		return errors.New("expiry date must not be before the issue date")
+	}
+	return nil
+}
+
+// quoteExpired reports whether a quote's expiry date is over. A quote can
+// still be accepted on the day it expires.
+func quoteExpired(quote *models.Quote, now time.Time) bool {
+	return quote.ExpiryDate.Before(now.UTC().Truncate(dayLength))
+}
+
+func quoteItemInputs(items []models.QuoteItem) []inputs.CreateInvoiceItemInput {
+	result := make([]inputs.CreateInvoiceItemInput, len(items))
+	for i, item := range items {
+		result[i] = inputs.CreateInvoiceItemInput{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TaxRateIDs:  item.TaxRateIDs,
+		}
+	}
+	return result
+}
+
+// sharedQuoteView keeps only what the customer needs to decide on a quote.
+func sharedQuoteView(quote *models.Quote) *response.SharedQuote {
+	view := &response.SharedQuote{
+		QuoteNumber: quote.QuoteNumber,
+		Status:      quote.Status,
+		IssueDate:   quote.IssueDate,
+		ExpiryDate:  quote.ExpiryDate,
+		Currency:    quote.Currency,
+		From: response.SharedParty{
+			Name:    strings.TrimSpace(quote.User.FirstName + " " + quote.User.LastName),
+			Email:   quote.User.Email,
+			Address: quote.User.Address,
+		},
+		BillTo: response.SharedParty{
+			Name:      quote.Customer.Name,
+			Address:   quote.Customer.Address,
+			TaxNumber: quote.Customer.TaxNumber,
+		},
+		Items:         make([]response.SharedItem, len(quote.Items)),
+		SubTotal:      quote.SubTotal,
+		Discount:      quote.Discount,
+		Taxes:         make([]response.SharedTax, len(quote.Taxes)),
+		TaxTotal:      quote.TaxTotal,
+		TotalAmount:   quote.TotalAmount,
+		Note:          quote.Note,
+		SignerName:    quote.SignerName,
+		AcceptedAt:    quote.AcceptedAt,
+		DeclinedAt:    quote.DeclinedAt,
+		DeclineReason: quote.DeclineReason,
+	}
+
+	for i, item := range quote.Items {
+		view.Items[i] = response.SharedItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		}
+	}
+	for i, tax := range quote.Taxes {
+		view.Taxes[i] = response.SharedTax{Name: tax.Name, Rate: tax.Rate, Amount: tax.Amount}
+	}
+
+	return view
+}
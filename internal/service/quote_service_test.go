@@ -0,0 +1,348 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sentQuote is a sent quote for 4 x 250 with a 10% discount, totalling 900.
+func sentQuote(userID uuid.UUID) *models.Quote {
+	quote := &models.Quote{
+		ID:            uuid.New(),
+		UserID:        userID,
+		CustomerID:    uuid.New(),
+		QuoteNumber:   "QUO-2026-00001",
+		IssueDate:     time.Now().AddDate(0, 0, -7),
+		ExpiryDate:    time.Now().AddDate(0, 0, 23),
+		Currency:      "USD",
+		SubTotal:      money.NewFromInt(1000),
+		DiscountType:  models.DiscountTypePercentage,
+		DiscountValue: money.NewFromInt(10),
+		Discount:      money.NewFromInt(100),
+		TotalAmount:   money.NewFromInt(900),
+		Status:        models.QuoteStatusSent,
+		Note:          "Valid for 30 days.",
+	}
+	quote.Items = []models.QuoteItem{{
+		ID:          uuid.New(),
+		QuoteID:     quote.ID,
+		Description: "Workshop day",
+		Quantity:    4,
+		UnitPrice:   money.NewFromInt(250),
+		Amount:      money.NewFromInt(1000),
+	}}
+	return quote
+}
+
+func TestCreateQuote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customer := &models.Customer{ID: uuid.New(), UserID: userID}
+	issued := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+	mockRepo.On("CreateQuote", mock.AnythingOfType("*models.Quote")).Return(nil)
+
+	quote, err := svc.CreateQuote(auth.Principal{UserID: userID}, inputs.CreateQuoteInput{
+		CustomerID:   customer.ID,
+		IssueDate:    issued,
+		Currency:     "USD",
+		DiscountType: models.DiscountTypePercentage,
+		Discount:     money.NewFromInt(10),
+		Items: []inputs.CreateInvoiceItemInput{
+			{Description: "Workshop day", Quantity: 4, UnitPrice: money.NewFromInt(250)},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.QuoteStatusDraft, quote.Status)
+	assert.Equal(t, issued.AddDate(0, 0, 30), quote.ExpiryDate)
+	assert.Equal(t, money.NewFromInt(100), quote.Discount)
+	assert.Equal(t, money.NewFromInt(900), quote.TotalAmount)
+	require.Len(t, quote.Items, 1)
+	assert.Equal(t, quote.ID, quote.Items[0].QuoteID)
+	assert.Equal(t, money.NewFromInt(1000), quote.Items[0].Amount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateQuote_SentQuote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+
+	err := svc.UpdateQuote(auth.Principal{UserID: userID}, quote.ID, map[string]interface{}{"note": "Changed"})
+
+	assert.EqualError(t, err, "only draft quotes can be edited")
+	mockRepo.AssertNotCalled(t, "UpdateQuote", mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuote(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		saved   bool
+		wantErr string
+	}{
+		{name: "draft", saved: true},
+		{name: "sent since it was read", saved: false, wantErr: "only draft quotes can be edited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			quote := sentQuote(userID)
+			quote.Status = models.QuoteStatusDraft
+			mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+			expectTx(mockRepo)
+			mockRepo.On("TransitionQuote", quote, []string{models.QuoteStatusDraft}).Return(tt.saved, nil)
+			mockRepo.On("ReplaceQuoteItems", quote.ID, mock.AnythingOfType("[]models.QuoteItem")).Return(nil).Maybe()
+
+			err := svc.UpdateQuote(auth.Principal{UserID: userID}, quote.ID, map[string]interface{}{"note": "Changed"})
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "ReplaceQuoteItems", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Changed", quote.Note)
+			mockRepo.AssertCalled(t, "ReplaceQuoteItems", quote.ID, mock.AnythingOfType("[]models.QuoteItem"))
+		})
+	}
+}
+
+func TestSendQuote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	quote.Status = models.QuoteStatusDraft
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+	mockRepo.On("TransitionQuote", quote, []string{models.QuoteStatusDraft, models.QuoteStatusSent}).Return(true, nil)
+
+	link, err := svc.SendQuote(auth.Principal{UserID: userID}, quote.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.QuoteStatusSent, quote.Status)
+	assert.NotNil(t, quote.SentAt)
+	assert.Equal(t, "/api/shared-quotes/"+link.Token, link.Path)
+	require.NotNil(t, quote.AcceptanceTokenHash)
+	assert.NotEqual(t, link.Token, *quote.AcceptanceTokenHash)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcceptQuote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	quote := sentQuote(uuid.New())
+	mockRepo.On("GetQuoteByTokenHash", mock.AnythingOfType("string")).Return(quote, nil)
+	mockRepo.On("TransitionQuote", quote, []string{models.QuoteStatusSent}).Return(true, nil)
+
+	view, err := svc.AcceptQuote(inputs.QuoteResponseInput{
+		Token:     "token",
+		Name:      " Grace Hopper ",
+		Signature: "Grace Hopper",
+		IPAddress: "203.0.113.7",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.QuoteStatusAccepted, view.Status)
+	assert.Equal(t, "Grace Hopper", view.SignerName)
+	assert.NotNil(t, quote.AcceptedAt)
+	assert.Equal(t, "Grace Hopper", quote.Signature)
+	assert.Equal(t, "203.0.113.7", quote.RespondedFromIP)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcceptQuote_AnsweredMeanwhile(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	// The quote was declined after it was read, so the acceptance is not saved
+	quote := sentQuote(uuid.New())
+	mockRepo.On("GetQuoteByTokenHash", mock.AnythingOfType("string")).Return(quote, nil)
+	mockRepo.On("TransitionQuote", quote, []string{models.QuoteStatusSent}).Return(false, nil)
+
+	_, err := svc.AcceptQuote(inputs.QuoteResponseInput{Token: "token", Name: "Grace Hopper", Signature: "GH"})
+
+	assert.ErrorIs(t, err, service.ErrQuoteClosed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnswerQuote_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		quote   func(q *models.Quote)
+		input   inputs.QuoteResponseInput
+		decline bool
+		wantErr string
+	}{
+		{
+			name:    "no signature",
+			input:   inputs.QuoteResponseInput{Name: "Grace Hopper"},
+			wantErr: "a signature is required to accept a quote",
+		},
+		{
+			name:    "no name",
+			input:   inputs.QuoteResponseInput{Reason: "Too expensive"},
+			decline: true,
+			wantErr: "name is required",
+		},
+		{
+			name:    "already declined",
+			quote:   func(q *models.Quote) { q.Status = models.QuoteStatusDeclined },
+			input:   inputs.QuoteResponseInput{Name: "Grace Hopper", Signature: "GH"},
+			wantErr: "quote is no longer open for acceptance",
+		},
+		{
+			name: "past its expiry date",
+			quote: func(q *models.Quote) {
+				q.ExpiryDate = time.Now().AddDate(0, 0, -2)
+			},
+			input:   inputs.QuoteResponseInput{Name: "Grace Hopper", Signature: "GH"},
+			wantErr: "quote is no longer open for acceptance",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			quote := sentQuote(uuid.New())
+			if tt.quote != nil {
+				tt.quote(quote)
+			}
+			mockRepo.On("GetQuoteByTokenHash", mock.AnythingOfType("string")).Return(quote, nil).Maybe()
+			// An expired quote is marked as such when it is opened
+			mockRepo.On("TransitionQuote", mock.MatchedBy(func(q *models.Quote) bool {
+				return q.Status == models.QuoteStatusExpired
+			}), []string{models.QuoteStatusSent}).Return(true, nil).Maybe()
+
+			var err error
+			tt.input.Token = "token"
+			if tt.decline {
+				_, err = svc.DeclineQuote(tt.input)
+			} else {
+				_, err = svc.AcceptQuote(tt.input)
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Nil(t, quote.AcceptedAt)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestConvertQuote(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	quote.Status = models.QuoteStatusAccepted
+	customer := &models.Customer{ID: quote.CustomerID, UserID: userID}
+
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+	mockRepo.On("ClaimQuoteConversion", quote.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("UpdateQuote", quote.ID, quote).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_CREATED"
+	})).Return(nil).Once()
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "QUOTE_CONVERTED"
+	})).Return(nil).Once()
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+
+	invoice, err := svc.ConvertQuote(auth.Principal{UserID: userID}, quote.ID)
+
+	require.NoError(t, err)
+	require.NotNil(t, invoice.QuoteID)
+	assert.Equal(t, quote.ID, *invoice.QuoteID)
+	assert.Equal(t, invoice.ID, *quote.InvoiceID)
+	assert.Equal(t, models.InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, money.NewFromInt(900), invoice.TotalAmount)
+	require.Len(t, invoice.Items, 1)
+	assert.Equal(t, "Workshop day", invoice.Items[0].Description)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvertQuote_PriceChanged(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	// The quote was priced with 20% tax on a rate that has since gone
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	quote.Status = models.QuoteStatusAccepted
+	quote.TotalAmount = money.NewFromInt(1080)
+	quote.TaxTotal = money.NewFromInt(180)
+
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+	mockRepo.On("ClaimQuoteConversion", quote.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("GetCustomerByID", quote.CustomerID).Return(&models.Customer{ID: quote.CustomerID, UserID: userID}, nil)
+	mockRepo.On("ReleaseQuoteConversion", quote.ID).Return(nil)
+
+	_, err := svc.ConvertQuote(auth.Principal{UserID: userID}, quote.ID)
+
+	assert.ErrorContains(t, err, "cannot convert quote: total amount")
+	assert.Nil(t, quote.InvoiceID)
+	mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvertQuote_AlreadyConverted(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	quote.Status = models.QuoteStatusAccepted
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+	mockRepo.On("ClaimQuoteConversion", quote.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	_, err := svc.ConvertQuote(auth.Principal{UserID: userID}, quote.ID)
+
+	assert.ErrorIs(t, err, service.ErrQuoteConverted)
+	mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvertQuote_NotAccepted(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	quote := sentQuote(userID)
+	mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+
+	_, err := svc.ConvertQuote(auth.Principal{UserID: userID}, quote.ID)
+
+	assert.EqualError(t, err, "only accepted quotes can be converted to an invoice")
+	mockRepo.AssertNotCalled(t, "ClaimQuoteConversion", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+)
+
+var ErrRecurringInvoiceNotFound = errors.New("recurring invoice not found")
+
+const (
+	defaultPaymentTermsDays = 30
+	maxPaymentTermsDays     = 365
+
+	// maxCatchUpPeriods bounds how many missed periods of one template are
+	// invoiced in a single run; the rest follow on the next run.
+	maxCatchUpPeriods = 100
+)
+
+func (s *service) CreateRecurringInvoice(principal auth.Principal, input inputs.CreateRecurringInvoiceInput) (*models.RecurringInvoice, error) {
+	if _, err := s.getOwnedCustomer(principal.UserID, input.CustomerID); err != nil {
+		return nil, err
+	}
+
+	terms := defaultPaymentTermsDays
+	if input.PaymentTermsDays != nil {
+		terms = *input.PaymentTermsDays
+	}
+	discountType := input.DiscountType
+	if discountType == "" {
+		discountType = models.DiscountTypeFixed
+	}
+
+	recurring := &models.RecurringInvoice{
+		ID:               uuid.New(),
+		UserID:           principal.UserID,
+		CustomerID:       input.CustomerID,
+		Name:             strings.TrimSpace(input.Name),
+		Currency:         input.Currency,
+		DiscountType:     discountType,
+		Discount:         input.Discount,
+		PricesIncludeTax: input.PricesIncludeTax,
+		Note:             input.Note,
+		PaymentTermsDays: terms,
+		Cadence:          input.Cadence,
+		CronRule:         strings.TrimSpace(input.CronRule),
+		StartDate:        input.StartDate.UTC(),
+		EndDate:          utcTime(input.EndDate),
+		AutoSend:         input.AutoSend,
+		Active:           true,
+	}
+	if recurring.Cadence != models.CadenceCustom {
+		recurring.CronRule = ""
+	}
+
+	if err := validateRecurringInvoice(recurring); err != nil {
+		return nil, err
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if recurring.StartDate.Before(today) {
+		return nil, errors.New("start date cannot be in the past")
+	}
+	if err := s.validateRecurringItems(recurring, input.Items); err != nil {
+		return nil, err
+	}
+
+	next, err := nextOccurrence(recurring, recurring.StartDate.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	recurring.NextRunAt = next
+
+	// A template without its items would bill nothing, so they are stored
+	// together
+	items := recurringItems(recurring.ID, input.Items)
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		if err := repo.CreateRecurringInvoice(recurring); err != nil {
+			return err
+		}
+		return repo.ReplaceRecurringInvoiceItems(recurring.ID, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recurring.Items = items
+
+	return recurring, nil
+}
+
+func (s *service) GetRecurringInvoices(principal auth.Principal) ([]models.RecurringInvoice, error) {
+	return s.repo.GetRecurringInvoices(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+func (s *service) GetRecurringInvoice(principal auth.Principal, id uuid.UUID) (*models.RecurringInvoice, error) {
+	return s.getOwnedRecurringInvoice(principal.UserID, id)
+}
+
+func (s *service) UpdateRecurringInvoice(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error {
+	recurring, err := s.getOwnedRecurringInvoice(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+
+	wasActive := recurring.Active
+	rescheduled := false
+	var items []inputs.CreateInvoiceItemInput
+	for key, value := range updates {
+		switch key {
+		case "name", "note", "cadence", "cron_rule", "currency", "discount_type":
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			switch key {
+			case "name":
+				recurring.Name = strings.TrimSpace(text)
+			case "note":
+				recurring.Note = text
+			case "cadence":
+				recurring.Cadence = text
+				rescheduled = true
+			case "cron_rule":
+				recurring.CronRule = strings.TrimSpace(text)
+				rescheduled = true
+			case "currency":
+				recurring.Currency = text
+			case "discount_type":
+				recurring.DiscountType = text
+			}
+		case "customer_id":
+			text, _ := value.(string)
+			customerID, err := uuid.Parse(text)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if _, err := s.getOwnedCustomer(principal.UserID, customerID); err != nil {
+				return err
+			}
+			recurring.CustomerID = customerID
+		case "discount":
+			discount, err := decimalValue(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			recurring.Discount = discount
+		case "payment_terms_days":
+			// 0 makes the invoice due on the day it is issued
+			days, ok := wholeNumber(value, 0)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			recurring.PaymentTermsDays = int(days)
+		case "prices_include_tax", "auto_send", "active":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			switch key {
+			case "prices_include_tax":
+				recurring.PricesIncludeTax = flag
+			case "auto_send":
+				recurring.AutoSend = flag
+			case "active":
+				recurring.Active = flag
+			}
+		case "end_date":
+			if value == nil {
+				recurring.EndDate = nil
+				continue
+			}
+			text, _ := value.(string)
+			end, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			recurring.EndDate = utcTime(&end)
+		case "items":
+			// Items use the same shape as when creating the template
+			raw, err := json.Marshal(value)
+			if err != nil || json.Unmarshal(raw, &items) != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if items == nil {
+				items = []inputs.CreateInvoiceItemInput{}
+			}
+		}
+	}
+	if recurring.Cadence != models.CadenceCustom {
+		recurring.CronRule = ""
+	}
+
+	if err := validateRecurringInvoice(recurring); err != nil {
+		return err
+	}
+	if items == nil {
+		items = recurringItemInputs(recurring.Items)
+	}
+	if err := s.validateRecurringItems(recurring, items); err != nil {
+		return err
+	}
+
+	// A new schedule, or one resumed after a pause, starts from now; periods
+	// skipped while paused are not billed.
+	if rescheduled || (recurring.Active && !wasActive) {
+		from := time.Now().UTC()
+		if recurring.StartDate.After(from) {
+			from = recurring.StartDate
+		}
+		next, err := nextOccurrence(recurring, from.Add(-time.Nanosecond))
+		if err != nil {
+			return err
+		}
+		recurring.NextRunAt = next
+	}
+
+	return s.repo.WithTx(func(repo repository.Repository) error {
+		if _, ok := updates["items"]; ok {
+			if err := repo.ReplaceRecurringInvoiceItems(id, recurringItems(id, items)); err != nil {
+				return err
+			}
+		}
+		return repo.UpdateRecurringInvoice(id, recurring)
+	})
+}
+
+func (s *service) DeleteRecurringInvoice(principal auth.Principal, id uuid.UUID) error {
+	if _, err := s.getOwnedRecurringInvoice(principal.UserID, id); err != nil {
+		return err
+	}
+	// Invoices already generated are kept
+	return s.repo.DeleteRecurringInvoice(id)
+}
+
+// GenerateRecurringInvoices creates the invoices of every template that has
+// come due by now, catching up on periods missed while the scheduler was not
+// running. It returns how many invoices were created. A template that fails
+// does not stop the others; its error is returned after they have run.
+func (s *service) GenerateRecurringInvoices(now time.Time) (int, error) {
+	due, err := s.repo.GetDueRecurringInvoices(now)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	var errs []error
+	for i := range due {
+		n, err := s.generateRecurringInvoice(&due[i], now)
+		generated += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurring invoice %s: %w", due[i].ID, err))
+		}
+	}
+
+	return generated, errors.Join(errs...)
+}
+
+// generateRecurringInvoice invoices each due period of a template in turn and
+// moves NextRunAt past it. A period that fails keeps NextRunAt where it is,
+// so it is tried again on the next run.
+func (s *service) generateRecurringInvoice(recurring *models.RecurringInvoice, now time.Time) (int, error) {
+	generated := 0
+	for i := 0; i < maxCatchUpPeriods && recurring.Active && !recurring.NextRunAt.After(now); i++ {
+		if recurring.EndDate != nil && recurring.NextRunAt.After(*recurring.EndDate) {
+			recurring.Active = false
+			break
+		}
+
+		created, err := s.invoiceRecurringPeriod(recurring, recurring.NextRunAt)
+		if err != nil {
+			return generated, err
+		}
+		if created {
+			generated++
+		}
+
+		next, err := nextOccurrence(recurring, recurring.NextRunAt)
+		if err != nil {
+			// The schedule has no further occurrences
+			recurring.Active = false
+			break
+		}
+		recurring.NextRunAt = next
+		if recurring.EndDate != nil && next.After(*recurring.EndDate) {
+			recurring.Active = false
+		}
+		if err := s.repo.UpdateRecurringInvoice(recurring.ID, recurring); err != nil {
+			return generated, err
+		}
+	}
+
+	if !recurring.Active {
+		return generated, s.repo.UpdateRecurringInvoice(recurring.ID, recurring)
+	}
+	return generated, nil
+}
+
+// invoiceRecurringPeriod creates the invoice for one period of a template. The
+// period is claimed in the transaction that creates the invoice, so it is
+// invoiced at most once however often it is run, and a period whose invoice
+// cannot be created stays unclaimed to be tried again. It reports false when
+// the period had already been claimed.
+func (s *service) invoiceRecurringPeriod(recurring *models.RecurringInvoice, period time.Time) (bool, error) {
+	// The invoice is created on the owner's behalf
+	owner := auth.Principal{UserID: recurring.UserID}
+	invoice, err := s.prepareInvoice(owner, inputs.CreateInvoiceInput{
+		CustomerID:       recurring.CustomerID,
+		IssueDate:        period,
+		DueDate:          period.AddDate(0, 0, recurring.PaymentTermsDays),
+		Currency:         recurring.Currency,
+		DiscountType:     recurring.DiscountType,
+		Discount:         recurring.Discount,
+		PricesIncludeTax: recurring.PricesIncludeTax,
+		Note:             recurring.Note,
+		Items:            recurringItemInputs(recurring.Items),
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	run := &models.RecurringInvoiceRun{
+		ID:                 uuid.New(),
+		RecurringInvoiceID: recurring.ID,
+		PeriodStart:        period,
+	}
+	claimed := false
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		var err error
+		claimed, err = repo.ClaimRecurringInvoiceRun(run)
+		if err != nil || !claimed {
+			return err
+		}
+		if err := insertInvoice(repo, invoice, &owner); err != nil {
+			return err
+		}
+		return repo.SetRecurringInvoiceRunInvoice(run.ID, invoice.ID)
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if recurring.AutoSend {
+		if err := s.autoSendInvoice(invoice.ID); err != nil {
+			return true, fmt.Errorf("invoice %s was created but not sent: %w", invoice.ID, err)
+		}
+	}
+
+	return true, nil
+}
+
+// autoSendInvoice emails a generated invoice to the customer, or only marks
+// it as sent when email is not configured.
+func (s *service) autoSendInvoice(id uuid.UUID) error {
+	// Reload it with the customer and user the email needs
+	invoice, err := s.repo.GetInvoiceByID(id)
+	if err != nil {
+		return err
+	}
+
+	if s.mailer == nil {
+		sent := models.InvoiceStatusSent
+		_, err = s.UpdateInvoice(auth.Principal{UserID: invoice.UserID}, id, inputs.UpdateInvoiceInput{Status: &sent})
+		return err
+	}
+	_, err = s.deliverInvoice(invoice, nil, nil, nil, "")
+	return err
+}
+
+func (s *service) getOwnedRecurringInvoice(userID, id uuid.UUID) (*models.RecurringInvoice, error) {
+	recurring, err := s.repo.GetRecurringInvoiceByID(id)
+	if err != nil || recurring.UserID != userID {
+		return nil, ErrRecurringInvoiceNotFound
+	}
+	return recurring, nil
+}
+
+// validateRecurringItems checks a template's lines the way CreateInvoice
+// will, so a template that could never produce an invoice is rejected now.
+func (s *service) validateRecurringItems(recurring *models.RecurringInvoice, items []inputs.CreateInvoiceItemInput) error {
+	if len(items) == 0 {
+		return errors.New("recurring invoice must have at least one item")
+	}
+
+	itemTaxes, err := s.resolveItemTaxes(recurring.UserID, items)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]invoiceLine, len(items))
+	for i, item := range items {
+		lines[i] = invoiceLine{Quantity: item.Quantity, UnitPrice: item.UnitPrice, Taxes: itemTaxes[i]}
+	}
+	_, err = calculateTotals(invoicePricing{
+		Currency:         recurring.Currency,
+		DiscountType:     recurring.DiscountType,
+		DiscountValue:    recurring.Discount,
+		PricesIncludeTax: recurring.PricesIncludeTax,
+	}, lines)
+	return err
+}
+
+func validateRecurringInvoice(recurring *models.RecurringInvoice) error {
+	if recurring.Name == "" {
+		return errors.New("recurring invoice name is required")
+	}
+	if recurring.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if recurring.PaymentTermsDays < 0 || recurring.PaymentTermsDays > maxPaymentTermsDays {
+		return fmt.Errorf("payment terms must be between 0 and %d days", maxPaymentTermsDays)
+	}
+	if recurring.StartDate.IsZero() {
+		return errors.New("start date is required")
+	}
+	if recurring.EndDate != nil && recurring.EndDate.Before(recurring.StartDate) {
+		return errors.New("end date must not be before the start date")
+	}
+	return validateCadence(recurring.Cadence, recurring.CronRule)
+}
+
+func recurringItems(recurringID uuid.UUID, items []inputs.CreateInvoiceItemInput) []models.RecurringInvoiceItem {
+	result := make([]models.RecurringInvoiceItem, len(items))
+	for i, item := range items {
+		result[i] = models.RecurringInvoiceItem{
+			ID:                 uuid.New(),
+			RecurringInvoiceID: recurringID,
+			Description:        item.Description,
+			Quantity:           item.Quantity,
+			UnitPrice:          item.UnitPrice,
+			TaxRateIDs:         item.TaxRateIDs,
+			Position:           i,
+		}
+	}
+	return result
+}
+
+func recurringItemInputs(items []models.RecurringInvoiceItem) []inputs.CreateInvoiceItemInput {
+	result := make([]inputs.CreateInvoiceItemInput, len(items))
+	for i, item := range items {
+		result[i] = inputs.CreateInvoiceItemInput{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TaxRateIDs:  item.TaxRateIDs,
+		}
+	}
+	return result
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
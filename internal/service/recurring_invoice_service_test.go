@@ -0,0 +1,330 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func monthlyTemplate(userID uuid.UUID, start time.Time) *models.RecurringInvoice {
+	return &models.RecurringInvoice{
+		ID:               uuid.New(),
+		UserID:           userID,
+		CustomerID:       uuid.New(),
+		Name:             "Retainer",
+		Currency:         "USD",
+		DiscountType:     models.DiscountTypeFixed,
+		PaymentTermsDays: 14,
+		Cadence:          models.CadenceMonthly,
+		StartDate:        start,
+		NextRunAt:        start,
+		Active:           true,
+		Items: []models.RecurringInvoiceItem{
+			{Description: "Support", Quantity: 1, UnitPrice: money.NewFromInt(500)},
+		},
+	}
+}
+
+func TestCreateRecurringInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	customer := &models.Customer{ID: uuid.New(), UserID: userID}
+	start := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+	mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateRecurringInvoice", mock.AnythingOfType("*models.RecurringInvoice")).Return(nil)
+	mockRepo.On("ReplaceRecurringInvoiceItems", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("[]models.RecurringInvoiceItem")).Return(nil)
+
+	recurring, err := svc.CreateRecurringInvoice(auth.Principal{UserID: userID}, inputs.CreateRecurringInvoiceInput{
+		CustomerID: customer.ID,
+		Name:       "Retainer",
+		Currency:   "USD",
+		Cadence:    models.CadenceQuarterly,
+		StartDate:  start,
+		Items: []inputs.CreateInvoiceItemInput{
+			{Description: "Support", Quantity: 1, UnitPrice: money.NewFromInt(500)},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, start, recurring.NextRunAt)
+	assert.Equal(t, 30, recurring.PaymentTermsDays)
+	assert.True(t, recurring.Active)
+	assert.Len(t, recurring.Items, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRecurringInvoice_Rejected(t *testing.T) {
+	userID := uuid.New()
+	customer := &models.Customer{ID: uuid.New(), UserID: userID}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	items := []inputs.CreateInvoiceItemInput{{Description: "Support", Quantity: 1, UnitPrice: money.NewFromInt(500)}}
+
+	tests := []struct {
+		name   string
+		modify func(input *inputs.CreateRecurringInvoiceInput)
+	}{
+		{"start in the past", func(input *inputs.CreateRecurringInvoiceInput) { input.StartDate = yesterday }},
+		{"end before start", func(input *inputs.CreateRecurringInvoiceInput) { input.EndDate = &yesterday }},
+		{"unknown cadence", func(input *inputs.CreateRecurringInvoiceInput) { input.Cadence = "fortnightly" }},
+		{"custom without rule", func(input *inputs.CreateRecurringInvoiceInput) { input.Cadence = models.CadenceCustom }},
+		{"invalid cron rule", func(input *inputs.CreateRecurringInvoiceInput) {
+			input.Cadence = models.CadenceCustom
+			input.CronRule = "0 0 32 * *"
+		}},
+		{"no items", func(input *inputs.CreateRecurringInvoiceInput) { input.Items = nil }},
+		{"negative quantity", func(input *inputs.CreateRecurringInvoiceInput) {
+			input.Items = []inputs.CreateInvoiceItemInput{{Description: "Support", Quantity: -1, UnitPrice: money.NewFromInt(500)}}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+			mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+
+			input := inputs.CreateRecurringInvoiceInput{
+				CustomerID: customer.ID,
+				Name:       "Retainer",
+				Currency:   "USD",
+				Cadence:    models.CadenceMonthly,
+				StartDate:  tomorrow,
+				Items:      items,
+			}
+			tt.modify(&input)
+
+			_, err := svc.CreateRecurringInvoice(auth.Principal{UserID: userID}, input)
+
+			assert.Error(t, err)
+			mockRepo.AssertNotCalled(t, "CreateRecurringInvoice", mock.Anything)
+		})
+	}
+}
+
+func TestGenerateRecurringInvoices_CatchesUpMissedPeriods(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+	recurring := monthlyTemplate(userID, start)
+	now := time.Date(2026, time.March, 5, 9, 0, 0, 0, time.UTC)
+
+	var issued, due []time.Time
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("ClaimRecurringInvoiceRun", mock.AnythingOfType("*models.RecurringInvoiceRun")).Return(true, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(&models.Customer{ID: recurring.CustomerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.MatchedBy(func(invoice *models.Invoice) bool {
+		issued = append(issued, invoice.IssueDate)
+		due = append(due, invoice.DueDate)
+		return invoice.TotalAmount.Equal(money.NewFromInt(500))
+	})).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+	mockRepo.On("SetRecurringInvoiceRunInvoice", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil)
+	var nextRuns []time.Time
+	mockRepo.On("UpdateRecurringInvoice", recurring.ID, mock.MatchedBy(func(r *models.RecurringInvoice) bool {
+		nextRuns = append(nextRuns, r.NextRunAt)
+		return r.Active
+	})).Return(nil)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, generated)
+	// The February invoice falls on the last day of the month
+	assert.Equal(t, []time.Time{start, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC)}, issued)
+	assert.Equal(t, start.AddDate(0, 0, 14), due[0])
+	assert.Equal(t, []time.Time{
+		time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}, nextRuns)
+	mockRepo.AssertNumberOfCalls(t, "ClaimRecurringInvoiceRun", 2)
+}
+
+func TestGenerateRecurringInvoices_PeriodAlreadyInvoiced(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	recurring := monthlyTemplate(uuid.New(), start)
+	now := start.Add(time.Hour)
+
+	// Another run claimed the period before this one got to it
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(&models.Customer{ID: recurring.CustomerID, UserID: recurring.UserID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("ClaimRecurringInvoiceRun", mock.AnythingOfType("*models.RecurringInvoiceRun")).Return(false, nil)
+	mockRepo.On("UpdateRecurringInvoice", recurring.ID, mock.MatchedBy(func(r *models.RecurringInvoice) bool {
+		return r.NextRunAt.Equal(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC))
+	})).Return(nil)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, generated)
+	mockRepo.AssertNotCalled(t, "CreateInvoice", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGenerateRecurringInvoices_InvalidTemplate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	recurring := monthlyTemplate(uuid.New(), start)
+	now := start.Add(time.Hour)
+
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(nil, assert.AnError)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, generated)
+	// The period is not claimed, and NextRunAt is left alone so it is tried
+	// again
+	mockRepo.AssertNotCalled(t, "ClaimRecurringInvoiceRun", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateRecurringInvoice", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGenerateRecurringInvoices_LinkFails(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	recurring := monthlyTemplate(uuid.New(), start)
+	now := start.Add(time.Hour)
+
+	// The claim, the invoice and the link to it share one transaction, so a
+	// failed link rolls back the claim and the invoice with it
+	var tx []string
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(&models.Customer{ID: recurring.CustomerID, UserID: recurring.UserID}, nil)
+	mockRepo.On("WithTx", mock.Anything).Return(func(fn func(repo repository.Repository) error) error {
+		before := len(mockRepo.Calls)
+		err := fn(mockRepo)
+		for _, call := range mockRepo.Calls[before:] {
+			tx = append(tx, call.Method)
+		}
+		return err
+	})
+	mockRepo.On("ClaimRecurringInvoiceRun", mock.AnythingOfType("*models.RecurringInvoiceRun")).Return(true, nil)
+	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+	mockRepo.On("SetRecurringInvoiceRunInvoice", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(assert.AnError)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 0, generated)
+	assert.Contains(t, tx, "ClaimRecurringInvoiceRun")
+	assert.Contains(t, tx, "CreateInvoice")
+	assert.Contains(t, tx, "SetRecurringInvoiceRunInvoice")
+	mockRepo.AssertNumberOfCalls(t, "WithTx", 1)
+	mockRepo.AssertNotCalled(t, "UpdateRecurringInvoice", mock.Anything, mock.Anything)
+}
+
+func TestGenerateRecurringInvoices_StopsAfterEndDate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 10)
+	recurring := monthlyTemplate(userID, start)
+	recurring.Cadence = models.CadenceWeekly
+	recurring.EndDate = &end
+	now := start.AddDate(0, 1, 0)
+
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("ClaimRecurringInvoiceRun", mock.AnythingOfType("*models.RecurringInvoiceRun")).Return(true, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(&models.Customer{ID: recurring.CustomerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.AnythingOfType("*models.Invoice")).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.Anything).Return(&models.Invoice{}, nil)
+	expectRevision(mockRepo)
+	mockRepo.On("SetRecurringInvoiceRunInvoice", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil)
+	var last *models.RecurringInvoice
+	mockRepo.On("UpdateRecurringInvoice", recurring.ID, mock.MatchedBy(func(r *models.RecurringInvoice) bool {
+		copied := *r
+		last = &copied
+		return true
+	})).Return(nil)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	require.NoError(t, err)
+	// March 2nd and 9th; the 16th is past the end date
+	assert.Equal(t, 2, generated)
+	assert.False(t, last.Active)
+}
+
+func TestGenerateRecurringInvoices_AutoSend(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	recurring := monthlyTemplate(userID, start)
+	recurring.AutoSend = true
+	now := start.Add(time.Hour)
+
+	var created *models.Invoice
+	mockRepo.On("GetDueRecurringInvoices", now).Return([]models.RecurringInvoice{*recurring}, nil)
+	mockRepo.On("ClaimRecurringInvoiceRun", mock.AnythingOfType("*models.RecurringInvoiceRun")).Return(true, nil)
+	mockRepo.On("GetCustomerByID", recurring.CustomerID).Return(&models.Customer{ID: recurring.CustomerID, UserID: userID}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateInvoice", mock.MatchedBy(func(invoice *models.Invoice) bool {
+		created = invoice
+		return true
+	})).Return(nil)
+	mockRepo.On("CreateInvoiceItem", mock.AnythingOfType("*models.InvoiceItem")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+	mockRepo.On("SetRecurringInvoiceRunInvoice", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil)
+	mockRepo.On("GetInvoiceByID", mock.AnythingOfType("uuid.UUID")).Return(func(id uuid.UUID) *models.Invoice {
+		return created
+	}, nil)
+	mockRepo.On("LockInvoice", mock.AnythingOfType("uuid.UUID")).Return(func(id uuid.UUID) *models.Invoice {
+		return created
+	}, nil)
+	mockRepo.On("NumberInvoice", mock.AnythingOfType("*models.Invoice")).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Invoice).InvoiceNumber = "INV-2026-00001"
+	}).Return(nil)
+	mockRepo.On("UpdateInvoice", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("*models.Invoice")).Return(nil)
+	expectRevision(mockRepo)
+	mockRepo.On("UpdateRecurringInvoice", recurring.ID, mock.AnythingOfType("*models.RecurringInvoice")).Return(nil)
+
+	generated, err := svc.GenerateRecurringInvoices(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	assert.Equal(t, models.InvoiceStatusSent, created.Status)
+	assert.NotNil(t, created.SentAt)
+	assert.Equal(t, "INV-2026-00001", created.InvoiceNumber)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/scheduler"
+)
+
+var ErrInvalidCadence = errors.New("cadence must be weekly, monthly, quarterly, yearly or custom")
+
+// cadenceMonths is the length in months of each calendar cadence.
+var cadenceMonths = map[string]int{
+	models.CadenceMonthly:   1,
+	models.CadenceQuarterly: 3,
+	models.CadenceYearly:    12,
+}
+
+// validateCadence checks a template's cadence and, for a custom cadence, its
+// cron rule.
+func validateCadence(cadence, cronRule string) error {
+	switch cadence {
+	case models.CadenceWeekly, models.CadenceMonthly, models.CadenceQuarterly, models.CadenceYearly:
+		return nil
+	case models.CadenceCustom:
+		if cronRule == "" {
+			return errors.New("a custom cadence needs a cron rule")
+		}
+		_, err := scheduler.ParseCron(cronRule)
+		return err
+	default:
+		return ErrInvalidCadence
+	}
+}
+
+// nextOccurrence returns the first occurrence of a template's schedule after
+// the given time. Calendar cadences count from StartDate, so a monthly
+// template starting on the 31st runs on the last day of shorter months and
+// returns to the 31st afterwards. Custom cadences follow the cron rule in UTC
+// and never run before StartDate.
+func nextOccurrence(recurring *models.RecurringInvoice, after time.Time) (time.Time, error) {
+	start := recurring.StartDate.UTC()
+	if after.Before(start) {
+		after = start.Add(-time.Nanosecond)
+	}
+
+	if recurring.Cadence == models.CadenceCustom {
+		cron, err := scheduler.ParseCron(recurring.CronRule)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.Next(after.UTC())
+	}
+
+	var occurrence func(n int) time.Time
+	var estimate int
+	switch recurring.Cadence {
+	case models.CadenceWeekly:
+		occurrence = func(n int) time.Time { return start.AddDate(0, 0, 7*n) }
+		estimate = int(after.Sub(start).Hours() / 24 / 7)
+	default:
+		months, ok := cadenceMonths[recurring.Cadence]
+		if !ok {
+			return time.Time{}, ErrInvalidCadence
+		}
+		occurrence = func(n int) time.Time { return addMonthsClamped(start, n*months) }
+		estimate = monthsBetween(start, after) / months
+	}
+
+	// The estimate can be one period off either way; step from just before it
+	n := estimate - 1
+	if n < 0 {
+		n = 0
+	}
+	for !occurrence(n).After(after) {
+		n++
+	}
+	return occurrence(n), nil
+}
+
+// addMonthsClamped adds months to t, keeping its day of the month unless the
+// target month is shorter, in which case its last day is used.
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
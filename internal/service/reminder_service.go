@@ -0,0 +1,362 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Limits on a user's reminder schedule.
+const (
+	maxReminderTemplates   = 10
+	maxReminderLeadDays    = 30
+	maxReminderDelayDays   = 365
+	maxReminderSubjectSize = 200
+	maxReminderBodySize    = 10_000
+)
+
+const dayLength = 24 * time.Hour
+
+// reminderData is what reminder templates can refer to, e.g.
+// {{.InvoiceNumber}} or {{.DaysOverdue}}.
+type reminderData struct {
+	CustomerName  string
+	InvoiceNumber string
+	Currency      string
+	AmountDue     string
+	DueDate       string
+	DaysOverdue   int
+	DaysUntilDue  int
+	SenderName    string
+}
+
+var sampleReminderData = reminderData{
+	CustomerName:  "Acme Ltd",
+	InvoiceNumber: "INV-2026-00001",
+	Currency:      "USD",
+	AmountDue:     "100.00",
+	DueDate:       "1 January 2026",
+	DaysOverdue:   7,
+	SenderName:    "Jane Doe",
+}
+
+func (s *service) GetReminderSettings(principal auth.Principal) (*models.ReminderSettings, error) {
+	return s.reminderSettings(principal.UserID)
+}
+
+// UpdateReminderSettings changes a user's reminder schedule. "templates"
+// replaces the whole schedule.
+func (s *service) UpdateReminderSettings(principal auth.Principal, updates map[string]interface{}) error {
+	settings, err := s.reminderSettings(principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		switch key {
+		case "enabled":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			settings.Enabled = flag
+		case "templates":
+			var templates []models.ReminderTemplate
+			raw, err := json.Marshal(value)
+			if err != nil || json.Unmarshal(raw, &templates) != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			settings.Templates = templates
+		}
+	}
+
+	if err := validateReminderTemplates(settings.Templates); err != nil {
+		return err
+	}
+	sort.Slice(settings.Templates, func(i, j int) bool {
+		return settings.Templates[i].DaysOffset < settings.Templates[j].DaysOffset
+	})
+
+	return s.repo.SaveReminderSettings(settings)
+}
+
+// MarkOverdueInvoices moves every unpaid invoice whose due date has passed to
+// overdue. An invoice due on a day is overdue from the start of the next one.
+// It returns how many invoices were marked.
+func (s *service) MarkOverdueInvoices(now time.Time) (int, error) {
+	cutoff := now.UTC().Truncate(dayLength)
+	invoices, err := s.repo.GetOutstandingInvoices(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	var errs []error
+	for i := range invoices {
+		invoice := &invoices[i]
+		if invoice.Status == models.InvoiceStatusOverdue {
+			continue
+		}
+		// The invoice is locked and checked again, since it may have been
+		// paid or moved to a later due date since the list was read
+		var action models.ActivityAction
+		var from string
+		err := s.repo.WithTx(func(repo repository.Repository) error {
+			locked, err := repo.LockInvoice(invoice.ID)
+			if err != nil {
+				return err
+			}
+			invoice = locked
+			from = locked.Status
+			if !locked.DueDate.Before(cutoff) {
+				return nil
+			}
+			action, err = transitionInvoice(locked, models.InvoiceStatusOverdue, now)
+			if err != nil {
+				action = ""
+				return nil
+			}
+			return writeInvoice(repo, locked, nil, action)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", invoice.InvoiceNumber, err))
+			continue
+		}
+		if action == "" {
+			continue
+		}
+		s.logStatusChange(nil, invoice, action, from)
+		marked++
+	}
+
+	return marked, errors.Join(errs...)
+}
+
+// SendInvoiceReminders emails customers about unpaid invoices following each
+// user's reminder schedule, and returns how many reminders were sent.
+//
+// Only the latest step that has come due is sent, so an invoice that missed
+// earlier steps, e.g. because reminders were turned on late, gets one email
+// rather than a burst. Steps that fell before the invoice was sent are
+// skipped. Paid, void and written off invoices get no reminders.
+func (s *service) SendInvoiceReminders(now time.Time) (int, error) {
+	if s.mailer == nil {
+		return 0, nil
+	}
+
+	today := now.UTC().Truncate(dayLength)
+	invoices, err := s.repo.GetOutstandingInvoices(today.AddDate(0, 0, maxReminderLeadDays+1))
+	if err != nil {
+		return 0, err
+	}
+
+	settingsByUser := make(map[uuid.UUID]*models.ReminderSettings)
+	sent := 0
+	var errs []error
+	for i := range invoices {
+		invoice := &invoices[i]
+
+		settings, ok := settingsByUser[invoice.UserID]
+		if !ok {
+			settings, err = s.reminderSettings(invoice.UserID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			settingsByUser[invoice.UserID] = settings
+		}
+		if !settings.Enabled || invoice.Customer.Email == "" {
+			continue
+		}
+
+		step, ok := dueReminder(settings.Templates, invoice, today)
+		if !ok {
+			continue
+		}
+
+		err := s.sendInvoiceReminder(invoice, step, today, now)
+		if errors.Is(err, errReminderAlreadySent) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", invoice.InvoiceNumber, err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+var errReminderAlreadySent = errors.New("reminder already sent")
+
+// sendInvoiceReminder sends one step of the schedule for an invoice. The step
+// is claimed before sending, so it goes out at most once, and released again
+// if it cannot be sent.
+func (s *service) sendInvoiceReminder(invoice *models.Invoice, step models.ReminderTemplate, today, now time.Time) error {
+	subject, body, err := renderReminder(step, reminderDataFor(invoice, today))
+	if err != nil {
+		return err
+	}
+
+	reminder := &models.InvoiceReminder{
+		ID:         uuid.New(),
+		InvoiceID:  invoice.ID,
+		DaysOffset: step.DaysOffset,
+		UserID:     invoice.UserID,
+		SentTo:     invoice.Customer.Email,
+		SentAt:     now,
+	}
+	claimed, err := s.repo.ClaimInvoiceReminder(reminder)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errReminderAlreadySent
+	}
+
+	err = s.mailer.Send(mailer.Message{
+		To:      invoice.Customer.Email,
+		ReplyTo: invoice.User.Email,
+		Subject: subject,
+		Body:    body,
+	})
+	if err != nil {
+		_ = s.repo.DeleteInvoiceReminder(reminder.ID)
+		return err
+	}
+
+	s.logInvoiceEntityActivity(nil, invoice, models.ActivityReminderSent, models.EntityInvoiceReminder, reminder.ID, models.ActivityMetadata{
+		NewValues: map[string]interface{}{"to": invoice.Customer.Email},
+	})
+	return nil
+}
+
+// reminderSettings returns the user's reminder schedule, or the default one if
+// they have not configured their own.
+func (s *service) reminderSettings(userID uuid.UUID) (*models.ReminderSettings, error) {
+	settings, err := s.repo.GetReminderSettings(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultReminderSettings(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// dueReminder returns the latest step of the schedule that has come due for
+// an invoice, unless it fell before the invoice was sent.
+func dueReminder(templates []models.ReminderTemplate, invoice *models.Invoice, today time.Time) (models.ReminderTemplate, bool) {
+	dueDay := invoice.DueDate.UTC().Truncate(dayLength)
+
+	var step models.ReminderTemplate
+	found := false
+	for _, tpl := range templates {
+		if dueDay.AddDate(0, 0, tpl.DaysOffset).After(today) {
+			continue
+		}
+		if !found || tpl.DaysOffset > step.DaysOffset {
+			step, found = tpl, true
+		}
+	}
+	if !found {
+		return step, false
+	}
+
+	if invoice.SentAt != nil && dueDay.AddDate(0, 0, step.DaysOffset).Before(invoice.SentAt.UTC().Truncate(dayLength)) {
+		return step, false
+	}
+	return step, true
+}
+
+func reminderDataFor(invoice *models.Invoice, today time.Time) reminderData {
+	dueDay := invoice.DueDate.UTC().Truncate(dayLength)
+	days := int(today.Sub(dueDay) / dayLength)
+
+	data := reminderData{
+		CustomerName:  invoice.Customer.Name,
+		InvoiceNumber: invoice.InvoiceNumber,
+		Currency:      invoice.Currency,
+		AmountDue:     invoice.BalanceDue.Format(invoice.Currency),
+		DueDate:       invoice.DueDate.Format("2 January 2006"),
+		SenderName:    strings.TrimSpace(invoice.User.FirstName + " " + invoice.User.LastName),
+	}
+	if days > 0 {
+		data.DaysOverdue = days
+	} else {
+		data.DaysUntilDue = -days
+	}
+	return data
+}
+
+func renderReminder(tpl models.ReminderTemplate, data reminderData) (string, string, error) {
+	subject, err := executeReminderTemplate("subject", tpl.Subject, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := executeReminderTemplate("body", tpl.Body, data)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject), body, nil
+}
+
+func executeReminderTemplate(name, text string, data reminderData) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid reminder %s: %w", name, err)
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("invalid reminder %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+func validateReminderTemplates(templates []models.ReminderTemplate) error {
+	if len(templates) > maxReminderTemplates {
+		return fmt.Errorf("at most %d reminders can be scheduled", maxReminderTemplates)
+	}
+
+	seen := make(map[int]bool)
+	for _, tpl := range templates {
+		if tpl.DaysOffset < -maxReminderLeadDays || tpl.DaysOffset > maxReminderDelayDays {
+			return fmt.Errorf("reminders must be between %d days before and %d days after the due date",
+				maxReminderLeadDays, maxReminderDelayDays)
+		}
+		if seen[tpl.DaysOffset] {
+			return fmt.Errorf("more than one reminder is scheduled %d days from the due date", tpl.DaysOffset)
+		}
+		seen[tpl.DaysOffset] = true
+
+		if strings.TrimSpace(tpl.Subject) == "" || strings.TrimSpace(tpl.Body) == "" {
+			return errors.New("reminder subject and body are required")
+		}
+		if len(tpl.Subject) > maxReminderSubjectSize || len(tpl.Body) > maxReminderBodySize {
+			return fmt.Errorf("reminder subject and body can be at most %d and %d characters",
+				maxReminderSubjectSize, maxReminderBodySize)
+		}
+
+		// Try the template out so mistakes show up now rather than when it is sent
+		subject, _, err := renderReminder(tpl, sampleReminderData)
+		if err != nil {
+			return err
+		}
+		if strings.ContainsAny(subject, "\r\n") {
+			return errors.New("reminder subject must be a single line")
+		}
+	}
+	return nil
+}
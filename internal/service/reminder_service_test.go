@@ -0,0 +1,294 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/mailer"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type failingSender struct{}
+
+func (failingSender) Send(mailer.Message) error {
+	return errors.New("connection refused")
+}
+
+// remindableInvoice is a sent invoice to a customer with an email address,
+// due the given number of days before now.
+func remindableInvoice(now time.Time, daysOverdue int) *models.Invoice {
+	invoice := sentInvoice(uuid.New(), "250")
+	invoice.InvoiceNumber = "INV-2026-00007"
+	invoice.DueDate = now.AddDate(0, 0, -daysOverdue)
+	sentAt := now.AddDate(0, 0, -daysOverdue-30)
+	invoice.SentAt = &sentAt
+	invoice.User = models.User{ID: invoice.UserID, FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"}
+	invoice.Customer = models.Customer{Name: "Acme Ltd", Email: "billing@acme.test"}
+	return invoice
+}
+
+func TestMarkOverdueInvoices(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	late := remindableInvoice(now, 2)
+	alreadyOverdue := remindableInvoice(now, 5)
+	alreadyOverdue.Status = models.InvoiceStatusOverdue
+	// Paid, and given a later due date, after the list was read
+	paid := remindableInvoice(now, 3)
+	paidNow := *paid
+	paidNow.Status = models.InvoiceStatusPaid
+	postponed := remindableInvoice(now, 4)
+	postponedNow := *postponed
+	postponedNow.DueDate = now.AddDate(0, 0, 7)
+
+	mockRepo.On("GetOutstandingInvoices", time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)).
+		Return([]models.Invoice{*late, *paid, *postponed, *alreadyOverdue}, nil)
+	mockRepo.On("LockInvoice", late.ID).Return(late, nil)
+	mockRepo.On("LockInvoice", paid.ID).Return(&paidNow, nil)
+	mockRepo.On("LockInvoice", postponed.ID).Return(&postponedNow, nil)
+	mockRepo.On("UpdateInvoice", late.ID, mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.Status == models.InvoiceStatusOverdue
+	})).Return(nil).Once()
+	mockRepo.On("GetInvoiceByID", late.ID).Return(late, nil)
+	expectTx(mockRepo)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", late.ID).Return(nil, gorm.ErrRecordNotFound)
+	// Made by the scheduler, so no user is recorded
+	mockRepo.On("CreateInvoiceRevision", mock.MatchedBy(func(revision *models.InvoiceRevision) bool {
+		return revision.Action == "INVOICE_OVERDUE" && revision.ActorID == nil
+	})).Return(nil).Once()
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_OVERDUE" && *log.InvoiceID == late.ID
+	})).Return(nil).Once()
+
+	marked, err := svc.MarkOverdueInvoices(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendInvoiceReminders(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	invoice := remindableInvoice(now, 8)
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetReminderSettings", invoice.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("ClaimInvoiceReminder", mock.MatchedBy(func(reminder *models.InvoiceReminder) bool {
+		return reminder.InvoiceID == invoice.ID && reminder.DaysOffset == 7
+	})).Return(true, nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "REMINDER_SENT"
+	})).Return(nil)
+
+	sent, err := svc.SendInvoiceReminders(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	messages := sender.Sent()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "billing@acme.test", messages[0].To)
+	assert.Equal(t, "jane@example.com", messages[0].ReplyTo)
+	assert.Equal(t, "Invoice INV-2026-00007 is overdue", messages[0].Subject)
+	assert.Contains(t, messages[0].Body, "Hi Acme Ltd,")
+	assert.Contains(t, messages[0].Body, "USD 250.00 was due on 2 March 2026 and is now 8 days overdue")
+	assert.Contains(t, messages[0].Body, "Jane Doe")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendInvoiceReminders_Schedule(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		daysOverdue int
+		sentDaysAgo int
+		wantOffset  int
+		wantNone    bool
+	}{
+		{name: "too early", daysOverdue: -5, sentDaysAgo: 10, wantNone: true},
+		{name: "before the due date", daysOverdue: -3, sentDaysAgo: 10, wantOffset: -3},
+		{name: "on the due date", daysOverdue: 0, sentDaysAgo: 10, wantOffset: 0},
+		{name: "only the latest missed step", daysOverdue: 20, sentDaysAgo: 40, wantOffset: 14},
+		{name: "after the last step", daysOverdue: 90, sentDaysAgo: 120, wantOffset: 30},
+		{name: "step before the invoice was sent", daysOverdue: -2, sentDaysAgo: 0, wantNone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			sender := mailer.NewMemory()
+			svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+			invoice := remindableInvoice(now, tt.daysOverdue)
+			sentAt := now.AddDate(0, 0, -tt.sentDaysAgo)
+			invoice.SentAt = &sentAt
+
+			mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+			mockRepo.On("GetReminderSettings", invoice.UserID).Return(models.DefaultReminderSettings(invoice.UserID), nil)
+			var offset *int
+			mockRepo.On("ClaimInvoiceReminder", mock.MatchedBy(func(reminder *models.InvoiceReminder) bool {
+				offset = &reminder.DaysOffset
+				return true
+			})).Return(true, nil)
+			mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+			_, err := svc.SendInvoiceReminders(now)
+			require.NoError(t, err)
+
+			if tt.wantNone {
+				assert.Nil(t, offset)
+				assert.Empty(t, sender.Sent())
+				return
+			}
+			require.NotNil(t, offset)
+			assert.Equal(t, tt.wantOffset, *offset)
+			assert.Len(t, sender.Sent(), 1)
+		})
+	}
+}
+
+func TestSendInvoiceReminders_AlreadySent(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+	now := time.Now()
+	invoice := remindableInvoice(now, 8)
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetReminderSettings", invoice.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("ClaimInvoiceReminder", mock.AnythingOfType("*models.InvoiceReminder")).Return(false, nil)
+
+	sent, err := svc.SendInvoiceReminders(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, sender.Sent())
+	mockRepo.AssertNotCalled(t, "CreateActivityLog", mock.Anything)
+}
+
+func TestSendInvoiceReminders_Disabled(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	sender := mailer.NewMemory()
+	svc := service.NewService(mockRepo, service.WithMailer(sender))
+
+	now := time.Now()
+	invoice := remindableInvoice(now, 8)
+	settings := models.DefaultReminderSettings(invoice.UserID)
+	settings.Enabled = false
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetReminderSettings", invoice.UserID).Return(settings, nil)
+
+	sent, err := svc.SendInvoiceReminders(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	mockRepo.AssertNotCalled(t, "ClaimInvoiceReminder", mock.Anything)
+}
+
+func TestSendInvoiceReminders_SendFailureReleasesClaim(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithMailer(failingSender{}))
+
+	now := time.Now()
+	invoice := remindableInvoice(now, 8)
+
+	mockRepo.On("GetOutstandingInvoices", mock.AnythingOfType("time.Time")).Return([]models.Invoice{*invoice}, nil)
+	mockRepo.On("GetReminderSettings", invoice.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("ClaimInvoiceReminder", mock.AnythingOfType("*models.InvoiceReminder")).Return(true, nil)
+	mockRepo.On("DeleteInvoiceReminder", mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+	sent, err := svc.SendInvoiceReminders(now)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateActivityLog", mock.Anything)
+}
+
+func TestSendInvoiceReminders_WithoutMailer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	sent, err := svc.SendInvoiceReminders(time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	mockRepo.AssertNotCalled(t, "GetOutstandingInvoices", mock.Anything)
+}
+
+func TestUpdateReminderSettings(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.On("GetReminderSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveReminderSettings", mock.MatchedBy(func(settings *models.ReminderSettings) bool {
+		return settings.UserID == userID &&
+			len(settings.Templates) == 2 &&
+			settings.Templates[0].DaysOffset == -1 &&
+			settings.Templates[1].DaysOffset == 10
+	})).Return(nil)
+
+	err := svc.UpdateReminderSettings(auth.Principal{UserID: userID}, map[string]interface{}{
+		"templates": []interface{}{
+			map[string]interface{}{"DaysOffset": float64(10), "Subject": "{{.InvoiceNumber}} is late", "Body": "Please pay {{.AmountDue}}."},
+			map[string]interface{}{"DaysOffset": float64(-1), "Subject": "{{.InvoiceNumber}} is due tomorrow", "Body": "Hi {{.CustomerName}}"},
+		},
+	})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateReminderSettings_Invalid(t *testing.T) {
+	userID := uuid.New()
+	template := func(offset float64, subject, body string) map[string]interface{} {
+		return map[string]interface{}{"DaysOffset": offset, "Subject": subject, "Body": body}
+	}
+
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		wantErr string
+	}{
+		{"enabled not a bool", map[string]interface{}{"enabled": "yes"}, "invalid value for enabled"},
+		{"templates not a list", map[string]interface{}{"templates": "weekly"}, "invalid value for templates"},
+		{"offset out of range", map[string]interface{}{"templates": []interface{}{template(-45, "Due", "Pay")}}, "between 30 days before"},
+		{"duplicate offset", map[string]interface{}{"templates": []interface{}{template(7, "A", "B"), template(7, "C", "D")}}, "more than one reminder"},
+		{"empty body", map[string]interface{}{"templates": []interface{}{template(7, "Late", " ")}}, "subject and body are required"},
+		{"syntax error", map[string]interface{}{"templates": []interface{}{template(7, "{{.InvoiceNumber", "Pay")}}, "invalid reminder subject"},
+		{"unknown field", map[string]interface{}{"templates": []interface{}{template(7, "Late", "{{.Password}}")}}, "invalid reminder body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+			mockRepo.On("GetReminderSettings", userID).Return(nil, gorm.ErrRecordNotFound)
+
+			err := svc.UpdateReminderSettings(auth.Principal{UserID: userID}, tt.updates)
+
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tt.wantErr), err.Error())
+			mockRepo.AssertNotCalled(t, "SaveReminderSettings", mock.Anything)
+		})
+	}
+}
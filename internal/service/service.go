@@ -1,43 +1,202 @@
 package service
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mailer"
 	"github.com/iyiola-dev/numeris/internal/models"
 	"github.com/iyiola-dev/numeris/internal/repository"
 	"github.com/iyiola-dev/numeris/internal/response"
 )
 
 type Service interface {
-	  // Auth
-	  Register(input inputs.RegisterInput) (*models.User, error)
-	  Login(input inputs.LoginInput) (*response.LoginResponse, error)
-	  
-	
+	// Auth
+	Register(input inputs.RegisterInput) (*models.User, error)
+	Login(input inputs.LoginInput) (*response.LoginResponse, error)
+	Refresh(input inputs.RefreshInput) (*response.TokenPair, error)
+	Logout(principal auth.Principal) error
+	LogoutAll(principal auth.Principal) (int, error)
+	RequestEmailVerification(input inputs.EmailInput) error
+	VerifyEmail(token string) error
+	RequestPasswordReset(input inputs.EmailInput) error
+	ResetPassword(input inputs.ResetPasswordInput) error
+	ChangePassword(principal auth.Principal, input inputs.ChangePasswordInput) error
+
 	// Invoice
-	CreateInvoice(input inputs.CreateInvoiceInput) (*models.Invoice, error)
-	GetInvoiceByID(id uuid.UUID) (*models.Invoice, error)
-	GetInvoices(filters map[string]interface{}) ([]models.Invoice, error)
-	UpdateInvoice(id uuid.UUID, updates map[string]interface{}) error
-	DeleteInvoice(id uuid.UUID) error
-	GetInvoiceWithItems(id uuid.UUID) (*models.Invoice, error)
-	
+	CreateInvoice(principal auth.Principal, input inputs.CreateInvoiceInput) (*models.Invoice, error)
+	GetInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error)
+	GetInvoices(principal auth.Principal) ([]models.Invoice, error)
+	UpdateInvoice(principal auth.Principal, id uuid.UUID, input inputs.UpdateInvoiceInput) (*models.Invoice, error)
+	DeleteInvoice(principal auth.Principal, id uuid.UUID) error
+	GetDeletedInvoices(principal auth.Principal) ([]models.Invoice, error)
+	RestoreInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error)
+	VoidInvoice(principal auth.Principal, id uuid.UUID) (*models.Invoice, error)
+	GetInvoiceRevisions(principal auth.Principal, invoiceID uuid.UUID) ([]models.InvoiceRevision, error)
+	GetInvoiceRevision(principal auth.Principal, invoiceID uuid.UUID, number int) (*models.InvoiceRevision, error)
+	DiffInvoiceRevisions(principal auth.Principal, invoiceID uuid.UUID, from, to int) (*response.InvoiceRevisionDiff, error)
+	GetInvoiceRevisionPDF(principal auth.Principal, invoiceID uuid.UUID, number int) (*response.InvoicePDF, error)
+
+	// Customer
+	CreateCustomer(principal auth.Principal, input inputs.CreateCustomerInput) (*models.Customer, error)
+	GetCustomers(principal auth.Principal) ([]models.Customer, error)
+	GetCustomer(principal auth.Principal, id uuid.UUID) (*response.CustomerResponse, error)
+	UpdateCustomer(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error
+	DeleteCustomer(principal auth.Principal, id uuid.UUID) error
+
+	// Tax Rates
+	CreateTaxRate(principal auth.Principal, input inputs.CreateTaxRateInput) (*models.TaxRate, error)
+	GetTaxRates(principal auth.Principal) ([]models.TaxRate, error)
+	GetTaxRate(principal auth.Principal, id uuid.UUID) (*models.TaxRate, error)
+	UpdateTaxRate(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error
+	DeleteTaxRate(principal auth.Principal, id uuid.UUID) error
+
+	// Payments
+	RecordPayment(principal auth.Principal, input inputs.RecordPaymentInput) (*models.Payment, error)
+	GetPayments(principal auth.Principal, invoiceID uuid.UUID) ([]models.Payment, error)
+	RefundPayment(principal auth.Principal, input inputs.RefundPaymentInput) (*models.Payment, error)
+	DeletePayment(principal auth.Principal, invoiceID, paymentID uuid.UUID) error
+
+	// Invoice Delivery
+	SendInvoice(principal auth.Principal, input inputs.SendInvoiceInput) (*models.InvoiceDelivery, error)
+	GetInvoiceDeliveries(principal auth.Principal, invoiceID uuid.UUID) ([]models.InvoiceDelivery, error)
+
+	// Invoice PDF
+	GetInvoicePDF(principal auth.Principal, id uuid.UUID) (*response.InvoicePDF, error)
+	GetInvoiceTemplateSettings(principal auth.Principal) (*models.InvoiceTemplateSettings, error)
+	UpdateInvoiceTemplateSettings(principal auth.Principal, updates map[string]interface{}) error
+
+	// Share Links
+	CreateShareLink(principal auth.Principal, input inputs.CreateShareLinkInput) (*response.ShareLinkResponse, error)
+	GetShareLinks(principal auth.Principal, invoiceID uuid.UUID) ([]models.ShareLink, error)
+	RevokeShareLink(principal auth.Principal, invoiceID, linkID uuid.UUID) error
+	GetSharedInvoice(token string) (*response.SharedInvoice, error)
+	GetSharedInvoicePDF(token string) (*response.InvoicePDF, error)
+
+	// Recurring Invoices
+	CreateRecurringInvoice(principal auth.Principal, input inputs.CreateRecurringInvoiceInput) (*models.RecurringInvoice, error)
+	GetRecurringInvoices(principal auth.Principal) ([]models.RecurringInvoice, error)
+	GetRecurringInvoice(principal auth.Principal, id uuid.UUID) (*models.RecurringInvoice, error)
+	UpdateRecurringInvoice(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error
+	DeleteRecurringInvoice(principal auth.Principal, id uuid.UUID) error
+	GenerateRecurringInvoices(now time.Time) (int, error)
+
+	// Reminders
+	GetReminderSettings(principal auth.Principal) (*models.ReminderSettings, error)
+	UpdateReminderSettings(principal auth.Principal, updates map[string]interface{}) error
+	MarkOverdueInvoices(now time.Time) (int, error)
+	SendInvoiceReminders(now time.Time) (int, error)
+
+	// Late Fees
+	GetLateFeePolicy(principal auth.Principal) (*models.LateFeePolicy, error)
+	UpdateLateFeePolicy(principal auth.Principal, updates map[string]interface{}) error
+	GetInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID) (*models.LateFeePolicy, error)
+	UpdateInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID, updates map[string]interface{}) error
+	DeleteInvoiceLateFeePolicy(principal auth.Principal, invoiceID uuid.UUID) error
+	GetLateFees(principal auth.Principal, invoiceID uuid.UUID) ([]models.LateFee, error)
+	ReverseLateFee(principal auth.Principal, input inputs.ReverseLateFeeInput) (*models.LateFee, error)
+	ChargeLateFees(now time.Time) (int, error)
+
+	// Credit Notes
+	CreateCreditNote(principal auth.Principal, input inputs.CreateCreditNoteInput) (*models.CreditNote, error)
+	GetCreditNotes(principal auth.Principal) ([]models.CreditNote, error)
+	GetInvoiceCreditNotes(principal auth.Principal, invoiceID uuid.UUID) ([]models.CreditNote, error)
+	GetCreditNote(principal auth.Principal, id uuid.UUID) (*models.CreditNote, error)
+	GetCreditNotePDF(principal auth.Principal, id uuid.UUID) (*response.InvoicePDF, error)
+
+	// Quotes
+	CreateQuote(principal auth.Principal, input inputs.CreateQuoteInput) (*models.Quote, error)
+	GetQuotes(principal auth.Principal) ([]models.Quote, error)
+	GetQuote(principal auth.Principal, id uuid.UUID) (*models.Quote, error)
+	UpdateQuote(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error
+	DeleteQuote(principal auth.Principal, id uuid.UUID) error
+	SendQuote(principal auth.Principal, id uuid.UUID) (*response.QuoteLinkResponse, error)
+	GetSharedQuote(token string) (*response.SharedQuote, error)
+	AcceptQuote(input inputs.QuoteResponseInput) (*response.SharedQuote, error)
+	DeclineQuote(input inputs.QuoteResponseInput) (*response.SharedQuote, error)
+	ConvertQuote(principal auth.Principal, id uuid.UUID) (*models.Invoice, error)
+	ExpireQuotes(now time.Time) (int, error)
+
+	// Invoice Numbering
+	GetInvoiceSequence(principal auth.Principal) (*response.InvoiceSequenceResponse, error)
+	UpdateInvoiceSequence(principal auth.Principal, updates map[string]interface{}) error
+	GetCreditNoteSequence(principal auth.Principal) (*response.CreditNoteSequenceResponse, error)
+	UpdateCreditNoteSequence(principal auth.Principal, updates map[string]interface{}) error
+	GetQuoteSequence(principal auth.Principal) (*response.QuoteSequenceResponse, error)
+	UpdateQuoteSequence(principal auth.Principal, updates map[string]interface{}) error
+
 	// Payment Details
-	CreatePaymentDetails(input inputs.CreatePaymentDetailsInput) (*models.PaymentDetails, error)
-	GetPaymentDetailsByInvoiceID(invoiceID uuid.UUID) (*models.PaymentDetails, error)
-	UpdatePaymentDetails(id uuid.UUID, updates map[string]interface{}) error
-	DeletePaymentDetails(id uuid.UUID) error
-	
+	CreatePaymentDetails(principal auth.Principal, input inputs.CreatePaymentDetailsInput) (*models.PaymentDetails, error)
+	GetPaymentDetails(principal auth.Principal, invoiceID uuid.UUID) (*models.PaymentDetails, error)
+	UpdatePaymentDetails(principal auth.Principal, invoiceID uuid.UUID, updates map[string]interface{}) error
+	DeletePaymentDetails(principal auth.Principal, invoiceID uuid.UUID) error
+
 	// Activity Logs
-	GetActivityLogs(filters map[string]interface{}) ([]models.ActivityLog, error)
+	GetActivityLogs(principal auth.Principal, input inputs.ActivityLogsInput) (*response.ActivityLogPage, error)
 }
 
-
 type service struct {
-	repo repository.Repository
+	repo      repository.Repository
+	mailer    mailer.Sender
+	tokens    *auth.Tokens
+	publicURL string
+
+	// background starts work a request should not wait for
+	background func(func())
+
+	requireVerifiedEmail bool
+}
+
+// Option configures optional dependencies of the service.
+type Option func(*service)
+
+// WithMailer sets the sender used for customer and account email. Without
+// one, no email is sent.
+func WithMailer(sender mailer.Sender) Option {
+	return func(s *service) {
+		s.mailer = sender
+	}
+}
+
+// WithTokens sets what access tokens are issued with. Without it, nobody can
+// log in.
+func WithTokens(tokens *auth.Tokens) Option {
+	return func(s *service) {
+		s.tokens = tokens
+	}
+}
+
+// WithEmailVerificationRequired refuses logins from users who have not
+// verified their email address.
+func WithEmailVerificationRequired() Option {
+	return func(s *service) {
+		s.requireVerifiedEmail = true
+	}
+}
+
+// WithPublicURL sets the address the API is reached at from outside, e.g.
+// https://invoices.example.com, used for links in customer email.
+func WithPublicURL(url string) Option {
+	return func(s *service) {
+		s.publicURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithBackground sets how work a request should not wait for, such as
+// account email, is started. By default each piece runs in its own goroutine.
+func WithBackground(run func(func())) Option {
+	return func(s *service) {
+		s.background = run
+	}
 }
 
-func NewService(repo repository.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo repository.Repository, opts ...Option) Service {
+	s := &service{repo: repo, background: func(fn func()) { go fn() }}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
-	
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidRefreshToken is returned for unknown, expired and revoked refresh
+// tokens alike.
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// ErrRefreshTokenReused is returned when a refresh token is used a second
+// time. The session it belongs to is revoked, since one of the two callers
+// must have stolen it.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used; session revoked")
+
+// startSession opens a session for user and issues its first tokens.
+func (s *service) startSession(user *models.User, ipAddress, userAgent string) (*response.TokenPair, error) {
+	if s.tokens == nil {
+		return nil, errors.New("error generating authentication token")
+	}
+
+	var pair *response.TokenPair
+	err := s.repo.WithTx(func(repo repository.Repository) error {
+		session := &models.Session{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			IPAddress: ipAddress,
+			UserAgent: userAgent,
+			ExpiresAt: time.Now().Add(s.tokens.RefreshTTL()),
+		}
+		if err := repo.CreateSession(session); err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = s.issueTokens(repo, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// issueTokens stores a new refresh token for session and signs an access
+// token to go with it. The refresh token lasts as long as the session.
+func (s *service) issueTokens(repo repository.Repository, session *models.Session) (*response.TokenPair, error) {
+	refresh, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.CreateRefreshToken(&models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: hashShareToken(refresh),
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.tokens.Issue(session.UserID, session.ID)
+	if err != nil {
+		return nil, errors.New("error generating authentication token")
+	}
+
+	return &response.TokenPair{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token works once: using one again revokes its session,
+// so a stolen token stops working for the thief and the user alike.
+func (s *service) Refresh(input inputs.RefreshInput) (*response.TokenPair, error) {
+	if s.tokens == nil {
+		return nil, errors.New("error generating authentication token")
+	}
+
+	stored, err := s.repo.GetRefreshTokenByHash(hashShareToken(input.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetSessionByID(stored.SessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !session.Active(now) || !now.Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedSession(session, input)
+	}
+
+	user, err := s.repo.GetUserByID(session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !user.Active {
+		return nil, errors.New("account is inactive")
+	}
+
+	var pair *response.TokenPair
+	reused := false
+	err = s.repo.WithTx(func(repo repository.Repository) error {
+		claimed, err := repo.ClaimRefreshToken(stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			reused = true
+			return nil
+		}
+		if err := repo.TouchSession(session.ID, now); err != nil {
+			return err
+		}
+
+		pair, err = s.issueTokens(repo, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Another request claimed the token between our read and our update
+	if reused {
+		return nil, s.revokeReusedSession(session, input)
+	}
+	return pair, nil
+}
+
+// revokeReusedSession revokes a session whose refresh token was used twice.
+func (s *service) revokeReusedSession(session *models.Session, input inputs.RefreshInput) error {
+	if err := s.repo.RevokeSession(session.ID, time.Now(), models.SessionRevokedTokenReuse); err != nil {
+		return err
+	}
+	s.logActivity(&models.ActivityLog{
+		UserID:     session.UserID,
+		EntityType: models.EntitySession,
+		EntityID:   &session.ID,
+		Action:     models.ActivityRefreshTokenReused,
+		Metadata: models.ActivityMetadata{
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+			RequestID: input.RequestID,
+		},
+	})
+	return ErrRefreshTokenReused
+}
+
+// Logout revokes the session the principal authenticated with, which ends
+// its access token and refresh token.
+func (s *service) Logout(principal auth.Principal) error {
+	if err := s.repo.RevokeSession(principal.SessionID, time.Now(), models.SessionRevokedLogout); err != nil {
+		return err
+	}
+	s.logActivity(sessionActivity(principal, models.ActivityLogout, &principal.SessionID, nil))
+	return nil
+}
+
+// LogoutAll revokes every open session of the principal's user, the current
+// one included, and returns how many were revoked.
+func (s *service) LogoutAll(principal auth.Principal) (int, error) {
+	revoked, err := s.repo.RevokeUserSessions(principal.UserID, time.Now(), models.SessionRevokedLogoutAll)
+	if err != nil {
+		return 0, err
+	}
+	s.logActivity(sessionActivity(principal, models.ActivityLogoutAll, nil, map[string]interface{}{
+		"sessions": revoked,
+	}))
+	return revoked, nil
+}
+
+func sessionActivity(principal auth.Principal, action models.ActivityAction, sessionID *uuid.UUID, newValues map[string]interface{}) *models.ActivityLog {
+	entry := &models.ActivityLog{
+		UserID:     principal.UserID,
+		EntityType: models.EntityUser,
+		EntityID:   &principal.UserID,
+		Action:     action,
+		Metadata: models.ActivityMetadata{
+			NewValues: newValues,
+			IPAddress: principal.IPAddress,
+			UserAgent: principal.UserAgent,
+			RequestID: principal.RequestID,
+		},
+	}
+	if sessionID != nil {
+		entry.EntityType = models.EntitySession
+		entry.EntityID = sessionID
+	}
+	return entry
+}
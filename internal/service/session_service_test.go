@@ -0,0 +1,239 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func openSession(userID uuid.UUID) *models.Session {
+	return &models.Session{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(24 * time.Hour)}
+}
+
+func refreshToken(session *models.Session, token string) *models.RefreshToken {
+	return &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: hashToken(token),
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	tokens := testTokens(t)
+	svc := service.NewService(mockRepo, service.WithTokens(tokens))
+
+	user := &models.User{ID: uuid.New(), Active: true}
+	session := openSession(user.ID)
+	stored := refreshToken(session, "old-refresh-token")
+
+	var rotated *models.RefreshToken
+	mockRepo.On("GetRefreshTokenByHash", hashToken("old-refresh-token")).Return(stored, nil)
+	mockRepo.On("GetSessionByID", session.ID).Return(session, nil)
+	mockRepo.On("GetUserByID", user.ID).Return(user, nil)
+	expectTx(mockRepo)
+	mockRepo.On("ClaimRefreshToken", stored.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("TouchSession", session.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateRefreshToken", mock.AnythingOfType("*models.RefreshToken")).Run(func(args mock.Arguments) {
+		rotated = args.Get(0).(*models.RefreshToken)
+	}).Return(nil)
+
+	pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "old-refresh-token"})
+
+	assert.NoError(t, err)
+	// The used token is replaced by a new one in the same session
+	assert.NotEqual(t, "old-refresh-token", pair.RefreshToken)
+	assert.Equal(t, hashToken(pair.RefreshToken), rotated.TokenHash)
+	assert.Equal(t, session.ID, rotated.SessionID)
+	assert.Equal(t, session.ExpiresAt, pair.RefreshExpiresAt)
+
+	claims, err := tokens.Verify(pair.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, session.ID.String(), claims.SessionID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_Reused(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)))
+
+	session := openSession(uuid.New())
+	stored := refreshToken(session, "stolen-token")
+	usedAt := time.Now().Add(-time.Minute)
+	stored.UsedAt = &usedAt
+
+	var entry *models.ActivityLog
+	mockRepo.On("GetRefreshTokenByHash", hashToken("stolen-token")).Return(stored, nil)
+	mockRepo.On("GetSessionByID", session.ID).Return(session, nil)
+	mockRepo.On("RevokeSession", session.ID, mock.AnythingOfType("time.Time"), models.SessionRevokedTokenReuse).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*models.ActivityLog)
+	}).Return(nil)
+
+	pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "stolen-token", IPAddress: "198.51.100.4"})
+
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	assert.Nil(t, pair)
+	assert.Equal(t, models.ActivityRefreshTokenReused, entry.Action)
+	assert.Equal(t, models.EntitySession, entry.EntityType)
+	assert.Equal(t, &session.ID, entry.EntityID)
+	assert.Equal(t, "198.51.100.4", entry.Metadata.IPAddress)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+// Two requests refreshing with the same token at once: the second loses the
+// claim and the session is revoked, as if the token had been reused.
+func TestRefresh_ConcurrentUse(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)))
+
+	user := &models.User{ID: uuid.New(), Active: true}
+	session := openSession(user.ID)
+	stored := refreshToken(session, "raced-token")
+
+	mockRepo.On("GetRefreshTokenByHash", hashToken("raced-token")).Return(stored, nil)
+	mockRepo.On("GetSessionByID", session.ID).Return(session, nil)
+	mockRepo.On("GetUserByID", user.ID).Return(user, nil)
+	expectTx(mockRepo)
+	mockRepo.On("ClaimRefreshToken", stored.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
+	mockRepo.On("RevokeSession", session.ID, mock.AnythingOfType("time.Time"), models.SessionRevokedTokenReuse).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "raced-token"})
+
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	assert.Nil(t, pair)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestRefresh_Rejected(t *testing.T) {
+	userID := uuid.New()
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		setup   func(session *models.Session, token *models.RefreshToken)
+		wantErr error
+	}{
+		{"revoked session", func(s *models.Session, _ *models.RefreshToken) {
+			s.RevokedAt = &revokedAt
+		}, service.ErrInvalidRefreshToken},
+		{"expired session", func(s *models.Session, _ *models.RefreshToken) {
+			s.ExpiresAt = time.Now().Add(-time.Minute)
+		}, service.ErrInvalidRefreshToken},
+		{"expired token", func(_ *models.Session, token *models.RefreshToken) {
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+		}, service.ErrInvalidRefreshToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)))
+
+			session := openSession(userID)
+			stored := refreshToken(session, "refresh-token")
+			tt.setup(session, stored)
+
+			mockRepo.On("GetRefreshTokenByHash", hashToken("refresh-token")).Return(stored, nil)
+			mockRepo.On("GetSessionByID", session.ID).Return(session, nil)
+
+			pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "refresh-token"})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, pair)
+			mockRepo.AssertExpectations(t)
+			mockRepo.AssertNotCalled(t, "WithTx", mock.Anything)
+		})
+	}
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)))
+
+	mockRepo.On("GetRefreshTokenByHash", hashToken("unknown")).Return(nil, gorm.ErrRecordNotFound)
+
+	pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "unknown"})
+
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	assert.Nil(t, pair)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_InactiveUser(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo, service.WithTokens(testTokens(t)))
+
+	user := &models.User{ID: uuid.New(), Active: false}
+	session := openSession(user.ID)
+	stored := refreshToken(session, "refresh-token")
+
+	mockRepo.On("GetRefreshTokenByHash", hashToken("refresh-token")).Return(stored, nil)
+	mockRepo.On("GetSessionByID", session.ID).Return(session, nil)
+	mockRepo.On("GetUserByID", user.ID).Return(user, nil)
+
+	pair, err := svc.Refresh(inputs.RefreshInput{RefreshToken: "refresh-token"})
+
+	assert.EqualError(t, err, "account is inactive")
+	assert.Nil(t, pair)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogout(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	principal := auth.Principal{UserID: uuid.New(), SessionID: uuid.New(), RequestID: "request-1"}
+
+	var entry *models.ActivityLog
+	mockRepo.On("RevokeSession", principal.SessionID, mock.AnythingOfType("time.Time"), models.SessionRevokedLogout).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*models.ActivityLog)
+	}).Return(nil)
+
+	err := svc.Logout(principal)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ActivityLogout, entry.Action)
+	assert.Equal(t, principal.UserID, entry.UserID)
+	assert.Equal(t, &principal.SessionID, entry.EntityID)
+	assert.Equal(t, "request-1", entry.Metadata.RequestID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogoutAll(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	principal := auth.Principal{UserID: uuid.New(), SessionID: uuid.New()}
+
+	var entry *models.ActivityLog
+	mockRepo.On("RevokeUserSessions", principal.UserID, mock.AnythingOfType("time.Time"), models.SessionRevokedLogoutAll).Return(3, nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Run(func(args mock.Arguments) {
+		entry = args.Get(0).(*models.ActivityLog)
+	}).Return(nil)
+
+	revoked, err := svc.LogoutAll(principal)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, revoked)
+	assert.Equal(t, models.ActivityLogoutAll, entry.Action)
+	assert.Equal(t, models.EntityUser, entry.EntityType)
+	assert.Equal(t, 3, entry.Metadata.NewValues["sessions"])
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/repository"
+	"github.com/iyiola-dev/numeris/internal/response"
+	"gorm.io/gorm"
+)
+
+// ErrShareLinkNotFound is returned for unknown, expired and revoked links
+// alike, so a token reveals nothing about why it stopped working.
+var ErrShareLinkNotFound = errors.New("share link not found")
+
+// sharedPathPrefix is where the public routes serve shared invoices.
+const sharedPathPrefix = "/api/shared/"
+
+// shareLinkViewWindow is how long after a counted view further views of the
+// same link are neither counted nor logged, so reloading a page or
+// downloading the PDF does not flood the activity log.
+const shareLinkViewWindow = 30 * time.Minute
+
+// CreateShareLink creates a link that lets anyone holding it view an issued
+// invoice. The token is returned once and only its hash is stored.
+func (s *service) CreateShareLink(principal auth.Principal, input inputs.CreateShareLinkInput) (*response.ShareLinkResponse, error) {
+	invoice, err := s.getOwnedInvoice(principal.UserID, input.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceStatusDraft {
+		return nil, errors.New("draft invoices cannot be shared")
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	link, err := s.newShareLink(invoice, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityShareLinkCreated, models.EntityShareLink, link.Link.ID, models.ActivityMetadata{})
+
+	return link, nil
+}
+
+// newShareLink stores a new link to an invoice and returns it with its token.
+func (s *service) newShareLink(invoice *models.Invoice, expiresAt *time.Time) (*response.ShareLinkResponse, error) {
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		ID:          uuid.New(),
+		InvoiceID:   invoice.ID,
+		UserID:      invoice.UserID,
+		TokenHash:   hashShareToken(token),
+		TokenPrefix: token[:8],
+		ExpiresAt:   expiresAt,
+	}
+
+	err = s.repo.CreateShareLink(link)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ShareLinkResponse{
+		Link:  link,
+		Token: token,
+		Path:  sharedPathPrefix + token,
+	}, nil
+}
+
+// GetShareLinks lists an invoice's links that can still be used.
+func (s *service) GetShareLinks(principal auth.Principal, invoiceID uuid.UUID) ([]models.ShareLink, error) {
+	if _, err := s.getOwnedInvoice(principal.UserID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.GetShareLinks(map[string]interface{}{
+		"invoice_id": invoiceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]models.ShareLink, 0, len(links))
+	for _, link := range links {
+		if link.Active(now) {
+			active = append(active, link)
+		}
+	}
+	return active, nil
+}
+
+func (s *service) RevokeShareLink(principal auth.Principal, invoiceID, linkID uuid.UUID) error {
+	invoice, err := s.getOwnedInvoice(principal.UserID, invoiceID)
+	if err != nil {
+		return err
+	}
+
+	links, err := s.repo.GetShareLinks(map[string]interface{}{
+		"id":         linkID,
+		"invoice_id": invoice.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 || links[0].RevokedAt != nil {
+		return ErrShareLinkNotFound
+	}
+
+	if err := s.repo.RevokeShareLink(linkID, time.Now()); err != nil {
+		return err
+	}
+	s.logInvoiceEntityActivity(&principal, invoice, models.ActivityShareLinkRevoked, models.EntityShareLink, linkID, models.ActivityMetadata{})
+
+	return nil
+}
+
+// GetSharedInvoice returns the customer facing view of the invoice behind a
+// share link and records the view.
+func (s *service) GetSharedInvoice(token string) (*response.SharedInvoice, error) {
+	invoice, err := s.openShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.repo.GetPaymentDetailsByInvoiceID(invoice.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		details = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return sharedInvoiceView(invoice, details), nil
+}
+
+// GetSharedInvoicePDF renders the invoice behind a share link.
+func (s *service) GetSharedInvoicePDF(token string) (*response.InvoicePDF, error) {
+	invoice, err := s.openShareLink(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderInvoicePDF(invoice)
+}
+
+// openShareLink resolves a token to its invoice and records that the
+// customer looked at it, once per link every shareLinkViewWindow. A sent
+// invoice becomes viewed the first time.
+func (s *service) openShareLink(token string) (*models.Invoice, error) {
+	now := time.Now()
+
+	link, err := s.repo.GetShareLinkByTokenHash(hashShareToken(token))
+	if err != nil || !link.Active(now) {
+		return nil, ErrShareLinkNotFound
+	}
+	invoice, err := s.repo.GetInvoiceByID(link.InvoiceID)
+	if err != nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	// Failing to count a view should not keep the customer from the invoice
+	counted, err := s.repo.RecordShareLinkView(link.ID, now, now.Add(-shareLinkViewWindow))
+	if err != nil {
+		log.Printf("Failed to record a view of share link %s: %v", link.ID, err)
+	}
+	if !counted {
+		return invoice, nil
+	}
+
+	if normalizeStatus(invoice.Status) == models.InvoiceStatusSent {
+		// The invoice is locked and checked again so a payment recorded in
+		// the meantime is not overwritten with the row read above
+		err := s.repo.WithTx(func(repo repository.Repository) error {
+			locked, err := repo.LockInvoice(invoice.ID)
+			if err != nil {
+				return err
+			}
+			invoice = locked
+			if normalizeStatus(locked.Status) != models.InvoiceStatusSent {
+				return nil
+			}
+			if _, err := transitionInvoice(locked, models.InvoiceStatusViewed, now); err != nil {
+				return nil
+			}
+			return writeInvoice(repo, locked, nil, models.ActivityInvoiceViewed)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.logInvoiceEntityActivity(nil, invoice, models.ActivityInvoiceViewed, models.EntityShareLink, link.ID, models.ActivityMetadata{})
+
+	return invoice, nil
+}
+
+// sharedInvoiceView keeps only what the customer needs to see and pay.
+func sharedInvoiceView(invoice *models.Invoice, details *models.PaymentDetails) *response.SharedInvoice {
+	view := &response.SharedInvoice{
+		InvoiceNumber: invoice.InvoiceNumber,
+		Status:        invoice.Status,
+		IssueDate:     invoice.IssueDate,
+		DueDate:       invoice.DueDate,
+		Currency:      invoice.Currency,
+		From: response.SharedParty{
+			Name:    strings.TrimSpace(invoice.User.FirstName + " " + invoice.User.LastName),
+			Email:   invoice.User.Email,
+			Address: invoice.User.Address,
+		},
+		BillTo: response.SharedParty{
+			Name:      invoice.Customer.Name,
+			Address:   invoice.Customer.Address,
+			TaxNumber: invoice.Customer.TaxNumber,
+		},
+		Items:        make([]response.SharedItem, len(invoice.Items)),
+		SubTotal:     invoice.SubTotal,
+		Discount:     invoice.Discount,
+		Taxes:        make([]response.SharedTax, len(invoice.Taxes)),
+		TaxTotal:     invoice.TaxTotal,
+		TotalAmount:  invoice.TotalAmount,
+		LateFeeTotal: invoice.LateFeeTotal,
+		CreditTotal:  invoice.CreditTotal,
+		AmountPaid:   invoice.AmountPaid,
+		BalanceDue:   invoice.BalanceDue,
+		Note:         invoice.Note,
+	}
+
+	for i, item := range invoice.Items {
+		view.Items[i] = response.SharedItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		}
+	}
+	for i, tax := range invoice.Taxes {
+		view.Taxes[i] = response.SharedTax{Name: tax.Name, Rate: tax.Rate, Amount: tax.Amount}
+	}
+	for _, fee := range invoice.LateFees {
+		view.LateFees = append(view.LateFees, response.SharedLateFee{Description: fee.Description, Amount: fee.Amount})
+	}
+	if details != nil {
+		view.PaymentDetails = &response.SharedPaymentDetails{
+			AccountName:    details.AccountName,
+			AccountNumber:  details.AccountNumber,
+			BankName:       details.BankName,
+			BankAddress:    details.BankAddress,
+			RoutingNumber:  details.RoutingNumber,
+			PaymentDueDate: details.PaymentDueDate,
+		}
+	}
+
+	return view
+}
+
+// newShareToken returns 256 random bits, URL safe.
+func newShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
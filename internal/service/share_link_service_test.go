@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateShareLink(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	expires := time.Now().Add(7 * 24 * time.Hour)
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("CreateShareLink", mock.AnythingOfType("*models.ShareLink")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	result, err := svc.CreateShareLink(auth.Principal{UserID: userID}, inputs.CreateShareLinkInput{
+		InvoiceID: invoice.ID,
+		ExpiresAt: &expires,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Token, 43)
+	assert.Equal(t, "/api/shared/"+result.Token, result.Path)
+	// Only the hash of the token is stored
+	assert.Equal(t, hashToken(result.Token), result.Link.TokenHash)
+	assert.Equal(t, result.Token[:8], result.Link.TokenPrefix)
+	assert.Equal(t, &expires, result.Link.ExpiresAt)
+	mockRepo.AssertExpectations(t)
+
+	// Every link gets a fresh token
+	other, err := svc.CreateShareLink(auth.Principal{UserID: userID}, inputs.CreateShareLinkInput{InvoiceID: invoice.ID})
+	assert.NoError(t, err)
+	assert.NotEqual(t, result.Token, other.Token)
+}
+
+func TestCreateShareLink_Rejected(t *testing.T) {
+	userID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		status  string
+		actor   uuid.UUID
+		input   inputs.CreateShareLinkInput
+		wantErr string
+	}{
+		{"draft invoice", models.InvoiceStatusDraft, userID, inputs.CreateShareLinkInput{}, "draft invoices cannot be shared"},
+		{"expiry in the past", models.InvoiceStatusSent, userID, inputs.CreateShareLinkInput{ExpiresAt: &past}, "expiry must be in the future"},
+		{"other user's invoice", models.InvoiceStatusSent, uuid.New(), inputs.CreateShareLinkInput{}, "invoice not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			invoice := sentInvoice(userID, "100")
+			invoice.Status = tt.status
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+
+			input := tt.input
+			input.InvoiceID = invoice.ID
+			_, err := svc.CreateShareLink(auth.Principal{UserID: tt.actor}, input)
+
+			assert.EqualError(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "CreateShareLink", mock.Anything)
+		})
+	}
+}
+
+func TestGetShareLinks_OnlyActive(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	links := []models.ShareLink{
+		{ID: uuid.New(), TokenPrefix: "active"},
+		{ID: uuid.New(), TokenPrefix: "expiring", ExpiresAt: &future},
+		{ID: uuid.New(), TokenPrefix: "expired", ExpiresAt: &past},
+		{ID: uuid.New(), TokenPrefix: "revoked", RevokedAt: &past},
+	}
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetShareLinks", map[string]interface{}{"invoice_id": invoice.ID}).Return(links, nil)
+
+	result, err := svc.GetShareLinks(auth.Principal{UserID: userID}, invoice.ID)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, "active", result[0].TokenPrefix)
+		assert.Equal(t, "expiring", result[1].TokenPrefix)
+	}
+}
+
+func TestRevokeShareLink(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	invoice := sentInvoice(userID, "100")
+	link := models.ShareLink{ID: uuid.New(), InvoiceID: invoice.ID}
+
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("GetShareLinks", map[string]interface{}{"id": link.ID, "invoice_id": invoice.ID}).Return([]models.ShareLink{link}, nil)
+	mockRepo.On("RevokeShareLink", link.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
+
+	err := svc.RevokeShareLink(auth.Principal{UserID: userID}, invoice.ID, link.ID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSharedInvoice(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	token := "sVw0yQ3Xh1nT1p7yq2c9n3h8cEoZ7rRk3JkS0tWfQeY"
+	invoice := sentInvoice(uuid.New(), "100")
+	invoice.InvoiceNumber = "INV-2026-00007"
+	invoice.User = models.User{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "hash"}
+	invoice.Customer = models.Customer{Name: "Acme Ltd", Email: "billing@acme.example", Address: "1 Road"}
+	invoice.Items = []models.InvoiceItem{
+		{ID: uuid.New(), Description: "Design", Quantity: 1, UnitPrice: money.NewFromInt(100), Amount: money.NewFromInt(100)},
+	}
+	link := &models.ShareLink{ID: uuid.New(), InvoiceID: invoice.ID}
+
+	mockRepo.On("GetShareLinkByTokenHash", hashToken(token)).Return(link, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("RecordShareLinkView", link.ID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+	mockRepo.On("UpdateInvoice", invoice.ID, invoice).Return(nil)
+	expectTx(mockRepo)
+	expectRevision(mockRepo)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_VIEWED" && *log.InvoiceID == invoice.ID
+	})).Return(nil).Once()
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+
+	view, err := svc.GetSharedInvoice(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2026-00007", view.InvoiceNumber)
+	assert.Equal(t, "Ada Lovelace", view.From.Name)
+	assert.Equal(t, "Acme Ltd", view.BillTo.Name)
+	assert.Empty(t, view.BillTo.Email)
+	assert.Len(t, view.Items, 1)
+	assert.Nil(t, view.PaymentDetails)
+
+	// Opening the link marks a sent invoice as viewed
+	assert.Equal(t, models.InvoiceStatusViewed, invoice.Status)
+	assert.NotNil(t, invoice.ViewedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSharedInvoice_RepeatedView(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	token := "sVw0yQ3Xh1nT1p7yq2c9n3h8cEoZ7rRk3JkS0tWfQeY"
+	invoice := sentInvoice(uuid.New(), "100")
+	invoice.Status = models.InvoiceStatusViewed
+	link := &models.ShareLink{ID: uuid.New(), InvoiceID: invoice.ID}
+
+	// The link was already viewed within the last 30 minutes
+	mockRepo.On("GetShareLinkByTokenHash", hashToken(token)).Return(link, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("RecordShareLinkView", link.ID, mock.AnythingOfType("time.Time"), mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) >= 30*time.Minute && time.Since(since) < 31*time.Minute
+	})).Return(false, nil)
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+
+	view, err := svc.GetSharedInvoice(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.InvoiceStatusViewed, view.Status)
+	mockRepo.AssertNotCalled(t, "CreateActivityLog", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSharedInvoice_PaidMeanwhile(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	token := "sVw0yQ3Xh1nT1p7yq2c9n3h8cEoZ7rRk3JkS0tWfQeY"
+	invoice := sentInvoice(uuid.New(), "100")
+	link := &models.ShareLink{ID: uuid.New(), InvoiceID: invoice.ID}
+	// A payment settled the invoice after it was read
+	paid := *invoice
+	paid.Status = models.InvoiceStatusPaid
+	paid.BalanceDue = money.Zero
+
+	mockRepo.On("GetShareLinkByTokenHash", hashToken(token)).Return(link, nil)
+	mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+	mockRepo.On("RecordShareLinkView", link.ID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	expectTx(mockRepo)
+	mockRepo.On("LockInvoice", invoice.ID).Return(&paid, nil)
+	mockRepo.On("CreateActivityLog", mock.MatchedBy(func(log *models.ActivityLog) bool {
+		return log.Action == "INVOICE_VIEWED"
+	})).Return(nil).Once()
+	mockRepo.On("GetPaymentDetailsByInvoiceID", invoice.ID).Return(nil, gorm.ErrRecordNotFound)
+
+	view, err := svc.GetSharedInvoice(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.InvoiceStatusPaid, view.Status)
+	mockRepo.AssertNotCalled(t, "UpdateInvoice", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSharedInvoice_Unusable(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		link *models.ShareLink
+		err  error
+	}{
+		{"unknown token", nil, gorm.ErrRecordNotFound},
+		{"expired", &models.ShareLink{ID: uuid.New(), ExpiresAt: &past}, nil},
+		{"revoked", &models.ShareLink{ID: uuid.New(), RevokedAt: &past}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			mockRepo.On("GetShareLinkByTokenHash", mock.Anything).Return(tt.link, tt.err)
+
+			_, err := svc.GetSharedInvoice("some-token")
+
+			assert.ErrorIs(t, err, service.ErrShareLinkNotFound)
+			mockRepo.AssertNotCalled(t, "GetInvoiceByID", mock.Anything)
+			mockRepo.AssertNotCalled(t, "RecordShareLinkView", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/models"
+)
+
+var ErrTaxRateNotFound = errors.New("tax rate not found")
+
+func (s *service) CreateTaxRate(principal auth.Principal, input inputs.CreateTaxRateInput) (*models.TaxRate, error) {
+	rate := &models.TaxRate{
+		ID:       uuid.New(),
+		UserID:   principal.UserID,
+		Name:     strings.TrimSpace(input.Name),
+		Rate:     input.Rate,
+		Compound: input.Compound,
+		Active:   true,
+	}
+
+	if err := validateTaxRate(rate); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.CreateTaxRate(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *service) GetTaxRates(principal auth.Principal) ([]models.TaxRate, error) {
+	return s.repo.GetTaxRates(map[string]interface{}{
+		"user_id": principal.UserID,
+	})
+}
+
+func (s *service) GetTaxRate(principal auth.Principal, id uuid.UUID) (*models.TaxRate, error) {
+	return s.getOwnedTaxRate(principal.UserID, id)
+}
+
+func (s *service) UpdateTaxRate(principal auth.Principal, id uuid.UUID, updates map[string]interface{}) error {
+	rate, err := s.getOwnedTaxRate(principal.UserID, id)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		switch key {
+		case "name":
+			name, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			rate.Name = strings.TrimSpace(name)
+		case "rate":
+			r, err := decimalValue(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			rate.Rate = r
+		case "compound", "active":
+			flag, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("invalid value for %s", key)
+			}
+			if key == "compound" {
+				rate.Compound = flag
+			} else {
+				rate.Active = flag
+			}
+		}
+	}
+
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+
+	// Invoices keep their own copy of the rate, so editing it only affects
+	// lines taxed from now on.
+	return s.repo.UpdateTaxRate(id, rate)
+}
+
+func (s *service) DeleteTaxRate(principal auth.Principal, id uuid.UUID) error {
+	if _, err := s.getOwnedTaxRate(principal.UserID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteTaxRate(id)
+}
+
+func (s *service) getOwnedTaxRate(userID, id uuid.UUID) (*models.TaxRate, error) {
+	rate, err := s.repo.GetTaxRateByID(id)
+	if err != nil || rate.UserID != userID {
+		return nil, ErrTaxRateNotFound
+	}
+	return rate, nil
+}
+
+// resolveItemTaxes looks up the tax rates named on each item and returns the
+// snapshots to store on the lines, in the order the client listed them.
+func (s *service) resolveItemTaxes(userID uuid.UUID, items []inputs.CreateInvoiceItemInput) ([][]models.InvoiceItemTax, error) {
+	taxes := make([][]models.InvoiceItemTax, len(items))
+
+	needed := false
+	for _, item := range items {
+		if len(item.TaxRateIDs) > 0 {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return taxes, nil
+	}
+
+	rates, err := s.repo.GetTaxRates(map[string]interface{}{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.TaxRate, len(rates))
+	for _, rate := range rates {
+		byID[rate.ID] = rate
+	}
+
+	for i, item := range items {
+		seen := make(map[uuid.UUID]bool)
+		for position, id := range item.TaxRateIDs {
+			rate, ok := byID[id]
+			if !ok || !rate.Active {
+				return nil, fmt.Errorf("item %d: unknown tax rate %s", i+1, id)
+			}
+			if seen[id] {
+				return nil, fmt.Errorf("item %d: tax rate %s is listed twice", i+1, rate.Name)
+			}
+			seen[id] = true
+			taxes[i] = append(taxes[i], models.InvoiceItemTax{
+				TaxRateID: rate.ID,
+				Name:      rate.Name,
+				Rate:      rate.Rate,
+				Compound:  rate.Compound,
+				Position:  position,
+			})
+		}
+	}
+
+	return taxes, nil
+}
+
+func validateTaxRate(rate *models.TaxRate) error {
+	if rate.Name == "" {
+		return errors.New("tax rate name is required")
+	}
+	if rate.Rate.IsNegative() || rate.Rate.GreaterThan(hundred) {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+	return nil
+}
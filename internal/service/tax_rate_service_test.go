@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTaxRate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	input := inputs.CreateTaxRateInput{
+		Name: "VAT 20%",
+		Rate: money.NewFromInt(20),
+	}
+
+	mockRepo.On("CreateTaxRate", mock.AnythingOfType("*models.TaxRate")).Return(nil)
+
+	rate, err := svc.CreateTaxRate(auth.Principal{UserID: userID}, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, rate.UserID)
+	assert.True(t, rate.Active)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTaxRate_Invalid(t *testing.T) {
+	tests := []inputs.CreateTaxRateInput{
+		{Name: "", Rate: money.NewFromInt(5)},
+		{Name: "Negative", Rate: money.NewFromInt(-1)},
+		{Name: "Too high", Rate: money.NewFromInt(101)},
+	}
+
+	for _, input := range tests {
+		mockRepo := new(mocks.Repository)
+		svc := service.NewService(mockRepo)
+
+		rate, err := svc.CreateTaxRate(auth.Principal{UserID: uuid.New()}, input)
+
+		assert.Error(t, err)
+		assert.Nil(t, rate)
+		mockRepo.AssertNotCalled(t, "CreateTaxRate", mock.Anything)
+	}
+}
+
+func TestUpdateTaxRate(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	userID := uuid.New()
+	id := uuid.New()
+	existing := &models.TaxRate{ID: id, UserID: userID, Name: "PST", Rate: money.NewFromInt(7), Active: true}
+
+	mockRepo.On("GetTaxRateByID", id).Return(existing, nil)
+	mockRepo.On("UpdateTaxRate", id, mock.MatchedBy(func(r *models.TaxRate) bool {
+		return r.Rate.Equal(money.MustParse("9.975")) && r.Compound && !r.Active
+	})).Return(nil)
+
+	err := svc.UpdateTaxRate(auth.Principal{UserID: userID}, id, map[string]interface{}{
+		"rate":     9.975,
+		"compound": true,
+		"active":   false,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaxRate_OtherUser(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	id := uuid.New()
+	mockRepo.On("GetTaxRateByID", id).Return(&models.TaxRate{ID: id, UserID: uuid.New()}, nil)
+
+	rate, err := svc.GetTaxRate(auth.Principal{UserID: uuid.New()}, id)
+
+	assert.ErrorIs(t, err, service.ErrTaxRateNotFound)
+	assert.Nil(t, rate)
+}
+
+func TestDeleteTaxRate_NotFound(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	id := uuid.New()
+	mockRepo.On("GetTaxRateByID", id).Return(nil, errors.New("not found"))
+
+	err := svc.DeleteTaxRate(auth.Principal{UserID: uuid.New()}, id)
+
+	assert.ErrorIs(t, err, service.ErrTaxRateNotFound)
+	mockRepo.AssertNotCalled(t, "DeleteTaxRate", mock.Anything)
+}
@@ -0,0 +1,243 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
+	"github.com/iyiola-dev/numeris/internal/inputs"
+	"github.com/iyiola-dev/numeris/internal/mocks"
+	"github.com/iyiola-dev/numeris/internal/models"
+	"github.com/iyiola-dev/numeris/internal/money"
+	"github.com/iyiola-dev/numeris/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestTenantIsolation calls every owner-scoped method as a user who does not
+// own the resource. Each must answer as if the resource did not exist and
+// must not write anything.
+func TestTenantIsolation(t *testing.T) {
+	owner := uuid.New()
+	intruder := auth.Principal{UserID: uuid.New()}
+
+	invoice := sentInvoice(owner, "100")
+	customer := &models.Customer{ID: uuid.New(), UserID: owner}
+	rate := &models.TaxRate{ID: uuid.New(), UserID: owner}
+	recurring := &models.RecurringInvoice{ID: uuid.New(), UserID: owner}
+	quote := &models.Quote{ID: uuid.New(), UserID: owner}
+	note := &models.CreditNote{ID: uuid.New(), UserID: owner, InvoiceID: invoice.ID}
+	updates := map[string]interface{}{"name": "Taken over"}
+
+	tests := []struct {
+		name    string
+		call    func(svc service.Service) error
+		wantErr error
+	}{
+		// Invoices
+		{"GetInvoice", func(svc service.Service) error {
+			_, err := svc.GetInvoice(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"UpdateInvoice", func(svc service.Service) error {
+			text := "mine now"
+			_, err := svc.UpdateInvoice(intruder, invoice.ID, inputs.UpdateInvoiceInput{Note: &text})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"DeleteInvoice", func(svc service.Service) error {
+			return svc.DeleteInvoice(intruder, invoice.ID)
+		}, service.ErrInvoiceNotFound},
+		{"VoidInvoice", func(svc service.Service) error {
+			_, err := svc.VoidInvoice(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetInvoiceRevisions", func(svc service.Service) error {
+			_, err := svc.GetInvoiceRevisions(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetInvoicePDF", func(svc service.Service) error {
+			_, err := svc.GetInvoicePDF(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetInvoiceDeliveries", func(svc service.Service) error {
+			_, err := svc.GetInvoiceDeliveries(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+
+		// Customers
+		{"GetCustomer", func(svc service.Service) error {
+			_, err := svc.GetCustomer(intruder, customer.ID)
+			return err
+		}, service.ErrCustomerNotFound},
+		{"UpdateCustomer", func(svc service.Service) error {
+			return svc.UpdateCustomer(intruder, customer.ID, updates)
+		}, service.ErrCustomerNotFound},
+		{"DeleteCustomer", func(svc service.Service) error {
+			return svc.DeleteCustomer(intruder, customer.ID)
+		}, service.ErrCustomerNotFound},
+
+		// Tax rates
+		{"GetTaxRate", func(svc service.Service) error {
+			_, err := svc.GetTaxRate(intruder, rate.ID)
+			return err
+		}, service.ErrTaxRateNotFound},
+		{"UpdateTaxRate", func(svc service.Service) error {
+			return svc.UpdateTaxRate(intruder, rate.ID, updates)
+		}, service.ErrTaxRateNotFound},
+		{"DeleteTaxRate", func(svc service.Service) error {
+			return svc.DeleteTaxRate(intruder, rate.ID)
+		}, service.ErrTaxRateNotFound},
+
+		// Payments
+		{"RecordPayment", func(svc service.Service) error {
+			_, err := svc.RecordPayment(intruder, inputs.RecordPaymentInput{InvoiceID: invoice.ID, Amount: money.NewFromInt(10)})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetPayments", func(svc service.Service) error {
+			_, err := svc.GetPayments(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"RefundPayment", func(svc service.Service) error {
+			_, err := svc.RefundPayment(intruder, inputs.RefundPaymentInput{InvoiceID: invoice.ID, PaymentID: uuid.New(), Amount: money.NewFromInt(10)})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"DeletePayment", func(svc service.Service) error {
+			return svc.DeletePayment(intruder, invoice.ID, uuid.New())
+		}, service.ErrInvoiceNotFound},
+
+		// Payment details
+		{"CreatePaymentDetails", func(svc service.Service) error {
+			_, err := svc.CreatePaymentDetails(intruder, inputs.CreatePaymentDetailsInput{InvoiceID: invoice.ID, AccountName: "Intruder"})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetPaymentDetails", func(svc service.Service) error {
+			_, err := svc.GetPaymentDetails(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"UpdatePaymentDetails", func(svc service.Service) error {
+			return svc.UpdatePaymentDetails(intruder, invoice.ID, updates)
+		}, service.ErrInvoiceNotFound},
+		{"DeletePaymentDetails", func(svc service.Service) error {
+			return svc.DeletePaymentDetails(intruder, invoice.ID)
+		}, service.ErrInvoiceNotFound},
+
+		// Share links
+		{"CreateShareLink", func(svc service.Service) error {
+			_, err := svc.CreateShareLink(intruder, inputs.CreateShareLinkInput{InvoiceID: invoice.ID})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetShareLinks", func(svc service.Service) error {
+			_, err := svc.GetShareLinks(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"RevokeShareLink", func(svc service.Service) error {
+			return svc.RevokeShareLink(intruder, invoice.ID, uuid.New())
+		}, service.ErrInvoiceNotFound},
+
+		// Late fees
+		{"GetInvoiceLateFeePolicy", func(svc service.Service) error {
+			_, err := svc.GetInvoiceLateFeePolicy(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetLateFees", func(svc service.Service) error {
+			_, err := svc.GetLateFees(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"ReverseLateFee", func(svc service.Service) error {
+			_, err := svc.ReverseLateFee(intruder, inputs.ReverseLateFeeInput{InvoiceID: invoice.ID, LateFeeID: uuid.New()})
+			return err
+		}, service.ErrInvoiceNotFound},
+
+		// Credit notes
+		{"CreateCreditNote", func(svc service.Service) error {
+			_, err := svc.CreateCreditNote(intruder, inputs.CreateCreditNoteInput{InvoiceID: invoice.ID, Reason: "Refund"})
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetInvoiceCreditNotes", func(svc service.Service) error {
+			_, err := svc.GetInvoiceCreditNotes(intruder, invoice.ID)
+			return err
+		}, service.ErrInvoiceNotFound},
+		{"GetCreditNote", func(svc service.Service) error {
+			_, err := svc.GetCreditNote(intruder, note.ID)
+			return err
+		}, service.ErrCreditNoteNotFound},
+		{"GetCreditNotePDF", func(svc service.Service) error {
+			_, err := svc.GetCreditNotePDF(intruder, note.ID)
+			return err
+		}, service.ErrCreditNoteNotFound},
+
+		// Recurring invoices
+		{"GetRecurringInvoice", func(svc service.Service) error {
+			_, err := svc.GetRecurringInvoice(intruder, recurring.ID)
+			return err
+		}, service.ErrRecurringInvoiceNotFound},
+		{"UpdateRecurringInvoice", func(svc service.Service) error {
+			return svc.UpdateRecurringInvoice(intruder, recurring.ID, updates)
+		}, service.ErrRecurringInvoiceNotFound},
+		{"DeleteRecurringInvoice", func(svc service.Service) error {
+			return svc.DeleteRecurringInvoice(intruder, recurring.ID)
+		}, service.ErrRecurringInvoiceNotFound},
+
+		// Quotes
+		{"GetQuote", func(svc service.Service) error {
+			_, err := svc.GetQuote(intruder, quote.ID)
+			return err
+		}, service.ErrQuoteNotFound},
+		{"UpdateQuote", func(svc service.Service) error {
+			return svc.UpdateQuote(intruder, quote.ID, updates)
+		}, service.ErrQuoteNotFound},
+		{"DeleteQuote", func(svc service.Service) error {
+			return svc.DeleteQuote(intruder, quote.ID)
+		}, service.ErrQuoteNotFound},
+		{"SendQuote", func(svc service.Service) error {
+			_, err := svc.SendQuote(intruder, quote.ID)
+			return err
+		}, service.ErrQuoteNotFound},
+		{"ConvertQuote", func(svc service.Service) error {
+			_, err := svc.ConvertQuote(intruder, quote.ID)
+			return err
+		}, service.ErrQuoteNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.Repository)
+			svc := service.NewService(mockRepo)
+
+			expectTx(mockRepo)
+			mockRepo.On("GetInvoiceByID", invoice.ID).Return(invoice, nil)
+			mockRepo.On("LockInvoice", invoice.ID).Return(invoice, nil)
+			mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+			mockRepo.On("GetTaxRateByID", rate.ID).Return(rate, nil)
+			mockRepo.On("GetRecurringInvoiceByID", recurring.ID).Return(recurring, nil)
+			mockRepo.On("GetQuoteByID", quote.ID).Return(quote, nil)
+			mockRepo.On("GetCreditNoteByID", note.ID).Return(note, nil)
+
+			err := tt.call(svc)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			// Only reads, or a transaction that locks the invoice and gives up
+			for _, call := range mockRepo.Calls {
+				assert.Contains(t, []string{"WithTx", "GetInvoiceByID", "LockInvoice", "GetCustomerByID", "GetTaxRateByID", "GetRecurringInvoiceByID", "GetQuoteByID", "GetCreditNoteByID"}, call.Method)
+			}
+		})
+	}
+}
+
+func TestCreateInvoice_OtherUsersCustomer(t *testing.T) {
+	mockRepo := new(mocks.Repository)
+	svc := service.NewService(mockRepo)
+
+	customer := &models.Customer{ID: uuid.New(), UserID: uuid.New()}
+	mockRepo.On("GetCustomerByID", customer.ID).Return(customer, nil)
+
+	invoice, err := svc.CreateInvoice(auth.Principal{UserID: uuid.New()}, inputs.CreateInvoiceInput{
+		CustomerID: customer.ID,
+		Currency:   "USD",
+		Items:      []inputs.CreateInvoiceItemInput{{Description: "Item", Quantity: 1, UnitPrice: money.NewFromInt(10)}},
+	})
+
+	assert.EqualError(t, err, "invalid customer")
+	assert.Nil(t, invoice)
+	mockRepo.AssertNotCalled(t, "WithTx", mock.Anything)
+}
@@ -3,8 +3,10 @@ package service_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/inputs"
 	"github.com/iyiola-dev/numeris/internal/mocks"
 	"github.com/iyiola-dev/numeris/internal/models"
@@ -26,6 +28,7 @@ func TestRegister(t *testing.T) {
 	}
 
 	// Set up expectations
+	mockRepo.On("GetUsers", map[string]interface{}{"email": input.Email}).Return([]models.User{}, nil)
 	mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(nil)
 
 	// Execute
@@ -55,6 +58,7 @@ func TestRegister_CreateUserError(t *testing.T) {
 		Password:  "password123",
 	}
 
+	mockRepo.On("GetUsers", map[string]interface{}{"email": input.Email}).Return([]models.User{}, nil)
 	mockRepo.On("CreateUser", mock.AnythingOfType("*models.User")).Return(errors.New("duplicate email"))
 
 	user, err := svc.Register(input)
@@ -64,9 +68,25 @@ func TestRegister_CreateUserError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func testTokens(t *testing.T) *auth.Tokens {
+	tokens, err := auth.NewTokens(auth.Config{
+		Issuer:       "numeris",
+		Audience:     "numeris-api",
+		TTL:          time.Hour,
+		RefreshTTL:   24 * time.Hour,
+		SigningKeyID: "test",
+		Keys:         []auth.Key{{ID: "test", Algorithm: auth.AlgHS256, Secret: []byte("0123456789abcdef0123456789abcdef")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
 func TestLogin(t *testing.T) {
 	mockRepo := new(mocks.Repository)
-	svc := service.NewService(mockRepo)
+	tokens := testTokens(t)
+	svc := service.NewService(mockRepo, service.WithTokens(tokens))
 
 	password := "password123"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -75,21 +95,47 @@ func TestLogin(t *testing.T) {
 		ID:       uuid.New(),
 		Email:    "test@example.com",
 		Password: string(hashedPassword),
+		Active:   true,
 	}
 
 	input := inputs.LoginInput{
-		Email:    "test@example.com",
-		Password: password,
+		Email:     "test@example.com",
+		Password:  password,
+		IPAddress: "203.0.113.7",
+		UserAgent: "test-agent",
 	}
 
+	var session *models.Session
+	var refresh *models.RefreshToken
 	mockRepo.On("GetUsers", map[string]interface{}{"email": input.Email}).Return([]models.User{*existingUser}, nil)
+	expectTx(mockRepo)
+	mockRepo.On("CreateSession", mock.AnythingOfType("*models.Session")).Run(func(args mock.Arguments) {
+		session = args.Get(0).(*models.Session)
+	}).Return(nil)
+	mockRepo.On("CreateRefreshToken", mock.AnythingOfType("*models.RefreshToken")).Run(func(args mock.Arguments) {
+		refresh = args.Get(0).(*models.RefreshToken)
+	}).Return(nil)
+	mockRepo.On("CreateActivityLog", mock.AnythingOfType("*models.ActivityLog")).Return(nil)
 
 	resp, err := svc.Login(input)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.NotEmpty(t, resp.Token)
 	assert.Equal(t, existingUser.ID, resp.User.ID)
+	claims, err := tokens.Verify(resp.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, existingUser.ID.String(), claims.Subject)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), resp.ExpiresAt, time.Minute)
+
+	// The login opens a session that the tokens belong to
+	assert.Equal(t, existingUser.ID, session.UserID)
+	assert.Equal(t, "203.0.113.7", session.IPAddress)
+	assert.Equal(t, "test-agent", session.UserAgent)
+	assert.Equal(t, session.ID.String(), claims.SessionID)
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.NotEqual(t, resp.RefreshToken, refresh.TokenHash, "only the hash is stored")
+	assert.Equal(t, session.ID, refresh.SessionID)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), resp.RefreshExpiresAt, time.Minute)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -1,40 +1,20 @@
 package util
 
 import (
-	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/iyiola-dev/numeris/internal/auth"
 	"github.com/iyiola-dev/numeris/internal/repository"
 )
 
-var (
-	secretKey = []byte("your-secret-key") // In production, use environment variable
-)
-
-type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	jwt.RegisteredClaims
-}
-
-func GenerateToken(userID uuid.UUID) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
-}
-
-func AuthMiddleware(repo repository.Repository) gin.HandlerFunc {
+// AuthMiddleware lets through requests that carry a valid access token for
+// an active user in an open session, and places their principal on the
+// request context.
+func AuthMiddleware(repo repository.Repository, tokens *auth.Tokens) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -50,24 +30,35 @@ func AuthMiddleware(repo repository.Repository) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := bearerToken[1]
-		claims := &Claims{}
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return secretKey, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := tokens.Verify(bearerToken[1])
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+		userID, err := uuid.Parse(claims.Subject)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
+		sessionID, err := uuid.Parse(claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// A token stops working as soon as its session is logged out
+		session, err := repo.GetSessionByID(sessionID)
+		if err != nil || session.UserID != userID || !session.Active(time.Now()) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked or has expired"})
+			c.Abort()
+			return
+		}
 
 		// Get user from database
-		user, err := repo.GetUserByID(claims.UserID)
+		user, err := repo.GetUserByID(userID)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
@@ -81,8 +72,19 @@ func AuthMiddleware(repo repository.Repository) gin.HandlerFunc {
 			return
 		}
 
-		// Set user in context
-		c.Set("user", user)
+		// Handlers read who is calling from the request context
+		principal := auth.Principal{
+			UserID:     user.ID,
+			OrgID:      user.ID,
+			Roles:      []auth.Role{auth.RoleOwner},
+			TokenID:    claims.ID,
+			SessionID:  session.ID,
+			AuthMethod: auth.MethodJWT,
+			IPAddress:  c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			RequestID:  c.GetString(RequestIDKey),
+		}
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
 		c.Next()
 	}
 }
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader carries the ID of a request in and out of the API
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is where RequestIDMiddleware stores the ID on the context
+	RequestIDKey = "requestID"
+)
+
+// RequestIDMiddleware gives every request an ID, keeping one the caller sent
+// so that a request can be traced across services, and echoes it back.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}